@@ -93,6 +93,7 @@ type RootItem struct {
 	Decl      *Decl      `parser:"  | @@ LF?  " json:"decl,omitempty"`
 	Func      *Func      `parser:"  | @@ LF?  " json:"func,omitempty"`
 	Type      *Type      `parser:"  | @@ LF?  " json:"type,omitempty"`
+	If        *IfBlock   `parser:"  | @@ LF?  " json:"if,omitempty"`
 	Block     *Block     `parser:"  | @@ LF?  " json:"block,omitempty"`
 	Attribute *Attribute `parser:"  | @@ LF?  " json:"attribute,omitempty"`
 	Comment   *Comment   `parser:"  | @@     )" json:"comment,omitempty"`
@@ -108,6 +109,7 @@ func (n *RootItem) Clone() *RootItem {
 		Decl:      n.Decl.Clone(),
 		Func:      n.Func.Clone(),
 		Type:      n.Type.Clone(),
+		If:        n.If.Clone(),
 		Block:     n.Block.Clone(),
 		Attribute: n.Attribute.Clone(),
 		Comment:   n.Comment.Clone(),
@@ -125,6 +127,8 @@ func (n *RootItem) Children() (children []Node) {
 		children = append(children, n.Func)
 	case n.Type != nil:
 		children = append(children, n.Type)
+	case n.If != nil:
+		children = append(children, n.If)
 	case n.Block != nil:
 		children = append(children, n.Block)
 	case n.Comment != nil:
@@ -143,6 +147,8 @@ func (n RootItem) FormattedString() string {
 		return n.Func.FormattedString()
 	case n.Type != nil:
 		return n.Type.FormattedString()
+	case n.If != nil:
+		return n.If.FormattedString()
 	case n.Block != nil:
 		return n.Block.FormattedString()
 	case n.Attribute != nil:
@@ -158,11 +164,20 @@ func (n RootItem) FormattedString() string {
 
 // /////////////////////////////////////
 
+// ParameterType is a type expression: a named type, with type arguments for
+// collections like list(string), a tuple([...]) or optional(...) type, or a
+// function signature. Types separated by '|' form a union.
 type ParameterType struct {
 	ASTNode
 
-	Ident *Ident         `parser:"(   @@   " json:"ident"`
-	Func  *FuncSignature `parser:"  | @@  )" json:"func"`
+	Tuple    bool             `parser:"(   @'tuple' '(' '['         " json:"tuple,omitempty"`
+	Elems    []*ParameterType `parser:"    [ @@ (',' @@)* ] ']' ')' " json:"elems,omitempty"`
+	Optional *ParameterType   `parser:"  | 'optional' '(' @@        " json:"optional,omitempty"`
+	Default  *Expr            `parser:"    [ ',' @@ ] ')'           " json:"default,omitempty"`
+	Ident    *Ident           `parser:"  | @@                       " json:"ident"`
+	Args     []*ParameterType `parser:"    [ '(' @@ (',' @@)* ')' ] " json:"args,omitempty"`
+	Func     *FuncSignature   `parser:"  | @@ )                     " json:"func"`
+	Or       *ParameterType   `parser:"[ '|' @@ ]                   " json:"or,omitempty"`
 }
 
 func (n *ParameterType) Clone() *ParameterType {
@@ -171,33 +186,90 @@ func (n *ParameterType) Clone() *ParameterType {
 	}
 
 	return &ParameterType{
-		ASTNode: n.ASTNode.Clone(),
-		Ident:   n.Ident.Clone(),
-		Func:    n.Func.Clone(),
+		ASTNode:  n.ASTNode.Clone(),
+		Tuple:    n.Tuple,
+		Elems:    cloneCollection(n.Elems),
+		Optional: n.Optional.Clone(),
+		Default:  n.Default.Clone(),
+		Ident:    n.Ident.Clone(),
+		Args:     cloneCollection(n.Args),
+		Func:     n.Func.Clone(),
+		Or:       n.Or.Clone(),
 	}
 }
 
 func (n *ParameterType) Children() (children []Node) {
+	for _, item := range n.Elems {
+		children = append(children, item)
+	}
+
+	if n.Optional != nil {
+		children = append(children, n.Optional)
+	}
+
+	if n.Default != nil {
+		children = append(children, n.Default)
+	}
+
 	if n.Ident != nil {
 		children = append(children, n.Ident)
 	}
 
+	for _, item := range n.Args {
+		children = append(children, item)
+	}
+
 	if n.Func != nil {
 		children = append(children, n.Func)
 	}
 
+	if n.Or != nil {
+		children = append(children, n.Or)
+	}
+
 	return
 }
 
 func (n ParameterType) FormattedString() string {
+	var sb strings.Builder
+
 	switch {
+	case n.Tuple:
+		mustFprintf(&sb, "tuple([%s])", formatTypes(n.Elems))
+	case n.Optional != nil:
+		mustFprintf(&sb, "optional(%s", n.Optional.FormattedString())
+
+		if n.Default != nil {
+			mustFprintf(&sb, ", %s", n.Default.FormattedString())
+		}
+
+		sb.WriteString(")")
 	case n.Ident != nil:
-		return n.Ident.FormattedString()
+		sb.WriteString(n.Ident.FormattedString())
+
+		if len(n.Args) != 0 {
+			mustFprintf(&sb, "(%s)", formatTypes(n.Args))
+		}
 	case n.Func != nil:
-		return n.Func.FormattedString()
+		sb.WriteString(n.Func.FormattedString())
 	default:
 		panic(repr.String(n, repr.Hide(Position{})))
 	}
+
+	if n.Or != nil {
+		mustFprintf(&sb, " | %s", n.Or.FormattedString())
+	}
+
+	return sb.String()
+}
+
+func formatTypes(types []*ParameterType) string {
+	items := make([]string, 0, len(types))
+	for _, item := range types {
+		items = append(items, item.FormattedString())
+	}
+
+	return strings.Join(items, ", ")
 }
 
 // /////////////////////////////////////
@@ -479,6 +479,21 @@ func TestRootItem_Parsing(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "If",
+			input:   "if foo {}",
+			wantErr: false,
+			want: &RootItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				If: &IfBlock{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Condition: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 3, Line: 1, Column: 4}},
+						Parts:   []string{"foo"},
+					}),
+				},
+			},
+		},
 		{
 			name:    "Attribute",
 			input:   "foo = 1",
@@ -593,6 +608,15 @@ func TestRootItem_Clone(t *testing.T) {
 				Block: &Block{},
 			},
 		},
+		{
+			name: "If",
+			input: &RootItem{
+				If: &IfBlock{},
+			},
+			want: &RootItem{
+				If: &IfBlock{},
+			},
+		},
 		{
 			name: "Attribute",
 			input: &RootItem{
@@ -684,6 +708,15 @@ func TestRootItem_Children(t *testing.T) {
 				&Block{},
 			},
 		},
+		{
+			name: "If",
+			input: &RootItem{
+				If: &IfBlock{},
+			},
+			want: []Node{
+				&IfBlock{},
+			},
+		},
 		{
 			name: "Attribute",
 			input: &RootItem{
@@ -775,6 +808,15 @@ func TestRootItem_FormattedString(t *testing.T) {
 			},
 			want: "foo {}",
 		},
+		{
+			name: "If",
+			input: &RootItem{
+				If: &IfBlock{
+					Condition: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"foo"}}),
+				},
+			},
+			want: "if foo {}",
+		},
 		{
 			name: "Attribute",
 			input: &RootItem{
@@ -849,6 +891,93 @@ func TestParameterType_Parsing(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "Type arguments",
+			input:   "list(string)",
+			wantErr: false,
+			want: &ParameterType{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Ident: &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Parts:   []string{"list"},
+				},
+				Args: []*ParameterType{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+						Ident: &Ident{
+							ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+							Parts:   []string{"string"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "Tuple",
+			input:   "tuple([bool])",
+			wantErr: false,
+			want: &ParameterType{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Tuple:   true,
+				Elems: []*ParameterType{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+						Ident: &Ident{
+							ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+							Parts:   []string{"bool"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "Empty tuple",
+			input:   "tuple([])",
+			wantErr: false,
+			want: &ParameterType{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Tuple:   true,
+			},
+		},
+		{
+			name:    "Optional",
+			input:   "optional(bool)",
+			wantErr: false,
+			want: &ParameterType{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Optional: &ParameterType{
+					ASTNode: ASTNode{Pos: Position{Offset: 9, Line: 1, Column: 10}},
+					Ident: &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 9, Line: 1, Column: 10}},
+						Parts:   []string{"bool"},
+					},
+				},
+			},
+		},
+		{
+			name:    "Union",
+			input:   "foo | bar",
+			wantErr: false,
+			want: &ParameterType{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Ident: &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Parts:   []string{"foo"},
+				},
+				Or: &ParameterType{
+					ASTNode: ASTNode{Pos: Position{Offset: 6, Line: 1, Column: 7}},
+					Ident: &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 6, Line: 1, Column: 7}},
+						Parts:   []string{"bar"},
+					},
+				},
+			},
+		},
+		{
+			name:    "Missing type argument",
+			input:   "list()",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -903,6 +1032,31 @@ func TestParameterType_Clone(t *testing.T) {
 				Func: &FuncSignature{Return: ParameterType{Ident: &Ident{Parts: []string{"foo"}}}},
 			},
 		},
+		{
+			name: "Composite",
+			input: &ParameterType{
+				Tuple: true,
+				Elems: []*ParameterType{
+					{Ident: &Ident{Parts: []string{"list"}}, Args: []*ParameterType{{Ident: &Ident{Parts: []string{"foo"}}}}},
+					{
+						Optional: &ParameterType{Ident: &Ident{Parts: []string{"bar"}}},
+						Default:  BuildTestExprTree[*Expr](t, &Value{Null: true}),
+					},
+				},
+				Or: &ParameterType{Ident: &Ident{Parts: []string{"baz"}}},
+			},
+			want: &ParameterType{
+				Tuple: true,
+				Elems: []*ParameterType{
+					{Ident: &Ident{Parts: []string{"list"}}, Args: []*ParameterType{{Ident: &Ident{Parts: []string{"foo"}}}}},
+					{
+						Optional: &ParameterType{Ident: &Ident{Parts: []string{"bar"}}},
+						Default:  BuildTestExprTree[*Expr](t, &Value{Null: true}),
+					},
+				},
+				Or: &ParameterType{Ident: &Ident{Parts: []string{"baz"}}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -943,6 +1097,41 @@ func TestParameterType_Children(t *testing.T) {
 				&FuncSignature{Return: ParameterType{Ident: &Ident{Parts: []string{"foo"}}}},
 			},
 		},
+		{
+			name: "Type arguments",
+			input: &ParameterType{
+				Ident: &Ident{Parts: []string{"map"}},
+				Args:  []*ParameterType{{Ident: &Ident{Parts: []string{"foo"}}}},
+				Or:    &ParameterType{Ident: &Ident{Parts: []string{"bar"}}},
+			},
+			want: []Node{
+				&Ident{Parts: []string{"map"}},
+				&ParameterType{Ident: &Ident{Parts: []string{"foo"}}},
+				&ParameterType{Ident: &Ident{Parts: []string{"bar"}}},
+			},
+		},
+		{
+			name: "Tuple",
+			input: &ParameterType{
+				Tuple: true,
+				Elems: []*ParameterType{{Ident: &Ident{Parts: []string{"foo"}}}, {Ident: &Ident{Parts: []string{"bar"}}}},
+			},
+			want: []Node{
+				&ParameterType{Ident: &Ident{Parts: []string{"foo"}}},
+				&ParameterType{Ident: &Ident{Parts: []string{"bar"}}},
+			},
+		},
+		{
+			name: "Optional",
+			input: &ParameterType{
+				Optional: &ParameterType{Ident: &Ident{Parts: []string{"foo"}}},
+				Default:  BuildTestExprTree[*Expr](t, &Value{Null: true}),
+			},
+			want: []Node{
+				&ParameterType{Ident: &Ident{Parts: []string{"foo"}}},
+				BuildTestExprTree[*Expr](t, &Value{Null: true}),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -985,6 +1174,55 @@ func TestParameterType_FormattedString(t *testing.T) {
 			},
 			want: FuncSignature{Return: ParameterType{Ident: &Ident{Parts: []string{"foo"}}}}.FormattedString(),
 		},
+		{
+			name: "Type arguments",
+			input: &ParameterType{
+				Ident: &Ident{Parts: []string{"map"}},
+				Args: []*ParameterType{
+					{Ident: &Ident{Parts: []string{"list"}}, Args: []*ParameterType{{Ident: &Ident{Parts: []string{"foo"}}}}},
+				},
+			},
+			want: "map(list(foo))",
+		},
+		{
+			name: "Tuple",
+			input: &ParameterType{
+				Tuple: true,
+				Elems: []*ParameterType{{Ident: &Ident{Parts: []string{"foo"}}}, {Ident: &Ident{Parts: []string{"bar"}}}},
+			},
+			want: "tuple([foo, bar])",
+		},
+		{
+			name:  "Empty tuple",
+			input: &ParameterType{Tuple: true},
+			want:  "tuple([])",
+		},
+		{
+			name: "Optional",
+			input: &ParameterType{
+				Optional: &ParameterType{Ident: &Ident{Parts: []string{"foo"}}},
+			},
+			want: "optional(foo)",
+		},
+		{
+			name: "Optional with default",
+			input: &ParameterType{
+				Optional: &ParameterType{Ident: &Ident{Parts: []string{"foo"}}},
+				Default:  BuildTestExprTree[*Expr](t, &Value{Null: true}),
+			},
+			want: "optional(foo, null)",
+		},
+		{
+			name: "Union",
+			input: &ParameterType{
+				Ident: &Ident{Parts: []string{"foo"}},
+				Or: &ParameterType{
+					Ident: &Ident{Parts: []string{"bar"}},
+					Or:    &ParameterType{Tuple: true},
+				},
+			},
+			want: "foo | bar | tuple([])",
+		},
 	}
 
 	for _, tt := range tests {
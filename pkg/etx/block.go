@@ -5,6 +5,12 @@ import (
 )
 
 // Block represents an optionally labeled block.
+//
+// A block with an `if:` meta-attribute is conditional: when the condition is
+// false, the block is still declared but its attributes are null. A block
+// with a `for_each:` or `count:` meta-attribute is repeated: it has an
+// instance per key of a map, set or list of strings, or per index up to a
+// number.
 type Block struct {
 	ASTNode
 
@@ -34,6 +40,46 @@ func (n *Block) Children() (children []Node) {
 	return
 }
 
+// Condition returns the expression of the `if:` meta-attribute of the block,
+// or nil when the block is unconditional.
+func (n *Block) Condition() *Expr {
+	for _, item := range n.Body {
+		if item.If != nil {
+			return item.If
+		}
+	}
+
+	return nil
+}
+
+// Repetition returns the item holding the `for_each:` or `count:`
+// meta-attribute of the block, or nil when the block has a single instance.
+func (n *Block) Repetition() *BlockItem {
+	for _, item := range n.Body {
+		if item.ForEach != nil || item.Count != nil {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether the name and labels of the block are a prefix of
+// the parts of an identifier.
+func (n *Block) matches(parts []string) bool {
+	if n.Name != parts[0] || len(n.Labels) >= len(parts) {
+		return false
+	}
+
+	for i, label := range n.Labels {
+		if parts[i+1] != label {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (n Block) FormattedString() string {
 	if n.Name == "" {
 		return ""
@@ -68,10 +114,13 @@ func (n Block) FormattedString() string {
 type BlockItem struct {
 	ASTNode
 
-	EmptyLine string     `parser:"(   @LF+      " json:"empty_line,omitempty"`
-	Block     *Block     `parser:"  | (@@ LF?)  " json:"block,omitempty"`
-	Attribute *Attribute `parser:"  | (@@ LF?)  " json:"attribute,omitempty"`
-	Comment   *Comment   `parser:"  | @@       )" json:"comment,omitempty"`
+	EmptyLine string     `parser:"(   @LF+                    " json:"empty_line,omitempty"`
+	ForEach   *Expr      `parser:"  | ('for_each' ':' @@ LF?) " json:"for_each,omitempty"`
+	Count     *Expr      `parser:"  | ('count' ':' @@ LF?)    " json:"count,omitempty"`
+	Block     *Block     `parser:"  | (@@ LF?)                " json:"block,omitempty"`
+	Attribute *Attribute `parser:"  | (@@ LF?)                " json:"attribute,omitempty"`
+	If        *Expr      `parser:"  | (If ':' @@ LF?)         " json:"if,omitempty"`
+	Comment   *Comment   `parser:"  | @@                     )" json:"comment,omitempty"`
 }
 
 func (n *BlockItem) Clone() *BlockItem {
@@ -83,6 +132,9 @@ func (n *BlockItem) Clone() *BlockItem {
 		ASTNode:   n.ASTNode.Clone(),
 		Block:     n.Block.Clone(),
 		Attribute: n.Attribute.Clone(),
+		If:        n.If.Clone(),
+		ForEach:   n.ForEach.Clone(),
+		Count:     n.Count.Clone(),
 		Comment:   n.Comment.Clone(),
 		EmptyLine: n.EmptyLine,
 	}
@@ -101,6 +153,18 @@ func (n *BlockItem) Children() (children []Node) {
 		children = append(children, n.Attribute)
 	}
 
+	if n.If != nil {
+		children = append(children, n.If)
+	}
+
+	if n.ForEach != nil {
+		children = append(children, n.ForEach)
+	}
+
+	if n.Count != nil {
+		children = append(children, n.Count)
+	}
+
 	return
 }
 
@@ -116,8 +180,68 @@ func (n BlockItem) FormattedString() string {
 		sb.WriteString(n.Block.FormattedString())
 	case n.Attribute != nil:
 		sb.WriteString(n.Attribute.FormattedString())
+	case n.If != nil:
+		mustFprintf(&sb, "if: %s", n.If.FormattedString())
+	case n.ForEach != nil:
+		mustFprintf(&sb, "for_each: %s", n.ForEach.FormattedString())
+	case n.Count != nil:
+		mustFprintf(&sb, "count: %s", n.Count.FormattedString())
 	default:
 	}
 
 	return sb.String()
 }
+
+// /////////////////////////////////////
+
+// IfBlock is a top-level `if` block. The blocks of its body are only
+// declared when its condition is true, and referencing them otherwise is an
+// error.
+type IfBlock struct {
+	ASTNode
+
+	Condition ExprLogicalOr `parser:"If @@ '{' LF?" json:"condition"`
+	Body      []*BlockItem  `parser:"@@* '}'"       json:"body"`
+}
+
+func (n *IfBlock) Clone() *IfBlock {
+	if n == nil {
+		return nil
+	}
+
+	return &IfBlock{
+		ASTNode:   n.ASTNode.Clone(),
+		Condition: *n.Condition.Clone(),
+		Body:      cloneCollection(n.Body),
+	}
+}
+
+func (n *IfBlock) Children() (children []Node) {
+	children = append(children, &n.Condition)
+
+	for _, item := range n.Body {
+		children = append(children, item)
+	}
+
+	return
+}
+
+func (n IfBlock) FormattedString() string {
+	var sb strings.Builder
+
+	mustFprintf(&sb, "if %s", n.Condition.FormattedString())
+
+	if len(n.Body) != 0 {
+		sb.WriteString(" {\n")
+
+		for _, item := range n.Body {
+			sb.WriteString(indent(item.FormattedString(), indentationChar))
+		}
+
+		sb.WriteString("\n}")
+	} else {
+		sb.WriteString(" {}")
+	}
+
+	return sb.String()
+}
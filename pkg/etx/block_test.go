@@ -517,7 +517,58 @@ func TestBlockItem_Parsing(t *testing.T) {
 				},
 			},
 		},
-
+		{
+			name:    "If meta-attribute",
+			input:   "if: foo",
+			wantErr: false,
+			want: &BlockItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				If: BuildTestExprTree[*Expr](t, &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 4, Line: 1, Column: 5}},
+					Parts:   []string{"foo"},
+				}),
+			},
+		},
+		{
+			name:    "For each meta-attribute",
+			input:   "for_each: foo",
+			wantErr: false,
+			want: &BlockItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				ForEach: BuildTestExprTree[*Expr](t, &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}},
+					Parts:   []string{"foo"},
+				}),
+			},
+		},
+		{
+			name:    "Count meta-attribute",
+			input:   "count: foo",
+			wantErr: false,
+			want: &BlockItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Count: BuildTestExprTree[*Expr](t, &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+					Parts:   []string{"foo"},
+				}),
+			},
+		},
+		{
+			name:    "Count attribute",
+			input:   "count = foo",
+			wantErr: false,
+			want: &BlockItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Attribute: &Attribute{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Key:     "count",
+					Value: BuildTestExprTree[*Expr](t, &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 8, Line: 1, Column: 9}},
+						Parts:   []string{"foo"},
+					}),
+				},
+			},
+		},
 		{
 			name:    "Comment",
 			input:   "// foo",
@@ -593,6 +644,33 @@ func TestBlockItem_Clone(t *testing.T) {
 				Block: &Block{Name: "resource"},
 			},
 		},
+		{
+			name: "If",
+			input: &BlockItem{
+				If: &Expr{ASTNode: ASTNode{Pos: Position{Offset: 4, Line: 1, Column: 5}}},
+			},
+			want: &BlockItem{
+				If: &Expr{ASTNode: ASTNode{Pos: Position{Offset: 4, Line: 1, Column: 5}}},
+			},
+		},
+		{
+			name: "For each",
+			input: &BlockItem{
+				ForEach: &Expr{ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}}},
+			},
+			want: &BlockItem{
+				ForEach: &Expr{ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}}},
+			},
+		},
+		{
+			name: "Count",
+			input: &BlockItem{
+				Count: &Expr{ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}}},
+			},
+			want: &BlockItem{
+				Count: &Expr{ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -642,6 +720,33 @@ func TestBlockItem_Children(t *testing.T) {
 				&Block{Name: "resource"},
 			},
 		},
+		{
+			name: "If",
+			input: &BlockItem{
+				If: &Expr{},
+			},
+			want: []Node{
+				&Expr{},
+			},
+		},
+		{
+			name: "For each",
+			input: &BlockItem{
+				ForEach: &Expr{},
+			},
+			want: []Node{
+				&Expr{},
+			},
+		},
+		{
+			name: "Count",
+			input: &BlockItem{
+				Count: &Expr{},
+			},
+			want: []Node{
+				&Expr{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -684,6 +789,27 @@ func TestBlockItem_FormattedString(t *testing.T) {
 			},
 			want: Block{Name: "resource"}.FormattedString(),
 		},
+		{
+			name: "If",
+			input: &BlockItem{
+				If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+			},
+			want: "if: foo",
+		},
+		{
+			name: "For each",
+			input: &BlockItem{
+				ForEach: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+			},
+			want: "for_each: foo",
+		},
+		{
+			name: "Count",
+			input: &BlockItem{
+				Count: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+			},
+			want: "count: foo",
+		},
 		{
 			name: "Comment",
 			input: &BlockItem{
@@ -699,3 +825,209 @@ func TestBlockItem_FormattedString(t *testing.T) {
 		})
 	}
 }
+
+func TestBlock_Condition(t *testing.T) {
+	t.Parallel()
+
+	cond := BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})
+
+	assert.Nil(t, (&Block{Name: "resource", Body: []*BlockItem{{Attribute: &Attribute{Key: "foo"}}}}).Condition())
+	assert.Same(t, cond, (&Block{Name: "resource", Body: []*BlockItem{{Attribute: &Attribute{Key: "foo"}}, {If: cond}}}).Condition())
+}
+
+func TestBlock_Repetition(t *testing.T) {
+	t.Parallel()
+
+	forEach := &BlockItem{ForEach: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}
+	count := &BlockItem{Count: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}
+
+	assert.Nil(t, (&Block{Name: "resource", Body: []*BlockItem{{Attribute: &Attribute{Key: "count"}}}}).Repetition())
+	assert.Same(t, forEach, (&Block{Name: "resource", Body: []*BlockItem{{Attribute: &Attribute{Key: "foo"}}, forEach}}).Repetition())
+	assert.Same(t, count, (&Block{Name: "resource", Body: []*BlockItem{count}}).Repetition())
+}
+
+func TestIfBlock_Parsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		want    *IfBlock
+	}{
+		{
+			name:    "No body",
+			input:   "if foo { }",
+			wantErr: false,
+			want: &IfBlock{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Condition: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 3, Line: 1, Column: 4}},
+					Parts:   []string{"foo"},
+				}),
+			},
+		},
+		{
+			name: "Body",
+			input: `
+if foo {
+	bar {}
+}`[1:],
+			wantErr: false,
+			want: &IfBlock{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Condition: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 3, Line: 1, Column: 4}},
+					Parts:   []string{"foo"},
+				}),
+				Body: []*BlockItem{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 2, Column: 2}},
+						Block: &Block{
+							ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 2, Column: 2}},
+							Name:    "bar",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "No condition",
+			input:   "if { }",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParser(t, tt.input, tt.want, tt.wantErr, true)
+		})
+	}
+}
+
+func TestIfBlock_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *IfBlock
+		want  *IfBlock
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Empty",
+			input: &IfBlock{},
+			want:  &IfBlock{},
+		},
+		{
+			name: "ASTNode",
+			input: &IfBlock{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+			},
+			want: &IfBlock{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+			},
+		},
+		{
+			name: "Condition",
+			input: &IfBlock{
+				Condition: ExprLogicalOr{ASTNode: ASTNode{Pos: Position{Offset: 3, Line: 1, Column: 4}}},
+			},
+			want: &IfBlock{
+				Condition: ExprLogicalOr{ASTNode: ASTNode{Pos: Position{Offset: 3, Line: 1, Column: 4}}},
+			},
+		},
+		{
+			name: "Body",
+			input: &IfBlock{
+				Body: []*BlockItem{{Block: &Block{Name: "resource"}}},
+			},
+			want: &IfBlock{
+				Body: []*BlockItem{{Block: &Block{Name: "resource"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCloner[*IfBlock](t, tt.want, tt.input.Clone())
+		})
+	}
+}
+
+func TestIfBlock_Children(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *IfBlock
+		want  []Node
+	}{
+		{
+			name:  "Empty",
+			input: &IfBlock{},
+			want: []Node{
+				&ExprLogicalOr{},
+			},
+		},
+		{
+			name: "Body",
+			input: &IfBlock{
+				Body: []*BlockItem{{}, {}},
+			},
+			want: []Node{
+				&ExprLogicalOr{},
+				&BlockItem{},
+				&BlockItem{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.Children())
+		})
+	}
+}
+
+func TestIfBlock_FormattedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     *IfBlock
+		wantPanic bool
+		want      string
+	}{
+		{
+			name:      "Nil",
+			input:     nil,
+			wantPanic: true,
+		},
+		{
+			name: "No body",
+			input: &IfBlock{
+				Condition: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"foo"}}),
+			},
+			want: "if foo {}",
+		},
+		{
+			name: "Body",
+			input: &IfBlock{
+				Condition: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"foo"}}),
+				Body:      []*BlockItem{{Block: &Block{Name: "resource", Labels: []string{"bar"}}}},
+			},
+			want: "if foo {\n\tresource \"bar\" {}\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testStringer(t, tt.wantPanic, tt.want, tt.input)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package etx
+
+import (
+	"github.com/hexbee-net/etxe/pkg/etx/funcs"
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// builtinFunctions are available to every expression, after the functions
+// of the evaluation context.
+//
+//nolint:gochecknoglobals // immutable function table
+var builtinFunctions = withLibrary(map[string]*value.Function{
+	"List": {
+		Name:     "List",
+		VarParam: &value.Param{Name: "items", AllowNull: true},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.List(args...), nil
+		},
+	},
+	"Set": {
+		Name:     "Set",
+		VarParam: &value.Param{Name: "items", AllowNull: true},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.Set(args...), nil
+		},
+	},
+})
+
+// withLibrary adds the functions of the standard library to fns.
+func withLibrary(fns map[string]*value.Function) map[string]*value.Function {
+	for name, fn := range funcs.Functions() {
+		fns[name] = fn
+	}
+
+	return fns
+}
@@ -0,0 +1,1237 @@
+package etx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/etx/funcs"
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// Check reports the type errors of an AST without evaluating it.
+//
+// The types of expressions are inferred bottom-up from literals, type
+// annotations and function signatures, and checked against the annotations
+// of declarations, parameters and return values, the operands of operators
+// and the arity of calls. Names whose type cannot be inferred, such as
+// variables provided at evaluation time, are not checked.
+func Check(ast *AST) Diagnostics {
+	var diags Diagnostics
+
+	ctx := &checkContext{
+		types: make(map[string]staticType),
+		enums: make(map[string]*staticEnum),
+		diags: &diags,
+	}
+
+	// Functions and types are available to the whole module, like at
+	// runtime. The types come first, as the signatures may reference them.
+	for _, item := range ast.Items {
+		if item.Type != nil {
+			item.Type.check(ctx)
+		}
+	}
+
+	for _, item := range ast.Items {
+		if item.Func != nil {
+			ctx.types[item.Func.Label] = item.Func.staticType(ctx)
+		}
+	}
+
+	for _, item := range ast.Items {
+		switch {
+		case item.Decl != nil:
+			item.Decl.check(ctx)
+		case item.Block != nil:
+			item.Block.check(ctx)
+		case item.If != nil:
+			item.If.check(ctx)
+		case item.Attribute != nil:
+			item.Attribute.check(ctx)
+		}
+	}
+
+	// Function bodies are checked last, as they may reference any value of
+	// the module.
+	for _, item := range ast.Items {
+		if item.Func != nil {
+			item.Func.check(ctx)
+		}
+	}
+
+	diags.Sort()
+
+	return diags
+}
+
+// checkContext holds the static types of the names available to an
+// expression during type checking.
+type checkContext struct {
+	types  map[string]staticType
+	enums  map[string]*staticEnum
+	diags  *Diagnostics
+	parent *checkContext
+}
+
+// newChild returns a new context whose lookups fall back to c.
+func (c *checkContext) newChild() *checkContext {
+	return &checkContext{
+		types:  make(map[string]staticType),
+		enums:  c.enums,
+		diags:  c.diags,
+		parent: c,
+	}
+}
+
+func (c *checkContext) report(diags ...*Diagnostic) {
+	*c.diags = append(*c.diags, diags...)
+}
+
+// lookup returns the static type of a name, falling back to the library
+// functions. Unknown names have the dynamic type.
+func (c *checkContext) lookup(name string) staticType {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if t, ok := ctx.types[name]; ok {
+			return t
+		}
+	}
+
+	if fn, ok := (&funcs.Sandbox{}).Function(name); ok {
+		return staticType{typ: value.TypeFunction, sig: &signature{fn: fn}}
+	}
+
+	if fn, ok := builtinFunctions[name]; ok {
+		return staticType{typ: value.TypeFunction, sig: &signature{fn: fn}}
+	}
+
+	return staticType{}
+}
+
+func (c *checkContext) declared(name string) bool {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if _, ok := ctx.types[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// staticType is the type of an expression inferred by Check. The zero value
+// is the dynamic type.
+type staticType struct {
+	typ value.Type
+
+	// sig is the signature of function values, when it is known.
+	sig *signature
+
+	// enum is the name of the enum of enum values, and member the name of
+	// the member when the value is known.
+	enum   string
+	member string
+
+	// aborts reports that the expression never yields a value, like an
+	// error expression.
+	aborts bool
+}
+
+// staticEnum is the static description of an enum type.
+type staticEnum struct {
+	members []string
+	types   map[string]staticType
+}
+
+// signature describes the parameters and result of a function value. The
+// function is only used for its name and parameters, it is never called.
+type signature struct {
+	fn     *value.Function
+	result staticType
+}
+
+// unify returns the common type of types, or the dynamic type. The types of
+// the expressions that abort are left out.
+func unify(types []staticType) staticType {
+	if heterogeneous(types) {
+		return staticType{}
+	}
+
+	for _, t := range types {
+		if !t.aborts {
+			return t
+		}
+	}
+
+	if len(types) != 0 {
+		return types[0]
+	}
+
+	return staticType{}
+}
+
+// heterogeneous reports whether types have no common type. The types of the
+// expressions that abort are left out.
+func heterogeneous(types []staticType) bool {
+	var first *staticType
+
+	for i := range types {
+		switch t := &types[i]; {
+		case t.aborts:
+		case first == nil:
+			first = t
+		case !t.typ.Equals(first.typ) || t.enum != first.enum:
+			return true
+		}
+	}
+
+	return false
+}
+
+// kindName returns the name of the kind of the values of type t, as written
+// in the runtime errors.
+func kindName(t value.Type) string {
+	switch t.Kind() {
+	case value.TypeKindList, value.TypeKindTuple:
+		return value.KindList.String()
+	case value.TypeKindSet:
+		return value.KindSet.String()
+	case value.TypeKindMap, value.TypeKindObject:
+		return value.KindMap.String()
+	default:
+		return t.String()
+	}
+}
+
+func isKnown(t staticType, kinds ...value.TypeKind) bool {
+	for _, kind := range kinds {
+		if t.typ.Kind() == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDynamic reports whether the values of type t are only known at runtime,
+// including the values of a union type, which may be of any of its members.
+func isDynamic(t staticType) bool {
+	return t.typ.Kind() == value.TypeKindAny || t.typ.Kind() == value.TypeKindUnion
+}
+
+// /////////////////////////////////////
+
+// staticType returns the static type described by a parameter type. The
+// parameters of function signatures are named after their position, and the
+// function after name. An enum is named either enum.name or name.
+func (n *ParameterType) staticType(ctx *checkContext, name string) staticType {
+	if enum, ok := n.enumName(); ok {
+		if _, exists := ctx.enums[enum]; exists || len(n.Ident.Parts) == 2 { //nolint:gomnd // enum.name
+			return staticType{typ: n.valueType(), enum: enum}
+		}
+	}
+
+	if n.Func == nil {
+		return staticType{typ: n.valueType()}
+	}
+
+	params := make([]value.Param, 0, len(n.Func.Parameters))
+	for i, p := range n.Func.Parameters {
+		params = append(params, value.Param{Name: strconv.Itoa(i + 1), Type: p.valueType(), AllowNull: true})
+	}
+
+	return staticType{
+		typ: value.TypeFunction,
+		sig: &signature{
+			fn:     &value.Function{Name: name, Params: params},
+			result: n.Func.Return.staticType(ctx, "function"),
+		},
+	}
+}
+
+// annotatedType returns the static type of a name declared with an optional
+// type annotation.
+func annotatedType(ctx *checkContext, name string, typ *ParameterType) staticType {
+	if typ == nil {
+		return staticType{}
+	}
+
+	return typ.staticType(ctx, name)
+}
+
+// checkValue checks the value of a declaration against its type annotation,
+// and returns the static type of the declared name.
+func checkValue(ctx *checkContext, declType, label string, typ *ParameterType, expr *Expr) staticType {
+	var t staticType
+	if expr != nil {
+		t = expr.check(ctx)
+	}
+
+	if typ == nil {
+		return t
+	}
+
+	declared := typ.staticType(ctx, label)
+
+	switch {
+	case expr == nil:
+	case !declared.typ.Accepts(t.typ):
+		ctx.report(errorDiag(expr.Pos, "Invalid value", fmt.Sprintf("%s %q must be a %s, got %s", declType, label, declared.typ, t.typ)))
+	case declared.enum != "" && t.enum != "" && t.enum != declared.enum:
+		ctx.report(errorDiag(expr.Pos, "Invalid value",
+			fmt.Sprintf("%s %q must be a member of enum %q, got a member of enum %q", declType, label, declared.enum, t.enum)))
+	}
+
+	return declared
+}
+
+func (n *Decl) check(ctx *checkContext) {
+	if len(n.Group) != 0 {
+		checkDeclGroup(ctx, n.DeclType, n.Group)
+
+		return
+	}
+
+	ctx.types[n.Label] = checkValue(ctx, n.DeclType, n.Label, n.Type, n.Value)
+}
+
+// checkDeclGroup checks the values of a grouped declaration block in
+// dependency order.
+func checkDeclGroup(ctx *checkContext, declType string, group []*DeclGroupItem) {
+	items := make(map[string]*DeclGroupItem)
+	entries := make([]*DeclGroupItem, 0, len(group))
+
+	for _, item := range group {
+		if item.Label == "" || items[item.Label] != nil {
+			continue
+		}
+
+		items[item.Label] = item
+		entries = append(entries, item)
+	}
+
+	sorted, diags := sortDeclGroup(declType, entries, items)
+	if diags.HasErrors() {
+		ctx.report(diags...)
+
+		sorted = entries
+	}
+
+	for _, item := range sorted {
+		ctx.types[item.Label] = checkValue(ctx, declType, item.Label, item.Type, item.Value)
+	}
+}
+
+// check declares the enum defined by n, with the types of its members.
+func (n *Type) check(ctx *checkContext) {
+	if n.Enum == nil {
+		return
+	}
+
+	enum := &staticEnum{types: make(map[string]staticType)}
+
+	for _, item := range n.Enum.Items {
+		if item.Label == "" {
+			continue
+		}
+
+		if _, exists := enum.types[item.Label]; !exists {
+			enum.members = append(enum.members, item.Label)
+		}
+
+		t := item.Value.check(ctx)
+		t.enum, t.member = n.Label, item.Label
+		enum.types[item.Label] = t
+	}
+
+	if _, exists := ctx.enums[n.Label]; !exists {
+		ctx.enums[n.Label] = enum
+	}
+}
+
+// check checks the body of a block. The for_each and count meta-attributes
+// are checked in the enclosing context, and the body in a context holding
+// each or count.
+func (n *Block) check(ctx *checkContext) {
+	body := ctx
+
+	for _, item := range n.Body {
+		switch {
+		case item.ForEach != nil:
+			t := item.ForEach.check(ctx)
+			if !isDynamic(t) && !isKnown(t, value.TypeKindMap, value.TypeKindObject, value.TypeKindSet, value.TypeKindList, value.TypeKindTuple) {
+				ctx.report(errorDiag(item.ForEach.Pos, "Invalid for_each argument",
+					fmt.Sprintf("for_each must be a map, or a set or list of strings, got %s", kindName(t.typ))))
+			}
+
+			body = ctx.newChild()
+			body.types[eachVariable] = staticType{typ: value.ObjectOf(map[string]value.Type{"key": value.TypeString, "value": value.TypeAny})}
+		case item.Count != nil:
+			if t := item.Count.check(ctx); !isDynamic(t) && !isKnown(t, value.TypeKindNumber) {
+				ctx.report(errorDiag(item.Count.Pos, "Invalid count argument", fmt.Sprintf("count must be a number, got %s", kindName(t.typ))))
+			}
+
+			body = ctx.newChild()
+			body.types[countVariable] = staticType{typ: value.ObjectOf(map[string]value.Type{"index": value.TypeNumber})}
+		}
+	}
+
+	for _, item := range n.Body {
+		switch {
+		case item.Block != nil:
+			item.Block.check(body)
+		case item.Attribute != nil:
+			item.Attribute.check(body)
+		case item.If != nil:
+			if t := item.If.check(body); !isDynamic(t) && !isKnown(t, value.TypeKindBool) {
+				ctx.report(errorDiag(item.If.Pos, "Invalid condition", fmt.Sprintf("condition must be a bool, got %s", kindName(t.typ))))
+			}
+		}
+	}
+}
+
+func (n *IfBlock) check(ctx *checkContext) {
+	checkCondition(ctx, &n.Condition)
+
+	for _, item := range n.Body {
+		if item.Block != nil {
+			item.Block.check(ctx)
+		}
+	}
+}
+
+func (n *Attribute) check(ctx *checkContext) {
+	if n.Value != nil {
+		n.Value.check(ctx)
+	}
+}
+
+// /////////////////////////////////////
+
+// staticType returns the static type of the function defined by n.
+func (n *Func) staticType(ctx *checkContext) staticType {
+	var result staticType
+
+	switch len(n.Return) {
+	case 0:
+	case 1:
+		result = n.Return[0].staticType(ctx, "function")
+	default:
+		result = staticType{typ: n.returnType()}
+	}
+
+	return staticType{
+		typ: value.TypeFunction,
+		sig: &signature{
+			fn:     &value.Function{Name: n.Label, Params: n.params()},
+			result: result,
+		},
+	}
+}
+
+// check checks the statements of the function body, and the result of the
+// function against its return types.
+func (n *Func) check(ctx *checkContext) {
+	scope := ctx.newChild()
+	for _, p := range n.Parameters {
+		scope.types[p.Label] = annotatedType(ctx, p.Label, p.Type)
+	}
+
+	var (
+		res *staticType
+		pos = n.Pos
+	)
+
+	for i, stmt := range n.Body {
+		switch {
+		case stmt.Return != nil:
+			if next := nextStatement(n.Body[i+1:]); next != nil {
+				scope.report(errorDiag(next.Pos, "Unreachable statement",
+					fmt.Sprintf("statements after a return in function %q are never evaluated", n.Label)))
+			}
+
+			t := stmt.Return.check(scope)
+
+			res, pos = &t, stmt.Return.Pos
+			switch len(stmt.Return.Values) {
+			case 0:
+				res = nil
+			case 1:
+				pos = stmt.Return.Values[0].Pos
+			}
+
+		case stmt.Decl != nil:
+			stmt.Decl.check(scope)
+
+			res = nil
+
+		case stmt.Expr != nil:
+			t := stmt.Expr.check(scope)
+
+			res, pos = &t, stmt.Expr.Pos
+		}
+	}
+
+	// A null result conforms to every type.
+	if len(n.Return) == 0 || res == nil {
+		return
+	}
+
+	if !n.returnType().Accepts(res.typ) {
+		scope.report(errorDiag(pos, "Invalid return value", n.returnDetail(res.typ)))
+	}
+}
+
+// check returns the type of the returned values. Several values are returned
+// together as a tuple.
+func (n *FuncReturn) check(ctx *checkContext) staticType {
+	types := make([]value.Type, 0, len(n.Values))
+
+	var last staticType
+
+	for _, item := range n.Values {
+		last = item.check(ctx)
+		types = append(types, last.typ)
+	}
+
+	if len(types) == 1 {
+		return last
+	}
+
+	return staticType{typ: value.TupleOf(types...)}
+}
+
+func (n *FuncDecl) check(ctx *checkContext) {
+	if len(n.Group) != 0 {
+		checkDeclGroup(ctx, n.DeclType, n.Group)
+
+		return
+	}
+
+	if len(n.Targets) == 0 {
+		ctx.types[n.Label] = checkValue(ctx, n.DeclType, n.Label, n.Type, n.Value)
+
+		return
+	}
+
+	var t staticType
+	if n.Value != nil {
+		t = n.Value.check(ctx)
+	}
+
+	types, got := n.targetTypes(t.typ)
+	if got != "" {
+		ctx.report(errorDiag(n.Value.Pos, "Invalid value",
+			fmt.Sprintf("%s %s must be a list of %d values, got %s", n.DeclType, n.names(), len(n.Targets), got)))
+	}
+
+	for i, target := range n.Targets {
+		typ := staticType{typ: types[i]}
+
+		if target.Type != nil {
+			declared := target.Type.staticType(ctx, target.Label)
+			if !declared.typ.Accepts(typ.typ) {
+				ctx.report(errorDiag(n.Value.Pos, "Invalid value",
+					fmt.Sprintf("%s %q must be a %s, got %s", n.DeclType, target.Label, declared.typ, typ.typ)))
+			}
+
+			typ = declared
+		}
+
+		ctx.types[target.Label] = typ
+	}
+}
+
+// targetTypes returns the types of the names of a destructuring declaration
+// of a value of type t. If t cannot hold one value per name, it also
+// returns a description of t.
+func (n *FuncDecl) targetTypes(t value.Type) ([]value.Type, string) {
+	types := make([]value.Type, len(n.Targets))
+
+	switch t.Kind() {
+	case value.TypeKindAny:
+		return types, ""
+	case value.TypeKindList:
+		for i := range types {
+			types[i] = t.Elem()
+		}
+
+		return types, ""
+	case value.TypeKindTuple:
+		if elems := t.Elems(); len(elems) == len(types) {
+			return elems, ""
+		}
+
+		return types, fmt.Sprintf("a list of %d values", len(t.Elems()))
+	default:
+		return types, t.String()
+	}
+}
+
+// /////////////////////////////////////
+
+func (e *Expr) check(ctx *checkContext) staticType {
+	switch {
+	case e.Error != nil:
+		return e.Error.check(ctx)
+	case e.Left != nil:
+		return e.Left.check(ctx)
+	case e.If != nil:
+		return e.If.check(ctx)
+	case e.Switch != nil:
+		return e.Switch.check(ctx)
+	default:
+		panic("expression not set")
+	}
+}
+
+func (e *ExprIf) check(ctx *checkContext) staticType {
+	checkCondition(ctx, &e.Condition)
+
+	// A missing branch is null, whose type is dynamic.
+	var left, right staticType
+
+	if e.Left != nil {
+		left = e.Left.check(ctx)
+	}
+
+	if e.Right != nil {
+		right = e.Right.check(ctx)
+	}
+
+	return unify([]staticType{left, right})
+}
+
+// check checks the cases of the switch. A switch over an enum value without
+// default case must handle every member of the enum.
+func (e *ExprSwitch) check(ctx *checkContext) staticType {
+	selector := e.Selector.check(ctx)
+
+	var (
+		fallback bool
+		handled  = make(map[string]bool)
+		types    = make([]staticType, 0, len(e.Cases))
+	)
+
+	for _, c := range e.Cases {
+		fallback = fallback || c.Default
+
+		for _, cond := range c.Conditions {
+			if t := cond.check(ctx); t.enum == selector.enum {
+				handled[t.member] = true
+			}
+		}
+
+		types = append(types, c.Expr.check(ctx))
+	}
+
+	if enum, ok := ctx.enums[selector.enum]; ok && !fallback {
+		var missing []string
+
+		for _, member := range enum.members {
+			if !handled[member] {
+				missing = append(missing, fmt.Sprintf("%s.%s.%s", enumNamespace, selector.enum, member))
+			}
+		}
+
+		if len(missing) != 0 {
+			ctx.report(errorDiag(e.Pos, "Non-exhaustive switch",
+				fmt.Sprintf("switch on enum %q does not handle %s and has no default case", selector.enum, strings.Join(missing, ", "))))
+		}
+	}
+
+	return unify(types)
+}
+
+// check checks that the message of the error is a string. The error never
+// yields a value, so it does not take part in the type of the branches.
+func (e *ExprError) check(ctx *checkContext) staticType {
+	if t := e.Message.check(ctx); !isDynamic(t) && !isKnown(t, value.TypeKindString) {
+		ctx.report(errorDiag(e.Message.Pos, "Invalid error message", fmt.Sprintf("error message must be a string, got %s", kindName(t.typ))))
+	}
+
+	return staticType{aborts: true}
+}
+
+func (e *ExprConditional) check(ctx *checkContext) staticType {
+	if !e.ConditionOp {
+		return e.Condition.check(ctx)
+	}
+
+	checkCondition(ctx, &e.Condition)
+
+	return unify([]staticType{e.TrueExpr.check(ctx), e.FalseExpr.check(ctx)})
+}
+
+func checkCondition(ctx *checkContext, e *ExprLogicalOr) {
+	if t := e.check(ctx); !isDynamic(t) && !isKnown(t, value.TypeKindBool) {
+		ctx.report(errorDiag(e.Pos, "Invalid condition", fmt.Sprintf("condition must be a bool, got %s", kindName(t.typ))))
+	}
+}
+
+// /////////////////////////////////////
+
+func (e *ExprLogicalOr) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprLogicalAnd) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprBitwiseOr) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprBitwiseXor) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprBitwiseAnd) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprEquality) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprRelational) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprShift) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprAdditive) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+func (e *ExprMultiplicative) check(ctx *checkContext) staticType { return checkBinary(ctx, e) }
+
+// checkBinary walks the chain of links like evalBinary, inferring the type
+// of each operation from the types of its operands.
+func checkBinary[T binaryLink[T]](ctx *checkContext, e T) staticType {
+	acc := e.operand().check(ctx)
+
+	for link := e; link.operator() != ""; link = link.next() {
+		op, next := link.operator(), link.next()
+		rhs := next.operand().check(ctx)
+
+		if op == OpLogicalOr || op == OpLogicalAnd {
+			switch {
+			case !isDynamic(acc) && !isKnown(acc, value.TypeKindBool):
+				ctx.report(operatorDiag(link.Node().Pos, op,
+					fmt.Errorf("%w: %s %s", value.ErrUnsupportedOp, kindName(acc.typ), op)))
+			case !isDynamic(rhs) && !isKnown(rhs, value.TypeKindBool):
+				ctx.report(operatorDiag(next.Node().Pos, op,
+					fmt.Errorf("%w: bool %s %s", value.ErrUnsupportedOp, op, kindName(rhs.typ))))
+			}
+
+			acc = staticType{typ: value.TypeBool}
+
+			continue
+		}
+
+		res, ok := binaryType(op, acc, rhs)
+		if !ok {
+			ctx.report(operatorDiag(link.Node().Pos, op,
+				fmt.Errorf("%w: %s %s %s", value.ErrUnsupportedOp, kindName(acc.typ), op, kindName(rhs.typ))))
+		}
+
+		acc = res
+	}
+
+	return acc
+}
+
+// binaryType returns the type of the result of a binary operator, and
+// whether the operands may be valid. The operands of unknown types are
+// assumed to be valid.
+func binaryType(op string, a, b staticType) (staticType, bool) {
+	var (
+		boolType   = staticType{typ: value.TypeBool}
+		numberType = staticType{typ: value.TypeNumber}
+	)
+
+	switch op {
+	case OpEqual, OpNotEqual:
+		return boolType, true
+
+	case OpLess, OpLessOrEqual, OpMore, OpMoreOrEqual:
+		for _, t := range []staticType{a, b} {
+			if !isDynamic(t) && !isKnown(t, value.TypeKindNumber, value.TypeKindString) {
+				return boolType, false
+			}
+		}
+
+		return boolType, isDynamic(a) || isDynamic(b) || a.typ.Equals(b.typ)
+
+	case OpPlus:
+		switch {
+		case isDynamic(a) && isDynamic(b):
+			return staticType{}, true
+		case isDynamic(a):
+			return b, isKnown(b, value.TypeKindNumber, value.TypeKindString)
+		case isDynamic(b):
+			return a, isKnown(a, value.TypeKindNumber, value.TypeKindString)
+		default:
+			return a, isKnown(a, value.TypeKindNumber, value.TypeKindString) && a.typ.Equals(b.typ)
+		}
+
+	default:
+		for _, t := range []staticType{a, b} {
+			if !isDynamic(t) && !isKnown(t, value.TypeKindNumber) {
+				return numberType, false
+			}
+		}
+
+		return numberType, true
+	}
+}
+
+// /////////////////////////////////////
+
+func (e *ExprUnary) check(ctx *checkContext) staticType {
+	t := e.Right.check(ctx)
+	if e.Op == "" {
+		return t
+	}
+
+	res := staticType{typ: value.TypeNumber}
+	if e.Op == OpLogicalNot {
+		res = staticType{typ: value.TypeBool}
+	}
+
+	if !isDynamic(t) && !t.typ.Equals(res.typ) {
+		ctx.report(operatorDiag(e.Pos, e.Op, fmt.Errorf("%w: %s%s", value.ErrUnsupportedOp, e.Op, kindName(t.typ))))
+	}
+
+	return res
+}
+
+func (e *ExprPostfix) check(ctx *checkContext) staticType {
+	if _, ok := e.Value.enumName(); ok && e.Index != nil && !ctx.declared(enumNamespace) {
+		// An index on an enum is a reverse lookup of the name of a member.
+		e.Value.check(ctx)
+		e.Index.check(ctx)
+
+		t := staticType{typ: value.TypeString}
+		if e.Post != nil {
+			return e.Post.checkOn(ctx, t)
+		}
+
+		return t
+	}
+
+	return e.checkSuffix(ctx, e.Value.check(ctx))
+}
+
+// checkOn checks the postfix expression as an attribute access on a value
+// of type recv.
+func (e *ExprPostfix) checkOn(ctx *checkContext, recv staticType) staticType {
+	return e.checkSuffix(ctx, e.Value.checkOn(ctx, recv))
+}
+
+func (e *ExprPostfix) checkSuffix(ctx *checkContext, t staticType) staticType {
+	if e.Index != nil {
+		key := e.Index.check(ctx)
+
+		res, err := indexType(t.typ, key.typ)
+		if err != nil {
+			ctx.report(errorDiag(e.Index.Pos, "Invalid index", err.Error()))
+		}
+
+		t = staticType{typ: res}
+	}
+
+	if e.Post != nil {
+		return e.Post.checkOn(ctx, t)
+	}
+
+	return t
+}
+
+// indexType returns the type of the elements of a value of type t indexed
+// by a key of type key, with the errors index would report.
+func indexType(t, key value.Type) (value.Type, error) {
+	switch t.Kind() {
+	case value.TypeKindAny:
+		return value.TypeAny, nil
+
+	case value.TypeKindList, value.TypeKindTuple:
+		if key.Kind() != value.TypeKindAny && key.Kind() != value.TypeKindNumber {
+			return value.TypeAny, fmt.Errorf("%w: list index must be a number, got %s", value.ErrArgument, kindName(key))
+		}
+
+		if t.Kind() == value.TypeKindTuple {
+			elems := make([]staticType, 0, len(t.Elems()))
+			for _, elem := range t.Elems() {
+				elems = append(elems, staticType{typ: elem})
+			}
+
+			return unify(elems).typ, nil
+		}
+
+		return t.Elem(), nil
+
+	case value.TypeKindMap, value.TypeKindObject:
+		if key.Kind() != value.TypeKindAny && key.Kind() != value.TypeKindString {
+			return value.TypeAny, fmt.Errorf("%w: map key must be a string, got %s", value.ErrArgument, kindName(key))
+		}
+
+		if t.Kind() == value.TypeKindMap {
+			return t.Elem(), nil
+		}
+
+		return value.TypeAny, nil
+
+	default:
+		return value.TypeAny, fmt.Errorf("%w: cannot index a %s", value.ErrUnsupportedOp, kindName(t))
+	}
+}
+
+func (e *ExprPrimary) check(ctx *checkContext) staticType {
+	switch {
+	case e.Lambda != nil:
+		return e.Lambda.check(ctx)
+	case e.SubExpression != nil:
+		return e.SubExpression.check(ctx)
+	case e.Value != nil:
+		return e.Value.check(ctx)
+	case e.Ident != nil:
+		return e.checkSuffix(ctx, e.Ident.check(ctx))
+	default:
+		panic("identifier not set")
+	}
+}
+
+// checkOn checks the primary expression as an attribute access on a value
+// of type recv.
+func (e *ExprPrimary) checkOn(ctx *checkContext, recv staticType) staticType {
+	if e.Ident == nil {
+		return staticType{}
+	}
+
+	t := recv
+	for _, part := range e.Ident.Parts {
+		t = attributeType(t, part)
+	}
+
+	return e.checkSuffix(ctx, t)
+}
+
+func (e *ExprPrimary) checkSuffix(ctx *checkContext, t staticType) staticType {
+	for _, params := range e.Monads {
+		t = params.check(ctx, t)
+	}
+
+	if e.Post != nil {
+		return e.Post.checkOn(ctx, t)
+	}
+
+	return t
+}
+
+// check checks the arguments of a call against the signature of the callee,
+// and returns the type of its result.
+func (e *ExprInvocationParams) check(ctx *checkContext, callee staticType) staticType {
+	args := make([]staticType, 0, len(e.Values))
+	for _, item := range e.Values {
+		args = append(args, item.check(ctx))
+	}
+
+	switch {
+	case isDynamic(callee):
+		return staticType{}
+	case !isKnown(callee, value.TypeKindFunction):
+		ctx.report(errorDiag(e.Pos, "Not a function", fmt.Sprintf("cannot call a %s", kindName(callee.typ))))
+
+		return staticType{}
+	case callee.sig == nil:
+		return staticType{}
+	}
+
+	fn := callee.sig.fn
+	summary := fmt.Sprintf("Error in function call %q", fn.Name)
+
+	if err := fn.CheckArity(len(args)); err != nil {
+		ctx.report(errorDiag(e.Pos, summary, err.Error()))
+
+		return callee.sig.result
+	}
+
+	for i, arg := range args {
+		if fn.IsLazy(i) {
+			continue
+		}
+
+		param := fn.VarParam
+		if i < len(fn.Params) {
+			param = &fn.Params[i]
+		}
+
+		if !param.Type.Accepts(arg.typ) {
+			ctx.report(errorDiag(e.Values[i].Pos, summary,
+				fmt.Sprintf("%s: argument %q must be a %s, got %s", value.ErrArgument, param.Name, param.Type, arg.typ)))
+		}
+	}
+
+	return callee.sig.result
+}
+
+// attributeType returns the type of the attribute name of a value of type t.
+// Only the attributes of maps and objects are known.
+func attributeType(t staticType, name string) staticType {
+	switch t.typ.Kind() {
+	case value.TypeKindMap:
+		return staticType{typ: t.typ.Elem()}
+	case value.TypeKindObject:
+		return staticType{typ: t.typ.Attrs()[name]}
+	default:
+		return staticType{}
+	}
+}
+
+func (i *Ident) check(ctx *checkContext) staticType {
+	if i.Parts[0] == enumNamespace && !ctx.declared(enumNamespace) {
+		return i.checkEnum(ctx)
+	}
+
+	t := ctx.lookup(i.Parts[0])
+	for _, part := range i.Parts[1:] {
+		t = attributeType(t, part)
+	}
+
+	return t
+}
+
+// checkEnum returns the type of an identifier of the enum namespace, with
+// the errors resolveEnum would report.
+func (i *Ident) checkEnum(ctx *checkContext) staticType {
+	if len(i.Parts) < 2 { //nolint:gomnd // enum.name
+		ctx.report(errorDiag(i.Pos, "Invalid enum reference", fmt.Sprintf("expected an enum name after %q", enumNamespace)))
+
+		return staticType{}
+	}
+
+	enum, ok := ctx.enums[i.Parts[1]]
+	if !ok {
+		ctx.report(errorDiag(i.Pos, "Unknown enum", fmt.Sprintf("there is no enum named %q", i.Parts[1])))
+
+		return staticType{}
+	}
+
+	if len(i.Parts) == 2 { //nolint:gomnd // enum.name
+		types := make([]staticType, 0, len(enum.members))
+		for _, member := range enum.members {
+			types = append(types, staticType{typ: enum.types[member].typ})
+		}
+
+		return staticType{typ: value.MapOf(unify(types).typ)}
+	}
+
+	t, ok := enum.types[i.Parts[2]]
+	if !ok {
+		ctx.report(errorDiag(i.Pos, "Unknown enum member", fmt.Sprintf("enum %q has no member %q", i.Parts[1], i.Parts[2])))
+
+		return staticType{}
+	}
+
+	for _, part := range i.Parts[3:] {
+		t = attributeType(t, part)
+	}
+
+	return t
+}
+
+// check returns the signature of the lambda, with the type of its body as
+// result.
+func (n *Lambda) check(ctx *checkContext) staticType {
+	scope := ctx.newChild()
+	for _, p := range n.Parameters {
+		scope.types[p.Label] = annotatedType(ctx, p.Label, p.Type)
+	}
+
+	return staticType{
+		typ: value.TypeFunction,
+		sig: &signature{
+			fn:     &value.Function{Name: "lambda", Params: n.params()},
+			result: n.Expr.check(scope),
+		},
+	}
+}
+
+// /////////////////////////////////////
+
+func (v *Value) check(ctx *checkContext) staticType {
+	switch {
+	case v.Null:
+		return staticType{}
+	case v.Bool != nil:
+		return staticType{typ: value.TypeBool}
+	case v.Number != nil:
+		return staticType{typ: value.TypeNumber}
+	case v.Str != nil:
+		return v.Str.check(ctx)
+	case v.Heredoc != nil:
+		return v.Heredoc.check(ctx)
+	case v.ForList != nil:
+		return v.ForList.check(ctx)
+	case v.ForMap != nil:
+		return v.ForMap.check(ctx)
+	case v.List != nil:
+		return v.List.check(ctx)
+	case v.Map != nil:
+		return v.Map.check(ctx)
+	default:
+		panic("value not set")
+	}
+}
+
+func (v *ValueString) check(ctx *checkContext) staticType {
+	scope := ctx.newChild()
+
+	for _, f := range v.Fragment {
+		checkTemplatePart(scope, f.Expr, f.Directive)
+	}
+
+	return staticType{typ: value.TypeString}
+}
+
+func (v *Heredoc) check(ctx *checkContext) staticType {
+	scope := ctx.newChild()
+
+	for _, f := range v.Body() {
+		checkTemplatePart(scope, f.Expr, f.Directive)
+	}
+
+	return staticType{typ: value.TypeString}
+}
+
+// checkTemplatePart checks an interpolation or a directive of a template.
+// The variables of for directives are declared in scope for the rest of the
+// template.
+func checkTemplatePart(scope *checkContext, expr *Expr, directive *TemplateDirective) {
+	switch {
+	case expr != nil:
+		expr.check(scope)
+	case directive != nil && directive.If != nil:
+		directive.If.check(scope)
+	case directive != nil && directive.For != nil:
+		directive.For.Collection.check(scope)
+
+		if directive.For.Key != "" {
+			scope.types[directive.For.Key] = staticType{}
+		}
+
+		scope.types[directive.For.Value] = staticType{}
+	}
+}
+
+// check returns the type of the list. The elements of a list without a
+// common type are typed one by one, as a tuple.
+func (v *ValueList) check(ctx *checkContext) staticType {
+	types := make([]staticType, 0, len(v.Items))
+
+	for _, item := range v.Items {
+		if item.Value != nil {
+			types = append(types, item.Value.check(ctx))
+		}
+	}
+
+	if !heterogeneous(types) {
+		return staticType{typ: value.ListOf(unify(types).typ)}
+	}
+
+	elems := make([]value.Type, 0, len(types))
+	for _, t := range types {
+		elems = append(elems, t.typ)
+	}
+
+	return staticType{typ: value.TupleOf(elems...)}
+}
+
+// check returns the type of the map. The attributes of a map without a
+// common type are typed one by one, as an object, when all the keys are
+// names.
+func (v *ValueMap) check(ctx *checkContext) staticType {
+	types := make([]staticType, 0, len(v.Items))
+	attrs := map[string]value.Type{}
+	named := true
+
+	for _, item := range v.Items {
+		if item.Key == nil {
+			continue
+		}
+
+		if item.Key.Str != nil {
+			item.Key.Str.check(ctx)
+
+			named = false
+		}
+
+		t := item.Value.check(ctx)
+		types = append(types, t)
+
+		if item.Key.Ident != nil {
+			attrs[item.Key.Ident.FormattedString()] = t.typ
+		}
+	}
+
+	if !heterogeneous(types) || !named {
+		return staticType{typ: value.MapOf(unify(types).typ)}
+	}
+
+	return staticType{typ: value.ObjectOf(attrs)}
+}
+
+func (n *ForList) check(ctx *checkContext) staticType {
+	scope := n.Clause.check(ctx)
+
+	if n.Cond != nil {
+		checkCondition(scope, n.Cond)
+	}
+
+	return staticType{typ: value.ListOf(n.Value.check(scope).typ)}
+}
+
+func (n *ForMap) check(ctx *checkContext) staticType {
+	scope := n.Clause.check(ctx)
+
+	if t := n.Key.check(scope); !isDynamic(t) && !isKnown(t, value.TypeKindString, value.TypeKindNumber, value.TypeKindBool) {
+		ctx.report(errorDiag(n.Key.Pos, "Invalid map key", fmt.Sprintf("key must be a string, number or bool, got %s", kindName(t.typ))))
+	}
+
+	if n.Cond != nil {
+		checkCondition(scope, n.Cond)
+	}
+
+	t := n.Value.check(scope).typ
+	if n.Group {
+		t = value.ListOf(t)
+	}
+
+	return staticType{typ: value.MapOf(t)}
+}
+
+// check checks the collection of the for clause, and returns a context
+// holding the types of the key and the value of its elements.
+func (n *ForClause) check(ctx *checkContext) *checkContext {
+	var key, elem staticType
+
+	switch t := n.Collection.check(ctx).typ; t.Kind() {
+	case value.TypeKindList, value.TypeKindSet:
+		key, elem = staticType{typ: value.TypeNumber}, staticType{typ: t.Elem()}
+	case value.TypeKindTuple:
+		key = staticType{typ: value.TypeNumber}
+	case value.TypeKindMap:
+		key, elem = staticType{typ: value.TypeString}, staticType{typ: t.Elem()}
+	case value.TypeKindObject:
+		key = staticType{typ: value.TypeString}
+	case value.TypeKindAny:
+	default:
+		ctx.report(errorDiag(n.Collection.Pos, "Invalid for collection", fmt.Sprintf("cannot iterate over a %s", kindName(t))))
+	}
+
+	scope := ctx.newChild()
+	scope.types[n.Value] = elem
+
+	if n.Key != "" {
+		scope.types[n.Key] = key
+	}
+
+	return scope
+}
+
+func (n *ForOperand) check(ctx *checkContext) staticType {
+	switch {
+	case n.Ident != nil:
+		return n.Ident.check(ctx)
+	case n.Literal != nil:
+		return n.Literal.check(ctx)
+	default:
+		return n.Expr.check(ctx)
+	}
+}
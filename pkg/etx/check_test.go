@@ -0,0 +1,449 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+input region: string
+val a = 1
+val (
+	c = b * 2
+	b: number = a + 1
+)
+const d = "${region}-${c}"
+val e: list = [a, b].map((x) => x + 1)
+val h: map = {for i, x in [a, b] : "k${i}" => x * 2 if x > 0}
+val f = adder(a)(b) < 10 ? "small" : "large"
+val g = unknown + 1
+val i: list(any) = [a, "b", [c]]
+val j: map(string | number) = {x = a, y = d}
+
+block "x" {
+	attr = upper(d)
+}
+
+def adder(v: number) (number) -> number {
+	(x) => x + v
+}
+
+type color enum {
+	red:   1
+	green: 2
+}
+
+def name(c: enum.color) string {
+	switch c {
+	case enum.color.red: { "red" }
+	case enum.color.green: { enum.color[c] }
+	}
+}
+
+def is-red(c: enum.color) bool {
+	switch c {
+	case enum.color.red: { true }
+	default: { false }
+	}
+}
+
+def value(c: enum.color) number {
+	switch c {
+	case enum.color.red: { 1 }
+	default: { error "invalid color ${enum.color[c]}" }
+	}
+}
+
+def divmod(a: number, b: number) (number, number) {
+	val (q, r: number) = [floor(a / b), a % b]
+	return q, r
+}
+`)
+	require.NoError(t, err)
+
+	diags := Check(ast)
+	assert.Empty(t, diags, diags.Error())
+}
+
+func TestCheck_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+		line    int
+		column  int
+	}{
+		{
+			name:    "Shift operand",
+			input:   `val a = "a" << 2`,
+			summary: `Invalid operand for "<<"`,
+			detail:  "unsupported operation: string << number",
+			line:    1,
+			column:  9,
+		},
+		{
+			name:    "Operand of inferred type",
+			input:   "val a = 1\nval b = a + \"x\"",
+			summary: `Invalid operand for "+"`,
+			detail:  "unsupported operation: number + string",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Logical operand",
+			input:   `val a = true && 1`,
+			summary: `Invalid operand for "&&"`,
+			detail:  "unsupported operation: bool && number",
+			line:    1,
+			column:  17,
+		},
+		{
+			name:    "Unary operand",
+			input:   `val a = -"a"`,
+			summary: `Invalid operand for "-"`,
+			detail:  "unsupported operation: -string",
+			line:    1,
+			column:  9,
+		},
+		{
+			name:    "Type of branches with an error",
+			input:   "val a = if true { 1 } else { error \"invalid\" }\nval b = a + \"x\"",
+			summary: `Invalid operand for "+"`,
+			detail:  "unsupported operation: number + string",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Error message",
+			input:   `val a = error 1 + 2`,
+			summary: "Invalid error message",
+			detail:  "error message must be a string, got number",
+			line:    1,
+			column:  15,
+		},
+		{
+			name:    "Annotation",
+			input:   `val a: string = 1 + 2`,
+			summary: "Invalid value",
+			detail:  `val "a" must be a string, got number`,
+			line:    1,
+			column:  17,
+		},
+		{
+			name:    "Tuple annotation",
+			input:   "val a: tuple([number, string]) | null = [1, 2]\nval b: optional(tuple([number, string])) = [1, 2]",
+			summary: "Invalid value",
+			detail:  `val "b" must be a tuple([number, string]), got list(number)`,
+			line:    2,
+			column:  44,
+		},
+		{
+			name:    "Input default",
+			input:   `input a: bool = "yes"`,
+			summary: "Invalid value",
+			detail:  `input "a" must be a bool, got string`,
+			line:    1,
+			column:  17,
+		},
+		{
+			name:    "Condition",
+			input:   `val a = 1 ? 2 : 3`,
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got number",
+			line:    1,
+			column:  9,
+		},
+		{
+			name:    "Index",
+			input:   `val a = [1, 2][true]`,
+			summary: "Invalid index",
+			detail:  "invalid argument: list index must be a number, got bool",
+			line:    1,
+			column:  16,
+		},
+		{
+			name:    "Arity",
+			input:   "val a = add(1)\ndef add(a: number, b: number) number { a + b }",
+			summary: `Error in function call "add"`,
+			detail:  "wrong number of arguments: add expects at least 2, got 1",
+			line:    1,
+			column:  13,
+		},
+		{
+			name:    "Argument type",
+			input:   "val a = add(1, \"x\")\ndef add(a: number, b: number) number { a + b }",
+			summary: `Error in function call "add"`,
+			detail:  `invalid argument: argument "b" must be a number, got string`,
+			line:    1,
+			column:  16,
+		},
+		{
+			name:    "Library function",
+			input:   `val a = upper(1)`,
+			summary: `Error in function call "upper"`,
+			detail:  `invalid argument: argument "str" must be a string, got number`,
+			line:    1,
+			column:  15,
+		},
+		{
+			name:    "Lambda parameter",
+			input:   "val f = (x: number) => x\nval a = f(\"a\")",
+			summary: `Error in function call "lambda"`,
+			detail:  `invalid argument: argument "x" must be a number, got string`,
+			line:    2,
+			column:  11,
+		},
+		{
+			name:    "Function signature",
+			input:   "def apply(f: (number, number) -> number) {\n\tf(1)\n}",
+			summary: `Error in function call "f"`,
+			detail:  "wrong number of arguments: f expects at least 2, got 1",
+			line:    2,
+			column:  4,
+		},
+		{
+			name:    "Not a function",
+			input:   "val a = \"a\"\nval b = a(1)",
+			summary: "Not a function",
+			detail:  "cannot call a string",
+			line:    2,
+			column:  11,
+		},
+		{
+			name:    "Return type",
+			input:   "def f(a: number) string {\n\ta * 2\n}",
+			summary: "Invalid return value",
+			detail:  `function "f" must return a string, got number`,
+			line:    2,
+			column:  2,
+		},
+		{
+			name:    "Returned values",
+			input:   "def f() (number, string) {\n\treturn 1, 2\n}",
+			summary: "Invalid return value",
+			detail:  `function "f" must return a list of 2 values (number, string), got tuple([number, number])`,
+			line:    2,
+			column:  2,
+		},
+		{
+			name:    "Destructuring",
+			input:   "def f() {\n\tval (a, b) = \"ab\"\n}",
+			summary: "Invalid value",
+			detail:  "val (a, b) must be a list of 2 values, got string",
+			line:    2,
+			column:  15,
+		},
+		{
+			name:    "Non-exhaustive switch",
+			input:   "type t enum {\n\ta: 1\n\tb: 2\n\tc: 3\n}\n\ndef f(v: enum.t) {\n\tswitch v {\n\tcase enum.t.b: { 1 }\n\t}\n}",
+			summary: "Non-exhaustive switch",
+			detail:  `switch on enum "t" does not handle enum.t.a, enum.t.c and has no default case`,
+			line:    8,
+			column:  2,
+		},
+		{
+			name:    "Non-exhaustive switch on a bare enum name",
+			input:   "type t enum {\n\ta: 1\n\tb: 2\n}\n\nval c: t = enum.t.a\nval d = switch c {\ncase enum.t.b: { 1 }\n}",
+			summary: "Non-exhaustive switch",
+			detail:  `switch on enum "t" does not handle enum.t.a and has no default case`,
+			line:    7,
+			column:  9,
+		},
+		{
+			name:    "Enum mismatch",
+			input:   "type t enum {\n\ta: 1\n}\n\ntype u enum {\n\ta: 1\n}\n\nval a: t = enum.u.a",
+			summary: "Invalid value",
+			detail:  `val "a" must be a member of enum "t", got a member of enum "u"`,
+			line:    9,
+			column:  12,
+		},
+		{
+			name:    "Unknown enum member",
+			input:   "type t enum {\n\ta: 1\n}\n\nval a = enum.t.b",
+			summary: "Unknown enum member",
+			detail:  `enum "t" has no member "b"`,
+			line:    5,
+			column:  9,
+		},
+		{
+			name:    "Enum member type",
+			input:   "type t enum {\n\ta: 1\n}\n\nval a: string = enum.t.a",
+			summary: "Invalid value",
+			detail:  `val "a" must be a string, got number`,
+			line:    5,
+			column:  17,
+		},
+		{
+			name:    "Template",
+			input:   `val a = "${1 - "b"}"`,
+			summary: `Invalid operand for "-"`,
+			detail:  "unsupported operation: number - string",
+			line:    1,
+			column:  12,
+		},
+		{
+			name:    "Block attribute",
+			input:   "block {\n\tattr = !\"a\"\n}",
+			summary: `Invalid operand for "!"`,
+			detail:  "unsupported operation: !string",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Block condition",
+			input:   "block {\n\tif: 1\n}",
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got number",
+			line:    2,
+			column:  6,
+		},
+		{
+			name:    "For each argument",
+			input:   "block {\n\tfor_each: 1\n}",
+			summary: "Invalid for_each argument",
+			detail:  "for_each must be a map, or a set or list of strings, got number",
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Count argument",
+			input:   "block {\n\tcount: \"a\"\n}",
+			summary: "Invalid count argument",
+			detail:  "count must be a number, got string",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Count index",
+			input:   "block {\n\tcount: 2\n\tattr = !count.index\n}",
+			summary: `Invalid operand for "!"`,
+			detail:  "unsupported operation: !number",
+			line:    3,
+			column:  9,
+		},
+		{
+			name:    "For collection",
+			input:   `val a = [for v in 1 : v]`,
+			summary: "Invalid for collection",
+			detail:  "cannot iterate over a number",
+			line:    1,
+			column:  19,
+		},
+		{
+			name:    "For map key",
+			input:   `val a = {for i, v in [1] : [i] => v}`,
+			summary: "Invalid map key",
+			detail:  "key must be a string, number or bool, got list",
+			line:    1,
+			column:  28,
+		},
+		{
+			name:    "For condition",
+			input:   `val a = [for v in ["a"] : v if v]`,
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got string",
+			line:    1,
+			column:  32,
+		},
+		{
+			name:    "Union",
+			input:   `val a: number | string = true`,
+			summary: "Invalid value",
+			detail:  `val "a" must be a number | string, got bool`,
+			line:    1,
+			column:  26,
+		},
+		{
+			name:    "Union element",
+			input:   `val a: list(string | number) = [true]`,
+			summary: "Invalid value",
+			detail:  `val "a" must be a list(string | number), got list(bool)`,
+			line:    1,
+			column:  32,
+		},
+		{
+			name:    "List element",
+			input:   `val k: list(number) = [1, "a"]`,
+			summary: "Invalid value",
+			detail:  `val "k" must be a list(number), got tuple([number, string])`,
+			line:    1,
+			column:  23,
+		},
+		{
+			name:    "Nested list element",
+			input:   `val g: list(list(number)) = [[1], ["x"]]`,
+			summary: "Invalid value",
+			detail:  `val "g" must be a list(list(number)), got tuple([list(number), list(string)])`,
+			line:    1,
+			column:  29,
+		},
+		{
+			name:    "Map element",
+			input:   `val m: map(number) = {a = 1, b = "x"}`,
+			summary: "Invalid value",
+			detail:  `val "m" must be a map(number), got object({a = number, b = string})`,
+			line:    1,
+			column:  22,
+		},
+		{
+			name:    "If block condition",
+			input:   "if \"a\" {\n\tblock {}\n}",
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got string",
+			line:    1,
+			column:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := Check(ast)
+			require.Len(t, diags, 1, diags.Error())
+			assert.Equal(t, tt.summary, diags[0].Summary)
+			assert.Equal(t, tt.detail, diags[0].Detail)
+			assert.Equal(t, tt.line, diags[0].Pos.Line, diags.Error())
+			assert.Equal(t, tt.column, diags[0].Pos.Column, diags.Error())
+		})
+	}
+}
+
+// TestCheck_Functions checks that the type errors of the functions used to
+// test the evaluation are reported without calling them.
+func TestCheck_Functions(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(testFunctions)
+	require.NoError(t, err)
+
+	var got []string
+	for _, d := range Check(ast) {
+		got = append(got, d.Error())
+	}
+
+	assert.Equal(t, []string{
+		`41:2: error: Invalid return value; function "wrong" must return a number, got string`,
+		`45:2: error: Invalid return value; function "wrong-tuple" must return a list of 2 values (number, string), got list(number)`,
+		`49:18: error: Invalid value; val "a" must be a string, got number`,
+		`82:2: error: Unreachable statement; statements after a return in function "unreachable" are never evaluated`,
+		`86:2: error: Invalid return value; function "bad-return" must return a list of 2 values (number, string), got tuple([number, number])`,
+		`90:18: error: Invalid value; val (a, b, c) must be a list of 3 values, got a list of 2 values`,
+		`95:15: error: Invalid value; val (a, b) must be a list of 2 values, got string`,
+		`100:23: error: Invalid value; val "a" must be a string, got number`,
+		`120:3: error: Reference cycle; val "a" depends on itself: a -> b -> a`,
+	}, got)
+}
@@ -4,17 +4,23 @@ import (
 	"strings"
 )
 
-// TODO: Since `val`s are immutable, do we really need `const`?
-// The one use case would be that const shouldn't accept an expr, only a value.
+// A `const` differs from a `val` in that its value must be known before
+// evaluation: Fold reports the consts whose value does not fold to a literal.
 
-// Decl is an `input`, `output`, `const` or `val` short form declaration.
+// Decl is an `input`, `output`, `const` or `val` short form declaration, or
+// a grouped declaration block of several values.
+//
+// The values of a declaration prefixed with `sensitive` are marked as
+// sensitive. Only sensitive outputs may hold sensitive values.
 type Decl struct {
 	ASTNode
 
-	DeclType string         `parser:"@('input' | 'output' | 'const' | 'val')" json:"decl_type"`
-	Label    string         `parser:"@Ident"                          json:"label"`
-	Type     *ParameterType `parser:"[':' @@]"                        json:"type,omitempty"`
-	Value    *Expr          `parser:"['=' @@]"                        json:"value,omitempty"`
+	Sensitive bool             `parser:"@'sensitive'?"                         json:"sensitive,omitempty"`
+	DeclType  string           `parser:"@('input' | 'output' | 'const' | 'val')" json:"decl_type"`
+	Label     string           `parser:"(   @Ident"                              json:"label"`
+	Type      *ParameterType   `parser:"    [':' @@]"                            json:"type,omitempty"`
+	Value     *Expr            `parser:"    ['=' @@]"                            json:"value,omitempty"`
+	Group     []*DeclGroupItem `parser:"  | '(' [ LF+ ] @@+ ')' )"               json:"group,omitempty"`
 }
 
 func (n *Decl) Clone() *Decl {
@@ -23,11 +29,13 @@ func (n *Decl) Clone() *Decl {
 	}
 
 	return &Decl{
-		ASTNode:  n.ASTNode.Clone(),
-		DeclType: n.DeclType,
-		Label:    n.Label,
-		Type:     n.Type.Clone(),
-		Value:    n.Value.Clone(),
+		ASTNode:   n.ASTNode.Clone(),
+		Sensitive: n.Sensitive,
+		DeclType:  n.DeclType,
+		Label:     n.Label,
+		Type:      n.Type.Clone(),
+		Value:     n.Value.Clone(),
+		Group:     cloneCollection(n.Group),
 	}
 }
 
@@ -40,16 +48,30 @@ func (n *Decl) Children() (children []Node) {
 		children = append(children, n.Value)
 	}
 
+	for _, item := range n.Group {
+		children = append(children, item)
+	}
+
 	return
 }
 
 func (n Decl) FormattedString() string {
 	var sb strings.Builder
 
-	if n.Label == "" {
+	if n.Sensitive {
+		sb.WriteString("sensitive ")
+	}
+
+	if len(n.Group) != 0 {
+		sb.WriteString(formatDeclGroup(n.DeclType, n.Group))
+
 		return sb.String()
 	}
 
+	if n.Label == "" {
+		return ""
+	}
+
 	mustFprintf(&sb, "%s %s", n.DeclType, n.Label)
 
 	if n.Type != nil {
@@ -62,3 +84,92 @@ func (n Decl) FormattedString() string {
 
 	return sb.String()
 }
+
+// /////////////////////////////////////
+
+// DeclGroupItem is an entry of a grouped declaration block.
+type DeclGroupItem struct {
+	ASTNode
+
+	EmptyLine string         `parser:"(   @LF+             " json:"empty_line,omitempty"`
+	Comment   *Comment       `parser:"  | @@               " json:"comment,omitempty"`
+	Label     string         `parser:"  | ( @Ident         " json:"label,omitempty"`
+	Type      *ParameterType `parser:"      [':' @@]       " json:"type,omitempty"`
+	Value     *Expr          `parser:"      ['=' @@] LF? ) )" json:"value,omitempty"`
+}
+
+func (n *DeclGroupItem) Clone() *DeclGroupItem {
+	if n == nil {
+		return nil
+	}
+
+	return &DeclGroupItem{
+		ASTNode:   n.ASTNode.Clone(),
+		EmptyLine: n.EmptyLine,
+		Comment:   n.Comment.Clone(),
+		Label:     n.Label,
+		Type:      n.Type.Clone(),
+		Value:     n.Value.Clone(),
+	}
+}
+
+func (n *DeclGroupItem) Children() (children []Node) {
+	if n.Comment != nil {
+		children = append(children, n.Comment)
+	}
+
+	if n.Type != nil {
+		children = append(children, n.Type)
+	}
+
+	if n.Value != nil {
+		children = append(children, n.Value)
+	}
+
+	return
+}
+
+func (n DeclGroupItem) FormattedString() string {
+	switch {
+	case n.Comment != nil:
+		return n.Comment.FormattedString()
+	case n.Label != "":
+		var sb strings.Builder
+
+		sb.WriteString(n.Label)
+
+		if n.Type != nil {
+			mustFprintf(&sb, ": %s", n.Type.FormattedString())
+		}
+
+		if n.Value != nil {
+			mustFprintf(&sb, " = %s", n.Value.FormattedString())
+		}
+
+		return sb.String()
+	case n.EmptyLine != "":
+		return n.EmptyLine
+	default:
+		return ""
+	}
+}
+
+// formatDeclGroup formats a grouped declaration block, one entry per line.
+func formatDeclGroup(declType string, items []*DeclGroupItem) string {
+	var sb strings.Builder
+
+	mustFprintf(&sb, "%s (\n", declType)
+
+	for _, item := range items {
+		s := item.FormattedString()
+		if !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+
+		sb.WriteString(indent(s, indentationChar))
+	}
+
+	sb.WriteString(")")
+
+	return sb.String()
+}
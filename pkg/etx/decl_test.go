@@ -102,6 +102,72 @@ func TestDecl_Parsing(t *testing.T) {
 				}),
 			},
 		},
+		{
+			name:    "Val group",
+			input:   "val (\n\t// foo\n\tfoo = 1\n\n\tbar: number\n)",
+			wantErr: false,
+			want: &Decl{
+				ASTNode:  ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				DeclType: "val",
+				Group: []*DeclGroupItem{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 2, Column: 2}},
+						Comment: &Comment{
+							ASTNode:    ASTNode{Pos: Position{Offset: 7, Line: 2, Column: 2}},
+							SingleLine: []string{"// foo"},
+						},
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 15, Line: 3, Column: 2}},
+						Label:   "foo",
+						Value: BuildTestExprTree[*Expr](t, &Value{
+							ASTNode: ASTNode{Pos: Position{Offset: 21, Line: 3, Column: 8}},
+							Number: &ValueNumber{
+								ASTNode{Pos: Position{Offset: 21, Line: 3, Column: 8}},
+								big.NewFloat(1),
+								"1",
+							},
+						}),
+					},
+					{
+						ASTNode:   ASTNode{Pos: Position{Offset: 23, Line: 4, Column: 1}},
+						EmptyLine: "\n",
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 25, Line: 5, Column: 2}},
+						Label:   "bar",
+						Type: &ParameterType{
+							ASTNode: ASTNode{Pos: Position{Offset: 30, Line: 5, Column: 7}},
+							Ident: &Ident{
+								ASTNode: ASTNode{Pos: Position{Offset: 30, Line: 5, Column: 7}},
+								Parts:   []string{"number"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "Val group - empty",
+			input:   "val (\n)",
+			wantErr: true,
+		},
+		{
+			name:    "Sensitive output",
+			input:   `sensitive output foo`,
+			wantErr: false,
+			want: &Decl{
+				ASTNode:   ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Sensitive: true,
+				DeclType:  "output",
+				Label:     "foo",
+			},
+		},
+		{
+			name:    "Sensitive - no declaration",
+			input:   `sensitive foo`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -138,6 +204,15 @@ func TestDecl_Clone(t *testing.T) {
 				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
 			},
 		},
+		{
+			name: "Sensitive",
+			input: &Decl{
+				Sensitive: true,
+			},
+			want: &Decl{
+				Sensitive: true,
+			},
+		},
 		{
 			name: "DeclType",
 			input: &Decl{
@@ -174,6 +249,15 @@ func TestDecl_Clone(t *testing.T) {
 				Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
 			},
 		},
+		{
+			name: "Group",
+			input: &Decl{
+				Group: []*DeclGroupItem{{Label: "foo"}, {Comment: &Comment{SingleLine: []string{"// bar"}}}},
+			},
+			want: &Decl{
+				Group: []*DeclGroupItem{{Label: "foo"}, {Comment: &Comment{SingleLine: []string{"// bar"}}}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -228,6 +312,16 @@ func TestDecl_Children(t *testing.T) {
 				BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
 			},
 		},
+		{
+			name: "Group",
+			input: &Decl{
+				Group: []*DeclGroupItem{{Label: "foo"}, {Label: "bar"}},
+			},
+			want: []Node{
+				&DeclGroupItem{Label: "foo"},
+				&DeclGroupItem{Label: "bar"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -292,6 +386,272 @@ func TestDecl_FormattedString(t *testing.T) {
 			},
 			want: "val foo: number = 1",
 		},
+		{
+			name: "Sensitive",
+			input: &Decl{
+				Sensitive: true,
+				DeclType:  "output",
+				Label:     "foo",
+				Value:     BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+			want: "sensitive output foo = 1",
+		},
+		{
+			name: "Sensitive group",
+			input: &Decl{
+				Sensitive: true,
+				DeclType:  "output",
+				Group: []*DeclGroupItem{
+					{Label: "foo", Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}})},
+				},
+			},
+			want: "sensitive output (\n\tfoo = 1\n)",
+		},
+		{
+			name: "Group",
+			input: &Decl{
+				DeclType: "val",
+				Group: []*DeclGroupItem{
+					{Comment: &Comment{SingleLine: []string{"// foo"}}},
+					{Label: "foo", Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}})},
+					{EmptyLine: "\n"},
+					{Comment: &Comment{Multiline: "/* bar */"}},
+					{Label: "bar", Type: &ParameterType{Ident: &Ident{Parts: []string{"number"}}}, Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}})},
+				},
+			},
+			want: "val (\n\t// foo\n\tfoo = 1\n\n\t/* bar */\n\tbar: number = 1\n)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testStringer(t, tt.wantPanic, tt.want, tt.input)
+		})
+	}
+}
+
+func TestDeclGroupItem_Parsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		want    *DeclGroupItem
+	}{
+		{
+			name:    "Label",
+			input:   "foo",
+			wantErr: false,
+			want: &DeclGroupItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Label:   "foo",
+			},
+		},
+		{
+			name:    "Label - type - value",
+			input:   "foo: number = 1",
+			wantErr: false,
+			want: &DeclGroupItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Label:   "foo",
+				Type: &ParameterType{
+					ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+					Ident: &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+						Parts:   []string{"number"},
+					},
+				},
+				Value: BuildTestExprTree[*Expr](t, &Value{
+					ASTNode: ASTNode{Pos: Position{Offset: 14, Line: 1, Column: 15}},
+					Number: &ValueNumber{
+						ASTNode{Pos: Position{Offset: 14, Line: 1, Column: 15}},
+						big.NewFloat(1),
+						"1",
+					},
+				}),
+			},
+		},
+		{
+			name:    "Comment",
+			input:   "// foo",
+			wantErr: false,
+			want: &DeclGroupItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Comment: &Comment{
+					ASTNode:    ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					SingleLine: []string{"// foo"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParser(t, tt.input, tt.want, tt.wantErr, true)
+		})
+	}
+}
+
+func TestDeclGroupItem_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *DeclGroupItem
+		want  *DeclGroupItem
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Empty",
+			input: &DeclGroupItem{},
+			want:  &DeclGroupItem{},
+		},
+		{
+			name: "ASTNode",
+			input: &DeclGroupItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+			},
+			want: &DeclGroupItem{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+			},
+		},
+		{
+			name: "EmptyLine",
+			input: &DeclGroupItem{
+				EmptyLine: "\n",
+			},
+			want: &DeclGroupItem{
+				EmptyLine: "\n",
+			},
+		},
+		{
+			name: "Comment",
+			input: &DeclGroupItem{
+				Comment: &Comment{SingleLine: []string{"// foo"}},
+			},
+			want: &DeclGroupItem{
+				Comment: &Comment{SingleLine: []string{"// foo"}},
+			},
+		},
+		{
+			name: "Label - type - value",
+			input: &DeclGroupItem{
+				Label: "foo",
+				Type:  &ParameterType{Ident: &Ident{Parts: []string{"number"}}},
+				Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+			want: &DeclGroupItem{
+				Label: "foo",
+				Type:  &ParameterType{Ident: &Ident{Parts: []string{"number"}}},
+				Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCloner[*DeclGroupItem](t, tt.want, tt.input.Clone())
+		})
+	}
+}
+
+func TestDeclGroupItem_Children(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *DeclGroupItem
+		want  []Node
+	}{
+		{
+			name:  "Empty",
+			input: &DeclGroupItem{},
+			want:  nil,
+		},
+		{
+			name: "Comment",
+			input: &DeclGroupItem{
+				Comment: &Comment{SingleLine: []string{"// foo"}},
+			},
+			want: []Node{
+				&Comment{SingleLine: []string{"// foo"}},
+			},
+		},
+		{
+			name: "Label - type - value",
+			input: &DeclGroupItem{
+				Label: "foo",
+				Type:  &ParameterType{Ident: &Ident{Parts: []string{"number"}}},
+				Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+			want: []Node{
+				&ParameterType{Ident: &Ident{Parts: []string{"number"}}},
+				BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.Children())
+		})
+	}
+}
+
+func TestDeclGroupItem_FormattedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     *DeclGroupItem
+		wantPanic bool
+		want      string
+	}{
+		{
+			name:      "Nil",
+			input:     nil,
+			wantPanic: true,
+		},
+		{
+			name:  "Empty",
+			input: &DeclGroupItem{},
+			want:  "",
+		},
+		{
+			name: "EmptyLine",
+			input: &DeclGroupItem{
+				EmptyLine: "\n",
+			},
+			want: "\n",
+		},
+		{
+			name: "Comment",
+			input: &DeclGroupItem{
+				Comment: &Comment{SingleLine: []string{"// foo"}},
+			},
+			want: "// foo\n",
+		},
+		{
+			name: "Label",
+			input: &DeclGroupItem{
+				Label: "foo",
+			},
+			want: "foo",
+		},
+		{
+			name: "Label - type - value",
+			input: &DeclGroupItem{
+				Label: "foo",
+				Type:  &ParameterType{Ident: &Ident{Parts: []string{"number"}}},
+				Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+			want: "foo: number = 1",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,127 @@
+package etx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// Severity of a diagnostic.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is an error or a warning attached to a position in the source.
+type Diagnostic struct {
+	Severity Severity
+	Summary  string
+	Detail   string
+	Pos      Position
+}
+
+func (d *Diagnostic) Error() string {
+	var sb strings.Builder
+
+	mustFprintf(&sb, "%s: %s: %s", d.Pos, d.Severity, d.Summary)
+
+	if d.Detail != "" {
+		mustFprintf(&sb, "; %s", d.Detail)
+	}
+
+	return sb.String()
+}
+
+// Diagnostics is a list of diagnostics.
+type Diagnostics []*Diagnostic
+
+// HasErrors reports whether any of the diagnostics is an error.
+func (d Diagnostics) HasErrors() bool {
+	for _, item := range d {
+		if item.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Errs returns the error diagnostics only.
+func (d Diagnostics) Errs() Diagnostics {
+	var out Diagnostics
+
+	for _, item := range d {
+		if item.Severity == SeverityError {
+			out = append(out, item)
+		}
+	}
+
+	return out
+}
+
+// redact returns copies of the diagnostics with their details hidden, for
+// the diagnostics caused by sensitive values.
+func (d Diagnostics) redact() Diagnostics {
+	out := make(Diagnostics, 0, len(d))
+
+	for _, item := range d {
+		redacted := *item
+		redacted.Detail = value.ErrSensitive.Error()
+		out = append(out, &redacted)
+	}
+
+	return out
+}
+
+// Sort orders the diagnostics by position in the source.
+func (d Diagnostics) Sort() {
+	sort.SliceStable(d, func(i, j int) bool {
+		if d[i].Pos.Line != d[j].Pos.Line {
+			return d[i].Pos.Line < d[j].Pos.Line
+		}
+
+		return d[i].Pos.Column < d[j].Pos.Column
+	})
+}
+
+func (d Diagnostics) Error() string {
+	items := make([]string, 0, len(d))
+	for _, item := range d {
+		items = append(items, item.Error())
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func errorDiag(pos Position, summary, detail string) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityError,
+		Summary:  summary,
+		Detail:   detail,
+		Pos:      pos,
+	}
+}
+
+func warningDiag(pos Position, summary, detail string) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityWarning,
+		Summary:  summary,
+		Detail:   detail,
+		Pos:      pos,
+	}
+}
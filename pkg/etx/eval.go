@@ -0,0 +1,1297 @@
+package etx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hexbee-net/etxe/pkg/etx/funcs"
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// EvalContext holds the variables and functions available to an expression
+// during evaluation.
+type EvalContext struct {
+	Variables map[string]value.Value
+	Functions map[string]*value.Function
+
+	// Enums are the enum types referenced as enum.name.
+	Enums map[string]*Enum
+
+	// Types are the object types, and BlockTypes the names of the object
+	// types the blocks are checked against, by block name.
+	Types      map[string]*ObjectType
+	BlockTypes map[string]string
+
+	// BaseDir is the directory the filesystem functions are restricted to,
+	// usually the module directory. Without it, the filesystem functions
+	// fail.
+	BaseDir string
+
+	// Now returns the current time for the timestamp function. It defaults
+	// to time.Now, and can be set to make evaluations reproducible.
+	Now func() time.Time
+
+	parent    *EvalContext
+	callDepth int
+
+	// disabled are the blocks turned off by their condition, by address.
+	disabled map[string]*disabledBlock
+
+	// nondeterministic are the values declared in the context that depend
+	// on a library function whose result changes on every evaluation, with
+	// the name of that function.
+	nondeterministic map[string]string
+
+	// funcs are the functions declared with def in the context, by name.
+	// Whether they depend on such a library function is only known once the
+	// values they reference are declared.
+	funcs map[string]*Func
+}
+
+// NewChild returns a new context whose lookups fall back to c.
+func (c *EvalContext) NewChild() *EvalContext {
+	return &EvalContext{
+		Variables: make(map[string]value.Value),
+		parent:    c,
+	}
+}
+
+// Parent returns the parent context, or nil for a root context.
+func (c *EvalContext) Parent() *EvalContext {
+	return c.parent
+}
+
+func (c *EvalContext) lookupVariable(name string) (value.Value, bool) {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if v, ok := ctx.Variables[name]; ok {
+			return v, true
+		}
+	}
+
+	return value.Null, false
+}
+
+func (c *EvalContext) lookupFunction(name string) (*value.Function, bool) {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if f, ok := ctx.Functions[name]; ok {
+			return f, true
+		}
+	}
+
+	if f, ok := c.sandbox().Function(name); ok {
+		return f, true
+	}
+
+	f, ok := builtinFunctions[name]
+
+	return f, ok
+}
+
+// sandbox returns the environment of the library functions accessing the
+// filesystem or the clock, as set by the closest contexts.
+func (c *EvalContext) sandbox() *funcs.Sandbox {
+	sb := &funcs.Sandbox{Template: c.renderTemplateFile}
+
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if sb.BaseDir == "" {
+			sb.BaseDir = ctx.BaseDir
+		}
+
+		if sb.Now == nil {
+			sb.Now = ctx.Now
+		}
+	}
+
+	return sb
+}
+
+// Eval evaluates an expression into a runtime value.
+//
+// The returned value is null whenever the diagnostics contain errors.
+func Eval(expr *Expr, ctx *EvalContext) (value.Value, Diagnostics) {
+	if ctx == nil {
+		ctx = &EvalContext{}
+	}
+
+	return expr.eval(ctx)
+}
+
+// /////////////////////////////////////
+
+type evaluable interface {
+	Node
+	eval(ctx *EvalContext) (value.Value, Diagnostics)
+	check(ctx *checkContext) staticType
+	fold(f *folder) *constant
+	collapse(lit *Value)
+}
+
+func (e *Expr) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	switch {
+	case e.Error != nil:
+		return e.Error.eval(ctx)
+	case e.Left != nil:
+		return e.Left.eval(ctx)
+	case e.If != nil:
+		return e.If.eval(ctx)
+	case e.Switch != nil:
+		return e.Switch.eval(ctx)
+	default:
+		panic("expression not set")
+	}
+}
+
+func (e *ExprIf) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	cond, diags := evalCondition(ctx, &e.Condition)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if !cond.IsKnown() {
+		v, diags := evalBranches(ctx, diags, e.Left, e.Right)
+
+		return v.InheritSensitive(cond), diags
+	}
+
+	branch := e.Right
+	if cond.AsBool() {
+		branch = e.Left
+	}
+
+	return evalBranch(ctx, cond, branch, diags)
+}
+
+func (e *ExprSwitch) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	selector, diags := e.Selector.eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	var fallback *ExprCase
+
+	// The cases that may match when the selector or their conditions are
+	// unknown, in order.
+	var candidates []*Expr
+
+	// The result is sensitive when a sensitive value took part in selecting
+	// the case.
+	var sensitive value.Value
+
+cases:
+	for _, c := range e.Cases {
+		if c.Default {
+			fallback = c
+
+			continue
+		}
+
+		for _, cond := range c.Conditions {
+			v, condDiags := cond.eval(ctx)
+
+			diags = append(diags, condDiags...)
+			if diags.HasErrors() {
+				return value.Null, diags
+			}
+
+			match, _ := value.Equal(selector, v)
+			if match.IsSensitive() {
+				sensitive = match
+			}
+
+			switch {
+			case !match.IsKnown():
+				candidates = append(candidates, c.Expr)
+
+				continue cases
+			case !match.AsBool():
+			case len(candidates) == 0:
+				return evalBranch(ctx, sensitive, c.Expr, diags)
+			default:
+				res, diags := evalBranches(ctx, diags, append(candidates, c.Expr)...)
+
+				return res.InheritSensitive(sensitive), diags
+			}
+		}
+	}
+
+	if fallback != nil && len(candidates) == 0 {
+		return evalBranch(ctx, sensitive, fallback.Expr, diags)
+	}
+
+	if fallback != nil {
+		candidates = append(candidates, fallback.Expr)
+	}
+
+	if len(candidates) == 0 {
+		return value.Null, append(diags, errorDiag(e.Pos, "No matching case",
+			fmt.Sprintf("switch value %s does not match any case and there is no default case", selector.GoString())))
+	}
+
+	res, diags := evalBranches(ctx, diags, candidates...)
+
+	return res.InheritSensitive(sensitive), diags
+}
+
+// eval aborts the evaluation with the message of the error, which must be a
+// string. A message that is not known yet or sensitive is not displayed.
+func (e *ExprError) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	msg, diags := e.Message.eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if msg.IsKnown() && msg.Kind() != value.KindString {
+		return value.Null, append(diags, errorDiag(e.Message.Pos, "Invalid error message",
+			fmt.Sprintf("error message must be a string, got %s", msg.Kind())))
+	}
+
+	return value.Null, append(diags, errorDiag(e.Pos, "Evaluation aborted", displayString(msg)))
+}
+
+func (e *ExprConditional) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	if !e.ConditionOp {
+		return e.Condition.eval(ctx)
+	}
+
+	cond, diags := evalCondition(ctx, &e.Condition)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if !cond.IsKnown() {
+		v, diags := evalBranches(ctx, diags, e.TrueExpr, e.FalseExpr)
+
+		return v.InheritSensitive(cond), diags
+	}
+
+	branch := e.FalseExpr
+	if cond.AsBool() {
+		branch = e.TrueExpr
+	}
+
+	return evalBranch(ctx, cond, branch, diags)
+}
+
+// evalBranch evaluates the branch selected by cond. A missing branch yields
+// null. The value is sensitive when the condition is.
+func evalBranch(ctx *EvalContext, cond value.Value, branch *Expr, diags Diagnostics) (value.Value, Diagnostics) {
+	if branch == nil {
+		return value.Null.InheritSensitive(cond), diags
+	}
+
+	v, branchDiags := branch.eval(ctx)
+
+	return v.InheritSensitive(cond), append(diags, branchDiags...)
+}
+
+// evalCondition evaluates a condition to a bool, or to an unknown value.
+func evalCondition(ctx *EvalContext, e *ExprLogicalOr) (value.Value, Diagnostics) {
+	v, diags := e.eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if !isBool(v) {
+		return value.Null, append(diags, errorDiag(e.Pos, "Invalid condition",
+			fmt.Sprintf("condition must be a bool, got %s", v.Kind())))
+	}
+
+	return v, diags
+}
+
+// evalBranches evaluates all the branches that may be selected by an
+// unknown condition. The result is the value of all the branches when they
+// agree, or else an unknown value refined with what they have in common. A
+// missing branch yields null.
+//
+// The error branches are left out, as whether they abort the evaluation is
+// not known yet. The result is unknown when all the branches are errors.
+func evalBranches(ctx *EvalContext, diags Diagnostics, branches ...*Expr) (value.Value, Diagnostics) {
+	res, first := value.Unknown(value.TypeAny), true
+
+	for _, branch := range branches {
+		if branch != nil && branch.Error != nil {
+			continue
+		}
+
+		v := value.Null
+
+		if branch != nil {
+			var branchDiags Diagnostics
+
+			v, branchDiags = branch.eval(ctx)
+
+			diags = append(diags, branchDiags...)
+			if diags.HasErrors() {
+				return value.Null, diags
+			}
+		}
+
+		if first {
+			res, first = v, false
+		} else {
+			res = value.Unify(res, v)
+		}
+	}
+
+	return res, diags
+}
+
+// /////////////////////////////////////
+
+// binaryLink is a level of the binary operators grammar.
+//
+// The grammar is right-recursive, so evalBinary walks the chain of links to
+// apply the operators from left to right.
+type binaryLink[T any] interface {
+	Node
+	operand() evaluable
+	operator() string
+	next() T
+	relink(op string, next T)
+}
+
+func (e *ExprLogicalOr) operand() evaluable   { return &e.Left }
+func (e *ExprLogicalOr) operator() string     { return e.Op }
+func (e *ExprLogicalOr) next() *ExprLogicalOr { return e.Right }
+
+func (e *ExprLogicalAnd) operand() evaluable    { return &e.Left }
+func (e *ExprLogicalAnd) operator() string      { return e.Op }
+func (e *ExprLogicalAnd) next() *ExprLogicalAnd { return e.Right }
+
+func (e *ExprBitwiseOr) operand() evaluable   { return &e.Left }
+func (e *ExprBitwiseOr) operator() string     { return e.Op }
+func (e *ExprBitwiseOr) next() *ExprBitwiseOr { return e.Right }
+
+func (e *ExprBitwiseXor) operand() evaluable    { return &e.Left }
+func (e *ExprBitwiseXor) operator() string      { return e.Op }
+func (e *ExprBitwiseXor) next() *ExprBitwiseXor { return e.Right }
+
+func (e *ExprBitwiseAnd) operand() evaluable    { return &e.Left }
+func (e *ExprBitwiseAnd) operator() string      { return e.Op }
+func (e *ExprBitwiseAnd) next() *ExprBitwiseAnd { return e.Right }
+
+func (e *ExprEquality) operand() evaluable  { return &e.Left }
+func (e *ExprEquality) operator() string    { return e.Op }
+func (e *ExprEquality) next() *ExprEquality { return e.Right }
+
+func (e *ExprRelational) operand() evaluable    { return &e.Left }
+func (e *ExprRelational) operator() string      { return e.Op }
+func (e *ExprRelational) next() *ExprRelational { return e.Right }
+
+func (e *ExprShift) operand() evaluable { return &e.Left }
+func (e *ExprShift) operator() string   { return e.Op }
+func (e *ExprShift) next() *ExprShift   { return e.Right }
+
+func (e *ExprAdditive) operand() evaluable  { return &e.Left }
+func (e *ExprAdditive) operator() string    { return e.Op }
+func (e *ExprAdditive) next() *ExprAdditive { return e.Right }
+
+func (e *ExprMultiplicative) operand() evaluable        { return &e.Left }
+func (e *ExprMultiplicative) operator() string          { return e.Op }
+func (e *ExprMultiplicative) next() *ExprMultiplicative { return e.Right }
+
+func (e *ExprLogicalOr) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprLogicalAnd) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprBitwiseOr) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprBitwiseXor) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprBitwiseAnd) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprEquality) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprRelational) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprShift) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprAdditive) eval(ctx *EvalContext) (value.Value, Diagnostics) { return evalBinary(ctx, e) }
+
+func (e *ExprMultiplicative) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	return evalBinary(ctx, e)
+}
+
+//nolint:gochecknoglobals // operator dispatch table
+var binaryOperators = map[string]func(a, b value.Value) (value.Value, error){
+	OpBitwiseOr:         value.BitwiseOr,
+	OpBitwiseXOr:        value.BitwiseXor,
+	OpBitwiseAnd:        value.BitwiseAnd,
+	OpEqual:             value.Equal,
+	OpNotEqual:          value.NotEqual,
+	OpLess:              value.LessThan,
+	OpLessOrEqual:       value.LessThanOrEqual,
+	OpMore:              value.GreaterThan,
+	OpMoreOrEqual:       value.GreaterThanOrEqual,
+	OpBitwiseShiftLeft:  value.ShiftLeft,
+	OpBitwiseShiftRight: value.ShiftRight,
+	OpPlus:              value.Add,
+	OpMinus:             value.Subtract,
+	OpMultiplication:    value.Multiply,
+	OpDivision:          value.Divide,
+	OpModulo:            value.Modulo,
+}
+
+func evalBinary[T binaryLink[T]](ctx *EvalContext, e T) (value.Value, Diagnostics) {
+	acc, diags := e.operand().eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	for link := e; link.operator() != ""; link = link.next() {
+		op, next := link.operator(), link.next()
+
+		if op == OpLogicalOr || op == OpLogicalAnd {
+			if !isBool(acc) {
+				return value.Null, append(diags, operatorDiag(link.Node().Pos, op,
+					fmt.Errorf("%w: %s %s", value.ErrUnsupportedOp, acc.Kind(), op)))
+			}
+
+			// Short-circuit the rest of the chain.
+			if acc.IsKnown() && acc.AsBool() == (op == OpLogicalOr) {
+				return acc, diags
+			}
+		}
+
+		rhs, rhsDiags := next.operand().eval(ctx)
+
+		diags = append(diags, rhsDiags...)
+		if diags.HasErrors() {
+			return value.Null, diags
+		}
+
+		if op == OpLogicalOr || op == OpLogicalAnd {
+			if !isBool(rhs) {
+				return value.Null, append(diags, operatorDiag(next.Node().Pos, op,
+					fmt.Errorf("%w: bool %s %s", value.ErrUnsupportedOp, op, rhs.Kind())))
+			}
+
+			acc = logicalOperator(op, acc, rhs)
+
+			continue
+		}
+
+		res, err := binaryOperators[op](acc, rhs)
+		if err != nil {
+			return value.Null, append(diags, operatorDiag(link.Node().Pos, op, err))
+		}
+
+		acc = res
+	}
+
+	return acc, diags
+}
+
+// displayString returns a string value as is, for the messages written by
+// the user. Other values, and the strings that are not known yet or
+// sensitive, are displayed like in the error messages.
+func displayString(v value.Value) string {
+	if v.IsKnown() && v.Kind() == value.KindString && !v.IsSensitive() {
+		return v.AsString()
+	}
+
+	return v.GoString()
+}
+
+// isBool reports whether v is a bool, or an unknown value that may be one.
+func isBool(v value.Value) bool {
+	if !v.IsKnown() {
+		return value.TypeBool.Accepts(v.Type())
+	}
+
+	return v.Kind() == value.KindBool
+}
+
+// logicalOperator returns the result of lhs op rhs once lhs did not
+// short-circuit the operator: rhs, unless lhs is unknown and rhs does not
+// decide the result on its own. The result is sensitive when either operand
+// is.
+func logicalOperator(op string, lhs, rhs value.Value) value.Value {
+	if lhs.IsKnown() || (rhs.IsKnown() && rhs.AsBool() == (op == OpLogicalOr)) {
+		return rhs.InheritSensitive(lhs)
+	}
+
+	return value.Unknown(value.TypeBool).RefineNotNull().InheritSensitive(lhs, rhs)
+}
+
+func operatorDiag(pos Position, op string, err error) *Diagnostic {
+	return errorDiag(pos, fmt.Sprintf("Invalid operand for %q", op), err.Error())
+}
+
+// /////////////////////////////////////
+
+func (e *ExprUnary) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	v, diags := e.Right.eval(ctx)
+	if diags.HasErrors() || e.Op == "" {
+		return v, diags
+	}
+
+	res, err := unaryOperator(e.Op, v)
+	if err != nil {
+		return value.Null, append(diags, operatorDiag(e.Pos, e.Op, err))
+	}
+
+	return res, diags
+}
+
+// unaryOperator applies the unary operator op to v.
+func unaryOperator(op string, v value.Value) (value.Value, error) {
+	switch op {
+	case OpMinus:
+		return value.Negate(v)
+	case OpPlus:
+		return value.Plus(v)
+	case OpLogicalNot:
+		return value.Not(v)
+	case OpBitwiseNot:
+		return value.BitwiseNot(v)
+	default:
+		panic(fmt.Sprintf("unknown unary operator %q", op))
+	}
+}
+
+func (e *ExprPostfix) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	if enum, ok := ctx.enumOf(&e.Value); ok && e.Index != nil {
+		return e.evalEnumLookup(ctx, enum)
+	}
+
+	v, diags := e.Value.eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return e.evalSuffix(ctx, v, diags)
+}
+
+// evalOn evaluates the postfix expression as an attribute access on recv.
+func (e *ExprPostfix) evalOn(ctx *EvalContext, recv value.Value) (value.Value, Diagnostics) {
+	v, diags := e.Value.evalOn(ctx, recv)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return e.evalSuffix(ctx, v, diags)
+}
+
+func (e *ExprPostfix) evalSuffix(ctx *EvalContext, v value.Value, diags Diagnostics) (value.Value, Diagnostics) {
+	if e.Index != nil {
+		key, keyDiags := e.Index.eval(ctx)
+
+		diags = append(diags, keyDiags...)
+		if diags.HasErrors() {
+			return value.Null, diags
+		}
+
+		res, err := index(v, key)
+		if err != nil {
+			if v.IsSensitive() || key.IsSensitive() {
+				err = value.RedactError(err)
+			}
+
+			return value.Null, append(diags, errorDiag(e.Index.Pos, "Invalid index", err.Error()))
+		}
+
+		v = res.InheritSensitive(v, key)
+	}
+
+	if e.Post != nil {
+		res, postDiags := e.Post.evalOn(ctx, v)
+
+		return res, append(diags, postDiags...)
+	}
+
+	return v, diags
+}
+
+func index(v, key value.Value) (value.Value, error) {
+	if !v.IsKnown() || !key.IsKnown() {
+		return unknownIndex(v, key)
+	}
+
+	switch v.Kind() {
+	case value.KindList:
+		if key.Kind() != value.KindNumber {
+			return value.Null, fmt.Errorf("%w: list index must be a number, got %s", value.ErrArgument, key.Kind())
+		}
+
+		i, err := key.AsInt()
+		if err != nil {
+			return value.Null, err
+		}
+
+		if i < 0 || i >= v.Len() {
+			return value.Null, fmt.Errorf("%w: %d with length %d", value.ErrIndexOutOfRange, i, v.Len())
+		}
+
+		return v.Index(i), nil
+
+	case value.KindMap:
+		if key.Kind() != value.KindString {
+			return value.Null, fmt.Errorf("%w: map key must be a string, got %s", value.ErrArgument, key.Kind())
+		}
+
+		res, ok := v.Get(key.AsString())
+		if !ok {
+			return value.Null, fmt.Errorf("%w: %s", value.ErrKeyNotFound, key.GoString())
+		}
+
+		return res, nil
+
+	default:
+		return value.Null, fmt.Errorf("%w: cannot index a %s", value.ErrUnsupportedOp, v.Kind())
+	}
+}
+
+// unknownIndex returns the unknown element of v at key when either is
+// unknown, refined with the element type of v.
+func unknownIndex(v, key value.Value) (value.Value, error) {
+	t := v.Type()
+
+	switch t.Kind() {
+	case value.TypeKindList, value.TypeKindTuple:
+		if key.IsKnown() && key.Kind() != value.KindNumber {
+			return value.Null, fmt.Errorf("%w: list index must be a number, got %s", value.ErrArgument, key.Kind())
+		}
+
+		if err := checkUnknownIndex(v, key); err != nil {
+			return value.Null, err
+		}
+	case value.TypeKindMap, value.TypeKindObject:
+		if key.IsKnown() && key.Kind() != value.KindString {
+			return value.Null, fmt.Errorf("%w: map key must be a string, got %s", value.ErrArgument, key.Kind())
+		}
+	case value.TypeKindAny:
+		return value.Unknown(value.TypeAny), nil
+	default:
+		return value.Null, fmt.Errorf("%w: cannot index a %s", value.ErrUnsupportedOp, t)
+	}
+
+	if t.Kind() == value.TypeKindList || t.Kind() == value.TypeKindMap {
+		return value.Unknown(t.Elem()), nil
+	}
+
+	return value.Unknown(value.TypeAny), nil
+}
+
+// checkUnknownIndex reports the index errors of an unknown list v at a known
+// key, when the length of v is known.
+func checkUnknownIndex(v, key value.Value) error {
+	if v.IsKnown() || !key.IsKnown() {
+		return nil
+	}
+
+	i, err := key.AsInt()
+	if err != nil {
+		return err
+	}
+
+	n := v.Refinement().Length
+	if t := v.Type(); t.Kind() == value.TypeKindTuple {
+		n = len(t.Elems())
+	}
+
+	switch {
+	case n >= 0 && (i < 0 || i >= n):
+		return fmt.Errorf("%w: %d with length %d", value.ErrIndexOutOfRange, i, n)
+	case i < 0:
+		return fmt.Errorf("%w: %d", value.ErrIndexOutOfRange, i)
+	default:
+		return nil
+	}
+}
+
+func (e *ExprPrimary) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	switch {
+	case e.Lambda != nil:
+		return e.Lambda.eval(ctx)
+	case e.SubExpression != nil:
+		return e.SubExpression.eval(ctx)
+	case e.Value != nil:
+		return e.Value.eval(ctx)
+	case e.Ident != nil:
+		v, diags := e.Ident.resolve(ctx)
+		if diags.HasErrors() {
+			return value.Null, diags
+		}
+
+		return e.evalSuffix(ctx, v, diags)
+	default:
+		panic("identifier not set")
+	}
+}
+
+// evalOn evaluates the primary expression as an attribute access on recv.
+func (e *ExprPrimary) evalOn(ctx *EvalContext, recv value.Value) (value.Value, Diagnostics) {
+	if e.Ident == nil {
+		return value.Null, Diagnostics{errorDiag(e.Pos, "Invalid attribute access", "expected an attribute name after '.'")}
+	}
+
+	v := recv
+
+	for _, part := range e.Ident.Parts {
+		res, err := attribute(v, part)
+		if err != nil {
+			return value.Null, Diagnostics{errorDiag(e.Ident.Pos, "Unsupported attribute", err.Error())}
+		}
+
+		v = res
+	}
+
+	return e.evalSuffix(ctx, v, nil)
+}
+
+func (e *ExprPrimary) evalSuffix(ctx *EvalContext, v value.Value, diags Diagnostics) (value.Value, Diagnostics) {
+	for _, params := range e.Monads {
+		res, callDiags := params.call(ctx, v)
+
+		diags = append(diags, callDiags...)
+		if diags.HasErrors() {
+			return value.Null, diags
+		}
+
+		v = res
+	}
+
+	if e.Post != nil {
+		res, postDiags := e.Post.evalOn(ctx, v)
+
+		return res, append(diags, postDiags...)
+	}
+
+	return v, diags
+}
+
+func (e *ExprInvocationParams) call(ctx *EvalContext, callee value.Value) (value.Value, Diagnostics) {
+	if !callee.IsKnown() && value.TypeFunction.Accepts(callee.Type()) {
+		return e.callUnknown(ctx, callee)
+	}
+
+	if callee.Kind() != value.KindFunction {
+		return value.Null, Diagnostics{errorDiag(e.Pos, "Not a function", fmt.Sprintf("cannot call a %s", callee.Kind()))}
+	}
+
+	var diags Diagnostics
+
+	fn := callee.AsFunction()
+	args := make([]value.Value, 0, len(e.Values))
+
+	for i, item := range e.Values {
+		if fn.IsLazy(i) {
+			args = append(args, thunk(ctx, item))
+
+			continue
+		}
+
+		arg, argDiags := item.eval(ctx)
+
+		diags = append(diags, argDiags...)
+		args = append(args, arg)
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	res, err := fn.Call(args)
+	if err != nil && callee.IsSensitive() {
+		err = value.RedactError(err)
+	}
+
+	if err != nil {
+		// Errors raised while evaluating a lambda body keep their own position,
+		// but not their details once redacted.
+		var bodyDiags Diagnostics
+		if errors.As(err, &bodyDiags) {
+			if errors.Is(err, value.ErrSensitive) {
+				bodyDiags = bodyDiags.redact()
+			}
+
+			return value.Null, append(diags, bodyDiags...)
+		}
+
+		pos := e.Pos
+
+		var argErr *value.ArgError
+		if errors.As(err, &argErr) && argErr.Index < len(e.Values) {
+			pos = e.Values[argErr.Index].Pos
+		}
+
+		return value.Null, append(diags, errorDiag(pos, fmt.Sprintf("Error in function call %q", fn.Name), err.Error()))
+	}
+
+	return res.InheritSensitive(callee), diags
+}
+
+// callUnknown evaluates the arguments of a call to an unknown function,
+// such as a method of an unknown collection. The result is unknown, and
+// sensitive when the callee or any of the arguments is.
+func (e *ExprInvocationParams) callUnknown(ctx *EvalContext, callee value.Value) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	res := value.Unknown(value.TypeAny).InheritSensitive(callee)
+
+	for _, item := range e.Values {
+		arg, argDiags := item.eval(ctx)
+		diags = append(diags, argDiags...)
+
+		if arg.ContainsSensitive() {
+			res = res.MarkSensitive()
+		}
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return res, diags
+}
+
+// thunk returns a function evaluating expr in ctx on each call, passed to
+// the lazy parameters of functions. Evaluation errors are returned as
+// Diagnostics.
+func thunk(ctx *EvalContext, expr *Expr) value.Value {
+	return value.Func(&value.Function{
+		Name: "thunk",
+		Impl: func([]value.Value) (value.Value, error) {
+			v, diags := expr.eval(ctx)
+			if diags.HasErrors() {
+				return value.Null, diags
+			}
+
+			return v, nil
+		},
+	})
+}
+
+// attribute returns the attribute name of v: a map entry, or else a method
+// of the Sequences API bound to v.
+//
+// The attributes of a sensitive value are sensitive.
+func attribute(v value.Value, name string) (value.Value, error) {
+	if !v.IsKnown() {
+		res, err := unknownAttribute(v, name)
+
+		return res.InheritSensitive(v), err
+	}
+
+	if v.Kind() == value.KindMap {
+		if res, ok := v.Get(name); ok {
+			return res.InheritSensitive(v), nil
+		}
+	}
+
+	if res, ok := lookupMethod(v, name); ok {
+		return res.InheritSensitive(v), nil
+	}
+
+	if v.Kind() == value.KindMap {
+		return value.Null, fmt.Errorf("%w: %q", value.ErrKeyNotFound, name)
+	}
+
+	return value.Null, fmt.Errorf("%w: a %s has no attribute %q", value.ErrUnsupportedOp, v.Kind(), name)
+}
+
+// unknownAttribute returns the unknown attribute name of the unknown value
+// v, refined with the type of the attribute when v is refined to an object
+// or a map.
+func unknownAttribute(v value.Value, name string) (value.Value, error) {
+	t := v.Type()
+	_, method := sequenceMethods[name]
+
+	switch t.Kind() {
+	case value.TypeKindAny:
+		return value.Unknown(value.TypeAny), nil
+	case value.TypeKindObject:
+		if attr, ok := t.Attrs()[name]; ok {
+			return value.Unknown(attr), nil
+		}
+	case value.TypeKindMap:
+		if !method {
+			return value.Unknown(t.Elem()), nil
+		}
+	}
+
+	if method && (t.IsCollection() || t.Kind() == value.TypeKindTuple || t.Kind() == value.TypeKindObject) {
+		return value.Unknown(value.TypeAny), nil
+	}
+
+	if t.Kind() == value.TypeKindObject {
+		return value.Null, fmt.Errorf("%w: %q", value.ErrKeyNotFound, name)
+	}
+
+	return value.Null, fmt.Errorf("%w: a %s has no attribute %q", value.ErrUnsupportedOp, t, name)
+}
+
+// resolve returns the value referenced by a dotted identifier. Referencing
+// a disabled block reports the condition that disabled it.
+func (i *Ident) resolve(ctx *EvalContext) (value.Value, Diagnostics) {
+	root := i.Parts[0]
+
+	var diags Diagnostics
+
+	if d := ctx.lookupDisabled(i.Parts); d != nil {
+		if !d.declared {
+			return value.Null, Diagnostics{errorDiag(i.Pos, "Reference to disabled block", d.detail())}
+		}
+
+		diags = append(diags, warningDiag(i.Pos, "Reference to disabled block", d.detail()))
+	}
+
+	v, ok := ctx.lookupVariable(root)
+	if !ok && root == enumNamespace {
+		return i.resolveEnum(ctx)
+	}
+
+	if !ok {
+		fn, isFunc := ctx.lookupFunction(root)
+		if !isFunc {
+			return value.Null, Diagnostics{errorDiag(i.Pos, "Unknown variable", fmt.Sprintf("there is no variable named %q", root))}
+		}
+
+		v = value.Func(fn)
+	}
+
+	for _, part := range i.Parts[1:] {
+		res, err := attribute(v, part)
+		if err != nil {
+			return value.Null, append(diags, errorDiag(i.Pos, "Unsupported attribute", err.Error()))
+		}
+
+		v = res
+	}
+
+	return v, diags
+}
+
+// /////////////////////////////////////
+
+// eval returns the lambda as a function value closing over ctx.
+func (n *Lambda) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	return value.Func(&value.Function{
+		Name:   "lambda",
+		Params: n.params(),
+		Impl: func(args []value.Value) (value.Value, error) {
+			leave, err := ctx.enterCall("lambda")
+			if err != nil {
+				return value.Null, err
+			}
+
+			defer leave()
+
+			scope := ctx.NewChild()
+			for i, p := range n.Parameters {
+				v, diags := p.Type.withDefault(scope, args[i])
+				if diags.HasErrors() {
+					return value.Null, diags
+				}
+
+				if diags := p.Type.conformEnum(scope, v, fmt.Sprintf("parameter %q", p.Label), p.Pos); diags.HasErrors() {
+					return value.Null, diags
+				}
+
+				scope.Variables[p.Label] = v
+			}
+
+			res, diags := n.Expr.eval(scope)
+			if diags.HasErrors() {
+				return value.Null, diags
+			}
+
+			return res, nil
+		},
+	}), nil
+}
+
+// params returns the runtime parameters of the lambda.
+func (n *Lambda) params() []value.Param {
+	params := make([]value.Param, 0, len(n.Parameters))
+
+	for _, p := range n.Parameters {
+		param := value.Param{Name: p.Label, AllowNull: true, AllowUnknown: true, AllowSensitive: true}
+		if p.Type != nil {
+			param.Type = p.Type.valueType()
+		}
+
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// valueType returns the runtime type described by a parameter type.
+// Named types that are not built-in are not checked at runtime, optional
+// types are checked as their underlying type, and a value conforms to a
+// union when it conforms to one of its members.
+func (n *ParameterType) valueType() value.Type {
+	switch {
+	case n.Or != nil:
+		first := *n
+		first.Or = nil
+
+		return value.UnionOf(first.valueType(), n.Or.valueType())
+	case n.Func != nil:
+		return value.TypeFunction
+	case n.Optional != nil:
+		return n.Optional.valueType()
+	case n.Tuple:
+		elems := make([]value.Type, 0, len(n.Elems))
+		for _, item := range n.Elems {
+			elems = append(elems, item.valueType())
+		}
+
+		return value.TupleOf(elems...)
+	}
+
+	switch n.Ident.FormattedString() {
+	case "bool":
+		return value.TypeBool
+	case "number":
+		return value.TypeNumber
+	case "string":
+		return value.TypeString
+	case "list":
+		return value.ListOf(n.elemType())
+	case "set":
+		return value.SetOf(n.elemType())
+	case "map":
+		return value.MapOf(n.elemType())
+	default:
+		return value.TypeAny
+	}
+}
+
+// withDefault returns the default of an optional type when v is null, and v
+// otherwise. The default must conform to the underlying type.
+func (n *ParameterType) withDefault(ctx *EvalContext, v value.Value) (value.Value, Diagnostics) {
+	if n == nil || n.Optional == nil || n.Default == nil || n.Or != nil || !v.IsNull() {
+		return v, nil
+	}
+
+	d, diags := n.Default.eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if t := n.Optional.valueType(); !t.Conforms(d) {
+		return value.Null, append(diags, errorDiag(n.Default.Pos, "Invalid default", fmt.Sprintf("default must be a %s, got %s", t, d.Type())))
+	}
+
+	return d, diags
+}
+
+// elemType returns the element type of a collection type, given by its
+// single type argument.
+func (n *ParameterType) elemType() value.Type {
+	if len(n.Args) != 1 {
+		return value.TypeAny
+	}
+
+	return n.Args[0].valueType()
+}
+
+// /////////////////////////////////////
+
+func (v *Value) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	switch {
+	case v.Null:
+		return value.Null, nil
+	case v.Bool != nil:
+		return value.Bool(v.Bool.Value), nil
+	case v.Number != nil:
+		return v.Number.eval()
+	case v.Str != nil:
+		return v.Str.eval(ctx)
+	case v.Heredoc != nil:
+		return v.Heredoc.eval(ctx)
+	case v.ForList != nil:
+		return v.ForList.eval(ctx)
+	case v.ForMap != nil:
+		return v.ForMap.eval(ctx)
+	case v.List != nil:
+		return v.List.eval(ctx)
+	case v.Map != nil:
+		return v.Map.eval(ctx)
+	default:
+		panic("value not set")
+	}
+}
+
+func (v *ValueNumber) eval() (value.Value, Diagnostics) {
+	if v.Source == "" {
+		return value.Number(v.Value), nil
+	}
+
+	// Parse the source again to get the full runtime precision.
+	f, _, err := new(big.Float).SetPrec(value.NumberPrecision).Parse(v.Source, 0)
+	if err != nil {
+		return value.Null, Diagnostics{errorDiag(v.Pos, "Invalid number", err.Error())}
+	}
+
+	return value.Number(f), nil
+}
+
+func (v *ValueString) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	parts := make([]templatePart, 0, len(v.Fragment))
+
+	for _, f := range v.Fragment {
+		part := templatePart{
+			pos:        f.Pos,
+			expr:       f.Expr,
+			directive:  f.Directive,
+			stripLeft:  f.StripLeft,
+			stripRight: f.StripRight,
+		}
+
+		switch {
+		case f.Escaped != "":
+			part.text = unescape(f.Escaped)
+		case f.Unicode != "":
+			r, err := strconv.ParseUint(f.Unicode, 16, 32)
+			if err != nil {
+				return value.Null, Diagnostics{errorDiag(f.Pos, "Invalid unicode escape", err.Error())}
+			}
+
+			part.text = string(rune(r))
+		case f.Expr == nil && f.Directive == nil:
+			part.text = unescapeTemplate(f.Text)
+		}
+
+		parts = append(parts, part)
+	}
+
+	return evalTemplate(ctx, parts)
+}
+
+func unescape(s string) string {
+	switch c := strings.TrimPrefix(s, `\`); c {
+	case "n":
+		return "\n"
+	case "r":
+		return "\r"
+	case "t":
+		return "\t"
+	default:
+		return c
+	}
+}
+
+func (v *Heredoc) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	return evalTemplate(ctx, heredocParts(v.Body()))
+}
+
+func heredocParts(fragments []*HeredocFragment) []templatePart {
+	parts := make([]templatePart, 0, len(fragments))
+
+	for _, f := range fragments {
+		parts = append(parts, templatePart{
+			pos:        f.Pos,
+			text:       unescapeTemplate(f.Text),
+			expr:       f.Expr,
+			directive:  f.Directive,
+			stripLeft:  f.StripLeft,
+			stripRight: f.StripRight,
+		})
+	}
+
+	return parts
+}
+
+func (v *ValueList) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	items := make([]value.Value, 0, len(v.Items))
+
+	for _, item := range v.Items {
+		if item.Value == nil {
+			continue
+		}
+
+		res, itemDiags := item.Value.eval(ctx)
+
+		diags = append(diags, itemDiags...)
+		items = append(items, res)
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return value.List(items...), diags
+}
+
+// eval returns the map of the entries. A map with unknown keys is unknown,
+// and a map with sensitive keys is sensitive.
+func (v *ValueMap) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	items := make(map[string]value.Value, len(v.Items))
+	known, sensitive := true, false
+
+	for _, item := range v.Items {
+		if item.Key == nil {
+			continue
+		}
+
+		key, keyDiags := item.Key.eval(ctx)
+
+		diags = append(diags, keyDiags...)
+		if keyDiags.HasErrors() {
+			continue
+		}
+
+		sensitive = sensitive || key.IsSensitive()
+
+		if !key.IsKnown() {
+			_, valueDiags := item.Value.eval(ctx)
+
+			diags = append(diags, valueDiags...)
+			known = false
+
+			continue
+		}
+
+		if _, exists := items[key.AsString()]; exists {
+			if sensitive {
+				// The key may duplicate a sensitive key.
+				key = key.MarkSensitive()
+			}
+
+			diags = append(diags, errorDiag(item.Key.Pos, "Duplicate map key", fmt.Sprintf("key %s is already defined", key.GoString())))
+
+			continue
+		}
+
+		res, valueDiags := item.Value.eval(ctx)
+
+		diags = append(diags, valueDiags...)
+		items[key.AsString()] = res
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	res := value.Map(items)
+	if !known {
+		res = value.Unknown(value.TypeAny).RefineNotNull()
+	}
+
+	if sensitive {
+		res = res.MarkSensitive()
+	}
+
+	return res, diags
+}
+
+func (v *MapKey) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	if v.Ident != nil {
+		return value.String(v.Ident.FormattedString()), nil
+	}
+
+	return v.Str.eval(ctx)
+}
@@ -0,0 +1,706 @@
+package etx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const (
+	metaIf      = "if"
+	metaForEach = "for_each"
+	metaCount   = "count"
+
+	eachVariable  = "each"
+	countVariable = "count"
+
+	// maxCount is the maximum number of instances of a block repeated by
+	// its count meta-attribute.
+	maxCount = 1024
+)
+
+// nondeterministicFunctions are the library functions whose result changes
+// on every evaluation. The instances of a repeated block cannot depend on
+// them, as their addresses would change too.
+//
+//nolint:gochecknoglobals // immutable name set
+var nondeterministicFunctions = map[string]bool{
+	"bcrypt":    true,
+	"timestamp": true,
+	"uuid":      true,
+}
+
+// blockEntry is a block declared by DefineBlocks, with the top-level if
+// block it belongs to, if any.
+type blockEntry struct {
+	block  *Block
+	parent *IfBlock
+}
+
+// disabledBlock is a block turned off by a false condition. The blocks of a
+// top-level if block are not declared, whereas a block whose if
+// meta-attribute is false is declared with null attributes.
+type disabledBlock struct {
+	block    *Block
+	cond     Position
+	declared bool
+}
+
+// detail explains which condition disabled the block.
+func (d *disabledBlock) detail() string {
+	if d.declared {
+		return fmt.Sprintf("the attributes of %s are null, as its if condition at %s is false", describeBlock(d.block), d.cond)
+	}
+
+	return fmt.Sprintf("%s is not declared, as the condition of its if block at %s is false", describeBlock(d.block), d.cond)
+}
+
+// describeBlock returns the name and labels of a block, as written in
+// diagnostics.
+func describeBlock(b *Block) string {
+	var sb strings.Builder
+
+	sb.WriteString(b.Name)
+
+	for _, label := range b.Labels {
+		mustFprintf(&sb, " %q", label)
+	}
+
+	return sb.String()
+}
+
+// DefineBlocks evaluates the blocks at the root of an AST and in its
+// top-level if blocks, and declares them in the context by name and labels,
+// such as resource.foo for resource "foo". The blocks are evaluated in
+// dependency order, once the values they reference are defined in the
+// context. Check blocks are evaluated by EvalChecks instead.
+//
+// A block whose if meta-attribute is false is declared with null
+// attributes, and the blocks of an if block whose condition is false are
+// not declared. A reference to a disabled block reports the condition that
+// disabled it, as a warning or an error respectively. When a condition is
+// not known yet, the attributes of the blocks it applies to are unknown
+// values that may be null.
+//
+// A block with a for_each meta-attribute is declared as a map of instances
+// by key, such as resource.foo["key"], and a block with a count
+// meta-attribute as a list of instances by index. The keys must be known
+// when the block is evaluated.
+func (c *EvalContext) DefineBlocks(ast *AST) Diagnostics {
+	if c.Variables == nil {
+		c.Variables = make(map[string]value.Value)
+	}
+
+	entries, diags := blockEntries(ast)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	sorted, sortDiags := sortBlocks(entries)
+	if sortDiags.HasErrors() {
+		return append(diags, sortDiags...)
+	}
+
+	var (
+		conds    = make(map[*IfBlock]value.Value)
+		declared = make(map[string]*Block)
+		names    = make(map[string]bool)
+	)
+
+	for _, entry := range sorted {
+		b, address := entry.block, blockAddress(entry.block)
+
+		parent := value.True
+
+		if entry.parent != nil {
+			cond, ok := conds[entry.parent]
+			if !ok {
+				var condDiags Diagnostics
+
+				cond, condDiags = evalCondition(c, &entry.parent.Condition)
+
+				diags = append(diags, condDiags...)
+				if condDiags.HasErrors() {
+					return diags
+				}
+
+				conds[entry.parent] = cond
+			}
+
+			if cond.IsKnown() && !cond.AsBool() {
+				if _, exists := declared[address]; !exists && c.disabled[address] == nil {
+					c.disable(address, &disabledBlock{block: b, cond: entry.parent.Condition.Pos})
+				}
+
+				continue
+			}
+
+			parent = cond
+		}
+
+		if prev, exists := declared[address]; exists {
+			return append(diags, errorDiag(b.Pos, "Duplicate block",
+				fmt.Sprintf("%s is already declared at %s", describeBlock(b), prev.Pos)))
+		}
+
+		v, blockDiags := c.evalConditionalBlock(b, address, parent)
+
+		diags = append(diags, blockDiags...)
+		if blockDiags.HasErrors() {
+			return diags
+		}
+
+		if _, exists := c.Variables[b.Name]; exists && !names[b.Name] {
+			return append(diags, errorDiag(b.Pos, "Duplicate declaration", fmt.Sprintf("%q is already declared in this scope", b.Name)))
+		}
+
+		declared[address] = b
+		names[b.Name] = true
+		c.Variables[b.Name] = withElem(c.Variables[b.Name], b.Labels, v)
+	}
+
+	return diags
+}
+
+// evalConditionalBlock evaluates a block enabled by the condition of its
+// parent if block, if any, and by its own if meta-attribute. A disabled
+// block has null attributes, and its body is not evaluated. The instances
+// of a repeated block are unknown, and may be null, when the condition of its
+// parent is.
+func (c *EvalContext) evalConditionalBlock(b *Block, address string, parent value.Value) (value.Value, Diagnostics) {
+	repeat, diags := repetition(b)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if repeat != nil {
+		delete(c.disabled, address)
+
+		v, instDiags := c.evalInstances(b, repeat)
+
+		diags = append(diags, instDiags...)
+		if instDiags.HasErrors() {
+			return value.Null, diags
+		}
+
+		if !parent.IsKnown() {
+			v = value.Unknown(v.Type())
+		}
+
+		return v.InheritSensitive(parent), diags
+	}
+
+	cond, condDiags := c.evalBlockCondition(b)
+
+	diags = append(diags, condDiags...)
+	if condDiags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if cond.IsKnown() && !cond.AsBool() {
+		if meta := b.Condition(); meta != nil {
+			c.disable(address, &disabledBlock{block: b, cond: meta.Pos, declared: true})
+		}
+
+		return nullBlock(b).InheritSensitive(parent, cond), diags
+	}
+
+	delete(c.disabled, address)
+
+	v, blockDiags := c.EvalBlock(b)
+
+	diags = append(diags, blockDiags...)
+	if blockDiags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if !cond.IsKnown() || !parent.IsKnown() {
+		v = nullable(v)
+	}
+
+	return v.InheritSensitive(parent, cond), diags
+}
+
+// evalBlockCondition evaluates the if meta-attribute of a block, which is
+// true when the block has none.
+func (c *EvalContext) evalBlockCondition(b *Block) (value.Value, Diagnostics) {
+	meta := b.Condition()
+	if meta == nil {
+		return value.True, nil
+	}
+
+	v, diags := meta.eval(c)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if !isBool(v) {
+		return value.Null, append(diags, errorDiag(meta.Pos, "Invalid condition",
+			fmt.Sprintf("condition must be a bool, got %s", v.Kind())))
+	}
+
+	return v, diags
+}
+
+// repetition returns the for_each or count meta-attribute of a block, if
+// any. A repeated block cannot have another for_each, count or if
+// meta-attribute: its instances are filtered by the collection instead.
+func repetition(b *Block) (*BlockItem, Diagnostics) {
+	repeat := b.Repetition()
+	if repeat == nil {
+		return nil, nil
+	}
+
+	for _, item := range b.Body {
+		if other := metaAttribute(item); item != repeat && other != "" {
+			return nil, Diagnostics{errorDiag(item.Pos, "Conflicting meta-attributes",
+				fmt.Sprintf("%s cannot have both %s and %s", describeBlock(b), metaAttribute(repeat), other))}
+		}
+	}
+
+	return repeat, nil
+}
+
+// metaAttribute returns the name of the meta-attribute of a block item, or
+// an empty string.
+func metaAttribute(item *BlockItem) string {
+	switch {
+	case item.If != nil:
+		return metaIf
+	case item.ForEach != nil:
+		return metaForEach
+	case item.Count != nil:
+		return metaCount
+	default:
+		return ""
+	}
+}
+
+// evalInstances evaluates the instances of a block repeated by its for_each
+// or count meta-attribute: a map by key for for_each, and a list by index
+// for count. Each instance is evaluated in a scope holding each.key and
+// each.value, or count.index.
+func (c *EvalContext) evalInstances(b *Block, repeat *BlockItem) (value.Value, Diagnostics) {
+	if repeat.Count != nil {
+		n, diags := c.evalCount(repeat.Count)
+		if diags.HasErrors() {
+			return value.Null, diags
+		}
+
+		items := make([]value.Value, 0, n)
+
+		for i := 0; i < n; i++ {
+			scope := c.NewChild()
+			scope.Variables[countVariable] = value.Map(map[string]value.Value{"index": value.Int(int64(i))})
+
+			v, blockDiags := scope.EvalBlock(b)
+
+			diags = append(diags, blockDiags...)
+			items = append(items, v)
+		}
+
+		if diags.HasErrors() {
+			return value.Null, diags
+		}
+
+		return value.List(items...), diags
+	}
+
+	keys, elems, diags := c.evalForEach(repeat.ForEach)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	items := make(map[string]value.Value, len(keys))
+
+	for _, key := range keys {
+		scope := c.NewChild()
+		scope.Variables[eachVariable] = value.Map(map[string]value.Value{"key": value.String(key), "value": elems[key]})
+
+		v, blockDiags := scope.EvalBlock(b)
+
+		diags = append(diags, blockDiags...)
+		items[key] = v
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return value.Map(items), diags
+}
+
+// evalForEach evaluates a for_each meta-attribute into the keys of the
+// instances, in order, and the value of each.value by key. The keys of a
+// map are sorted, and the strings of a set or list are their own values.
+func (c *EvalContext) evalForEach(expr *Expr) ([]string, map[string]value.Value, Diagnostics) {
+	if diags := c.checkDeterministic(expr, metaForEach); diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	v, diags := expr.eval(c)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	invalid := func(detail string, args ...any) Diagnostics {
+		return append(diags, errorDiag(expr.Pos, "Invalid for_each argument", fmt.Sprintf(detail, args...)))
+	}
+
+	switch {
+	case !v.IsKnown():
+		return nil, nil, invalid("the keys of for_each must be known when the block is evaluated")
+	case v.IsSensitive():
+		return nil, nil, invalid("for_each must not be sensitive, as its keys are part of the addresses of the instances")
+	}
+
+	switch v.Kind() {
+	case value.KindMap:
+		elems := v.AsMap()
+
+		keys := make([]string, 0, len(elems))
+		for key := range elems {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		return keys, elems, diags
+
+	case value.KindSet, value.KindList:
+		keys := make([]string, 0, v.Len())
+		elems := make(map[string]value.Value, v.Len())
+
+		for _, item := range v.AsList() {
+			switch {
+			case !item.IsKnown():
+				return nil, nil, invalid("the keys of for_each must be known when the block is evaluated")
+			case item.IsSensitive():
+				return nil, nil, invalid("for_each must not be sensitive, as its keys are part of the addresses of the instances")
+			case item.Kind() != value.KindString:
+				return nil, nil, invalid("for_each must hold strings to be used as keys, got %s", item.Kind())
+			}
+
+			key := item.AsString()
+			if _, exists := elems[key]; exists {
+				return nil, nil, append(diags, errorDiag(expr.Pos, "Duplicate key", fmt.Sprintf("for_each holds %q more than once", key)))
+			}
+
+			keys = append(keys, key)
+			elems[key] = item
+		}
+
+		return keys, elems, diags
+
+	default:
+		return nil, nil, invalid("for_each must be a map, or a set or list of strings, got %s", v.Kind())
+	}
+}
+
+// evalCount evaluates a count meta-attribute into the number of instances.
+func (c *EvalContext) evalCount(expr *Expr) (int, Diagnostics) {
+	if diags := c.checkDeterministic(expr, metaCount); diags.HasErrors() {
+		return 0, diags
+	}
+
+	v, diags := expr.eval(c)
+	if diags.HasErrors() {
+		return 0, diags
+	}
+
+	invalid := func(detail string, args ...any) Diagnostics {
+		return append(diags, errorDiag(expr.Pos, "Invalid count argument", fmt.Sprintf(detail, args...)))
+	}
+
+	switch {
+	case !v.IsKnown():
+		return 0, invalid("count must be known when the block is evaluated")
+	case v.IsSensitive():
+		return 0, invalid("count must not be sensitive, as it gives the addresses of the instances")
+	case v.Kind() != value.KindNumber:
+		return 0, invalid("count must be a number, got %s", v.Kind())
+	}
+
+	n, err := v.AsInt()
+	if err != nil || n < 0 {
+		return 0, invalid("count must be a non-negative whole number, got %s", v.GoString())
+	}
+
+	if n > maxCount {
+		return 0, invalid("count must be at most %d, got %d", maxCount, n)
+	}
+
+	return n, diags
+}
+
+// checkDeterministic reports the references of the expression of a
+// meta-attribute to the library functions whose result changes on every
+// evaluation, and to the values and functions depending on them, unless a
+// variable or a function of the context shadows them.
+func (c *EvalContext) checkDeterministic(expr *Expr, meta string) Diagnostics {
+	var diags Diagnostics
+
+	collectReferences(expr, nil, func(ident *Ident) {
+		name := ident.Parts[0]
+
+		source := c.nondeterministicSource(name)
+		switch source {
+		case "":
+			return
+		case name:
+			diags = append(diags, errorDiag(ident.Pos, "Non-deterministic keys",
+				fmt.Sprintf("%s must not depend on %s, whose result changes on every evaluation", meta, name)))
+		default:
+			diags = append(diags, errorDiag(ident.Pos, "Non-deterministic keys",
+				fmt.Sprintf("%s must not depend on %s, which depends on %s, whose result changes on every evaluation", meta, name, source)))
+		}
+	})
+
+	return diags
+}
+
+// nondeterministicSource returns the library function whose result changes
+// on every evaluation that a name depends on, or an empty string. A value or
+// a function of the context depends on the functions its declaration
+// references, directly or through other values and functions.
+func (c *EvalContext) nondeterministicSource(name string) string {
+	return c.nondeterministicSourceOf(name, map[*Func]bool{})
+}
+
+// nondeterministicSourceOf is nondeterministicSource, skipping the functions
+// being visited. The bodies of the functions declared with def are walked
+// on each lookup, as the values they reference may be declared after them.
+func (c *EvalContext) nondeterministicSourceOf(name string, visiting map[*Func]bool) string {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if _, isVar := ctx.Variables[name]; isVar {
+			return ctx.nondeterministic[name]
+		}
+
+		if _, isFunc := ctx.Functions[name]; !isFunc {
+			continue
+		}
+
+		fn := ctx.funcs[name]
+		if fn == nil || visiting[fn] {
+			return ""
+		}
+
+		visiting[fn] = true
+
+		params := make(map[string]bool, len(fn.Parameters))
+		for _, p := range fn.Parameters {
+			params[p.Label] = true
+		}
+
+		for _, stmt := range fn.Body {
+			if source := ctx.nondeterministicDependencyOf(stmt, params, visiting); source != "" {
+				return source
+			}
+		}
+
+		return ""
+	}
+
+	if nondeterministicFunctions[name] {
+		return name
+	}
+
+	return ""
+}
+
+// nondeterministicDependency returns the library function whose result
+// changes on every evaluation that a node depends on, or an empty string.
+// The names in shadowed are not references.
+func (c *EvalContext) nondeterministicDependency(node Node, shadowed map[string]bool) string {
+	return c.nondeterministicDependencyOf(node, shadowed, map[*Func]bool{})
+}
+
+// nondeterministicDependencyOf is nondeterministicDependency, skipping the
+// functions being visited.
+func (c *EvalContext) nondeterministicDependencyOf(node Node, shadowed map[string]bool, visiting map[*Func]bool) string {
+	var source string
+
+	collectReferences(node, shadowed, func(ident *Ident) {
+		if source == "" {
+			source = c.nondeterministicSourceOf(ident.Parts[0], visiting)
+		}
+	})
+
+	return source
+}
+
+// markNondeterministic records that the value declared in the context with
+// the name depends on source.
+func (c *EvalContext) markNondeterministic(name, source string) {
+	if c.nondeterministic == nil {
+		c.nondeterministic = make(map[string]string)
+	}
+
+	c.nondeterministic[name] = source
+}
+
+// instanceList returns the instances of a repeated block as a list, by key
+// or by index.
+func instanceList(v value.Value) []value.Value {
+	if v.Kind() != value.KindMap {
+		return v.AsList()
+	}
+
+	elems := v.AsMap()
+
+	keys := make([]string, 0, len(elems))
+	for key := range elems {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	items := make([]value.Value, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, elems[key])
+	}
+
+	return items
+}
+
+// disable records that the block at address is disabled.
+func (c *EvalContext) disable(address string, d *disabledBlock) {
+	if c.disabled == nil {
+		c.disabled = make(map[string]*disabledBlock)
+	}
+
+	c.disabled[address] = d
+}
+
+// lookupDisabled returns the disabled block referenced by the parts of an
+// identifier, unless the name is declared by a closer context.
+func (c *EvalContext) lookupDisabled(parts []string) *disabledBlock {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		for n := len(parts); n > 0; n-- {
+			if d, ok := ctx.disabled[strings.Join(parts[:n], ".")]; ok {
+				return d
+			}
+		}
+
+		if _, ok := ctx.Variables[parts[0]]; ok {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// blockEntries returns the blocks of an AST declared by DefineBlocks.
+func blockEntries(ast *AST) ([]*blockEntry, Diagnostics) {
+	var (
+		entries []*blockEntry
+		diags   Diagnostics
+	)
+
+	for _, item := range ast.Items {
+		switch {
+		case item.Block != nil && item.Block.Name != checkBlock:
+			entries = append(entries, &blockEntry{block: item.Block})
+		case item.If != nil:
+			for _, body := range item.If.Body {
+				switch {
+				case body.Block != nil:
+					entries = append(entries, &blockEntry{block: body.Block, parent: item.If})
+				case body.Attribute != nil || body.If != nil:
+					diags = append(diags, errorDiag(body.Pos, "Unexpected attribute", "an if block only holds blocks"))
+				}
+			}
+		}
+	}
+
+	return entries, diags
+}
+
+// sortBlocks orders the blocks so that each block comes after the blocks
+// referenced by its body and by the condition of its if block.
+func sortBlocks(entries []*blockEntry) ([]*blockEntry, Diagnostics) {
+	sorted, cycle := sortDependencies(entries, func(entry *blockEntry) []*blockEntry {
+		var deps []*blockEntry
+
+		add := func(ident *Ident) {
+			for _, dep := range entries {
+				if dep.block.matches(ident.Parts) {
+					deps = append(deps, dep)
+				}
+			}
+		}
+
+		collectReferences(entry.block, nil, add)
+
+		if entry.parent != nil {
+			collectReferences(&entry.parent.Condition, nil, add)
+		}
+
+		return deps
+	})
+
+	if cycle != nil {
+		path := make([]string, 0, len(cycle))
+		for _, entry := range cycle {
+			path = append(path, blockAddress(entry.block))
+		}
+
+		b := cycle[len(cycle)-1].block
+
+		return nil, Diagnostics{errorDiag(b.Pos, "Reference cycle",
+			fmt.Sprintf("%s depends on itself: %s", describeBlock(b), strings.Join(path, " -> ")))}
+	}
+
+	return sorted, nil
+}
+
+// nullBlock returns the value of a disabled block: the attributes and nested
+// blocks of its body, all null.
+func nullBlock(b *Block) value.Value {
+	attrs := make(map[string]value.Value)
+
+	for _, item := range b.Body {
+		switch {
+		case item.Attribute != nil:
+			attrs[item.Attribute.Key] = value.Null
+		case item.Block != nil:
+			attrs[item.Block.Name] = value.Null
+		}
+	}
+
+	return value.Map(attrs)
+}
+
+// nullable returns the attributes of a block that may be disabled, as
+// unknown values that may be null.
+func nullable(v value.Value) value.Value {
+	if v.Kind() != value.KindMap {
+		return value.Unify(v, value.Null)
+	}
+
+	attrs := v.AsMap()
+	for k, item := range attrs {
+		attrs[k] = value.Unify(item, value.Null)
+	}
+
+	return value.Map(attrs).InheritSensitive(v)
+}
+
+// withElem returns the map m with v at the path of nested keys, or v itself
+// when the path is empty.
+func withElem(m value.Value, path []string, v value.Value) value.Value {
+	if len(path) == 0 {
+		return v
+	}
+
+	attrs := make(map[string]value.Value)
+	if m.Kind() == value.KindMap {
+		attrs = m.AsMap()
+	}
+
+	attrs[path[0]] = withElem(attrs[path[0]], path[1:], v)
+
+	return value.Map(attrs)
+}
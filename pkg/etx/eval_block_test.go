@@ -0,0 +1,436 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestEvalContext_DefineBlocks(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+val enabled = false
+
+resource "web" {
+	name = "web-${resource.db.port}"
+}
+
+resource "db" {
+	port = 5432
+}
+
+resource "cache" {
+	if: enabled
+	size = 1
+	node {}
+}
+
+if enabled {
+	resource "queue" {
+		size = 2
+	}
+}
+
+if !enabled {
+	resource "queue" {
+		size = 3
+	}
+}
+
+resource "app" {
+	server {
+		if: enabled
+		host = "a"
+	}
+	server {
+		host = "b"
+	}
+}
+
+data {
+	size = resource.cache.size
+	cached = "${resource.cache.size != null}"
+}
+
+check "web" {
+	condition = false
+}
+`)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+
+	diags := ctx.DefineValues(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	diags = ctx.DefineBlocks(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	require.Len(t, diags, 2)
+	assert.Equal(t, `41:9: warning: Reference to disabled block; the attributes of resource "cache" are null, as its if condition at 13:6 is false`, diags[0].Error())
+	assert.Equal(t, `42:14: warning: Reference to disabled block; the attributes of resource "cache" are null, as its if condition at 13:6 is false`, diags[1].Error())
+
+	want := map[string]value.Value{
+		"resource": value.Map(map[string]value.Value{
+			"web":   value.Map(map[string]value.Value{"name": value.String("web-5432")}),
+			"db":    value.Map(map[string]value.Value{"port": value.Int(5432)}),
+			"cache": value.Map(map[string]value.Value{"size": value.Null, "node": value.Null}),
+			"queue": value.Map(map[string]value.Value{"size": value.Int(3)}),
+			"app": value.Map(map[string]value.Value{
+				"server": value.List(value.Map(map[string]value.Value{"host": value.String("b")})),
+			}),
+		}),
+		"data": value.Map(map[string]value.Value{"size": value.Null, "cached": value.String("false")}),
+	}
+
+	for name, v := range want {
+		assert.True(t, v.Equals(ctx.Variables[name]), "%s: want %s, got %s", name, v.GoString(), ctx.Variables[name].GoString())
+	}
+
+	assert.NotContains(t, ctx.Variables, "check")
+}
+
+func TestEvalContext_DefineBlocksUnknown(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+resource "a" {
+	if: ready
+	id = "a"
+}
+
+if ready {
+	resource "b" {
+		id = "b"
+	}
+
+	resource "d" {
+		count: 2
+		id = "d"
+	}
+}
+
+resource "c" {
+	server {
+		if: ready
+	}
+}
+`)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	ctx.Variables["ready"] = value.Unknown(value.TypeBool)
+
+	diags := ctx.DefineBlocks(ast)
+	require.Empty(t, diags, diags.Error())
+
+	tests := map[string]string{
+		`resource.a.id`:      "unknown(string)",
+		`resource.b.id`:      "unknown(string)",
+		`resource.c.server`:  "unknown(list(any), not null)",
+		`resource.d`:         "unknown(list(map(string)))",
+		`resource.d == null`: "unknown(bool, not null)",
+	}
+
+	for input, want := range tests {
+		res, diags := Eval(parseTestExpr(t, input), ctx)
+		require.False(t, diags.HasErrors(), diags.Error())
+		assert.Equal(t, want, res.GoString(), input)
+	}
+}
+
+func TestEvalContext_DefineBlocksRepeated(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+val ports = { http = 80, https = 443 }
+
+resource "listener" {
+	for_each: ports
+	name = "${each.key}:${each.value}"
+}
+
+resource "zone" {
+	for_each: ["b", "a"]
+	id = upper(each.value)
+}
+
+resource "node" {
+	count: 2
+	index = count.index
+	disk {
+		count: count.index + 1
+		size = 10
+	}
+}
+
+resource "app" {
+	listener = resource.listener["https"].name
+	nodes = length(resource.node)
+}
+`)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+
+	diags := ctx.DefineValues(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	diags = ctx.DefineBlocks(ast)
+	require.Empty(t, diags, diags.Error())
+
+	disk := value.Map(map[string]value.Value{"size": value.Int(10)})
+
+	want := value.Map(map[string]value.Value{
+		"listener": value.Map(map[string]value.Value{
+			"http":  value.Map(map[string]value.Value{"name": value.String("http:80")}),
+			"https": value.Map(map[string]value.Value{"name": value.String("https:443")}),
+		}),
+		"zone": value.Map(map[string]value.Value{
+			"a": value.Map(map[string]value.Value{"id": value.String("A")}),
+			"b": value.Map(map[string]value.Value{"id": value.String("B")}),
+		}),
+		"node": value.List(
+			value.Map(map[string]value.Value{"index": value.Int(0), "disk": value.List(disk)}),
+			value.Map(map[string]value.Value{"index": value.Int(1), "disk": value.List(disk, disk)}),
+		),
+		"app": value.Map(map[string]value.Value{"listener": value.String("https:443"), "nodes": value.Int(2)}),
+	})
+
+	assert.True(t, want.Equals(ctx.Variables["resource"]), "want %s, got %s", want.GoString(), ctx.Variables["resource"].GoString())
+}
+
+func TestEvalContext_DefineBlocksErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+		line    int
+		column  int
+	}{
+		{
+			name:    "Reference to disabled block",
+			input:   "if false {\n\tresource \"a\" {}\n}\nresource \"b\" {\n\tid = resource.a.id\n}",
+			summary: "Reference to disabled block",
+			detail:  `resource "a" is not declared, as the condition of its if block at 1:4 is false`,
+			line:    5,
+			column:  7,
+		},
+		{
+			name:    "Cycle",
+			input:   "resource \"a\" {\n\tid = resource.b.id\n}\nresource \"b\" {\n\tid = resource.a.id\n}",
+			summary: "Reference cycle",
+			detail:  `resource "a" depends on itself: resource.a -> resource.b -> resource.a`,
+			line:    1,
+			column:  1,
+		},
+		{
+			name:    "Duplicate block",
+			input:   "resource \"a\" {}\nresource \"a\" {}",
+			summary: "Duplicate block",
+			detail:  `resource "a" is already declared at 1:1`,
+			line:    2,
+			column:  1,
+		},
+		{
+			name:    "Duplicate declaration",
+			input:   "resource \"a\" {}",
+			summary: "Duplicate declaration",
+			detail:  `"resource" is already declared in this scope`,
+			line:    1,
+			column:  1,
+		},
+		{
+			name:    "Non-boolean condition",
+			input:   "resource \"a\" {\n\tif: 1\n}",
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got number",
+			line:    2,
+			column:  6,
+		},
+		{
+			name:    "Non-boolean if block condition",
+			input:   "if \"a\" {\n\tresource \"a\" {}\n}",
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got string",
+			line:    1,
+			column:  4,
+		},
+		{
+			name:    "Unknown for_each",
+			input:   "resource \"a\" {\n\tfor_each: ready\n}",
+			summary: "Invalid for_each argument",
+			detail:  "the keys of for_each must be known when the block is evaluated",
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Unknown for_each key",
+			input:   "resource \"a\" {\n\tfor_each: [\"a\", id]\n}",
+			summary: "Invalid for_each argument",
+			detail:  "the keys of for_each must be known when the block is evaluated",
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Sensitive for_each",
+			input:   "resource \"a\" {\n\tfor_each: secrets\n}",
+			summary: "Invalid for_each argument",
+			detail:  "for_each must not be sensitive, as its keys are part of the addresses of the instances",
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Non-string for_each key",
+			input:   "resource \"a\" {\n\tfor_each: [1]\n}",
+			summary: "Invalid for_each argument",
+			detail:  "for_each must hold strings to be used as keys, got number",
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Invalid for_each",
+			input:   "resource \"a\" {\n\tfor_each: \"a\"\n}",
+			summary: "Invalid for_each argument",
+			detail:  "for_each must be a map, or a set or list of strings, got string",
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Duplicate key",
+			input:   "resource \"a\" {\n\tfor_each: [\"a\", \"a\"]\n}",
+			summary: "Duplicate key",
+			detail:  `for_each holds "a" more than once`,
+			line:    2,
+			column:  12,
+		},
+		{
+			name:    "Non-deterministic keys",
+			input:   "resource \"a\" {\n\tfor_each: [uuid()]\n}",
+			summary: "Non-deterministic keys",
+			detail:  "for_each must not depend on uuid, whose result changes on every evaluation",
+			line:    2,
+			column:  13,
+		},
+		{
+			name:    "Non-deterministic val",
+			input:   "val idv = uuid()\nresource \"a\" {\n\tfor_each: [idv]\n}",
+			summary: "Non-deterministic keys",
+			detail:  "for_each must not depend on idv, which depends on uuid, whose result changes on every evaluation",
+			line:    3,
+			column:  13,
+		},
+		{
+			name:    "Non-deterministic lambda",
+			input:   "val lf = () => uuid()\nresource \"a\" {\n\tfor_each: [lf()]\n}",
+			summary: "Non-deterministic keys",
+			detail:  "for_each must not depend on lf, which depends on uuid, whose result changes on every evaluation",
+			line:    3,
+			column:  13,
+		},
+		{
+			name:    "Non-deterministic function",
+			input:   "def key() { stamp() }\ndef stamp() { timestamp() }\nresource \"a\" {\n\tcount: length(key())\n}",
+			summary: "Non-deterministic keys",
+			detail:  "count must not depend on key, which depends on timestamp, whose result changes on every evaluation",
+			line:    4,
+			column:  16,
+		},
+		{
+			name:    "Function of a non-deterministic val",
+			input:   "def key() { idv }\nval idv = uuid()\nresource \"a\" {\n\tcount: length(key())\n}",
+			summary: "Non-deterministic keys",
+			detail:  "count must not depend on key, which depends on uuid, whose result changes on every evaluation",
+			line:    4,
+			column:  16,
+		},
+		{
+			name:    "Salted hash",
+			input:   "resource \"a\" {\n\tfor_each: [bcrypt(\"a\")]\n}",
+			summary: "Non-deterministic keys",
+			detail:  "for_each must not depend on bcrypt, whose result changes on every evaluation",
+			line:    2,
+			column:  13,
+		},
+		{
+			name:    "Unknown count",
+			input:   "resource \"a\" {\n\tcount: id\n}",
+			summary: "Invalid count argument",
+			detail:  "count must be known when the block is evaluated",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Negative count",
+			input:   "resource \"a\" {\n\tcount: -1\n}",
+			summary: "Invalid count argument",
+			detail:  "count must be a non-negative whole number, got -1",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Count too large",
+			input:   "resource \"a\" {\n\tcount: 10000000000\n}",
+			summary: "Invalid count argument",
+			detail:  "count must be at most 1024, got 10000000000",
+			line:    2,
+			column:  9,
+		},
+		{
+			name:    "Conflicting meta-attributes",
+			input:   "resource \"a\" {\n\tcount: 1\n\tif: true\n}",
+			summary: "Conflicting meta-attributes",
+			detail:  `resource "a" cannot have both count and if`,
+			line:    3,
+			column:  2,
+		},
+		{
+			name:    "Attribute in if block",
+			input:   "if true {\n\tid = 1\n}",
+			summary: "Unexpected attribute",
+			detail:  "an if block only holds blocks",
+			line:    2,
+			column:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			ctx := testEvalContext()
+			ctx.Variables["ready"] = value.Unknown(value.MapOf(value.TypeString))
+			ctx.Variables["id"] = value.Unknown(value.TypeString)
+			ctx.Variables["secrets"] = value.Map(map[string]value.Value{"a": value.String("b")}).MarkSensitive()
+
+			if tt.summary == "Duplicate declaration" {
+				ctx.Variables["resource"] = value.Int(1)
+			}
+
+			diags := ctx.DefineFunctions(ast)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			diags = ctx.DefineValues(ast)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			diags = ctx.DefineBlocks(ast)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.summary, diags[0].Summary)
+			assert.Equal(t, tt.detail, diags[0].Detail)
+			assert.Equal(t, tt.line, diags[0].Pos.Line)
+			assert.Equal(t, tt.column, diags[0].Pos.Column)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package etx
+
+import (
+	"fmt"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const (
+	checkBlock         = "check"
+	checkAttrCondition = "condition"
+	checkAttrError     = "error"
+)
+
+// EvalChecks evaluates the check blocks at the root of an AST, once the
+// values they reference are defined in the context.
+//
+// A check block has a bool condition, and the error message reported when
+// the condition is false. All the failed checks are reported, not only the
+// first one. The checks whose condition is not known yet are skipped.
+func (c *EvalContext) EvalChecks(ast *AST) Diagnostics {
+	var diags Diagnostics
+
+	for _, item := range ast.Items {
+		if item.Block == nil || item.Block.Name != checkBlock {
+			continue
+		}
+
+		diags = append(diags, c.evalCheck(item.Block)...)
+	}
+
+	return diags
+}
+
+// evalCheck evaluates the condition of a check block, and reports its error
+// message when the condition is false.
+func (c *EvalContext) evalCheck(b *Block) Diagnostics {
+	name := describeBlock(b)
+
+	attrs := make(map[string]*Attribute)
+
+	for _, item := range b.Body {
+		switch {
+		case item.Block != nil:
+			return Diagnostics{errorDiag(item.Block.Pos, "Unexpected block", fmt.Sprintf("%s does not accept blocks", name))}
+		case item.Attribute == nil:
+			continue
+		}
+
+		switch key := item.Attribute.Key; {
+		case key != checkAttrCondition && key != checkAttrError:
+			return Diagnostics{errorDiag(item.Attribute.Pos, "Unsupported attribute", fmt.Sprintf("%s does not accept attribute %q", name, key))}
+		case attrs[key] != nil:
+			return Diagnostics{errorDiag(item.Attribute.Pos, "Duplicate attribute", fmt.Sprintf("attribute %q is already defined", key))}
+		case item.Attribute.Value == nil:
+			return Diagnostics{errorDiag(item.Attribute.Pos, "Missing value", fmt.Sprintf("attribute %q of %s must have a value", key, name))}
+		default:
+			attrs[key] = item.Attribute
+		}
+	}
+
+	cond := attrs[checkAttrCondition]
+	if cond == nil {
+		return Diagnostics{errorDiag(b.Pos, "Missing attribute", fmt.Sprintf("%s requires attribute %q", name, checkAttrCondition))}
+	}
+
+	v, diags := cond.Value.eval(c)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	switch {
+	case !isBool(v):
+		return append(diags, errorDiag(cond.Value.Pos, "Invalid condition", fmt.Sprintf("condition must be a bool, got %s", v.Kind())))
+	case !v.IsKnown() || v.AsBool():
+		return diags
+	}
+
+	msg := attrs[checkAttrError]
+	if msg == nil {
+		return append(diags, errorDiag(b.Pos, "Check failed", fmt.Sprintf("the condition of %s is false", name)))
+	}
+
+	detail, msgDiags := msg.Value.eval(c)
+
+	diags = append(diags, msgDiags...)
+	if msgDiags.HasErrors() {
+		return diags
+	}
+
+	if detail.IsKnown() && detail.Kind() != value.KindString {
+		return append(diags, errorDiag(msg.Value.Pos, "Invalid error message", fmt.Sprintf("error message must be a string, got %s", detail.Kind())))
+	}
+
+	return append(diags, errorDiag(b.Pos, "Check failed", displayString(detail)))
+}
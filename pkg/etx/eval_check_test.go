@@ -0,0 +1,130 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestEvalContext_EvalChecks(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+val port = 80
+val hosts = ["a", "b"]
+
+check "port" {
+	condition = port > 1024
+	error = "port ${port} is privileged"
+}
+
+check "hosts" {
+	condition = length(hosts) > 0
+	error = "hosts must not be empty"
+}
+
+check "count" {
+	condition = length(hosts) == 3
+	error = "expected 3 hosts, got ${length(hosts)}"
+}
+
+check "id" {
+	condition = resource.foo.id != "a"
+	error = "invalid id"
+}
+
+check "secret" {
+	condition = false
+	error = "invalid secret ${secret}"
+}
+
+check "silent" {
+	condition = false
+}
+`)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	ctx.Variables["secret"] = value.String("hunter2").MarkSensitive()
+	ctx.Variables["resource"] = value.Map(map[string]value.Value{
+		"foo": value.Map(map[string]value.Value{"id": value.Unknown(value.TypeString)}),
+	})
+
+	diags := ctx.DefineValues(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	diags = ctx.EvalChecks(ast)
+
+	got := make([]string, 0, len(diags))
+	for _, d := range diags {
+		got = append(got, d.Error())
+	}
+
+	assert.Equal(t, []string{
+		`5:1: error: Check failed; port 80 is privileged`,
+		`15:1: error: Check failed; expected 3 hosts, got 2`,
+		`25:1: error: Check failed; (sensitive value)`,
+		`30:1: error: Check failed; the condition of check "silent" is false`,
+	}, got)
+}
+
+func TestEvalContext_EvalChecksErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+	}{
+		{
+			name:    "Missing condition",
+			input:   "check \"a\" {\n\terror = \"a\"\n}",
+			summary: "Missing attribute",
+			detail:  `check "a" requires attribute "condition"`,
+		},
+		{
+			name:    "Non-boolean condition",
+			input:   "check \"a\" {\n\tcondition = 1\n}",
+			summary: "Invalid condition",
+			detail:  "condition must be a bool, got number",
+		},
+		{
+			name:    "Non-string error",
+			input:   "check \"a\" {\n\tcondition = false\n\terror = 1\n}",
+			summary: "Invalid error message",
+			detail:  "error message must be a string, got number",
+		},
+		{
+			name:    "Unsupported attribute",
+			input:   "check \"a\" {\n\tcondition = true\n\tmessage = \"a\"\n}",
+			summary: "Unsupported attribute",
+			detail:  `check "a" does not accept attribute "message"`,
+		},
+		{
+			name:    "Duplicate attribute",
+			input:   "check \"a\" {\n\tcondition = true\n\tcondition = false\n}",
+			summary: "Duplicate attribute",
+			detail:  `attribute "condition" is already defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := testEvalContext().EvalChecks(ast)
+			require.Len(t, diags, 1)
+			assert.Equal(t, tt.summary, diags[0].Summary)
+			assert.Equal(t, tt.detail, diags[0].Detail)
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package etx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// DefineValues declares the values of the val and const declarations at the
+// root of an AST in the context, in order of declaration. Inputs and outputs
+// are not declared.
+func (c *EvalContext) DefineValues(ast *AST) Diagnostics {
+	var diags Diagnostics
+
+	if c.Variables == nil {
+		c.Variables = make(map[string]value.Value)
+	}
+
+	for _, item := range ast.Items {
+		if item.Decl == nil || (item.Decl.DeclType != "val" && item.Decl.DeclType != "const") {
+			continue
+		}
+
+		declDiags := item.Decl.eval(c)
+
+		diags = append(diags, declDiags...)
+		if declDiags.HasErrors() {
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// EvalOutputs evaluates the output declarations at the root of an AST in a
+// child of the context, and returns their values by name. The values and
+// functions of the context must already be defined.
+//
+// An output holding a sensitive value must itself be declared sensitive.
+func (c *EvalContext) EvalOutputs(ast *AST) (map[string]value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	scope := c.NewChild()
+
+	for _, item := range ast.Items {
+		if item.Decl == nil || item.Decl.DeclType != "output" {
+			continue
+		}
+
+		diags = append(diags, item.Decl.eval(scope)...)
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return scope.Variables, diags
+}
+
+// eval declares the value, or the values of the group, in ctx.
+func (n *Decl) eval(ctx *EvalContext) Diagnostics {
+	if len(n.Group) != 0 {
+		return evalDeclGroup(ctx, n.DeclType, n.Sensitive, n.Group)
+	}
+
+	return declareValue(ctx, n.DeclType, n.Sensitive, n.Pos, n.Label, n.Type, n.Value)
+}
+
+// declareValue evaluates expr and declares its value in ctx, checked against
+// its type. The value is marked as sensitive when the declaration is.
+func declareValue(ctx *EvalContext, declType string, sensitive bool, pos Position, label string, typ *ParameterType, expr *Expr) Diagnostics {
+	if _, exists := ctx.Variables[label]; exists {
+		return Diagnostics{errorDiag(pos, "Duplicate declaration", fmt.Sprintf("%q is already declared in this scope", label))}
+	}
+
+	if expr == nil {
+		return Diagnostics{errorDiag(pos, "Missing value", fmt.Sprintf("%s %q must have a value", declType, label))}
+	}
+
+	v, diags := expr.eval(ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	v, typeDiags := typ.conformDeclared(ctx, declType, label, v, expr.Pos)
+
+	diags = append(diags, typeDiags...)
+	if typeDiags.HasErrors() {
+		return diags
+	}
+
+	switch {
+	case sensitive:
+		v = v.MarkSensitive()
+	case declType == "output" && v.ContainsSensitive():
+		return append(diags, errorDiag(expr.Pos, "Output refers to sensitive values",
+			fmt.Sprintf("output %q must be declared sensitive to hold a sensitive value, or the value must be revealed with nonsensitive", label)))
+	}
+
+	ctx.Variables[label] = v
+
+	if source := ctx.nondeterministicDependency(expr, nil); source != "" {
+		ctx.markNondeterministic(label, source)
+	}
+
+	return diags
+}
+
+// conformDeclared returns the value v declared with the type annotation n,
+// with the defaults of n applied. The errors name the declaration, and are
+// reported at pos.
+func (n *ParameterType) conformDeclared(ctx *EvalContext, declType, label string, v value.Value, pos Position) (value.Value, Diagnostics) {
+	subject := fmt.Sprintf("%s %q", declType, label)
+
+	v, diags := n.withDefault(ctx, v)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	v, conformDiags := n.conformObjects(ctx, v, subject, pos)
+
+	diags = append(diags, conformDiags...)
+	if conformDiags.HasErrors() {
+		return value.Null, diags
+	}
+
+	if n != nil {
+		if t := n.valueType(); !t.Conforms(v) {
+			return value.Null, append(diags, errorDiag(pos, "Invalid value", fmt.Sprintf("%s must be a %s, got %s", subject, t, v.Type())))
+		}
+	}
+
+	if enumDiags := n.conformEnum(ctx, v, subject, pos); enumDiags.HasErrors() {
+		return value.Null, append(diags, enumDiags...)
+	}
+
+	return v, diags
+}
+
+// evalDeclGroup declares the values of a grouped declaration block in ctx.
+// The values are evaluated in dependency order, so they may reference each
+// other regardless of their order in the block, as long as there is no
+// reference cycle.
+func evalDeclGroup(ctx *EvalContext, declType string, sensitive bool, group []*DeclGroupItem) Diagnostics {
+	items := make(map[string]*DeclGroupItem)
+	entries := make([]*DeclGroupItem, 0, len(group))
+
+	for _, item := range group {
+		if item.Label == "" {
+			continue
+		}
+
+		if _, exists := ctx.Variables[item.Label]; exists || items[item.Label] != nil {
+			return Diagnostics{errorDiag(item.Pos, "Duplicate declaration", fmt.Sprintf("%q is already declared in this scope", item.Label))}
+		}
+
+		items[item.Label] = item
+		entries = append(entries, item)
+	}
+
+	sorted, diags := sortDeclGroup(declType, entries, items)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	for _, item := range sorted {
+		itemDiags := declareValue(ctx, declType, sensitive, item.Pos, item.Label, item.Type, item.Value)
+
+		diags = append(diags, itemDiags...)
+		if itemDiags.HasErrors() {
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// sortDeclGroup orders the entries of a grouped declaration block so that
+// each entry comes after the entries it references.
+func sortDeclGroup(declType string, entries []*DeclGroupItem, items map[string]*DeclGroupItem) ([]*DeclGroupItem, Diagnostics) {
+	sorted, cycle := sortDependencies(entries, func(item *DeclGroupItem) []*DeclGroupItem {
+		if item.Value == nil {
+			return nil
+		}
+
+		var deps []*DeclGroupItem
+
+		for _, ref := range references(item.Value) {
+			if dep, ok := items[ref]; ok {
+				deps = append(deps, dep)
+			}
+		}
+
+		return deps
+	})
+
+	if cycle != nil {
+		path := make([]string, 0, len(cycle))
+		for _, item := range cycle {
+			path = append(path, item.Label)
+		}
+
+		item := cycle[len(cycle)-1]
+
+		return nil, Diagnostics{errorDiag(item.Pos, "Reference cycle",
+			fmt.Sprintf("%s %q depends on itself: %s", declType, item.Label, strings.Join(path, " -> ")))}
+	}
+
+	return sorted, nil
+}
+
+// sortDependencies orders nodes so that each node comes after the nodes it
+// depends on. When nodes depend on each other, it returns the first
+// reference cycle found instead, as the path from a node back to itself.
+func sortDependencies[T comparable](nodes []T, deps func(T) []T) (sorted, cycle []T) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[T]int, len(nodes))
+	sorted = make([]T, 0, len(nodes))
+
+	var (
+		path  []T
+		visit func(node T) []T
+	)
+
+	visit = func(node T) []T {
+		switch state[node] {
+		case visited:
+			return nil
+		case visiting:
+			for i, item := range path {
+				if item == node {
+					return append(path[i:], node)
+				}
+			}
+		}
+
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, dep := range deps(node) {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node] = visited
+		sorted = append(sorted, node)
+
+		return nil
+	}
+
+	for _, node := range nodes {
+		if cycle := visit(node); cycle != nil {
+			return nil, cycle
+		}
+	}
+
+	return sorted, nil
+}
+
+// /////////////////////////////////////
+
+// references returns the names of the variables referenced by a node, in
+// order of first reference. The parameters of lambdas and the names of
+// attributes and methods are not references.
+func references(node Node) []string {
+	var refs []string
+
+	seen := make(map[string]bool)
+
+	collectReferences(node, nil, func(ident *Ident) {
+		if name := ident.Parts[0]; !seen[name] {
+			seen[name] = true
+			refs = append(refs, name)
+		}
+	})
+
+	return refs
+}
+
+func collectReferences(node Node, shadowed map[string]bool, add func(ident *Ident)) {
+	switch n := node.(type) {
+	case *Ident:
+		if !shadowed[n.Parts[0]] {
+			add(n)
+		}
+
+		return
+
+	case *Lambda:
+		inner := make(map[string]bool, len(shadowed)+len(n.Parameters))
+		for name := range shadowed {
+			inner[name] = true
+		}
+
+		for _, p := range n.Parameters {
+			inner[p.Label] = true
+		}
+
+		collectReferences(&n.Expr, inner, add)
+
+		return
+
+	case *ForList:
+		collectForReferences(n, n.Clause, shadowed, add)
+
+		return
+
+	case *ForMap:
+		collectForReferences(n, n.Clause, shadowed, add)
+
+		return
+
+	case *ExprPostfix:
+		collectReferences(&n.Value, shadowed, add)
+
+		if n.Index != nil {
+			collectReferences(n.Index, shadowed, add)
+		}
+
+		collectMemberReferences(n.Post, shadowed, add)
+
+		return
+
+	case *ExprPrimary:
+		if n.Ident != nil {
+			collectReferences(n.Ident, shadowed, add)
+
+			for _, item := range n.Monads {
+				collectReferences(item, shadowed, add)
+			}
+
+			collectMemberReferences(n.Post, shadowed, add)
+
+			return
+		}
+	}
+
+	for _, child := range node.Children() {
+		collectReferences(child, shadowed, add)
+	}
+}
+
+// collectForReferences collects the references of a for-expression. Its key
+// and value variables shadow the names of the enclosing scopes, except in
+// its collection.
+func collectForReferences(n Node, clause *ForClause, shadowed map[string]bool, add func(ident *Ident)) {
+	collectReferences(clause, shadowed, add)
+
+	inner := make(map[string]bool, len(shadowed)+2) //nolint:gomnd // key and value
+	for name := range shadowed {
+		inner[name] = true
+	}
+
+	if clause.Key != "" {
+		inner[clause.Key] = true
+	}
+
+	inner[clause.Value] = true
+
+	for _, child := range n.Children() {
+		if child != Node(clause) {
+			collectReferences(child, inner, add)
+		}
+	}
+}
+
+// collectMemberReferences collects the references of the arguments of an
+// attribute or method access, whose name is not a reference.
+func collectMemberReferences(post *ExprPostfix, shadowed map[string]bool, add func(ident *Ident)) {
+	if post == nil {
+		return
+	}
+
+	if post.Value.Ident == nil {
+		collectReferences(&post.Value, shadowed, add)
+	} else {
+		for _, item := range post.Value.Monads {
+			collectReferences(item, shadowed, add)
+		}
+
+		collectMemberReferences(post.Value.Post, shadowed, add)
+	}
+
+	if post.Index != nil {
+		collectReferences(post.Index, shadowed, add)
+	}
+
+	collectMemberReferences(post.Post, shadowed, add)
+}
@@ -0,0 +1,271 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestEvalContext_DefineValues(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+input region: string
+val a = 1
+val (
+	// c is declared before b, which it references
+	c = b * 2
+	b: number = a + 1
+	d = list.map((b) => b + c)
+)
+const e = "${c}"
+val f: optional(number, 3) = null
+val g: number | string = "g"
+`)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	diags := ctx.DefineValues(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	want := map[string]value.Value{
+		"a": value.Int(1),
+		"b": value.Int(2),
+		"c": value.Int(4),
+		"d": value.List(value.Int(14), value.Int(24), value.Int(34)),
+		"e": value.String("4"),
+		"f": value.Int(3),
+		"g": value.String("g"),
+	}
+
+	for name, v := range want {
+		assert.True(t, v.Equals(ctx.Variables[name]), "%s: want %s, got %s", name, v.GoString(), ctx.Variables[name].GoString())
+	}
+
+	assert.NotContains(t, ctx.Variables, "region")
+}
+
+func TestEvalContext_DefineValuesErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+		line    int
+		column  int
+	}{
+		{
+			name:    "Cycle",
+			input:   "val (\n\ta = c\n\tb = 1\n\tc = [a, b]\n)",
+			summary: "Reference cycle",
+			detail:  `val "a" depends on itself: a -> c -> a`,
+			line:    2,
+			column:  2,
+		},
+		{
+			name:    "Self reference",
+			input:   "val (\n\ta = a + 1\n)",
+			summary: "Reference cycle",
+			detail:  `val "a" depends on itself: a -> a`,
+			line:    2,
+			column:  2,
+		},
+		{
+			name:    "Duplicate in group",
+			input:   "val (\n\ta = 1\n\ta = 2\n)",
+			summary: "Duplicate declaration",
+			detail:  `"a" is already declared in this scope`,
+			line:    3,
+			column:  2,
+		},
+		{
+			name:    "Duplicate across declarations",
+			input:   "val a = 1\nval (\n\ta = 2\n)",
+			summary: "Duplicate declaration",
+			detail:  `"a" is already declared in this scope`,
+			line:    3,
+			column:  2,
+		},
+		{
+			name:    "Missing value",
+			input:   "const (\n\ta\n)",
+			summary: "Missing value",
+			detail:  `const "a" must have a value`,
+			line:    2,
+			column:  2,
+		},
+		{
+			name:    "Type",
+			input:   "val (\n\ta: string = 1\n)",
+			summary: "Invalid value",
+			detail:  `val "a" must be a string, got number`,
+			line:    2,
+			column:  14,
+		},
+		{
+			name:    "Element type",
+			input:   "val a: list(string) = [1]",
+			summary: "Invalid value",
+			detail:  `val "a" must be a list(string), got list(number)`,
+			line:    1,
+			column:  23,
+		},
+		{
+			name:    "Union",
+			input:   "val a: number | string = true",
+			summary: "Invalid value",
+			detail:  `val "a" must be a number | string, got bool`,
+			line:    1,
+			column:  26,
+		},
+		{
+			name:    "Union element",
+			input:   "val a: list(string | number) = [true]",
+			summary: "Invalid value",
+			detail:  `val "a" must be a list(string | number), got list(bool)`,
+			line:    1,
+			column:  32,
+		},
+		{
+			name:    "Invalid default",
+			input:   "val a: optional(number, \"x\") = null",
+			summary: "Invalid default",
+			detail:  "default must be a number, got string",
+			line:    1,
+			column:  25,
+		},
+		{
+			name:    "Unknown variable",
+			input:   "val a = b",
+			summary: "Unknown variable",
+			detail:  `there is no variable named "b"`,
+			line:    1,
+			column:  9,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := (&EvalContext{}).DefineValues(ast)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.summary, diags[0].Summary, diags.Error())
+			assert.Equal(t, tt.detail, diags[0].Detail)
+			assert.Equal(t, tt.line, diags[0].Pos.Line, diags.Error())
+			assert.Equal(t, tt.column, diags[0].Pos.Column, diags.Error())
+		})
+	}
+}
+
+func TestEvalContext_EvalOutputs(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+sensitive val password = "hunter2"
+val user = "admin"
+output login = upper(user)
+sensitive output (
+	creds = "${user}:${password}"
+)
+output revealed = nonsensitive(password)
+`)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	require.False(t, ctx.DefineValues(ast).HasErrors())
+	assert.True(t, ctx.Variables["password"].IsSensitive())
+
+	outputs, diags := ctx.EvalOutputs(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	assert.True(t, value.String("ADMIN").Equals(outputs["login"]))
+	assert.False(t, outputs["login"].IsSensitive())
+	assert.True(t, value.String("admin:hunter2").Equals(outputs["creds"]))
+	assert.True(t, outputs["creds"].IsSensitive())
+	assert.Equal(t, "(sensitive value)", outputs["creds"].GoString())
+	assert.False(t, outputs["revealed"].IsSensitive())
+	assert.NotContains(t, ctx.Variables, "login")
+}
+
+func TestEvalContext_EvalOutputsSensitive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{name: "Direct", input: "sensitive val a = 1\noutput b = a", line: 2},
+		{name: "Operator", input: "sensitive val a = 1\noutput b = a + 1", line: 2},
+		{name: "Element", input: "sensitive val a = 1\noutput b = [1, a]", line: 2},
+		{name: "Function", input: "output b = sensitive(1)", line: 1},
+		{name: "Group", input: "sensitive val a = 1\noutput (\n\tb = 1\n\tc = \"${a}\"\n)", line: 4},
+		{
+			name:  "Destructured in a function",
+			input: "sensitive val pair = [\"a\", \"b\"]\n\ndef second() string {\n\tval (x, y) = pair\n\ty\n}\n\noutput b = second()",
+			line:  8,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			ctx := &EvalContext{}
+			require.False(t, ctx.DefineFunctions(ast).HasErrors())
+			require.False(t, ctx.DefineValues(ast).HasErrors())
+
+			_, diags := ctx.EvalOutputs(ast)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, "Output refers to sensitive values", diags[0].Summary, diags.Error())
+			assert.Equal(t, tt.line, diags[0].Pos.Line, diags.Error())
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Ident", input: `a`, want: []string{"a"}},
+		{name: "Dotted ident", input: `a.b.c`, want: []string{"a"}},
+		{name: "Operators", input: `a + b * a`, want: []string{"a", "b"}},
+		{name: "Call", input: `f(a, g(b))`, want: []string{"f", "a", "g", "b"}},
+		{name: "Method", input: `a.map(b).length()`, want: []string{"a", "b"}},
+		{name: "Index", input: `a[b].c`, want: []string{"a", "b"}},
+		{name: "Lambda", input: `(x) => x + y`, want: []string{"y"}},
+		{name: "Conditional", input: `a ? b : c`, want: []string{"a", "b", "c"}},
+		{name: "Template", input: `"${a}-${b}"`, want: []string{"a", "b"}},
+		{name: "Literal", input: `1`, want: nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, references(parseTestExpr(t, tt.input)))
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package etx
+
+import (
+	"fmt"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// eval returns the list of the values of the for-expression. The list is
+// unknown when the collection or a condition is, and sensitive when the
+// collection or a condition is.
+func (n *ForList) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	var items []value.Value
+
+	known, sensitive, diags := n.Clause.iterate(ctx, n.Cond, func(scope *EvalContext) Diagnostics {
+		v, diags := n.Value.eval(scope)
+		items = append(items, v)
+
+		return diags
+	})
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	res := value.List(items...)
+	if !known {
+		res = value.Unknown(value.ListOf(value.TypeAny)).RefineNotNull()
+	}
+
+	if sensitive {
+		res = res.MarkSensitive()
+	}
+
+	return res, diags
+}
+
+// eval returns the map of the entries of the for-expression. The keys are
+// strings, numbers or bools, converted to strings. A duplicate key is an
+// error, unless the values are grouped by key into lists.
+//
+// Like a map literal, the map is unknown when a key is, and sensitive when a
+// key is. It is also unknown when the collection or a condition is, and
+// sensitive when the collection or a condition is.
+func (n *ForMap) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	var (
+		items  = make(map[string]value.Value)
+		groups = make(map[string][]value.Value)
+
+		knownKeys, sensitiveKeys = true, false
+	)
+
+	known, sensitive, diags := n.Clause.iterate(ctx, n.Cond, func(scope *EvalContext) Diagnostics {
+		key, diags := n.Key.eval(scope)
+		if diags.HasErrors() {
+			return diags
+		}
+
+		v, valueDiags := n.Value.eval(scope)
+
+		diags = append(diags, valueDiags...)
+		if valueDiags.HasErrors() {
+			return diags
+		}
+
+		sensitiveKeys = sensitiveKeys || key.IsSensitive()
+
+		if !key.IsKnown() {
+			knownKeys = false
+
+			return diags
+		}
+
+		str, ok := templateString(key)
+		if !ok {
+			return append(diags, errorDiag(n.Key.Pos, "Invalid map key", fmt.Sprintf("key must be a string, number or bool, got %s", key.Kind())))
+		}
+
+		if n.Group {
+			groups[str] = append(groups[str], v)
+
+			return diags
+		}
+
+		if _, exists := items[str]; exists {
+			return append(diags, errorDiag(n.Key.Pos, "Duplicate map key",
+				fmt.Sprintf("key %s is already defined; add %s after the value to group the values by key", key.GoString(), OpEllipsis)))
+		}
+
+		items[str] = v
+
+		return diags
+	})
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	for key, group := range groups {
+		items[key] = value.List(group...)
+	}
+
+	res := value.Map(items)
+	if !known || !knownKeys {
+		res = value.Unknown(value.MapOf(value.TypeAny)).RefineNotNull()
+	}
+
+	if sensitive || sensitiveKeys {
+		res = res.MarkSensitive()
+	}
+
+	return res, diags
+}
+
+// iterate evaluates the collection of a for clause, and calls fn with a
+// scope holding the key and the value of each element for which cond, if
+// any, is true. The key is the index of the elements of a list or set, and
+// the key of the elements of a map, in order.
+//
+// It reports whether the collection and the conditions are known, in which
+// case all the elements were iterated, and whether any of them is
+// sensitive.
+func (n *ForClause) iterate(ctx *EvalContext, cond *ExprLogicalOr, fn func(scope *EvalContext) Diagnostics) (known, sensitive bool, diags Diagnostics) {
+	coll, diags := n.Collection.eval(ctx)
+	if diags.HasErrors() {
+		return false, false, diags
+	}
+
+	known, sensitive = true, coll.IsSensitive()
+
+	if !coll.IsKnown() {
+		switch coll.Type().Kind() {
+		case value.TypeKindAny, value.TypeKindList, value.TypeKindTuple, value.TypeKindSet, value.TypeKindMap, value.TypeKindObject:
+			return false, sensitive, diags
+		}
+	}
+
+	var keys, items []value.Value
+
+	switch coll.Kind() {
+	case value.KindList, value.KindSet:
+		for i, item := range coll.AsList() {
+			keys = append(keys, value.Int(int64(i)))
+			items = append(items, item)
+		}
+	case value.KindMap:
+		for _, key := range coll.Keys() {
+			item, _ := coll.Get(key)
+
+			keys = append(keys, value.String(key))
+			items = append(items, item)
+		}
+	default:
+		return false, sensitive, append(diags, errorDiag(n.Collection.Pos, "Invalid for collection",
+			fmt.Sprintf("cannot iterate over a %s", coll.Kind())))
+	}
+
+	for i, item := range items {
+		scope := ctx.NewChild()
+		scope.Variables[n.Value] = item
+
+		if n.Key != "" {
+			scope.Variables[n.Key] = keys[i]
+		}
+
+		if cond != nil {
+			v, condDiags := evalCondition(scope, cond)
+
+			diags = append(diags, condDiags...)
+			if condDiags.HasErrors() {
+				return false, sensitive, diags
+			}
+
+			sensitive = sensitive || v.IsSensitive()
+
+			if !v.IsKnown() {
+				known = false
+
+				continue
+			}
+
+			if !v.AsBool() {
+				continue
+			}
+		}
+
+		diags = append(diags, fn(scope)...)
+		if diags.HasErrors() {
+			return false, sensitive, diags
+		}
+	}
+
+	return known, sensitive, diags
+}
+
+func (n *ForOperand) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	switch {
+	case n.Ident != nil:
+		return n.Ident.resolve(ctx)
+	case n.Literal != nil:
+		return n.Literal.eval(ctx)
+	default:
+		return n.Expr.eval(ctx)
+	}
+}
@@ -0,0 +1,352 @@
+package etx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// maxCallDepth is the maximum number of nested calls of user-defined
+// functions and lambdas, bounding recursion.
+const maxCallDepth = 512
+
+// ErrCallDepth is returned when nested calls of user-defined functions and
+// lambdas exceed the maximum call depth.
+var ErrCallDepth = errors.New("maximum call depth exceeded")
+
+// DefineFunctions registers the functions declared with def at the root of
+// an AST in the context. The functions close over the context, so they can
+// call each other and themselves.
+func (c *EvalContext) DefineFunctions(ast *AST) Diagnostics {
+	var diags Diagnostics
+
+	if c.Functions == nil {
+		c.Functions = make(map[string]*value.Function)
+	}
+
+	defined := make(map[string]bool)
+
+	for _, item := range ast.Items {
+		if item.Func == nil {
+			continue
+		}
+
+		if defined[item.Func.Label] {
+			diags = append(diags, errorDiag(item.Func.Pos, "Duplicate function", fmt.Sprintf("function %q is already defined", item.Func.Label)))
+
+			continue
+		}
+
+		defined[item.Func.Label] = true
+		c.Functions[item.Func.Label] = item.Func.function(c)
+
+		if c.funcs == nil {
+			c.funcs = make(map[string]*Func)
+		}
+
+		c.funcs[item.Func.Label] = item.Func
+	}
+
+	return diags
+}
+
+func (c *EvalContext) root() *EvalContext {
+	ctx := c
+	for ctx.parent != nil {
+		ctx = ctx.parent
+	}
+
+	return ctx
+}
+
+// enterCall counts a call of a user-defined function or lambda against the
+// maximum call depth, shared by all the scopes of the root context. The
+// returned function must be called when the call returns.
+func (c *EvalContext) enterCall(name string) (func(), error) {
+	root := c.root()
+	if root.callDepth >= maxCallDepth {
+		return nil, fmt.Errorf("%w: %s is nested more than %d times", ErrCallDepth, name, maxCallDepth)
+	}
+
+	root.callDepth++
+
+	return func() { root.callDepth-- }, nil
+}
+
+// function returns the runtime function defined by n, closing over ctx.
+func (n *Func) function(ctx *EvalContext) *value.Function {
+	return &value.Function{
+		Name:   n.Label,
+		Params: n.params(),
+		Impl: func(args []value.Value) (value.Value, error) {
+			leave, err := ctx.enterCall(n.Label)
+			if err != nil {
+				return value.Null, err
+			}
+
+			defer leave()
+
+			scope := ctx.NewChild()
+			for i, p := range n.Parameters {
+				v, diags := p.Type.withDefault(scope, args[i])
+				if diags.HasErrors() {
+					return value.Null, diags
+				}
+
+				if diags := p.Type.conformEnum(scope, v, fmt.Sprintf("parameter %q", p.Label), p.Pos); diags.HasErrors() {
+					return value.Null, diags
+				}
+
+				scope.Variables[p.Label] = v
+			}
+
+			res, diags := n.evalBody(scope)
+			if diags.HasErrors() {
+				return value.Null, diags
+			}
+
+			return res, nil
+		},
+	}
+}
+
+// params returns the runtime parameters of the function.
+func (n *Func) params() []value.Param {
+	params := make([]value.Param, 0, len(n.Parameters))
+
+	for _, p := range n.Parameters {
+		param := value.Param{Name: p.Label, AllowNull: true, AllowUnknown: true, AllowSensitive: true}
+		if p.Type != nil {
+			param.Type = p.Type.valueType()
+		}
+
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// evalBody evaluates the statements of the function body in order. A return
+// statement ends the evaluation with its values. Otherwise, the result of the
+// function is the value of the last statement if it is an expression, and
+// null if it is a declaration. The result must conform to the return types.
+func (n *Func) evalBody(ctx *EvalContext) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	res, pos := value.Null, n.Pos
+
+	for i, stmt := range n.Body {
+		switch {
+		case stmt.Return != nil:
+			if next := nextStatement(n.Body[i+1:]); next != nil {
+				return value.Null, append(diags, errorDiag(next.Pos, "Unreachable statement",
+					fmt.Sprintf("statements after a return in function %q are never evaluated", n.Label)))
+			}
+
+			v, retDiags := stmt.Return.eval(ctx)
+
+			diags = append(diags, retDiags...)
+			if retDiags.HasErrors() {
+				return value.Null, diags
+			}
+
+			res, pos = v, stmt.Return.Pos
+			if len(stmt.Return.Values) == 1 {
+				pos = stmt.Return.Values[0].Pos
+			}
+
+		case stmt.Decl != nil:
+			declDiags := stmt.Decl.eval(ctx)
+
+			diags = append(diags, declDiags...)
+			if declDiags.HasErrors() {
+				return value.Null, diags
+			}
+
+			res, pos = value.Null, stmt.Decl.Pos
+
+		case stmt.Expr != nil:
+			v, exprDiags := stmt.Expr.eval(ctx)
+
+			diags = append(diags, exprDiags...)
+			if exprDiags.HasErrors() {
+				return value.Null, diags
+			}
+
+			res, pos = v, stmt.Expr.Pos
+		}
+	}
+
+	if len(n.Return) == 0 {
+		return res, diags
+	}
+
+	typ := n.returnType()
+	if !typ.Conforms(res) {
+		return value.Null, append(diags, errorDiag(pos, "Invalid return value", n.returnDetail(res.Type())))
+	}
+
+	if !res.IsKnown() && res.Type().Kind() == value.TypeKindAny {
+		res = res.RefineType(typ)
+	}
+
+	return res, diags
+}
+
+// returnDetail describes a result of type got not matching the return types.
+func (n *Func) returnDetail(got value.Type) string {
+	if len(n.Return) == 1 {
+		return fmt.Sprintf("function %q must return a %s, got %s", n.Label, n.returnType(), got)
+	}
+
+	types := make([]string, 0, len(n.Return))
+	for _, item := range n.Return {
+		types = append(types, item.FormattedString())
+	}
+
+	return fmt.Sprintf("function %q must return a list of %d values (%s), got %s",
+		n.Label, len(n.Return), strings.Join(types, ", "), got)
+}
+
+// nextStatement returns the first statement that is neither a comment nor
+// an empty line, or nil if there is none.
+func nextStatement(stmts []*FuncStatement) *FuncStatement {
+	for _, stmt := range stmts {
+		if stmt.Return != nil || stmt.Decl != nil || stmt.Expr != nil {
+			return stmt
+		}
+	}
+
+	return nil
+}
+
+// returnType returns the type of the result of the function. Several return
+// types are returned together as a tuple.
+func (n *Func) returnType() value.Type {
+	if len(n.Return) == 1 {
+		return n.Return[0].valueType()
+	}
+
+	types := make([]value.Type, 0, len(n.Return))
+	for _, item := range n.Return {
+		types = append(types, item.valueType())
+	}
+
+	return value.TupleOf(types...)
+}
+
+// eval returns the value of the return statement: null without values, the
+// value itself for a single one, and the list of the values otherwise.
+func (n *FuncReturn) eval(ctx *EvalContext) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	values := make([]value.Value, 0, len(n.Values))
+
+	for _, item := range n.Values {
+		v, itemDiags := item.eval(ctx)
+
+		diags = append(diags, itemDiags...)
+		if itemDiags.HasErrors() {
+			return value.Null, diags
+		}
+
+		values = append(values, v)
+	}
+
+	switch len(values) {
+	case 0:
+		return value.Null, diags
+	case 1:
+		return values[0], diags
+	default:
+		return value.List(values...), diags
+	}
+}
+
+// eval declares the value in ctx, checked against its type. A destructuring
+// declaration declares each element of a list holding exactly one value per
+// name, and a grouped declaration block declares each of its values.
+func (n *FuncDecl) eval(ctx *EvalContext) Diagnostics {
+	if len(n.Group) != 0 {
+		return evalDeclGroup(ctx, n.DeclType, false, n.Group)
+	}
+
+	if len(n.Targets) == 0 {
+		return declareValue(ctx, n.DeclType, false, n.Pos, n.Label, n.Type, n.Value)
+	}
+
+	declared := make(map[string]bool, len(n.Targets))
+
+	for _, target := range n.Targets {
+		if _, exists := ctx.Variables[target.Label]; exists || declared[target.Label] {
+			return Diagnostics{errorDiag(target.Pos, "Duplicate declaration", fmt.Sprintf("%q is already declared in this scope", target.Label))}
+		}
+
+		declared[target.Label] = true
+	}
+
+	if n.Value == nil {
+		return Diagnostics{errorDiag(n.Pos, "Missing value", fmt.Sprintf("%s %s must have a value", n.DeclType, n.names()))}
+	}
+
+	v, diags := n.Value.eval(ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	values := make([]value.Value, 0, len(n.Targets))
+
+	if !v.IsKnown() {
+		for range n.Targets {
+			values = append(values, value.Unknown(value.TypeAny).InheritSensitive(v))
+		}
+	} else {
+		if v.Kind() != value.KindList || v.Len() != len(n.Targets) {
+			got := v.Type().String()
+			if v.Kind() == value.KindList {
+				got = fmt.Sprintf("a list of %d values", v.Len())
+			}
+
+			return append(diags, errorDiag(n.Value.Pos, "Invalid value",
+				fmt.Sprintf("%s %s must be a list of %d values, got %s", n.DeclType, n.names(), len(n.Targets), got)))
+		}
+
+		// The elements keep the mark of the list they are taken from.
+		for _, item := range v.AsList() {
+			values = append(values, item.InheritSensitive(v))
+		}
+	}
+
+	for i, target := range n.Targets {
+		v, typeDiags := target.Type.conformDeclared(ctx, n.DeclType, target.Label, values[i], n.Value.Pos)
+
+		diags = append(diags, typeDiags...)
+		if typeDiags.HasErrors() {
+			return diags
+		}
+
+		values[i] = v
+	}
+
+	for i, target := range n.Targets {
+		ctx.Variables[target.Label] = values[i]
+	}
+
+	return diags
+}
+
+// names returns the names declared by n, as written in diagnostics.
+func (n *FuncDecl) names() string {
+	if len(n.Targets) == 0 {
+		return fmt.Sprintf("%q", n.Label)
+	}
+
+	labels := make([]string, 0, len(n.Targets))
+	for _, target := range n.Targets {
+		labels = append(labels, target.Label)
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(labels, ", "))
+}
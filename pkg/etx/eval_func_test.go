@@ -0,0 +1,365 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const testFunctions = `
+def add(a: number, b: number) number {
+	a + b
+}
+
+def scaled(x) {
+	val y = x * 2
+	val z: number = y + 1
+	z
+}
+
+def adder(v: number) (number) -> number {
+	(x) => x + v
+}
+
+def divmod(a: number, b: number) (number, number) {
+	[floor(a / b), a % b]
+}
+
+def fact(n: number) number {
+	n <= 1 ? 1 : n * fact(n - 1)
+}
+
+def even(n: number) bool {
+	n == 0 ? true : odd(n - 1)
+}
+
+def odd(n: number) bool {
+	n == 0 ? false : even(n - 1)
+}
+
+def shout(s: string) string {
+	"${upper(s)}!"
+}
+
+def loop(n) {
+	loop(n + 1)
+}
+
+def wrong() number {
+	"a"
+}
+
+def wrong-tuple() (number, string) {
+	[1, 2]
+}
+
+def bad-decl() {
+	val a: string = 1
+	a
+}
+
+def redeclared(a) {
+	val a = 1
+	a
+}
+
+def nothing() {}
+
+def divide(a: number, b: number) (number, number) {
+	val (q, r: number) = divmod(a, b)
+	return q, r
+}
+
+def clamp(n: number) number {
+	// negative numbers are clamped
+	return n < 0 ? 0 : n
+}
+
+def bare() {
+	return
+}
+
+def declared-last() {
+	1
+	val a = 2
+}
+
+def unreachable() {
+	return 1
+	// comments may follow
+	2
+}
+
+def bad-return() (number, string) {
+	return 1, 2
+}
+
+def short() {
+	val (a, b, c) = divmod(1, 2)
+	a
+}
+
+def not-list() {
+	val (a, b) = "ab"
+	a
+}
+
+def bad-target() {
+	val (a: string, b) = divmod(1, 2)
+	a
+}
+
+def duplicate-target() {
+	val (a, a) = divmod(1, 2)
+	a
+}
+
+def grouped(n: number) number {
+	val (
+		// b is declared before a, which it references
+		b = a * 2
+		a = n + 1
+	)
+	b
+}
+
+def cyclic() {
+	val (
+		a = b
+		b = a
+	)
+	a
+}
+
+type color enum {
+	red: 1
+}
+
+type server object {
+	host: string
+	port: optional(number, 8080)
+}
+
+def defaulted() number {
+	val x: optional(number, 5) = null
+	x
+}
+
+def server-port() number {
+	val s: server = {host = "a"}
+	s.port
+}
+
+def paint(v) {
+	val c: color = v
+	c
+}
+`
+
+func testFuncContext(t *testing.T) *EvalContext {
+	t.Helper()
+
+	ast, err := ParseString(testFunctions)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	require.Empty(t, ctx.DefineTypes(ast))
+	require.Empty(t, ctx.DefineFunctions(ast))
+
+	return ctx
+}
+
+func TestEval_Functions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  value.Value
+	}{
+		{name: "Call", input: `add(1, 2)`, want: value.Int(3)},
+		{name: "Declarations", input: `scaled(2)`, want: value.Int(5)},
+		{name: "Curried call", input: `adder(2)(3)`, want: value.Int(5)},
+		{name: "Multiple return values", input: `divmod(10, 3)`, want: value.List(value.Int(3), value.Int(1))},
+		{name: "Recursion", input: `fact(10)`, want: value.Int(3628800)},
+		{name: "Mutual recursion", input: `even(10)`, want: value.True},
+		{name: "Library function", input: `shout(name)`, want: value.String("ETX!")},
+		{name: "Function value", input: `list.map(scaled)`, want: value.List(value.Int(21), value.Int(41), value.Int(61))},
+		{name: "Function as argument", input: `apply(add, 1, 2)`, want: value.Int(3)},
+		{name: "Empty body", input: `nothing()`, want: value.Null},
+		{name: "Destructuring", input: `divide(7, 2)`, want: value.List(value.Int(3), value.Int(1))},
+		{name: "Return", input: `clamp(-2)`, want: value.Int(0)},
+		{name: "Return without value", input: `bare()`, want: value.Null},
+		{name: "Declaration last", input: `declared-last()`, want: value.Null},
+		{name: "Grouped declarations", input: `grouped(1)`, want: value.Int(4)},
+		{name: "Declaration default", input: `defaulted()`, want: value.Int(5)},
+		{name: "Declaration object default", input: `server-port()`, want: value.Int(8080)},
+		{name: "Declaration enum", input: `paint(1)`, want: value.Int(1)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), testFuncContext(t))
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestEval_FunctionErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+		line    int
+		column  int
+	}{
+		{
+			name:    "Argument type",
+			input:   `add(1, "a")`,
+			summary: `Error in function call "add"`,
+			detail:  `invalid argument: argument "b" must be a number, got string`,
+			line:    1,
+			column:  8,
+		},
+		{name: "Arity", input: `add(1)`, summary: `Error in function call "add"`, detail: "wrong number of arguments: add expects at least 2, got 1", line: 1, column: 5},
+		{name: "Return type", input: `wrong()`, summary: "Invalid return value", detail: `function "wrong" must return a number, got string`, line: 41, column: 2},
+		{
+			name:    "Return values",
+			input:   `wrong-tuple()`,
+			summary: "Invalid return value",
+			detail:  `function "wrong-tuple" must return a list of 2 values (number, string), got list(number)`,
+			line:    45,
+			column:  2,
+		},
+		{name: "Declaration type", input: `bad-decl()`, summary: "Invalid value", detail: `val "a" must be a string, got number`, line: 49, column: 18},
+		{name: "Redeclaration", input: `redeclared(1)`, summary: "Duplicate declaration", detail: `"a" is already declared in this scope`, line: 54, column: 2},
+		{
+			name:    "Unreachable statement",
+			input:   `unreachable()`,
+			summary: "Unreachable statement",
+			detail:  `statements after a return in function "unreachable" are never evaluated`,
+			line:    82,
+			column:  2,
+		},
+		{
+			name:    "Returned values",
+			input:   `bad-return()`,
+			summary: "Invalid return value",
+			detail:  `function "bad-return" must return a list of 2 values (number, string), got list(number)`,
+			line:    86,
+			column:  2,
+		},
+		{
+			name:    "Destructuring count",
+			input:   `short()`,
+			summary: "Invalid value",
+			detail:  "val (a, b, c) must be a list of 3 values, got a list of 2 values",
+			line:    90,
+			column:  18,
+		},
+		{
+			name:    "Destructuring non-list",
+			input:   `not-list()`,
+			summary: "Invalid value",
+			detail:  "val (a, b) must be a list of 2 values, got string",
+			line:    95,
+			column:  15,
+		},
+		{
+			name:    "Destructuring type",
+			input:   `bad-target()`,
+			summary: "Invalid value",
+			detail:  `val "a" must be a string, got number`,
+			line:    100,
+			column:  23,
+		},
+		{
+			name:    "Destructuring duplicate",
+			input:   `duplicate-target()`,
+			summary: "Duplicate declaration",
+			detail:  `"a" is already declared in this scope`,
+			line:    105,
+			column:  10,
+		},
+		{
+			name:    "Grouped declarations cycle",
+			input:   `cyclic()`,
+			summary: "Reference cycle",
+			detail:  `val "a" depends on itself: a -> b -> a`,
+			line:    120,
+			column:  3,
+		},
+		{
+			name:    "Declaration enum member",
+			input:   `paint(7)`,
+			summary: "Invalid value",
+			detail:  `val "c" must be a member of enum "color", got 7`,
+			line:    146,
+			column:  17,
+		},
+		{
+			name:    "Call depth",
+			input:   `loop(0)`,
+			summary: `Error in function call "loop"`,
+			detail:  "maximum call depth exceeded: loop is nested more than 512 times",
+			line:    37,
+			column:  7,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), testFuncContext(t))
+			require.True(t, diags.HasErrors())
+			assert.True(t, res.IsNull())
+			assert.Equal(t, tt.summary, diags[0].Summary, diags.Error())
+			assert.Equal(t, tt.detail, diags[0].Detail)
+			assert.Equal(t, tt.line, diags[0].Pos.Line, diags.Error())
+			assert.Equal(t, tt.column, diags[0].Pos.Column, diags.Error())
+		})
+	}
+}
+
+func TestEvalContext_DefineFunctions(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString("def f() { 1 }\n\ndef f() { 2 }\n")
+	require.NoError(t, err)
+
+	ctx := &EvalContext{}
+	diags := ctx.DefineFunctions(ast)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Duplicate function", diags[0].Summary)
+	assert.Equal(t, 3, diags[0].Pos.Line)
+
+	res, diags := Eval(parseTestExpr(t, `f()`), ctx.NewChild())
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.True(t, value.Int(1).Equals(res))
+}
+
+func TestEvalContext_LambdaCallDepth(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString("val f = (g) => g(g)\nval b = f(f)\n")
+	require.NoError(t, err)
+
+	diags := (&EvalContext{}).DefineValues(ast)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `Error in function call "lambda"`, diags[0].Summary)
+	assert.Equal(t, "maximum call depth exceeded: lambda is nested more than 512 times", diags[0].Detail)
+}
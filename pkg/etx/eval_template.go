@@ -0,0 +1,365 @@
+package etx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// templatePart is a fragment of a string or heredoc template: either
+// literal text, an interpolation or a directive.
+type templatePart struct {
+	pos        Position
+	text       string
+	expr       *Expr
+	directive  *TemplateDirective
+	stripLeft  bool
+	stripRight bool
+}
+
+// templateNode is a node of a template once its directives have been
+// matched into nested if and for blocks.
+type templateNode struct {
+	pos       Position
+	text      string
+	expr      *Expr
+	cond      *Expr
+	loop      *TemplateFor
+	body      []*templateNode
+	otherwise []*templateNode
+	inElse    bool
+}
+
+func (n *templateNode) add(child *templateNode) {
+	if n.inElse {
+		n.otherwise = append(n.otherwise, child)
+	} else {
+		n.body = append(n.body, child)
+	}
+}
+
+func evalTemplate(ctx *EvalContext, parts []templatePart) (value.Value, Diagnostics) {
+	stripTemplate(parts)
+
+	root, diags := buildTemplate(parts)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	var sb templateWriter
+
+	if diags = renderTemplate(ctx, &sb, root.body); diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	res := value.String(sb.String())
+	if sb.unknown {
+		res = value.Unknown(value.TypeString).RefineNotNull()
+	}
+
+	if sb.sensitive {
+		res = res.MarkSensitive()
+	}
+
+	return res, diags
+}
+
+// templateWriter accumulates the rendered text of a template. Once a part
+// of the template is unknown, the whole text is unknown, and once a part
+// depends on a sensitive value, the whole text is sensitive.
+type templateWriter struct {
+	strings.Builder
+	unknown   bool
+	sensitive bool
+}
+
+// stripTemplate removes the whitespace next to the interpolations and
+// directives using the "~" strip markers.
+func stripTemplate(parts []templatePart) {
+	for i := range parts {
+		part := parts[i]
+		if part.directive != nil {
+			part.stripLeft, part.stripRight = part.directive.StripLeft, part.directive.StripRight
+		}
+
+		if part.stripLeft {
+			for j := i - 1; j >= 0 && parts[j].expr == nil && parts[j].directive == nil; j-- {
+				if parts[j].text = strings.TrimRight(parts[j].text, " \t\r\n"); parts[j].text != "" {
+					break
+				}
+			}
+		}
+
+		if part.stripRight {
+			for j := i + 1; j < len(parts) && parts[j].expr == nil && parts[j].directive == nil; j++ {
+				if parts[j].text = strings.TrimLeft(parts[j].text, " \t\r\n"); parts[j].text != "" {
+					break
+				}
+			}
+		}
+	}
+}
+
+// buildTemplate matches the if, else, endif, for and endfor directives
+// into a tree of nodes.
+func buildTemplate(parts []templatePart) (*templateNode, Diagnostics) {
+	root := &templateNode{}
+	stack := []*templateNode{root}
+
+	for _, part := range parts {
+		top := stack[len(stack)-1]
+
+		switch d := part.directive; {
+		case d == nil && part.expr != nil:
+			top.add(&templateNode{pos: part.pos, expr: part.expr})
+		case d == nil:
+			top.add(&templateNode{pos: part.pos, text: part.text})
+		case d.If != nil:
+			node := &templateNode{pos: d.Pos, cond: d.If}
+			top.add(node)
+			stack = append(stack, node)
+		case d.Else:
+			if top.cond == nil || top.inElse {
+				return nil, Diagnostics{errorDiag(d.Pos, "Unexpected directive", "else directive without a matching if")}
+			}
+
+			top.inElse = true
+		case d.EndIf:
+			if top.cond == nil {
+				return nil, Diagnostics{errorDiag(d.Pos, "Unexpected directive", "endif directive without a matching if")}
+			}
+
+			stack = stack[:len(stack)-1]
+		case d.For != nil:
+			node := &templateNode{pos: d.Pos, loop: d.For}
+			top.add(node)
+			stack = append(stack, node)
+		case d.EndFor:
+			if top.loop == nil {
+				return nil, Diagnostics{errorDiag(d.Pos, "Unexpected directive", "endfor directive without a matching for")}
+			}
+
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if top := stack[len(stack)-1]; top != root {
+		if top.cond != nil {
+			return nil, Diagnostics{errorDiag(top.pos, "Unterminated directive", "if directive without a matching endif")}
+		}
+
+		return nil, Diagnostics{errorDiag(top.pos, "Unterminated directive", "for directive without a matching endfor")}
+	}
+
+	return root, nil
+}
+
+func renderTemplate(ctx *EvalContext, sb *templateWriter, nodes []*templateNode) Diagnostics {
+	var diags Diagnostics
+
+	for _, node := range nodes {
+		switch {
+		case node.expr != nil:
+			diags = append(diags, renderInterpolation(ctx, sb, node)...)
+		case node.cond != nil:
+			diags = append(diags, renderIf(ctx, sb, node)...)
+		case node.loop != nil:
+			diags = append(diags, renderFor(ctx, sb, node)...)
+		default:
+			sb.WriteString(node.text)
+		}
+
+		if diags.HasErrors() {
+			return diags
+		}
+	}
+
+	return diags
+}
+
+func renderInterpolation(ctx *EvalContext, sb *templateWriter, node *templateNode) Diagnostics {
+	v, diags := node.expr.eval(ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	sb.sensitive = sb.sensitive || v.IsSensitive()
+
+	if !v.IsKnown() {
+		switch v.Type().Kind() {
+		case value.TypeKindAny, value.TypeKindString, value.TypeKindNumber, value.TypeKindBool:
+			sb.unknown = true
+
+			return diags
+		default:
+			return append(diags, errorDiag(node.pos, "Invalid template interpolation value",
+				fmt.Sprintf("cannot include a %s in a string template", v.Type())))
+		}
+	}
+
+	s, ok := templateString(v)
+	if !ok {
+		return append(diags, errorDiag(node.pos, "Invalid template interpolation value",
+			fmt.Sprintf("cannot include a %s in a string template", v.Kind())))
+	}
+
+	sb.WriteString(s)
+
+	return diags
+}
+
+func renderIf(ctx *EvalContext, sb *templateWriter, node *templateNode) Diagnostics {
+	v, diags := node.cond.eval(ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if !isBool(v) {
+		return append(diags, errorDiag(node.cond.Pos, "Invalid condition",
+			fmt.Sprintf("condition must be a bool, got %s", v.Kind())))
+	}
+
+	sb.sensitive = sb.sensitive || v.IsSensitive()
+
+	// Both branches are rendered for their errors when the condition is
+	// unknown, as either may be rendered.
+	if !v.IsKnown() {
+		sb.unknown = true
+
+		if diags = append(diags, renderTemplate(ctx, sb, node.body)...); diags.HasErrors() {
+			return diags
+		}
+
+		return append(diags, renderTemplate(ctx, sb, node.otherwise)...)
+	}
+
+	if v.AsBool() {
+		return append(diags, renderTemplate(ctx, sb, node.body)...)
+	}
+
+	return append(diags, renderTemplate(ctx, sb, node.otherwise)...)
+}
+
+func renderFor(ctx *EvalContext, sb *templateWriter, node *templateNode) Diagnostics {
+	coll, diags := node.loop.Collection.eval(ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	sb.sensitive = sb.sensitive || coll.IsSensitive()
+
+	iterate := func(key, item value.Value) Diagnostics {
+		child := ctx.NewChild()
+		child.Variables[node.loop.Value] = item
+
+		if node.loop.Key != "" {
+			child.Variables[node.loop.Key] = key
+		}
+
+		return renderTemplate(child, sb, node.body)
+	}
+
+	if !coll.IsKnown() {
+		switch coll.Type().Kind() {
+		case value.TypeKindAny, value.TypeKindList, value.TypeKindTuple, value.TypeKindSet, value.TypeKindMap, value.TypeKindObject:
+			sb.unknown = true
+
+			return diags
+		}
+	}
+
+	switch coll.Kind() {
+	case value.KindList, value.KindSet:
+		for i, item := range coll.AsList() {
+			if diags = append(diags, iterate(value.Int(int64(i)), item)...); diags.HasErrors() {
+				return diags
+			}
+		}
+	case value.KindMap:
+		for _, key := range coll.Keys() {
+			item, _ := coll.Get(key)
+			if diags = append(diags, iterate(value.String(key), item)...); diags.HasErrors() {
+				return diags
+			}
+		}
+	default:
+		return append(diags, errorDiag(node.loop.Collection.Pos, "Invalid for collection",
+			fmt.Sprintf("cannot iterate over a %s", coll.Kind())))
+	}
+
+	return diags
+}
+
+// templateString converts an interpolated value into its string form.
+// Only strings, numbers and bools can be interpolated.
+func templateString(v value.Value) (string, bool) {
+	switch v.Kind() {
+	case value.KindString:
+		return v.AsString(), true
+	case value.KindNumber:
+		return value.FormatNumber(v.AsBigFloat()), true
+	case value.KindBool:
+		return strconv.FormatBool(v.AsBool()), true
+	default:
+		return "", false
+	}
+}
+
+// unescapeTemplate replaces the "$${" and "%%{" escape sequences of a
+// template literal.
+func unescapeTemplate(s string) string {
+	return strings.NewReplacer("$${", "${", "%%{", "%{").Replace(s)
+}
+
+// /////////////////////////////////////
+
+// templateFile is the content of a file rendered by the templatefile
+// function, parsed like the body of a heredoc.
+type templateFile struct {
+	Fragments []*HeredocFragment `parser:"@@*"`
+}
+
+// renderTemplateFile renders a template file with the given variables only.
+// The library functions are available to the template, except templatefile
+// itself.
+func (c *EvalContext) renderTemplateFile(path, src string, vars map[string]value.Value) (value.Value, error) {
+	file := &templateFile{}
+	if err := templateParser().ParseString(path, src, file); err != nil {
+		var parseErr participle.Error
+		if errors.As(err, &parseErr) {
+			return value.Null, Diagnostics{errorDiag(parseErr.Position(), "Invalid template", parseErr.Message())}
+		}
+
+		return value.Null, fmt.Errorf("%w: invalid template %q: %s", value.ErrArgument, path, err)
+	}
+
+	sb := c.sandbox()
+	ctx := &EvalContext{
+		Variables: vars,
+		Functions: map[string]*value.Function{
+			"templatefile": {
+				Name:     "templatefile",
+				VarParam: &value.Param{Name: "args", AllowNull: true},
+				Impl: func([]value.Value) (value.Value, error) {
+					return value.Null, fmt.Errorf("%w: cannot recursively call templatefile from inside a template", value.ErrArgument)
+				},
+			},
+		},
+		BaseDir: sb.BaseDir,
+		Now:     sb.Now,
+	}
+
+	// The template only sees vars, so it cannot reference a disabled block,
+	// and a function has no way to return warnings along with its result.
+	res, diags := evalTemplate(ctx, heredocParts(file.Fragments))
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return res, nil
+}
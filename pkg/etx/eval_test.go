@@ -0,0 +1,558 @@
+package etx
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func parseTestExpr(t *testing.T, input string) *Expr {
+	t.Helper()
+
+	var res Expr
+
+	parser := participle.MustBuild(&res,
+		participle.Lexer(lexer.MustStateful(lexRules())),
+		participle.UseLookahead(parserLookahead))
+
+	require.NoError(t, parser.ParseString("", input, &res))
+
+	return &res
+}
+
+func testEvalContext() *EvalContext {
+	return &EvalContext{
+		Variables: map[string]value.Value{
+			"foo":  value.Int(42),
+			"name": value.String("etx"),
+			"list": value.List(value.Int(10), value.Int(20), value.Int(30)),
+			"obj": value.Map(map[string]value.Value{
+				"a": value.Int(1),
+				"b": value.Map(map[string]value.Value{
+					"c": value.String("nested"),
+				}),
+				"items": value.List(value.String("x"), value.String("y")),
+			}),
+		},
+		Functions: map[string]*value.Function{
+			"upper": {
+				Name:   "upper",
+				Params: []value.Param{{Name: "str"}},
+				Impl: func(args []value.Value) (value.Value, error) {
+					return value.String(strings.ToUpper(args[0].AsString())), nil
+				},
+			},
+			"apply": {
+				Name:     "apply",
+				Params:   []value.Param{{Name: "f"}},
+				VarParam: &value.Param{Name: "args", AllowNull: true},
+				Impl: func(args []value.Value) (value.Value, error) {
+					return args[0].AsFunction().Call(args[1:])
+				},
+			},
+			"adder": {
+				Name:   "adder",
+				Params: []value.Param{{Name: "n"}},
+				Impl: func(args []value.Value) (value.Value, error) {
+					n := args[0]
+
+					return value.Func(&value.Function{
+						Name:   "add",
+						Params: []value.Param{{Name: "x"}},
+						Impl: func(args []value.Value) (value.Value, error) {
+							return value.Add(n, args[0])
+						},
+					}), nil
+				},
+			},
+		},
+	}
+}
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	bigProduct, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	tests := []struct {
+		name  string
+		input string
+		want  value.Value
+	}{
+		{name: "Null", input: `null`, want: value.Null},
+		{name: "Bool", input: `true`, want: value.True},
+		{name: "Number", input: `42`, want: value.Int(42)},
+		{name: "Hexadecimal number", input: `0xff`, want: value.Int(255)},
+		{name: "Octal number", input: `010`, want: value.Int(8)},
+		{name: "String", input: `"foo"`, want: value.String("foo")},
+		{name: "String escapes", input: `"a\tbA"`, want: value.String("a\tbA")},
+		{name: "Heredoc", input: "<<EOF\nfoo\nbar\nEOF", want: value.String("foo\nbar\n")},
+		{name: "Interpolation", input: `"${name} is ${foo / 2}"`, want: value.String("etx is 21")},
+		{name: "Interpolated bool and fraction", input: `"${true}-${1 / 4}"`, want: value.String("true-0.25")},
+		{name: "Escaped templates", input: `"$${name} %%{x}"`, want: value.String("${name} %{x}")},
+		{name: "If directive", input: `"%{ if foo == 42 }yes%{ else }no%{ endif }"`, want: value.String("yes")},
+		{name: "Else directive", input: `"%{ if foo != 42 }yes%{ else }no%{ endif }"`, want: value.String("no")},
+		{name: "For directive", input: `"%{ for x in list }${x},%{ endfor }"`, want: value.String("10,20,30,")},
+		{name: "For directive with index", input: `"%{ for i, x in obj.items }${i}=${x} %{ endfor }"`, want: value.String("0=x 1=y ")},
+		{name: "For directive over map", input: `"%{ for k, v in obj.b }${k}=${v}%{ endfor }"`, want: value.String("c=nested")},
+		{name: "Nested directives", input: `"%{ for x in list }%{ if x > 10 }${x}%{ endif }%{ endfor }"`, want: value.String("2030")},
+		{name: "Strip markers", input: `"a  ${~ name ~}  b %{~ if true ~} c %{~ endif }"`, want: value.String("aetxbc")},
+		{name: "Heredoc interpolation", input: "<<EOF\nHello ${upper(name)}\nEOF", want: value.String("Hello ETX\n")},
+		{name: "Indented heredoc", input: "<<-EOF\n    foo\n      bar\n\n    baz\n    EOF", want: value.String("foo\n  bar\n\nbaz\n")},
+		{name: "Indented heredoc with tabs", input: "<<-EOF\n\t\tfoo\n\tbar\n\tEOF", want: value.String("\tfoo\nbar\n")},
+		{name: "Indented heredoc interpolation", input: "<<-EOF\n  ${name}\n    ${foo}\n  EOF", want: value.String("etx\n  42\n")},
+		{name: "Heredoc directives", input: "<<EOF\n%{ for x in list ~}\n- ${x}\n%{ endfor ~}\nEOF", want: value.String("- 10\n- 20\n- 30\n")},
+		{name: "List", input: `[1, "a", null]`, want: value.List(value.Int(1), value.String("a"), value.Null)},
+		{name: "Map", input: `{a = 1, "b" = "x"}`, want: value.Map(map[string]value.Value{"a": value.Int(1), "b": value.String("x")})},
+
+		{name: "Addition", input: `1 + 2 + 3`, want: value.Int(6)},
+		{name: "Subtraction is left associative", input: `10 - 4 - 3`, want: value.Int(3)},
+		{name: "Division is left associative", input: `100 / 10 / 5`, want: value.Int(2)},
+		{name: "Precedence", input: `1 + 2 * 3`, want: value.Int(7)},
+		{name: "Parentheses", input: `(1 + 2) * 3`, want: value.Int(9)},
+		{name: "Modulo", input: `-7 % 3`, want: value.Int(-1)},
+		{name: "Fractional modulo", input: `5.5 % 2`, want: value.Float(1.5)},
+		{name: "Fraction", input: `1 / 4`, want: value.Float(0.25)},
+		{name: "Arbitrary precision", input: `12345678901234567890123456789 * 10`, want: value.Number(new(big.Float).SetInt(bigProduct))},
+		{name: "String concatenation", input: `"foo" + "bar"`, want: value.String("foobar")},
+
+		{name: "Unary minus", input: `-foo`, want: value.Int(-42)},
+		{name: "Unary plus", input: `+foo`, want: value.Int(42)},
+		{name: "Logical not", input: `!true`, want: value.False},
+		{name: "Bitwise not", input: `~5`, want: value.Int(-6)},
+
+		{name: "Bitwise and", input: `6 & 3`, want: value.Int(2)},
+		{name: "Bitwise or", input: `6 | 3`, want: value.Int(7)},
+		{name: "Bitwise xor", input: `6 ^ 3`, want: value.Int(5)},
+		{name: "Shift left", input: `1 << 4`, want: value.Int(16)},
+		{name: "Shift right", input: `256 >> 4 >> 2`, want: value.Int(4)},
+		{name: "Shift before comparison", input: `1 << 2 == 4`, want: value.True},
+
+		{name: "Less", input: `1 < 2`, want: value.True},
+		{name: "Less or equal", input: `2 <= 2`, want: value.True},
+		{name: "More", input: `1 > 2`, want: value.False},
+		{name: "More or equal", input: `1 >= 2`, want: value.False},
+		{name: "String ordering", input: `"a" < "b"`, want: value.True},
+		{name: "Equal", input: `foo == 42`, want: value.True},
+		{name: "Not equal", input: `name != "etx"`, want: value.False},
+		{name: "Deep equality", input: `[1, {a = 2}] == [1, {a = 2}]`, want: value.True},
+		{name: "Different kinds are not equal", input: `1 == "1"`, want: value.False},
+
+		{name: "Logical and", input: `true && false`, want: value.False},
+		{name: "Logical or", input: `false || true`, want: value.True},
+		{name: "Logical or short-circuit", input: `true || undefined`, want: value.True},
+		{name: "Logical and short-circuit", input: `false && undefined`, want: value.False},
+		{name: "Logical precedence", input: `true || false && false`, want: value.True},
+
+		{name: "Conditional true", input: `foo > 1 ? "big" : "small"`, want: value.String("big")},
+		{name: "Conditional false", input: `foo > 100 ? "big" : "small"`, want: value.String("small")},
+		{name: "Conditional skips the other branch", input: `true ? 1 : undefined`, want: value.Int(1)},
+		{name: "If", input: `if foo == 42 { "yes" } else { "no" }`, want: value.String("yes")},
+		{name: "Else", input: `if foo != 42 { "yes" } else { "no" }`, want: value.String("no")},
+		{name: "If without else", input: `if false { "yes" }`, want: value.Null},
+		{name: "Switch", input: "switch foo {\ncase 1, 42: { \"a\" }\ndefault: { \"b\" }\n}", want: value.String("a")},
+		{name: "Switch default", input: "switch foo {\ndefault: { \"b\" }\ncase 1: { \"a\" }\n}", want: value.String("b")},
+
+		{name: "Variable", input: `name`, want: value.String("etx")},
+		{name: "Attribute", input: `obj.b.c`, want: value.String("nested")},
+		{name: "List index", input: `list[1]`, want: value.Int(20)},
+		{name: "Map index", input: `obj["a"]`, want: value.Int(1)},
+		{name: "Index then attribute", input: `obj["b"].c`, want: value.String("nested")},
+		{name: "Attribute then index", input: `obj.items[1]`, want: value.String("y")},
+		{name: "Function call", input: `upper(name)`, want: value.String("ETX")},
+		{name: "Curried call", input: `adder(1)(2)`, want: value.Int(3)},
+		{name: "Lambda", input: `apply(x => x * 2, 21)`, want: value.Int(42)},
+		{name: "Parenthesised lambda", input: `apply((a, b) => a - b, 5, 3)`, want: value.Int(2)},
+		{name: "Lambda parameters", input: `apply((k, v) => k + v, 1, 2)`, want: value.Int(3)},
+		{name: "Typed lambda", input: `apply((x: number) => x + 1, 1)`, want: value.Int(2)},
+		{name: "Lambda closure", input: `apply(x => x + foo, 1)`, want: value.Int(43)},
+		{name: "Nested lambda closure", input: `apply(x => apply(y => x * 10 + y, 2), 1)`, want: value.Int(12)},
+		{name: "Lambda parameter shadowing", input: `apply(foo => foo, 1)`, want: value.Int(1)},
+		{name: "Lambda null argument", input: `apply(x => x == null, null)`, want: value.True},
+		{name: "Function in expression", input: `upper("a") + upper("b")`, want: value.String("AB")},
+		{name: "Library function", input: `format("%s-%03d", lower("ETX"), foo)`, want: value.String("etx-042")},
+		{name: "Context function before library function", input: `upper(name)`, want: value.String("ETX")},
+		{name: "Library function value", input: `apply(max, 1, foo, 3)`, want: value.Int(42)},
+		{name: "Try", input: `try(obj.z, bar, obj.a)`, want: value.Int(1)},
+		{name: "Try first success", input: `try(foo, bar)`, want: value.Int(42)},
+		{name: "Can", input: `can(obj.z)`, want: value.False},
+		{name: "Can success", input: `can(list[2])`, want: value.True},
+		{name: "Lazy arguments in lambda", input: `apply(x => try(x.z, "none"), obj)`, want: value.String("none")},
+		{name: "For list", input: `[for v in list : v * 2]`, want: value.List(value.Int(20), value.Int(40), value.Int(60))},
+		{name: "For list with index", input: `[for i, v in obj.items : "${i}${v}"]`, want: value.List(value.String("0x"), value.String("1y"))},
+		{name: "For list with condition", input: `[for v in list : v if v > 15]`, want: value.List(value.Int(20), value.Int(30))},
+		{name: "For map", input: `{for k, v in obj : k => v if k != "b"}`, want: value.Map(map[string]value.Value{"a": value.Int(1), "items": value.List(value.String("x"), value.String("y"))})},
+		{name: "For map over a list", input: `{for i, v in list : i => v}`, want: value.Map(map[string]value.Value{"0": value.Int(10), "1": value.Int(20), "2": value.Int(30)})},
+		{name: "For map grouping", input: `{for s in ["a", "bb", "c"] : length(s) => s...}`, want: value.Map(map[string]value.Value{"1": value.List(value.String("a"), value.String("c")), "2": value.List(value.String("bb"))})},
+		{name: "For keyword key", input: `{for x in [1] : true => x}`, want: value.Map(map[string]value.Value{"true": value.Int(1)})},
+		{name: "For expression key", input: `{for v in obj.items : upper(v) => v}`, want: value.Map(map[string]value.Value{"X": value.String("x"), "Y": value.String("y")})},
+		{name: "Nested for", input: `[for v in [for w in list : w / 10] : v + 1]`, want: value.List(value.Int(2), value.Int(3), value.Int(4))},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), testEvalContext())
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		column  int
+	}{
+		{name: "Unknown variable", input: `1 + bar`, summary: "Unknown variable", column: 5},
+		{name: "Invalid operand", input: `"a" << 2`, summary: `Invalid operand for "<<"`, column: 1},
+		{name: "Division by zero", input: `1 / 0`, summary: `Invalid operand for "/"`, column: 1},
+		{name: "Bitwise on fraction", input: `1.5 & 1`, summary: `Invalid operand for "&"`, column: 1},
+		{name: "Non-boolean condition", input: `1 ? 2 : 3`, summary: "Invalid condition", column: 1},
+		{name: "Non-boolean logical operand", input: `true && 1`, summary: `Invalid operand for "&&"`, column: 9},
+		{name: "Index out of range", input: `list[3]`, summary: "Invalid index", column: 6},
+		{name: "Missing key", input: `obj["z"]`, summary: "Invalid index", column: 5},
+		{name: "Missing attribute", input: `obj.z`, summary: "Unsupported attribute", column: 1},
+		{name: "Call of a non-function", input: `foo(1)`, summary: "Not a function", column: 5},
+		{name: "Wrong arity", input: `upper("a", "b")`, summary: `Error in function call "upper"`, column: 7},
+		{name: "Lambda argument type", input: `apply((x: number) => x, "a")`, summary: `Error in function call "apply"`, column: 7},
+		{name: "Error in lambda body", input: `apply(x => x + bar, 1)`, summary: "Unknown variable", column: 16},
+		{name: "Lambda arity", input: `apply((a, b) => a, 1)`, summary: `Error in function call "apply"`, column: 7},
+		{name: "Library function argument", input: `parseint("12", 2)`, summary: `Error in function call "parseint"`, column: 10},
+		{name: "Try without success", input: `try(bar, obj.z)`, summary: `Error in function call "try"`, column: 5},
+		{name: "No matching case", input: "switch foo {\ncase 1: { 1 }\n}", summary: "No matching case", column: 1},
+		{name: "Interpolated null", input: `"a${null}"`, summary: "Invalid template interpolation value", column: 3},
+		{name: "Interpolated list", input: `"a${list}"`, summary: "Invalid template interpolation value", column: 3},
+		{name: "Non-boolean directive condition", input: `"%{ if foo }a%{ endif }"`, summary: "Invalid condition", column: 8},
+		{name: "Invalid for collection", input: `"%{ for x in foo }a%{ endfor }"`, summary: "Invalid for collection", column: 14},
+		{name: "Unterminated if", input: `"%{ if true }a"`, summary: "Unterminated directive", column: 2},
+		{name: "Unexpected endfor", input: `"%{ if true }a%{ endfor }"`, summary: "Unexpected directive", column: 15},
+		{name: "Duplicate map key", input: `{a = 1, a = 2}`, summary: "Duplicate map key", column: 9},
+		{name: "For over a number", input: `[for v in foo : v]`, summary: "Invalid for collection", column: 11},
+		{name: "For over a bool", input: `[for x in false : x]`, summary: "Invalid for collection", column: 11},
+		{name: "For over null", input: `[for x in null : x]`, summary: "Invalid for collection", column: 11},
+		{name: "Non-boolean for condition", input: `[for v in list : v if v]`, summary: "Invalid condition", column: 23},
+		{name: "Invalid for map key", input: `{for v in list : list => v}`, summary: "Invalid map key", column: 18},
+		{name: "Duplicate for map key", input: `{for v in list : "a" => v}`, summary: "Duplicate map key", column: 18},
+		{name: "For variable outside its expression", input: `[[for v in list : v], v]`, summary: "Unknown variable", column: 23},
+		{name: "Error", input: `error "invalid ${name}"`, summary: "Evaluation aborted", column: 1},
+		{name: "Error in switch", input: `switch foo { case 1: { 1 } default: { error "invalid" } }`, summary: "Evaluation aborted", column: 39},
+		{name: "Error with a non-string message", input: `error foo`, summary: "Invalid error message", column: 7},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), testEvalContext())
+			require.True(t, diags.HasErrors())
+			assert.True(t, res.IsNull())
+			assert.Equal(t, tt.summary, diags[0].Summary, diags.Error())
+			assert.Equal(t, tt.column, diags[0].Pos.Column, diags.Error())
+		})
+	}
+}
+
+func TestEval_Error(t *testing.T) {
+	t.Parallel()
+
+	_, diags := Eval(parseTestExpr(t, `foo > 1 ? error "invalid name ${upper(name)}" : 1`), testEvalContext())
+	require.Len(t, diags, 1)
+	assert.Equal(t, `1:11: error: Evaluation aborted; invalid name ETX`, diags[0].Error())
+}
+
+func TestEval_ForDuplicateKey(t *testing.T) {
+	t.Parallel()
+
+	_, diags := Eval(parseTestExpr(t, `{for v in ["a", "b", "a"] : v => 1}`), testEvalContext())
+	require.Len(t, diags, 1)
+	assert.Equal(t, `1:29: error: Duplicate map key; key "a" is already defined; add ... after the value to group the values by key`, diags[0].Error())
+}
+
+func TestEval_Unknown(t *testing.T) {
+	t.Parallel()
+
+	ctx := func() *EvalContext {
+		ctx := testEvalContext()
+		ctx.Variables["resource"] = value.Map(map[string]value.Value{
+			"foo": value.Map(map[string]value.Value{
+				"id":    value.Unknown(value.TypeString).RefineNotNull(),
+				"count": value.Unknown(value.TypeNumber),
+				"tags":  value.Unknown(value.ListOf(value.TypeString)).RefineLength(3),
+				"attrs": value.Unknown(value.ObjectOf(map[string]value.Type{"arn": value.TypeString})),
+				"ready": value.Unknown(value.TypeBool),
+			}),
+		})
+
+		return ctx
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Arithmetic", input: `resource.foo.count * 2 + 1`, want: "unknown(number, not null)"},
+		{name: "Concatenation", input: `"id-" + resource.foo.id`, want: "unknown(string, not null)"},
+		{name: "Comparison", input: `resource.foo.count > 2`, want: "unknown(bool, not null)"},
+		{name: "Equality", input: `resource.foo.id == "a"`, want: "unknown(bool, not null)"},
+		{name: "Equality with null refined", input: `resource.foo.id == null`, want: "false"},
+		{name: "Logical short-circuit", input: `false && resource.foo.ready`, want: "false"},
+		{name: "Logical decided by right operand", input: `resource.foo.ready || true`, want: "true"},
+		{name: "Logical", input: `resource.foo.ready && true`, want: "unknown(bool, not null)"},
+		{name: "Negation", input: `!resource.foo.ready`, want: "unknown(bool, not null)"},
+		{name: "Unary plus", input: `+resource.foo.count`, want: "unknown(number, not null)"},
+		{name: "Conditional", input: `resource.foo.ready ? "a" : "b"`, want: "unknown(string, not null, length 1)"},
+		{name: "Conditional with same branches", input: `resource.foo.ready ? 1 : 1`, want: "1"},
+		{name: "Conditional with null branch", input: `resource.foo.ready ? "a" : null`, want: "unknown(string)"},
+		{name: "If", input: `if (resource.foo.ready) { [1, 2, 3] } else { resource.foo.tags }`, want: "unknown(not null, length 3)"},
+		{name: "Switch on unknown", input: `switch resource.foo.id { case "a": { 1 } default: { 2 } }`, want: "unknown(number, not null)"},
+		{name: "Switch after known case", input: `switch foo { case 42: { 1 } case resource.foo.count: { 2 } }`, want: "1"},
+		{name: "Switch on unknown case", input: `switch foo { case resource.foo.count: { 1 } case 42: { 2 } case 43: { 3 } }`, want: "unknown(number, not null)"},
+		{name: "Switch with error case", input: `switch resource.foo.id { case "a": { 1 } default: { error "invalid" } }`, want: "1"},
+		{name: "Switch with error cases only", input: `switch resource.foo.id { case "a": { error "a" } default: { error "b" } }`, want: "unknown"},
+		{name: "Conditional with error branch", input: `resource.foo.ready ? error resource.foo.id : 1`, want: "1"},
+		{name: "Index", input: `resource.foo.tags[0]`, want: "unknown(string)"},
+		{name: "Unknown index", input: `list[resource.foo.count]`, want: "unknown(number)"},
+		{name: "Attribute", input: `resource.foo.attrs.arn`, want: "unknown(string)"},
+		{name: "Known length", input: `length(resource.foo.tags)`, want: "3"},
+		{name: "Function call", input: `upper(resource.foo.id)`, want: "unknown"},
+		{name: "Unknown list element", input: `length([resource.foo.id, "b"])`, want: "2"},
+		{name: "Method on unknown", input: `resource.foo.tags.map((t) => upper(t))`, want: "unknown"},
+		{name: "Lambda", input: `apply((x) => x + 1, resource.foo.count)`, want: "unknown"},
+		{name: "Lambda on unknown elements", input: `[1, resource.foo.count].map((x) => x * 2)`, want: "[2, unknown(number, not null)]"},
+		{name: "Unknown predicate", input: `[1, 2].filter((x) => x == resource.foo.count)`, want: "unknown"},
+		{name: "Template", input: `"id: ${resource.foo.id}"`, want: "unknown(string, not null)"},
+		{name: "Template directive", input: `"%{ if resource.foo.ready }a%{ endif }"`, want: "unknown(string, not null)"},
+		{name: "Template loop", input: `"%{ for t in resource.foo.tags }${t}%{ endfor }"`, want: "unknown(string, not null)"},
+		{name: "For over unknown", input: `[for t in resource.foo.tags : upper(t)]`, want: "unknown(list(any), not null)"},
+		{name: "For with unknown condition", input: `[for v in list : v if v > resource.foo.count]`, want: "unknown(list(any), not null)"},
+		{name: "For with unknown value", input: `[for v in list : resource.foo.id]`, want: "[unknown(string, not null), unknown(string, not null), unknown(string, not null)]"},
+		{name: "For with unknown key", input: `{for v in list : resource.foo.id => v}`, want: "unknown(map(any), not null)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), ctx())
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, tt.want, res.GoString())
+		})
+	}
+}
+
+func TestEval_UnknownErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := testEvalContext()
+	ctx.Variables["id"] = value.Unknown(value.TypeString)
+	ctx.Variables["tags"] = value.Unknown(value.ListOf(value.TypeString)).RefineLength(3)
+
+	for _, input := range []string{`id - 1`, `id && true`, `id ? 1 : 2`, `id[0]`, `"${[id]}"`, `tags[3]`, `tags[-1]`} {
+		_, diags := Eval(parseTestExpr(t, input), ctx)
+		assert.True(t, diags.HasErrors(), input)
+	}
+
+	_, diags := Eval(parseTestExpr(t, `tags[5]`), ctx)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "1:6: error: Invalid index; index out of range: 5 with length 3", diags[0].Error())
+}
+
+func TestEval_Sensitive(t *testing.T) {
+	t.Parallel()
+
+	ctx := func() *EvalContext {
+		ctx := testEvalContext()
+		ctx.Variables["password"] = value.String("hunter2").MarkSensitive()
+		ctx.Variables["limit"] = value.Int(2).MarkSensitive()
+		ctx.Variables["creds"] = value.Map(map[string]value.Value{
+			"user":     value.String("admin"),
+			"password": value.String("hunter2").MarkSensitive(),
+		})
+
+		return ctx
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		want      value.Value
+		sensitive bool
+	}{
+		{name: "Variable", input: `password`, want: value.String("hunter2"), sensitive: true},
+		{name: "Operator", input: `password + "!"`, want: value.String("hunter2!"), sensitive: true},
+		{name: "Comparison", input: `limit > 1`, want: value.True, sensitive: true},
+		{name: "Equality", input: `password == "x"`, want: value.False, sensitive: true},
+		{name: "Unary", input: `-limit`, want: value.Int(-2), sensitive: true},
+		{name: "Logical", input: `limit > 1 || false`, want: value.True, sensitive: true},
+		{name: "Logical short-circuit", input: `false && limit > 1`, want: value.False},
+		{name: "Template", input: `"pass: ${password}"`, want: value.String("pass: hunter2"), sensitive: true},
+		{name: "Template directive", input: `"%{ if limit > 1 }big%{ endif }"`, want: value.String("big"), sensitive: true},
+		{name: "Function", input: `upper(password)`, want: value.String("HUNTER2"), sensitive: true},
+		{name: "Function on element", input: `length([password, "a"])`, want: value.Int(2), sensitive: true},
+		{name: "Lambda", input: `apply((x) => x, 1) + apply((x) => x, limit)`, want: value.Int(3), sensitive: true},
+		{name: "Conditional", input: `limit > 1 ? "a" : "b"`, want: value.String("a"), sensitive: true},
+		{name: "If", input: `if (limit > 3) { "a" }`, want: value.Null, sensitive: true},
+		{name: "Switch", input: `switch limit { case 1: { "one" } case 2: { "two" } }`, want: value.String("two"), sensitive: true},
+		{name: "List keeps element marks", input: `[password, "a"]`, want: value.List(value.String("hunter2"), value.String("a"))},
+		{name: "Sensitive element", input: `[password, "a"][0]`, want: value.String("hunter2"), sensitive: true},
+		{name: "Other element", input: `[password, "a"][1]`, want: value.String("a")},
+		{name: "Sensitive index", input: `list[limit]`, want: value.Int(30), sensitive: true},
+		{name: "Attribute", input: `creds.password`, want: value.String("hunter2"), sensitive: true},
+		{name: "Other attribute", input: `creds.user`, want: value.String("admin")},
+		{name: "Sensitive map key", input: `{"${password}" = 1}`, want: value.Map(map[string]value.Value{"hunter2": value.Int(1)}), sensitive: true},
+		{name: "Method", input: `list.map((x) => x * limit)`, want: value.List(value.Int(20), value.Int(40), value.Int(60))},
+		{name: "Method element", input: `list.map((x) => x * limit)[0]`, want: value.Int(20), sensitive: true},
+		{name: "Method comparing elements", input: `[password, "a"].contains("a")`, want: value.True, sensitive: true},
+		{name: "Method decided by callback", input: `list.filter((x) => x > limit * 10)`, want: value.List(value.Int(30)), sensitive: true},
+		{name: "Method on sensitive collection", input: `sensitive(list).reverse()`, want: value.List(value.Int(30), value.Int(20), value.Int(10)), sensitive: true},
+		{name: "For over sensitive collection", input: `[for v in sensitive(list) : v]`, want: value.List(value.Int(10), value.Int(20), value.Int(30)), sensitive: true},
+		{name: "For keeps element marks", input: `[for v in [password, "a"] : v]`, want: value.List(value.String("hunter2"), value.String("a"))},
+		{name: "For decided by condition", input: `[for v in list : v if v > limit * 10]`, want: value.List(value.Int(30)), sensitive: true},
+		{name: "For sensitive key", input: `{for v in [1] : password => v}`, want: value.Map(map[string]value.Value{"hunter2": value.Int(1)}), sensitive: true},
+		{name: "Sensitive", input: `sensitive("a")`, want: value.String("a"), sensitive: true},
+		{name: "Nonsensitive", input: `nonsensitive(password)`, want: value.String("hunter2")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), ctx())
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+			assert.Equal(t, tt.sensitive, res.IsSensitive())
+		})
+	}
+}
+
+func TestEval_SensitiveRedaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := testEvalContext()
+	ctx.Variables["password"] = value.String("hunter2").MarkSensitive()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+	}{
+		{name: "Function error", input: `base64decode(password)`, summary: `Error in function call "base64decode"`},
+		{name: "Missing key", input: `obj[password]`, summary: "Invalid index"},
+		{name: "Duplicate map key", input: `{"${password}" = 1, hunter2 = 2}`, summary: "Duplicate map key"},
+		{name: "No matching case", input: `switch password { case "a": { 1 } }`, summary: "No matching case"},
+		{name: "Error message", input: `error "invalid password ${password}"`, summary: "Evaluation aborted"},
+		{name: "Lambda body error", input: `apply((p) => error "invalid password ${p}", password)`, summary: "Evaluation aborted"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, diags := Eval(parseTestExpr(t, tt.input), ctx)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.summary, diags[0].Summary)
+			assert.NotContains(t, diags.Error(), "hunter2")
+		})
+	}
+}
+
+func TestEval_Sandbox(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"hello.txt":      "Hello World",
+		"list.tmpl":      "%{ for i, item in items ~}\n${i}: ${upper(item)}\n%{ endfor ~}\nTotal: ${length(items)}",
+		"file.tmpl":      `${trimspace(file("hello.txt"))} at ${timestamp()}`,
+		"escaped.tmpl":   "$${name} %%{ if }",
+		"recursive.tmpl": `${templatefile("recursive.tmpl", vars)}`,
+		"invalid.tmpl":   "line\n${ 1 + }",
+		"unknown.tmpl":   "${name}",
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o600))
+	}
+
+	ctx := &EvalContext{
+		BaseDir: root,
+		Now: func() time.Time {
+			return time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)
+		},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    value.Value
+		wantErr string
+	}{
+		{name: "Timestamp", input: `timestamp()`, want: value.String("2022-06-01T12:30:00Z")},
+		{name: "File", input: `file("hello.txt")`, want: value.String("Hello World")},
+		{name: "File outside of the base directory", input: `file("../hello.txt")`, wantErr: "is outside of the base directory"},
+		{name: "Template", input: `templatefile("list.tmpl", {items = ["a", "b"]})`, want: value.String("0: A\n1: B\nTotal: 2")},
+		{name: "Template with functions", input: `templatefile("file.tmpl", {})`, want: value.String("Hello World at 2022-06-01T12:30:00Z")},
+		{name: "Template escapes", input: `templatefile("escaped.tmpl", {})`, want: value.String("${name} %{ if }")},
+		{name: "Template variables", input: `templatefile("unknown.tmpl", {})`, wantErr: "unknown.tmpl:1:3: error: Unknown variable"},
+		{name: "Recursive template", input: `templatefile("recursive.tmpl", {vars = {}})`, wantErr: "cannot recursively call templatefile"},
+		{name: "Invalid template", input: `templatefile("invalid.tmpl", {})`, wantErr: "invalid.tmpl:2:6: error: Invalid template"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), ctx.NewChild())
+			if tt.wantErr != "" {
+				require.True(t, diags.HasErrors())
+				assert.Contains(t, diags.Error(), tt.wantErr)
+
+				return
+			}
+
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestEval_SandboxDisabled(t *testing.T) {
+	t.Parallel()
+
+	_, diags := Eval(parseTestExpr(t, `file("hello.txt")`), nil)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "filesystem access is disabled")
+
+	res, diags := Eval(parseTestExpr(t, `timestamp()`), nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`, res.AsString())
+}
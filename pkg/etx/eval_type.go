@@ -0,0 +1,622 @@
+package etx
+
+import (
+	"fmt"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// enumNamespace is the root of the identifiers referencing enums, as in
+// enum.name.member.
+const enumNamespace = "enum"
+
+// Enum is an enum type defined in a module: an ordered set of members with
+// unique constant values.
+type Enum struct {
+	Name    string
+	Members []string
+	Values  map[string]value.Value
+}
+
+// Label returns the name of the member whose value is v.
+func (e *Enum) Label(v value.Value) (string, bool) {
+	for _, member := range e.Members {
+		if e.Values[member].Equals(v) {
+			return member, true
+		}
+	}
+
+	return "", false
+}
+
+// Value returns the members of the enum as a map of their values.
+func (e *Enum) Value() value.Value {
+	return value.Map(e.Values)
+}
+
+// DefineTypes declares the enums and object types defined at the root of an
+// AST in the context. The values of enum members and the defaults of object
+// attributes are evaluated in order of declaration, and the values of the
+// members must be unique within an enum.
+func (c *EvalContext) DefineTypes(ast *AST) Diagnostics {
+	var diags Diagnostics
+
+	if c.Enums == nil {
+		c.Enums = make(map[string]*Enum)
+	}
+
+	if c.Types == nil {
+		c.Types = make(map[string]*ObjectType)
+	}
+
+	for _, item := range ast.Items {
+		if item.Type == nil {
+			continue
+		}
+
+		label := item.Type.Label
+
+		if c.Enums[label] != nil || c.Types[label] != nil {
+			diags = append(diags, errorDiag(item.Type.Pos, "Duplicate type", fmt.Sprintf("type %q is already defined", label)))
+
+			continue
+		}
+
+		switch {
+		case item.Type.Enum != nil:
+			enum, enumDiags := item.Type.Enum.eval(c, label)
+
+			diags = append(diags, enumDiags...)
+			if !enumDiags.HasErrors() {
+				c.Enums[label] = enum
+			}
+
+		case item.Type.Object != nil:
+			typ, typeDiags := item.Type.Object.eval(c, label)
+
+			diags = append(diags, typeDiags...)
+			if !typeDiags.HasErrors() {
+				c.Types[label] = typ
+			}
+		}
+	}
+
+	return diags
+}
+
+func (c *EvalContext) lookupEnum(name string) (*Enum, bool) {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if e, ok := ctx.Enums[name]; ok {
+			return e, true
+		}
+	}
+
+	return nil, false
+}
+
+// enumOf returns the enum referenced by the expression, unless a variable
+// shadows the enum namespace.
+func (c *EvalContext) enumOf(e *ExprPrimary) (*Enum, bool) {
+	name, ok := e.enumName()
+	if !ok {
+		return nil, false
+	}
+
+	if _, shadowed := c.lookupVariable(enumNamespace); shadowed {
+		return nil, false
+	}
+
+	return c.lookupEnum(name)
+}
+
+// eval returns the enum defined by n.
+func (n *TypeEnum) eval(ctx *EvalContext, name string) (*Enum, Diagnostics) {
+	var diags Diagnostics
+
+	enum := &Enum{Name: name, Values: make(map[string]value.Value)}
+
+	for _, item := range n.Items {
+		if item.Label == "" {
+			continue
+		}
+
+		if _, exists := enum.Values[item.Label]; exists {
+			return nil, append(diags, errorDiag(item.Pos, "Duplicate enum member",
+				fmt.Sprintf("%q is already a member of enum %q", item.Label, name)))
+		}
+
+		v, itemDiags := item.Value.eval(ctx)
+
+		diags = append(diags, itemDiags...)
+		if itemDiags.HasErrors() {
+			return nil, diags
+		}
+
+		if other, exists := enum.Label(v); exists {
+			return nil, append(diags, errorDiag(item.Value.Pos, "Duplicate enum value",
+				fmt.Sprintf("value %s of %q is already used by %q", v.GoString(), item.Label, other)))
+		}
+
+		enum.Members = append(enum.Members, item.Label)
+		enum.Values[item.Label] = v
+	}
+
+	return enum, diags
+}
+
+// enumName returns the name of the enum referenced by the expression, when
+// it is exactly enum.name.
+func (e *ExprPrimary) enumName() (string, bool) {
+	if e.Ident == nil || len(e.Monads) != 0 || e.Post != nil {
+		return "", false
+	}
+
+	if len(e.Ident.Parts) != 2 || e.Ident.Parts[0] != enumNamespace { //nolint:gomnd // enum.name
+		return "", false
+	}
+
+	return e.Ident.Parts[1], true
+}
+
+// enumName returns the name of the enum named by the annotation n, written
+// enum.name or name. A single name is only an enum if such an enum is
+// defined.
+func (n *ParameterType) enumName() (string, bool) {
+	if n == nil || n.Ident == nil || n.Or != nil || len(n.Args) != 0 {
+		return "", false
+	}
+
+	switch parts := n.Ident.Parts; {
+	case len(parts) == 2 && parts[0] == enumNamespace: //nolint:gomnd // enum.name
+		return parts[1], true
+	case len(parts) == 1:
+		return parts[0], true
+	default:
+		return "", false
+	}
+}
+
+// conformEnum checks that v is the value of a member of the enum named by
+// the annotation n, if any. The errors name the value as subject, and are
+// reported at pos.
+func (n *ParameterType) conformEnum(ctx *EvalContext, v value.Value, subject string, pos Position) Diagnostics {
+	if n != nil && n.Optional != nil {
+		n = n.Optional
+	}
+
+	name, ok := n.enumName()
+	if !ok || !v.IsKnown() || v.IsNull() {
+		return nil
+	}
+
+	enum, ok := ctx.lookupEnum(name)
+	if !ok {
+		return nil
+	}
+
+	if _, ok := enum.Label(v); !ok {
+		return Diagnostics{errorDiag(pos, "Invalid value", fmt.Sprintf("%s must be a member of enum %q, got %s", subject, enum.Name, v.GoString()))}
+	}
+
+	return nil
+}
+
+// resolveEnum returns the value referenced by an identifier of the enum
+// namespace: enum.name is the map of the members of an enum, and
+// enum.name.member the value of a member.
+func (i *Ident) resolveEnum(ctx *EvalContext) (value.Value, Diagnostics) {
+	if len(i.Parts) < 2 { //nolint:gomnd // enum.name
+		return value.Null, Diagnostics{errorDiag(i.Pos, "Invalid enum reference", fmt.Sprintf("expected an enum name after %q", enumNamespace))}
+	}
+
+	enum, ok := ctx.lookupEnum(i.Parts[1])
+	if !ok {
+		return value.Null, Diagnostics{errorDiag(i.Pos, "Unknown enum", fmt.Sprintf("there is no enum named %q", i.Parts[1]))}
+	}
+
+	if len(i.Parts) == 2 { //nolint:gomnd // enum.name
+		return enum.Value(), nil
+	}
+
+	v, ok := enum.Values[i.Parts[2]]
+	if !ok {
+		return value.Null, Diagnostics{errorDiag(i.Pos, "Unknown enum member", fmt.Sprintf("enum %q has no member %q", enum.Name, i.Parts[2]))}
+	}
+
+	for _, part := range i.Parts[3:] {
+		res, err := attribute(v, part)
+		if err != nil {
+			return value.Null, Diagnostics{errorDiag(i.Pos, "Unsupported attribute", err.Error())}
+		}
+
+		v = res
+	}
+
+	return v, nil
+}
+
+// evalEnumLookup evaluates an index on an enum as a reverse lookup: the
+// result is the name of the member whose value is the key.
+func (e *ExprPostfix) evalEnumLookup(ctx *EvalContext, enum *Enum) (value.Value, Diagnostics) {
+	key, diags := e.Index.eval(ctx)
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	// The name of the member of an unknown value is unknown too.
+	name := value.Unknown(value.TypeString).RefineNotNull()
+
+	if key.IsKnown() {
+		label, ok := enum.Label(key)
+		if !ok {
+			return value.Null, append(diags, errorDiag(e.Index.Pos, "Invalid index",
+				fmt.Sprintf("%s: enum %q has no member with value %s", value.ErrKeyNotFound, enum.Name, key.GoString())))
+		}
+
+		name = value.String(label)
+	}
+
+	name = name.InheritSensitive(key)
+
+	if e.Post != nil {
+		res, postDiags := e.Post.evalOn(ctx, name)
+
+		return res, append(diags, postDiags...)
+	}
+
+	return name, diags
+}
+
+// /////////////////////////////////////
+
+// ObjectType is an object type defined in a module: a set of typed
+// attributes, either required or optional with a default value.
+type ObjectType struct {
+	Name  string
+	Attrs []*ObjectAttr
+}
+
+// ObjectAttr is an attribute of an object type.
+type ObjectAttr struct {
+	Name     string
+	Type     value.Type
+	Optional bool
+
+	// Default is the value of a missing optional attribute.
+	Default value.Value
+}
+
+// Attr returns the attribute name of the type.
+func (t *ObjectType) Attr(name string) (*ObjectAttr, bool) {
+	for _, attr := range t.Attrs {
+		if attr.Name == name {
+			return attr, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c *EvalContext) lookupType(name string) (*ObjectType, bool) {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if t, ok := ctx.Types[name]; ok {
+			return t, true
+		}
+	}
+
+	return nil, false
+}
+
+// lookupBlockType returns the object type bound to the blocks named name.
+func (c *EvalContext) lookupBlockType(name string) (*ObjectType, bool) {
+	for ctx := c; ctx != nil; ctx = ctx.parent {
+		if typeName, ok := ctx.BlockTypes[name]; ok {
+			return c.lookupType(typeName)
+		}
+	}
+
+	return nil, false
+}
+
+// objectType returns the object type named by the annotation n, if any.
+func (n *ParameterType) objectType(ctx *EvalContext) (*ObjectType, bool) {
+	if n == nil || n.Ident == nil || len(n.Ident.Parts) != 1 || n.Or != nil || len(n.Args) != 0 {
+		return nil, false
+	}
+
+	return ctx.lookupType(n.Ident.Parts[0])
+}
+
+// conformObjects checks v against the object types named by the
+// annotation n, including the object types of the elements of list, set,
+// map and tuple annotations, and returns v completed with their defaults.
+// The errors name the value as subject, and are reported at pos.
+func (n *ParameterType) conformObjects(ctx *EvalContext, v value.Value, subject string, pos Position) (value.Value, Diagnostics) {
+	if n == nil || n.Or != nil || !v.IsKnown() {
+		return v, nil
+	}
+
+	if n.Optional != nil {
+		if v.IsNull() {
+			return v, nil
+		}
+
+		return n.Optional.conformObjects(ctx, v, subject, pos)
+	}
+
+	if obj, ok := n.objectType(ctx); ok {
+		if v.Kind() != value.KindMap {
+			return value.Null, Diagnostics{errorDiag(pos, "Invalid value", fmt.Sprintf("%s must be a %s, got %s", subject, obj.Name, typeName(v)))}
+		}
+
+		return obj.conform(v, subject, pos, nil)
+	}
+
+	var diags Diagnostics
+
+	conformItem := func(elem *ParameterType, item value.Value, subject string) value.Value {
+		res, itemDiags := elem.conformObjects(ctx, item, subject, pos)
+		diags = append(diags, itemDiags...)
+
+		return res
+	}
+
+	switch {
+	case n.Tuple && v.Kind() == value.KindList && v.Len() == len(n.Elems):
+		items := v.AsList()
+		for i, item := range items {
+			items[i] = conformItem(n.Elems[i], item, fmt.Sprintf("element %d of %s", i, subject))
+		}
+
+		v = value.List(items...).InheritSensitive(v)
+	case n.Ident == nil || len(n.Args) != 1:
+		return v, nil
+	case n.Ident.FormattedString() == "list" && v.Kind() == value.KindList,
+		n.Ident.FormattedString() == "set" && v.Kind() == value.KindSet:
+		items := v.AsList()
+		for i, item := range items {
+			items[i] = conformItem(n.Args[0], item, fmt.Sprintf("element %d of %s", i, subject))
+		}
+
+		if v.Kind() == value.KindSet {
+			v = value.Set(items...).InheritSensitive(v)
+		} else {
+			v = value.List(items...).InheritSensitive(v)
+		}
+	case n.Ident.FormattedString() == "map" && v.Kind() == value.KindMap:
+		items := v.AsMap()
+		for _, key := range v.Keys() {
+			items[key] = conformItem(n.Args[0], items[key], fmt.Sprintf("element %q of %s", key, subject))
+		}
+
+		v = value.Map(items).InheritSensitive(v)
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return v, diags
+}
+
+// typeName returns the name of the type of v as written in the errors,
+// naming null values.
+func typeName(v value.Value) string {
+	if v.IsNull() {
+		return value.KindNull.String()
+	}
+
+	return v.Type().String()
+}
+
+// eval returns the object type defined by n.
+func (n *TypeObject) eval(ctx *EvalContext, name string) (*ObjectType, Diagnostics) {
+	var diags Diagnostics
+
+	typ := &ObjectType{Name: name}
+
+	for _, item := range n.Items {
+		if item.Label == "" {
+			continue
+		}
+
+		if _, exists := typ.Attr(item.Label); exists {
+			return nil, append(diags, errorDiag(item.Pos, "Duplicate attribute",
+				fmt.Sprintf("%q is already an attribute of type %q", item.Label, name)))
+		}
+
+		attr := &ObjectAttr{Name: item.Label, Type: item.Type.valueType()}
+
+		if item.Type.Optional != nil && item.Type.Or == nil {
+			attr.Optional = true
+
+			if item.Type.Default != nil {
+				v, defaultDiags := item.Type.Default.eval(ctx)
+
+				diags = append(diags, defaultDiags...)
+				if defaultDiags.HasErrors() {
+					return nil, diags
+				}
+
+				if !attr.Type.Conforms(v) {
+					return nil, append(diags, errorDiag(item.Type.Default.Pos, "Invalid default",
+						fmt.Sprintf("default of attribute %q must be a %s, got %s", item.Label, attr.Type, v.Type())))
+				}
+
+				attr.Default = v
+			}
+		}
+
+		typ.Attrs = append(typ.Attrs, attr)
+	}
+
+	return typ, diags
+}
+
+// conform checks the attributes of a map value against the object type, and
+// returns the map completed with the defaults of the missing optional
+// attributes. The errors are reported at the position of the attribute in
+// attrPos when it is known, and at pos otherwise.
+func (t *ObjectType) conform(v value.Value, subject string, pos Position, attrPos map[string]Position) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	at := func(name string) Position {
+		if p, ok := attrPos[name]; ok {
+			return p
+		}
+
+		return pos
+	}
+
+	names := make([]string, 0, len(t.Attrs))
+	for _, attr := range t.Attrs {
+		names = append(names, attr.Name)
+	}
+
+	for _, key := range v.Keys() {
+		if _, ok := t.Attr(key); ok {
+			continue
+		}
+
+		detail := fmt.Sprintf("%q is not an attribute of type %q", key, t.Name)
+		if suggestion, ok := suggest(key, names); ok {
+			detail += fmt.Sprintf("; did you mean %q?", suggestion)
+		}
+
+		diags = append(diags, errorDiag(at(key), "Unsupported attribute", detail))
+	}
+
+	attrs := v.AsMap()
+
+	for _, attr := range t.Attrs {
+		item, ok := attrs[attr.Name]
+
+		switch {
+		case !ok && attr.Optional:
+			attrs[attr.Name] = attr.Default
+		case !ok:
+			diags = append(diags, errorDiag(pos, "Missing attribute", fmt.Sprintf("%s requires attribute %q", subject, attr.Name)))
+		case !attr.Type.Conforms(item):
+			diags = append(diags, errorDiag(at(attr.Name), "Invalid value",
+				fmt.Sprintf("attribute %q must be a %s, got %s", attr.Name, attr.Type, item.Type())))
+		}
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	return value.Map(attrs).InheritSensitive(v), diags
+}
+
+// EvalBlock evaluates the body of a block into a map of its attributes.
+// Nested blocks are evaluated the same way, and collected into lists by
+// name. If an object type is bound to the name of the block in BlockTypes,
+// the attributes are checked against it and completed with its defaults.
+//
+// The meta-attributes of the block are left to the caller, whereas the
+// nested blocks whose if meta-attribute is false are left out, and the
+// nested blocks repeated by for_each or count add an instance per key or
+// index. The list of nested blocks is unknown when any of their conditions
+// is.
+func (c *EvalContext) EvalBlock(b *Block) (value.Value, Diagnostics) {
+	var diags Diagnostics
+
+	attrs := make(map[string]value.Value)
+	attrPos := make(map[string]Position)
+	nested := make(map[string][]value.Value)
+	conds := make(map[string][]value.Value)
+
+	for _, item := range b.Body {
+		switch {
+		case item.Attribute != nil:
+			key := item.Attribute.Key
+
+			if _, exists := attrPos[key]; exists {
+				diags = append(diags, errorDiag(item.Attribute.Pos, "Duplicate attribute", fmt.Sprintf("attribute %q is already defined", key)))
+
+				continue
+			}
+
+			attrPos[key] = item.Attribute.Pos
+
+			if item.Attribute.Value == nil {
+				attrs[key] = value.Null
+
+				continue
+			}
+
+			v, attrDiags := item.Attribute.Value.eval(c)
+
+			diags = append(diags, attrDiags...)
+			attrs[key] = v
+
+		case item.Block != nil:
+			name := item.Block.Name
+			if _, exists := attrPos[name]; !exists {
+				attrPos[name] = item.Block.Pos
+				nested[name] = nil
+			}
+
+			repeat, repeatDiags := repetition(item.Block)
+
+			diags = append(diags, repeatDiags...)
+			if repeatDiags.HasErrors() {
+				continue
+			}
+
+			if repeat != nil {
+				instances, instDiags := c.evalInstances(item.Block, repeat)
+
+				diags = append(diags, instDiags...)
+				if !instDiags.HasErrors() {
+					nested[name] = append(nested[name], instanceList(instances)...)
+				}
+
+				continue
+			}
+
+			cond, condDiags := c.evalBlockCondition(item.Block)
+
+			diags = append(diags, condDiags...)
+			if condDiags.HasErrors() || (cond.IsKnown() && !cond.AsBool()) {
+				continue
+			}
+
+			v, blockDiags := c.EvalBlock(item.Block)
+
+			diags = append(diags, blockDiags...)
+			nested[name] = append(nested[name], v)
+			conds[name] = append(conds[name], cond)
+		}
+	}
+
+	for name, blocks := range nested {
+		if _, exists := attrs[name]; exists {
+			diags = append(diags, errorDiag(attrPos[name], "Duplicate attribute", fmt.Sprintf("attribute %q is already defined", name)))
+
+			continue
+		}
+
+		attrs[name] = value.List(blocks...)
+
+		for _, cond := range conds[name] {
+			if !cond.IsKnown() {
+				attrs[name] = value.Unknown(value.ListOf(value.TypeAny)).RefineNotNull()
+			}
+		}
+
+		attrs[name] = attrs[name].InheritSensitive(conds[name]...)
+	}
+
+	if diags.HasErrors() {
+		return value.Null, diags
+	}
+
+	typ, ok := c.lookupBlockType(b.Name)
+	if !ok {
+		return value.Map(attrs), diags
+	}
+
+	return typ.conform(value.Map(attrs), fmt.Sprintf("block %q", b.Name), b.Pos, attrPos)
+}
@@ -0,0 +1,424 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const testEnums = `
+type color enum {
+	red:   1
+	green: 2
+	blue:  1 + 2
+}
+`
+
+func testEnumContext(t *testing.T) *EvalContext {
+	t.Helper()
+
+	ast, err := ParseString(testEnums)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	diags := ctx.DefineTypes(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	return ctx
+}
+
+func TestEval_Enums(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  value.Value
+	}{
+		{name: "Member", input: `enum.color.blue`, want: value.Int(3)},
+		{name: "Members", input: `enum.color`, want: value.Map(map[string]value.Value{"red": value.Int(1), "green": value.Int(2), "blue": value.Int(3)})},
+		{name: "Reverse lookup", input: `enum.color[2]`, want: value.String("green")},
+		{name: "Reverse lookup argument", input: `upper(enum.color[1 + 2])`, want: value.String("BLUE")},
+		{name: "Comparison", input: `enum.color.red == 1`, want: value.True},
+		{
+			name:  "Switch",
+			input: "switch enum.color.green {\ncase enum.color.red: { \"r\" }\ncase enum.color.green: { \"g\" }\ncase enum.color.blue: { \"b\" }\n}",
+			want:  value.String("g"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), testEnumContext(t))
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestEval_EnumErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+		column  int
+	}{
+		{name: "Unknown enum", input: `enum.size.small`, summary: "Unknown enum", detail: `there is no enum named "size"`, column: 1},
+		{name: "Unknown member", input: `enum.color.pink`, summary: "Unknown enum member", detail: `enum "color" has no member "pink"`, column: 1},
+		{name: "Namespace", input: `enum`, summary: "Invalid enum reference", detail: `expected an enum name after "enum"`, column: 1},
+		{
+			name:    "Reverse lookup",
+			input:   `enum.color[4]`,
+			summary: "Invalid index",
+			detail:  `key not found: enum "color" has no member with value 4`,
+			column:  12,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, diags := Eval(parseTestExpr(t, tt.input), testEnumContext(t))
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.summary, diags[0].Summary)
+			assert.Equal(t, tt.detail, diags[0].Detail)
+			assert.Equal(t, tt.column, diags[0].Pos.Column)
+		})
+	}
+}
+
+func TestEval_EnumShadowed(t *testing.T) {
+	t.Parallel()
+
+	ctx := testEnumContext(t)
+	ctx.Variables[enumNamespace] = value.Map(map[string]value.Value{"color": value.List(value.String("a"))})
+
+	res, diags := Eval(parseTestExpr(t, `enum.color[0]`), ctx)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.True(t, value.String("a").Equals(res))
+}
+
+func TestEval_EnumDeclarations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "Namespaced", input: `val v: enum.color = 2`},
+		{name: "Bare", input: `val v: color = enum.color.blue`},
+		{name: "Optional", input: `val v: optional(color) = null`},
+		{name: "Parameter", input: "def f(c: color) number {\n\tc\n}\n\nval v = f(3)"},
+		{name: "Namespaced non-member", input: `val v: enum.color = 7`, wantErr: `1:21: error: Invalid value; val "v" must be a member of enum "color", got 7`},
+		{name: "Bare non-member", input: `val v: color = 7`, wantErr: `1:16: error: Invalid value; val "v" must be a member of enum "color", got 7`},
+		{
+			name:    "Parameter non-member",
+			input:   "def f(c: color) number {\n\tc\n}\n\nval v = f(7)",
+			wantErr: `Invalid value; parameter "c" must be a member of enum "color", got 7`,
+		},
+		{
+			name:    "Lambda parameter non-member",
+			input:   "val f = (c: color) => c\nval v = f(7)",
+			wantErr: `Invalid value; parameter "c" must be a member of enum "color", got 7`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			ctx := testEnumContext(t)
+			diags := ctx.DefineFunctions(ast)
+			diags = append(diags, ctx.DefineValues(ast)...)
+
+			if tt.wantErr == "" {
+				require.False(t, diags.HasErrors(), diags.Error())
+
+				return
+			}
+
+			require.True(t, diags.HasErrors())
+			assert.Contains(t, diags.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestEvalContext_DefineTypesErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+		detail  string
+		line    int
+		column  int
+	}{
+		{
+			name:    "Duplicate value",
+			input:   "type t enum {\n\ta: 1\n\tb: 2\n\tc: 3 - 2\n}",
+			summary: "Duplicate enum value",
+			detail:  `value 1 of "c" is already used by "a"`,
+			line:    4,
+			column:  5,
+		},
+		{
+			name:    "Duplicate member",
+			input:   "type t enum {\n\ta: 1\n\ta: 2\n}",
+			summary: "Duplicate enum member",
+			detail:  `"a" is already a member of enum "t"`,
+			line:    3,
+			column:  2,
+		},
+		{
+			name:    "Duplicate attribute",
+			input:   "type t object {\n\ta: string\n\ta: number\n}",
+			summary: "Duplicate attribute",
+			detail:  `"a" is already an attribute of type "t"`,
+			line:    3,
+			column:  2,
+		},
+		{
+			name:    "Invalid default",
+			input:   "type t object {\n\ta: optional(number, \"a\")\n}",
+			summary: "Invalid default",
+			detail:  `default of attribute "a" must be a number, got string`,
+			line:    2,
+			column:  22,
+		},
+		{
+			name:    "Duplicate object type",
+			input:   "type t enum {\n\ta: 1\n}\n\ntype t object {\n\tb: string\n}",
+			summary: "Duplicate type",
+			detail:  `type "t" is already defined`,
+			line:    5,
+			column:  1,
+		},
+		{
+			name:    "Duplicate type",
+			input:   "type t enum {\n\ta: 1\n}\n\ntype t enum {\n\tb: 1\n}",
+			summary: "Duplicate type",
+			detail:  `type "t" is already defined`,
+			line:    5,
+			column:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := (&EvalContext{}).DefineTypes(ast)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.summary, diags[0].Summary, diags.Error())
+			assert.Equal(t, tt.detail, diags[0].Detail)
+			assert.Equal(t, tt.line, diags[0].Pos.Line, diags.Error())
+			assert.Equal(t, tt.column, diags[0].Pos.Column, diags.Error())
+		})
+	}
+}
+
+const testObjectTypes = `
+type server object {
+	host: string
+	port: optional(number, 8080)
+	tags: optional(list(string))
+}
+
+server {
+	host = "localhost"
+	tags = ["a"]
+}
+
+server {
+	hots = "localhost"
+	port = "80"
+}
+
+listener {
+	port = 80
+	server {
+		host = "a"
+	}
+	server {
+		host = "b"
+	}
+}
+`
+
+func testObjectContext(t *testing.T) (*EvalContext, []*Block) {
+	t.Helper()
+
+	ast, err := ParseString(testObjectTypes)
+	require.NoError(t, err)
+
+	ctx := testEvalContext()
+	diags := ctx.DefineTypes(ast)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	ctx.BlockTypes = map[string]string{"server": "server"}
+
+	var blocks []*Block
+
+	for _, item := range ast.Items {
+		if item.Block != nil {
+			blocks = append(blocks, item.Block)
+		}
+	}
+
+	return ctx, blocks
+}
+
+func TestEvalContext_EvalBlock(t *testing.T) {
+	t.Parallel()
+
+	ctx, blocks := testObjectContext(t)
+
+	res, diags := ctx.EvalBlock(blocks[0])
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	want := value.Map(map[string]value.Value{
+		"host": value.String("localhost"),
+		"port": value.Int(8080),
+		"tags": value.List(value.String("a")),
+	})
+	assert.True(t, want.Equals(res), "want %s, got %s", want.GoString(), res.GoString())
+
+	res, diags = ctx.EvalBlock(blocks[2])
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	want = value.Map(map[string]value.Value{
+		"port": value.Int(80),
+		"server": value.List(
+			value.Map(map[string]value.Value{"host": value.String("a"), "port": value.Int(8080), "tags": value.Null}),
+			value.Map(map[string]value.Value{"host": value.String("b"), "port": value.Int(8080), "tags": value.Null}),
+		),
+	})
+	assert.True(t, want.Equals(res), "want %s, got %s", want.GoString(), res.GoString())
+}
+
+func TestEvalContext_EvalBlockErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, blocks := testObjectContext(t)
+
+	res, diags := ctx.EvalBlock(blocks[1])
+	assert.True(t, res.IsNull())
+	require.Len(t, diags, 3, diags.Error())
+
+	assert.Equal(t, "Unsupported attribute", diags[0].Summary)
+	assert.Equal(t, `"hots" is not an attribute of type "server"; did you mean "host"?`, diags[0].Detail)
+	assert.Equal(t, 14, diags[0].Pos.Line)
+
+	assert.Equal(t, "Missing attribute", diags[1].Summary)
+	assert.Equal(t, `block "server" requires attribute "host"`, diags[1].Detail)
+	assert.Equal(t, 13, diags[1].Pos.Line)
+
+	assert.Equal(t, "Invalid value", diags[2].Summary)
+	assert.Equal(t, `attribute "port" must be a number, got string`, diags[2].Detail)
+	assert.Equal(t, 15, diags[2].Pos.Line)
+}
+
+func TestEval_ObjectTypeDeclarations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    value.Value
+		summary string
+		detail  string
+	}{
+		{
+			name:  "Defaults",
+			input: `val s: server = { host = "a" }`,
+			want:  value.Map(map[string]value.Value{"host": value.String("a"), "port": value.Int(8080), "tags": value.Null}),
+		},
+		{
+			name:  "List element defaults",
+			input: `val s: list(server) = [{ host = "a" }]`,
+			want:  value.List(value.Map(map[string]value.Value{"host": value.String("a"), "port": value.Int(8080), "tags": value.Null})),
+		},
+		{
+			name:  "Optional",
+			input: `val s: optional(server) = null`,
+			want:  value.Null,
+		},
+		{name: "Not a map", input: `val s: server = "a"`, summary: "Invalid value", detail: `val "s" must be a server, got string`},
+		{name: "Null", input: `val s: server = null`, summary: "Invalid value", detail: `val "s" must be a server, got null`},
+		{
+			name:    "List element attribute",
+			input:   `val s: list(server) = [{ hots = "a" }]`,
+			summary: "Unsupported attribute",
+			detail:  `"hots" is not an attribute of type "server"; did you mean "host"?`,
+		},
+		{
+			name:    "Set element",
+			input:   `val s: set(server) = toset(["a"])`,
+			summary: "Invalid value",
+			detail:  `element 0 of val "s" must be a server, got string`,
+		},
+		{
+			name:    "Map element",
+			input:   `val s: map(server) = { k = {} }`,
+			summary: "Missing attribute",
+			detail:  `element "k" of val "s" requires attribute "host"`,
+		},
+		{name: "Missing attribute", input: `val s: server = { port = 1 }`, summary: "Missing attribute", detail: `val "s" requires attribute "host"`},
+		{
+			name:    "Unknown attribute",
+			input:   `val s: server = { host = "a", size = 1 }`,
+			summary: "Unsupported attribute",
+			detail:  `"size" is not an attribute of type "server"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, _ := testObjectContext(t)
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := ctx.DefineValues(ast)
+			if tt.summary != "" {
+				require.True(t, diags.HasErrors())
+				assert.Equal(t, tt.summary, diags[0].Summary, diags.Error())
+				assert.Equal(t, tt.detail, diags[0].Detail)
+
+				return
+			}
+
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(ctx.Variables["s"]), "want %s, got %s", tt.want.GoString(), ctx.Variables["s"].GoString())
+		})
+	}
+}
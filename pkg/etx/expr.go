@@ -8,7 +8,8 @@ import (
 type Expr struct {
 	ASTNode
 
-	Left   *ExprConditional `parser:"(   @@  " json:"left,omitempty"`
+	Error  *ExprError       `parser:"(   @@  " json:"error,omitempty"`
+	Left   *ExprConditional `parser:"  | @@  " json:"left,omitempty"`
 	If     *ExprIf          `parser:"  | @@  " json:"if,omitempty"`
 	Switch *ExprSwitch      `parser:"  | @@ )" json:"switch,omitempty"`
 }
@@ -20,6 +21,7 @@ func (e *Expr) Clone() *Expr {
 
 	return &Expr{
 		ASTNode: e.ASTNode.Clone(),
+		Error:   e.Error.Clone(),
 		Left:    e.Left.Clone(),
 		If:      e.If.Clone(),
 		Switch:  e.Switch.Clone(),
@@ -28,6 +30,8 @@ func (e *Expr) Clone() *Expr {
 
 func (e *Expr) Children() (children []Node) {
 	switch {
+	case e.Error != nil:
+		children = append(children, e.Error)
 	case e.Left != nil:
 		children = append(children, e.Left)
 	case e.If != nil:
@@ -42,6 +46,8 @@ func (e *Expr) Children() (children []Node) {
 
 func (e Expr) FormattedString() string {
 	switch {
+	case e.Error != nil:
+		return e.Error.FormattedString()
 	case e.Left != nil:
 		return e.Left.FormattedString()
 	case e.If != nil:
@@ -204,6 +210,37 @@ func (e ExprCase) FormattedString() string {
 
 // /////////////////////////////////////
 
+// ExprError aborts the evaluation with a message, such as in the case of a
+// switch that handles an invalid value.
+type ExprError struct {
+	ASTNode
+
+	Message ExprLogicalOr `parser:"'error' @@" json:"message"`
+}
+
+func (e *ExprError) Clone() *ExprError {
+	if e == nil {
+		return nil
+	}
+
+	return &ExprError{
+		ASTNode: e.ASTNode.Clone(),
+		Message: *e.Message.Clone(),
+	}
+}
+
+func (e *ExprError) Children() (children []Node) {
+	children = append(children, &e.Message)
+
+	return
+}
+
+func (e ExprError) FormattedString() string {
+	return fmt.Sprintf("error %s", e.Message.FormattedString())
+}
+
+// /////////////////////////////////////
+
 // ExprConditional is a ternary expression.
 //
 // Ternaries are bad but necessary for Terraform compatibility, so they are
@@ -797,7 +834,8 @@ func (e ExprPostfix) FormattedString() string {
 type ExprPrimary struct {
 	ASTNode
 
-	SubExpression *Expr                   `parser:"(   ( '(' @@ ')' )           " json:"sub_expression,omitempty"`
+	Lambda        *Lambda                 `parser:"(   @@                       " json:"lambda,omitempty"`
+	SubExpression *Expr                   `parser:"  | ( '(' @@ ')' )           " json:"sub_expression,omitempty"`
 	Value         *Value                  `parser:"  | @@                       " json:"value,omitempty"`
 	Ident         *Ident                  `parser:"  | ( @@                     " json:"ident"`
 	Monads        []*ExprInvocationParams `parser:"      [ ( '(' @@ ')' )+ ]    " json:"monads,omitempty"`
@@ -811,6 +849,7 @@ func (e *ExprPrimary) Clone() *ExprPrimary {
 
 	return &ExprPrimary{
 		ASTNode:       e.ASTNode.Clone(),
+		Lambda:        e.Lambda.Clone(),
 		SubExpression: e.SubExpression.Clone(),
 		Ident:         e.Ident.Clone(),
 		Monads:        cloneCollection(e.Monads),
@@ -820,6 +859,10 @@ func (e *ExprPrimary) Clone() *ExprPrimary {
 }
 
 func (e *ExprPrimary) Children() (children []Node) {
+	if e.Lambda != nil {
+		children = append(children, e.Lambda)
+	}
+
 	if e.SubExpression != nil {
 		children = append(children, e.SubExpression)
 	}
@@ -844,6 +887,10 @@ func (e *ExprPrimary) Children() (children []Node) {
 }
 
 func (e ExprPrimary) FormattedString() string {
+	if e.Lambda != nil {
+		return e.Lambda.FormattedString()
+	}
+
 	if e.SubExpression != nil {
 		return e.SubExpression.FormattedString()
 	}
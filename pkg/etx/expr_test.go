@@ -483,6 +483,48 @@ switch foo {
 	}
 }
 
+func TestExpr_Parsing_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		want    *Expr
+	}{
+		{
+			name:    "Error",
+			input:   `error msg`,
+			wantErr: false,
+			want: &Expr{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Error: &ExprError{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Message: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 6, Line: 1, Column: 7}},
+						Parts:   []string{"msg"},
+					}),
+				},
+			},
+		},
+		{
+			name:    "Error as a name",
+			input:   `error`,
+			wantErr: false,
+			want: BuildTestExprTree[*Expr](t, &Ident{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Parts:   []string{"error"},
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParser(t, tt.input, tt.want, tt.wantErr, true)
+		})
+	}
+}
+
 func TestExpr_Parsing_Ternary(t *testing.T) {
 	t.Parallel()
 
@@ -2300,6 +2342,78 @@ func TestExpr_Parsing_Primary(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name:    "Invocation - Lambda parameter",
+			input:   `a.filter((k, v) => v)`,
+			wantErr: false,
+			want: BuildTestExprTree[*Expr](t, &ExprPrimary{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Ident: &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Parts:   []string{"a", "filter"},
+				},
+				Monads: []*ExprInvocationParams{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 9, Line: 1, Column: 10}},
+						Values: []*Expr{
+							BuildTestExprTree[*Expr](t, &Lambda{
+								ASTNode: ASTNode{Pos: Position{Offset: 9, Line: 1, Column: 10}},
+								Parameters: []*LambdaParameter{
+									{
+										ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}},
+										Label:   "k",
+									},
+									{
+										ASTNode: ASTNode{Pos: Position{Offset: 13, Line: 1, Column: 14}},
+										Label:   "v",
+									},
+								},
+								Expr: *BuildTestExprTree[*Expr](t, &Ident{
+									ASTNode: ASTNode{Pos: Position{Offset: 19, Line: 1, Column: 20}},
+									Parts:   []string{"v"},
+								}),
+							}),
+						},
+					},
+				},
+			}),
+		},
+		{
+			name:    "Invocation - Lambda after an argument",
+			input:   `apply(foo, v => v)`,
+			wantErr: false,
+			want: BuildTestExprTree[*Expr](t, &ExprPrimary{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Ident: &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Parts:   []string{"apply"},
+				},
+				Monads: []*ExprInvocationParams{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 6, Line: 1, Column: 7}},
+						Values: []*Expr{
+							BuildTestExprTree[*Expr](t, &Ident{
+								ASTNode: ASTNode{Pos: Position{Offset: 6, Line: 1, Column: 7}},
+								Parts:   []string{"foo"},
+							}),
+							BuildTestExprTree[*Expr](t, &Lambda{
+								ASTNode: ASTNode{Pos: Position{Offset: 11, Line: 1, Column: 12}},
+								Parameters: []*LambdaParameter{
+									{
+										ASTNode: ASTNode{Pos: Position{Offset: 11, Line: 1, Column: 12}},
+										Label:   "v",
+									},
+								},
+								Expr: *BuildTestExprTree[*Expr](t, &Ident{
+									ASTNode: ASTNode{Pos: Position{Offset: 16, Line: 1, Column: 17}},
+									Parts:   []string{"v"},
+								}),
+							}),
+						},
+					},
+				},
+			}),
+		},
 		{
 			name:    "Invocation - Monadic invocation",
 			input:   `foo(bar)(baz)`,
@@ -2459,6 +2573,19 @@ func TestExpr_Clone(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Error",
+			input: &Expr{
+				Error: &ExprError{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				},
+			},
+			want: &Expr{
+				Error: &ExprError{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2672,6 +2799,55 @@ func TestCase_Clone(t *testing.T) {
 	}
 }
 
+func TestError_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *ExprError
+		want  *ExprError
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Empty",
+			input: &ExprError{},
+			want:  &ExprError{},
+		},
+		{
+			name: "ASTNode",
+			input: &ExprError{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+			},
+			want: &ExprError{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+			},
+		},
+		{
+			name: "Message",
+			input: &ExprError{
+				Message: ExprLogicalOr{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				},
+			},
+			want: &ExprError{
+				Message: ExprLogicalOr{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCloner[*ExprError](t, tt.want, tt.input.Clone())
+		})
+	}
+}
+
 func TestConditional_Clone(t *testing.T) {
 	t.Parallel()
 
@@ -3815,6 +3991,15 @@ func TestExpr_Children(t *testing.T) {
 				&ExprSwitch{},
 			},
 		},
+		{
+			name: "Error",
+			input: &Expr{
+				Error: &ExprError{},
+			},
+			want: []Node{
+				&ExprError{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -3973,6 +4158,30 @@ func TestCase_Children(t *testing.T) {
 	}
 }
 
+func TestError_Children(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *ExprError
+		want  []Node
+	}{
+		{
+			name:  "Message",
+			input: &ExprError{},
+			want: []Node{
+				&ExprLogicalOr{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.Children())
+		})
+	}
+}
+
 func TestConditional_Children(t *testing.T) {
 	t.Parallel()
 
@@ -4814,6 +5023,16 @@ func TestExpr_FormattedString(t *testing.T) {
 			},
 			want: `switch foo { }`,
 		},
+		{
+			name:        "Error",
+			description: "",
+			input: &Expr{
+				Error: &ExprError{
+					Message: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"msg"}}),
+				},
+			},
+			want: `error msg`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -5224,6 +5443,37 @@ default: {
 	}
 }
 
+func TestError_FormattedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		description string
+		input       *ExprError
+		wantPanic   bool
+		want        string
+	}{
+		{
+			name:      "Nil",
+			input:     nil,
+			wantPanic: true,
+		},
+		{
+			name: "Message",
+			input: &ExprError{
+				Message: *BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"msg"}}),
+			},
+			want: `error msg`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testStringer(t, tt.wantPanic, tt.want, tt.input)
+		})
+	}
+}
+
 func TestConditional_FormattedString(t *testing.T) {
 	t.Parallel()
 
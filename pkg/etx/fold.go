@@ -0,0 +1,908 @@
+package etx
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// Fold reduces the constant sub-expressions of an AST to literals, in place,
+// and reports the const declarations whose value does not fold to a literal.
+//
+// An expression is constant when it only involves literals, consts, enum
+// members and the operators applied to them. Conditionals, if and switch
+// expressions whose condition is constant are reduced to the selected
+// branch, and logical operators to their result when their left operand
+// short-circuits them. Function calls are never folded, as their result may
+// depend on the environment, and neither are the operations that fail, so
+// that their error is reported at runtime. The sub-expressions of lambdas and
+// for-expressions are folded, but not the lambdas and for-expressions
+// themselves.
+func Fold(ast *AST) Diagnostics {
+	var diags Diagnostics
+
+	res, _ := Resolve(ast)
+
+	f := &folder{
+		res:    res,
+		values: make(map[Node]*constant),
+	}
+
+	for _, item := range ast.Items {
+		switch {
+		case item.Decl != nil:
+			diags = append(diags, f.foldDecl(item.Decl)...)
+		case item.Func != nil:
+			diags = append(diags, f.foldFunc(item.Func)...)
+		default:
+			f.foldNode(item)
+		}
+	}
+
+	ast.UpdateParentRefs()
+
+	diags.Sort()
+
+	return diags
+}
+
+// constant is the value of a constant expression. Literal constants are
+// written as literals in the AST, and the others are replaced by a literal
+// when possible.
+type constant struct {
+	val     value.Value
+	literal bool
+}
+
+type folder struct {
+	res *Resolution
+
+	// values are the constant values of the declarations, by declaring
+	// node. A nil value is either not constant or being folded.
+	values map[Node]*constant
+}
+
+// foldNode folds the expressions of a node and its children.
+func (f *folder) foldNode(node Node) {
+	if e, ok := node.(*Expr); ok {
+		e.fold(f)
+
+		return
+	}
+
+	for _, child := range node.Children() {
+		f.foldNode(child)
+	}
+}
+
+func (f *folder) foldDecl(n *Decl) Diagnostics {
+	if len(n.Group) != 0 {
+		return f.foldDeclGroup(n.DeclType, n.Group)
+	}
+
+	if n.Type != nil {
+		f.foldNode(n.Type)
+	}
+
+	return f.foldValue(n, n.DeclType, fmt.Sprintf("%q", n.Label), n.Value)
+}
+
+func (f *folder) foldDeclGroup(declType string, group []*DeclGroupItem) Diagnostics {
+	var diags Diagnostics
+
+	for _, item := range group {
+		if item.Type != nil {
+			f.foldNode(item.Type)
+		}
+
+		if item.Label != "" {
+			diags = append(diags, f.foldValue(item, declType, fmt.Sprintf("%q", item.Label), item.Value)...)
+		}
+	}
+
+	return diags
+}
+
+func (f *folder) foldFunc(n *Func) Diagnostics {
+	var diags Diagnostics
+
+	for _, stmt := range n.Body {
+		if stmt.Decl == nil {
+			f.foldNode(stmt)
+
+			continue
+		}
+
+		if len(stmt.Decl.Group) != 0 {
+			diags = append(diags, f.foldDeclGroup(stmt.Decl.DeclType, stmt.Decl.Group)...)
+
+			continue
+		}
+
+		for _, child := range stmt.Decl.Children() {
+			if child != stmt.Decl.Value {
+				f.foldNode(child)
+			}
+		}
+
+		diags = append(diags, f.foldValue(stmt.Decl, stmt.Decl.DeclType, stmt.Decl.names(), stmt.Decl.Value)...)
+	}
+
+	return diags
+}
+
+// foldValue folds the value of a declaration. The value of a const must
+// fold to a literal.
+func (f *folder) foldValue(node Node, declType, names string, expr *Expr) Diagnostics {
+	if expr == nil {
+		return nil
+	}
+
+	if c := f.declValue(node, expr); declType != "const" || (c != nil && c.literal) {
+		return nil
+	}
+
+	return Diagnostics{errorDiag(expr.Pos, "Invalid constant",
+		fmt.Sprintf("const %s must fold to a literal value; use a val for values computed at runtime", names))}
+}
+
+// declValue folds the value of a declaration once, and returns its constant
+// value. The declarations referencing themselves are not constant.
+func (f *folder) declValue(node Node, expr *Expr) *constant {
+	if c, ok := f.values[node]; ok {
+		return c
+	}
+
+	f.values[node] = nil
+
+	c := expr.fold(f)
+	f.values[node] = c
+
+	return c
+}
+
+// symbolValue returns the value of a const or an enum member, if it is
+// constant.
+func (f *folder) symbolValue(sym *Symbol, i *Ident) *constant {
+	var (
+		c   *constant
+		typ *ParameterType
+	)
+
+	if sym.Kind == SymbolType {
+		return f.enumMemberValue(sym, i)
+	}
+
+	if sym.Kind != SymbolValue || sym.DeclType != "const" {
+		return nil
+	}
+
+	// References to sensitive consts are kept, so that the evaluation marks
+	// their values.
+	switch n := sym.Node.(type) {
+	case *Decl:
+		if n.Sensitive {
+			return nil
+		}
+
+		c, typ = f.declValue(n, n.Value), n.Type
+	case *DeclGroupItem:
+		if decl, ok := n.Parent.(*Decl); ok && decl.Sensitive {
+			return nil
+		}
+
+		c, typ = f.declValue(n, n.Value), n.Type
+	case *FuncDecl:
+		c, typ = f.declValue(n, n.Value), n.Type
+	case *FuncParameter:
+		decl, ok := n.Parent.(*FuncDecl)
+		if !ok {
+			return nil
+		}
+
+		c, typ = f.declValue(decl, decl.Value), n.Type
+		if c == nil || c.val.Kind() != value.KindList || c.val.Len() != len(decl.Targets) {
+			return nil
+		}
+
+		for j, target := range decl.Targets {
+			if target == n {
+				c = &constant{val: c.val.Index(j)}
+			}
+		}
+	}
+
+	if c == nil {
+		return nil
+	}
+
+	// Values of named types are completed at runtime with the defaults of
+	// their type.
+	if typ != nil {
+		if _, named := f.res.Binding(typ.Ident); named || !typ.valueType().Conforms(c.val) {
+			return nil
+		}
+	}
+
+	return &constant{val: c.val}
+}
+
+// enumMemberValue returns the value of the enum member referenced as
+// enum.name.member.
+func (f *folder) enumMemberValue(sym *Symbol, i *Ident) *constant {
+	n, ok := sym.Node.(*Type)
+	if !ok || n.Enum == nil || len(i.Parts) < 3 || i.Parts[0] != enumNamespace { //nolint:gomnd // enum.name.member
+		return nil
+	}
+
+	for _, item := range n.Enum.Items {
+		if item.Label != i.Parts[2] {
+			continue
+		}
+
+		if c := f.declValue(item, &item.Value); c != nil {
+			return &constant{val: c.val}
+		}
+	}
+
+	return nil
+}
+
+// /////////////////////////////////////
+
+// fold folds the expression, and replaces it with a literal when it is
+// constant or with the selected branch when its condition is constant.
+func (e *Expr) fold(f *folder) *constant {
+	var (
+		c        *constant
+		branch   *Expr
+		selected bool
+	)
+
+	switch {
+	case e.Error != nil:
+		// The message is folded, but an error never is a constant.
+		e.Error.Message.fold(f)
+	case e.Left != nil:
+		c, branch, selected = e.Left.fold(f)
+	case e.If != nil:
+		branch, selected = e.If.fold(f)
+	case e.Switch != nil:
+		branch, selected = e.Switch.fold(f)
+	default:
+		panic("expression not set")
+	}
+
+	if selected {
+		if branch != nil {
+			*e = *branch
+
+			return e.fold(f)
+		}
+
+		c = &constant{val: value.Null}
+	}
+
+	if c == nil || c.literal {
+		return c
+	}
+
+	lit, ok := literalValue(e.Pos, c.val)
+	if !ok {
+		return c
+	}
+
+	e.collapse(lit)
+
+	return &constant{val: c.val, literal: true}
+}
+
+func (e *Expr) collapse(lit *Value) {
+	*e = Expr{
+		ASTNode: e.ASTNode,
+		Left:    &ExprConditional{ASTNode: e.ASTNode},
+	}
+
+	e.Left.Condition.collapse(lit)
+}
+
+// fold folds the condition and the branches of the conditional. When the
+// condition is a constant bool, it also returns the selected branch, which
+// is nil if it is missing.
+func (e *ExprConditional) fold(f *folder) (*constant, *Expr, bool) {
+	cond := e.Condition.fold(f)
+	if !e.ConditionOp {
+		return cond, nil, false
+	}
+
+	for _, branch := range []*Expr{e.TrueExpr, e.FalseExpr} {
+		if branch != nil {
+			branch.fold(f)
+		}
+	}
+
+	if cond == nil || cond.val.Kind() != value.KindBool {
+		return nil, nil, false
+	}
+
+	if cond.val.AsBool() {
+		return nil, e.TrueExpr, true
+	}
+
+	return nil, e.FalseExpr, true
+}
+
+// fold folds the condition and the branches of the if expression, and
+// returns the selected branch when the condition is a constant bool.
+func (e *ExprIf) fold(f *folder) (*Expr, bool) {
+	cond := e.Condition.fold(f)
+
+	for _, branch := range []*Expr{e.Left, e.Right} {
+		if branch != nil {
+			branch.fold(f)
+		}
+	}
+
+	if cond == nil || cond.val.Kind() != value.KindBool {
+		return nil, false
+	}
+
+	if cond.val.AsBool() {
+		return e.Left, true
+	}
+
+	return e.Right, true
+}
+
+// fold folds the selector and the cases of the switch, and returns the body
+// of the selected case when the selector and the conditions up to the
+// matching one are constant.
+func (e *ExprSwitch) fold(f *folder) (*Expr, bool) {
+	selector := e.Selector.fold(f)
+
+	conds := make([][]*constant, 0, len(e.Cases))
+
+	for _, c := range e.Cases {
+		values := make([]*constant, 0, len(c.Conditions))
+		for _, cond := range c.Conditions {
+			values = append(values, cond.fold(f))
+		}
+
+		conds = append(conds, values)
+
+		c.Expr.fold(f)
+	}
+
+	if selector == nil {
+		return nil, false
+	}
+
+	var fallback *ExprCase
+
+	for i, c := range e.Cases {
+		if c.Default {
+			fallback = c
+
+			continue
+		}
+
+		for _, cond := range conds[i] {
+			if cond == nil {
+				return nil, false
+			}
+
+			if selector.val.Equals(cond.val) {
+				return c.Expr, true
+			}
+		}
+	}
+
+	if fallback == nil {
+		return nil, false
+	}
+
+	return fallback.Expr, true
+}
+
+// /////////////////////////////////////
+
+func (e *ExprLogicalOr) relink(op string, next *ExprLogicalOr)   { e.Op, e.Right = op, next }
+func (e *ExprLogicalAnd) relink(op string, next *ExprLogicalAnd) { e.Op, e.Right = op, next }
+func (e *ExprBitwiseOr) relink(op string, next *ExprBitwiseOr)   { e.Op, e.Right = op, next }
+func (e *ExprBitwiseXor) relink(op string, next *ExprBitwiseXor) { e.Op, e.Right = op, next }
+func (e *ExprBitwiseAnd) relink(op string, next *ExprBitwiseAnd) { e.Op, e.Right = op, next }
+func (e *ExprEquality) relink(op string, next *ExprEquality)     { e.Op, e.Right = op, next }
+func (e *ExprRelational) relink(op string, next *ExprRelational) { e.Op, e.Right = op, next }
+func (e *ExprShift) relink(op string, next *ExprShift)           { e.Op, e.Right = op, next }
+func (e *ExprAdditive) relink(op string, next *ExprAdditive)     { e.Op, e.Right = op, next }
+
+func (e *ExprMultiplicative) relink(op string, next *ExprMultiplicative) { e.Op, e.Right = op, next }
+
+func (e *ExprLogicalOr) fold(f *folder) *constant      { return foldBinary(f, e) }
+func (e *ExprLogicalAnd) fold(f *folder) *constant     { return foldBinary(f, e) }
+func (e *ExprBitwiseOr) fold(f *folder) *constant      { return foldBinary(f, e) }
+func (e *ExprBitwiseXor) fold(f *folder) *constant     { return foldBinary(f, e) }
+func (e *ExprBitwiseAnd) fold(f *folder) *constant     { return foldBinary(f, e) }
+func (e *ExprEquality) fold(f *folder) *constant       { return foldBinary(f, e) }
+func (e *ExprRelational) fold(f *folder) *constant     { return foldBinary(f, e) }
+func (e *ExprShift) fold(f *folder) *constant          { return foldBinary(f, e) }
+func (e *ExprAdditive) fold(f *folder) *constant       { return foldBinary(f, e) }
+func (e *ExprMultiplicative) fold(f *folder) *constant { return foldBinary(f, e) }
+
+func (e *ExprLogicalOr) collapse(lit *Value)      { collapseBinary[*ExprLogicalOr](e, lit) }
+func (e *ExprLogicalAnd) collapse(lit *Value)     { collapseBinary[*ExprLogicalAnd](e, lit) }
+func (e *ExprBitwiseOr) collapse(lit *Value)      { collapseBinary[*ExprBitwiseOr](e, lit) }
+func (e *ExprBitwiseXor) collapse(lit *Value)     { collapseBinary[*ExprBitwiseXor](e, lit) }
+func (e *ExprBitwiseAnd) collapse(lit *Value)     { collapseBinary[*ExprBitwiseAnd](e, lit) }
+func (e *ExprEquality) collapse(lit *Value)       { collapseBinary[*ExprEquality](e, lit) }
+func (e *ExprRelational) collapse(lit *Value)     { collapseBinary[*ExprRelational](e, lit) }
+func (e *ExprShift) collapse(lit *Value)          { collapseBinary[*ExprShift](e, lit) }
+func (e *ExprAdditive) collapse(lit *Value)       { collapseBinary[*ExprAdditive](e, lit) }
+func (e *ExprMultiplicative) collapse(lit *Value) { collapseBinary[*ExprMultiplicative](e, lit) }
+
+// collapseBinary replaces the chain of links starting at e with the literal.
+func collapseBinary[T binaryLink[T]](e T, lit *Value) {
+	var none T
+
+	e.Node().Pos = lit.Pos
+	e.relink("", none)
+	e.operand().collapse(lit)
+}
+
+// foldBinary folds the operands of a chain of links, and applies the
+// operators like evalBinary as long as the operands are constant. A
+// constant chain is returned as a constant, otherwise its constant prefix
+// and its constant operands are replaced with literals.
+func foldBinary[T binaryLink[T]](f *folder, e T) *constant {
+	operands := []*constant{e.operand().fold(f)}
+	for link := e; link.operator() != ""; link = link.next() {
+		operands = append(operands, link.next().operand().fold(f))
+	}
+
+	if len(operands) == 1 {
+		return operands[0]
+	}
+
+	acc, link, i := operands[0], e, 1
+
+	for ; acc != nil && link.operator() != ""; link, i = link.next(), i+1 {
+		op, rhs := link.operator(), operands[i]
+
+		if op == OpLogicalOr || op == OpLogicalAnd {
+			if acc.val.Kind() != value.KindBool {
+				acc = nil
+
+				break
+			}
+
+			// The rest of the chain is short-circuited.
+			if acc.val.AsBool() == (op == OpLogicalOr) {
+				return &constant{val: acc.val}
+			}
+
+			if rhs == nil || rhs.val.Kind() != value.KindBool {
+				break
+			}
+
+			acc = &constant{val: rhs.val}
+
+			continue
+		}
+
+		if rhs == nil {
+			break
+		}
+
+		res, err := binaryOperators[op](acc.val, rhs.val)
+		if err != nil {
+			break
+		}
+
+		acc = &constant{val: res}
+	}
+
+	if acc != nil && link.operator() == "" {
+		return acc
+	}
+
+	j := 0
+	for l := e; ; l = l.next() {
+		if c := operands[j]; c != nil && !c.literal {
+			if lit, ok := literalValue(l.operand().Node().Pos, c.val); ok {
+				l.operand().collapse(lit)
+			}
+		}
+
+		if j++; l.operator() == "" {
+			break
+		}
+	}
+
+	if acc != nil && i > 1 {
+		if lit, ok := literalValue(e.operand().Node().Pos, acc.val); ok {
+			e.operand().collapse(lit)
+			e.relink(link.operator(), link.next())
+		}
+	}
+
+	return nil
+}
+
+func (e *ExprUnary) fold(f *folder) *constant {
+	c := e.Right.fold(f)
+	if e.Op == "" || c == nil {
+		return c
+	}
+
+	res, err := unaryOperator(e.Op, c.val)
+	if err != nil {
+		return nil
+	}
+
+	return &constant{val: res}
+}
+
+func (e *ExprUnary) collapse(lit *Value) {
+	e.Pos, e.Op = lit.Pos, ""
+	e.Right.collapse(lit)
+}
+
+func (e *ExprPostfix) fold(f *folder) *constant {
+	return e.foldSuffix(f, e.Value.fold(f))
+}
+
+// foldOn folds the postfix expression as an attribute access on recv.
+func (e *ExprPostfix) foldOn(f *folder, recv *constant) *constant {
+	return e.foldSuffix(f, e.Value.foldOn(f, recv))
+}
+
+func (e *ExprPostfix) foldSuffix(f *folder, c *constant) *constant {
+	if e.Index != nil {
+		key := e.Index.fold(f)
+		if c == nil || key == nil {
+			c = nil
+		} else if v, err := index(c.val, key.val); err == nil {
+			c = &constant{val: v}
+		} else {
+			c = nil
+		}
+	}
+
+	if e.Post != nil {
+		return e.Post.foldOn(f, c)
+	}
+
+	return c
+}
+
+func (e *ExprPostfix) collapse(lit *Value) {
+	e.Pos, e.Index, e.Post = lit.Pos, nil, nil
+	e.Value.collapse(lit)
+}
+
+func (e *ExprPrimary) fold(f *folder) *constant {
+	switch {
+	case e.Lambda != nil:
+		e.Lambda.Expr.fold(f)
+
+		return nil
+	case e.SubExpression != nil:
+		if c := e.SubExpression.fold(f); c != nil {
+			return &constant{val: c.val}
+		}
+
+		return nil
+	case e.Value != nil:
+		return e.Value.fold(f)
+	case e.Ident != nil:
+		sym, ok := f.res.Binding(e.Ident)
+		if !ok {
+			return e.foldSuffix(f, nil)
+		}
+
+		// The attributes follow the name, or the member for enum.name.member.
+		c, names := f.symbolValue(sym, e.Ident), e.Ident.Parts[1:]
+		if sym.Kind == SymbolType && c != nil {
+			names = e.Ident.Parts[3:]
+		}
+
+		return e.foldSuffix(f, attributes(c, names))
+	default:
+		panic("identifier not set")
+	}
+}
+
+// foldOn folds the primary expression as an attribute access on recv.
+func (e *ExprPrimary) foldOn(f *folder, recv *constant) *constant {
+	if e.Ident == nil {
+		e.fold(f)
+
+		return nil
+	}
+
+	return e.foldSuffix(f, attributes(recv, e.Ident.Parts))
+}
+
+func (e *ExprPrimary) foldSuffix(f *folder, c *constant) *constant {
+	for _, params := range e.Monads {
+		for _, item := range params.Values {
+			item.fold(f)
+		}
+
+		c = nil
+	}
+
+	if e.Post != nil {
+		return e.Post.foldOn(f, c)
+	}
+
+	return c
+}
+
+func (e *ExprPrimary) collapse(lit *Value) {
+	*e = ExprPrimary{ASTNode: ASTNode{Pos: lit.Pos}, Value: lit}
+}
+
+// attributes returns the attribute of a constant map designated by the
+// names. Methods are not constant.
+func attributes(c *constant, names []string) *constant {
+	for _, name := range names {
+		if c == nil {
+			return nil
+		}
+
+		v, err := attribute(c.val, name)
+		if err != nil || v.Kind() == value.KindFunction {
+			return nil
+		}
+
+		c = &constant{val: v}
+	}
+
+	return c
+}
+
+// /////////////////////////////////////
+
+func (v *Value) fold(f *folder) *constant {
+	switch {
+	case v.Null:
+		return &constant{val: value.Null, literal: true}
+	case v.Bool != nil:
+		return &constant{val: value.Bool(v.Bool.Value), literal: true}
+	case v.Number != nil:
+		n, diags := v.Number.eval()
+		if diags.HasErrors() {
+			return nil
+		}
+
+		return &constant{val: n, literal: true}
+	case v.Str != nil:
+		parts := make([]templatePart, 0, len(v.Str.Fragment))
+		for _, fr := range v.Str.Fragment {
+			parts = append(parts, templatePart{expr: fr.Expr, directive: fr.Directive})
+		}
+
+		return foldTemplate(f, parts, func() (value.Value, Diagnostics) { return v.Str.eval(&EvalContext{}) })
+	case v.Heredoc != nil:
+		return foldTemplate(f, heredocParts(v.Heredoc.Body()), func() (value.Value, Diagnostics) {
+			return v.Heredoc.eval(&EvalContext{})
+		})
+	case v.ForList != nil:
+		f.foldNode(v.ForList)
+
+		return nil
+	case v.ForMap != nil:
+		f.foldNode(v.ForMap)
+
+		return nil
+	case v.List != nil:
+		return v.List.fold(f)
+	case v.Map != nil:
+		return v.Map.fold(f)
+	default:
+		panic("value not set")
+	}
+}
+
+// foldTemplate folds the interpolations and directives of a template. A
+// template is constant when they all are, and literal when it has none.
+func foldTemplate(f *folder, parts []templatePart, eval func() (value.Value, Diagnostics)) *constant {
+	folded, literal := true, true
+
+	for _, part := range parts {
+		var exprs []*Expr
+
+		switch d := part.directive; {
+		case part.expr != nil:
+			exprs = append(exprs, part.expr)
+		case d != nil && d.If != nil:
+			exprs = append(exprs, d.If)
+		case d != nil && d.For != nil:
+			exprs = append(exprs, d.For.Collection)
+		}
+
+		for _, expr := range exprs {
+			literal = false
+			folded = expr.fold(f) != nil && folded
+		}
+	}
+
+	if !folded {
+		return nil
+	}
+
+	v, diags := eval()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	return &constant{val: v, literal: literal}
+}
+
+func (v *ValueList) fold(f *folder) *constant {
+	items := make([]value.Value, 0, len(v.Items))
+	ok, literal := true, true
+
+	for _, item := range v.Items {
+		if item.Value == nil {
+			continue
+		}
+
+		c := item.Value.fold(f)
+		if c == nil {
+			ok = false
+
+			continue
+		}
+
+		items = append(items, c.val)
+		literal = literal && c.literal
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return &constant{val: value.List(items...), literal: literal}
+}
+
+func (v *ValueMap) fold(f *folder) *constant {
+	items := make(map[string]value.Value, len(v.Items))
+	ok, literal := true, true
+
+	for _, item := range v.Items {
+		if item.Key == nil {
+			continue
+		}
+
+		var key *constant
+
+		if item.Key.Ident != nil {
+			key = &constant{val: value.String(item.Key.Ident.FormattedString()), literal: true}
+		} else {
+			key = (&Value{Str: item.Key.Str}).fold(f)
+		}
+
+		c := item.Value.fold(f)
+		if key == nil || c == nil {
+			ok = false
+
+			continue
+		}
+
+		if _, exists := items[key.val.AsString()]; exists {
+			ok = false
+		}
+
+		items[key.val.AsString()] = c.val
+		literal = literal && key.literal && c.literal
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return &constant{val: value.Map(items), literal: literal}
+}
+
+// /////////////////////////////////////
+
+// literalValue returns the literal of a null, bool, number or string value,
+// or of a list or a map of such values.
+func literalValue(pos Position, v value.Value) (*Value, bool) {
+	lit := &Value{ASTNode: ASTNode{Pos: pos}}
+
+	switch v.Kind() {
+	case value.KindNull:
+		lit.Null = true
+	case value.KindBool:
+		lit.Bool = &ValueBool{ASTNode: lit.ASTNode, Value: v.AsBool()}
+	case value.KindNumber:
+		f := new(big.Float).Copy(v.AsBigFloat())
+		lit.Number = &ValueNumber{ASTNode: lit.ASTNode, Value: f, Source: f.Text('f', -1)}
+	case value.KindString:
+		lit.Str = stringLiteral(pos, v.AsString())
+	case value.KindList:
+		lit.List = &ValueList{ASTNode: lit.ASTNode}
+
+		for _, item := range v.AsList() {
+			elem, ok := literalValue(pos, item)
+			if !ok {
+				return nil, false
+			}
+
+			lit.List.Items = append(lit.List.Items, &ListItem{ASTNode: lit.ASTNode, Value: literalExpr(elem)})
+		}
+	case value.KindMap:
+		lit.Map = &ValueMap{ASTNode: lit.ASTNode}
+
+		for _, key := range v.Keys() {
+			item, _ := v.Get(key)
+
+			elem, ok := literalValue(pos, item)
+			if !ok {
+				return nil, false
+			}
+
+			lit.Map.Items = append(lit.Map.Items, &MapItem{
+				ASTNode: lit.ASTNode,
+				Key:     &MapKey{ASTNode: lit.ASTNode, Str: stringLiteral(pos, key)},
+				Value:   literalExpr(elem),
+			})
+		}
+	default:
+		return nil, false
+	}
+
+	return lit, true
+}
+
+// literalExpr returns an expression made of a literal only.
+func literalExpr(lit *Value) *Expr {
+	e := &Expr{ASTNode: lit.ASTNode}
+	e.collapse(lit)
+
+	return e
+}
+
+// stringLiteral returns the literal of a string, escaping its quotes,
+// backslashes, control characters and template sequences.
+func stringLiteral(pos Position, s string) *ValueString {
+	lit := &ValueString{ASTNode: ASTNode{Pos: pos}}
+
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() != 0 {
+			lit.Fragment = append(lit.Fragment, &StringFragment{ASTNode: lit.ASTNode, Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			flush()
+			lit.Fragment = append(lit.Fragment, &StringFragment{ASTNode: lit.ASTNode, Escaped: `\` + string(r)})
+		case r == '\n' || r == '\r' || r == '\t':
+			flush()
+			lit.Fragment = append(lit.Fragment, &StringFragment{ASTNode: lit.ASTNode, Escaped: strings.Trim(fmt.Sprintf("%q", r), "'")})
+		case unicode.IsControl(r):
+			flush()
+			lit.Fragment = append(lit.Fragment, &StringFragment{ASTNode: lit.ASTNode, Unicode: fmt.Sprintf("%04x", r)})
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			text.WriteRune(r)
+			text.WriteRune(r)
+		default:
+			text.WriteRune(r)
+		}
+	}
+
+	flush()
+
+	return lit
+}
@@ -0,0 +1,132 @@
+package etx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestFold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Arithmetic", input: `const a = 1 + 2 * 3`, want: `const a = 7`},
+		{name: "Unary", input: `const a = -(2 + 3)`, want: `const a = -5`},
+		{name: "Const reference", input: "const a = 2\nconst b = a * 3", want: "const b = 6"},
+		{name: "String", input: `const a = "a" + "b"`, want: `const a = "ab"`},
+		{name: "Template", input: "const a = 1\nconst b = \"${a + 1}x\"", want: `const b = "2x"`},
+		{name: "List", input: `const a = [1 + 1, "a"]`, want: "const a = [\n\t2,\n\t\"a\",\n]"},
+		{name: "Map attribute", input: `const a = { k = 1 + 1 }.k`, want: `const a = 2`},
+		{name: "Enum member", input: "type c enum {\n\tred: 1\n}\nconst a = enum.c.red + 1", want: "const a = 2"},
+		{name: "Conditional", input: "input x: number\nval a = true ? x : 0", want: "val a = x"},
+		{name: "Missing branch", input: `const a = if (false) { 1 }`, want: `const a = null`},
+		{name: "If", input: "input x: number\nval a = if (1 > 2) { 0 } else { x }", want: "val a = x"},
+		{name: "Switch", input: "const a = switch 2 {\n\tcase 1: { \"one\" }\n\tcase 2: { \"two\" }\n}", want: `const a = "two"`},
+		{name: "Short-circuit", input: "input x: bool\nval a = false && x", want: "val a = false"},
+		{name: "Constant prefix", input: "input x: number\nval a = 1 + 2 + x", want: "val a = 3 + x"},
+		{name: "Constant operand", input: "input x: number\nval a = x * (2 + 3)", want: "val a = x * 5"},
+		{name: "Function call", input: `val a = upper("a" + "b")`, want: `val a = upper("ab")`},
+		{name: "Failing operation", input: `val a = 1 / 0`, want: `val a = 1 / 0`},
+		{name: "Function body", input: "def f(x) {\n\tconst y = 1 + 1\n\tx * y\n}", want: "def f(x) {\n\tconst y = 2\n\tx * 2\n}"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := Fold(ast)
+			require.Empty(t, diags, diags.Error())
+
+			last := ast.Items[len(ast.Items)-1]
+			assert.Equal(t, tt.want, strings.TrimSpace(last.FormattedString()))
+		})
+	}
+}
+
+func TestFold_Diagnostics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "Input reference",
+			input: "input x: number\nconst a = x + 1",
+			want:  []string{`2:11: error: Invalid constant; const "a" must fold to a literal value; use a val for values computed at runtime`},
+		},
+		{
+			name:  "Function call",
+			input: `const a = upper("a")`,
+			want:  []string{`1:11: error: Invalid constant; const "a" must fold to a literal value; use a val for values computed at runtime`},
+		},
+		{
+			name:  "Val reference",
+			input: "val a = 1\nconst b = a",
+			want:  []string{`2:11: error: Invalid constant; const "b" must fold to a literal value; use a val for values computed at runtime`},
+		},
+		{
+			name:  "Self reference",
+			input: "const (\n\ta = b\n\tb = a\n)",
+			want: []string{
+				`2:6: error: Invalid constant; const "a" must fold to a literal value; use a val for values computed at runtime`,
+				`3:6: error: Invalid constant; const "b" must fold to a literal value; use a val for values computed at runtime`,
+			},
+		},
+		{
+			name:  "Destructuring in a function body",
+			input: "def f(x) {\n\tconst (a, b) = [x, 1]\n\ta + b\n}",
+			want:  []string{`2:17: error: Invalid constant; const (a, b) must fold to a literal value; use a val for values computed at runtime`},
+		},
+		{
+			name:  "Failing operation",
+			input: `const a = 1 / 0`,
+			want:  []string{`1:11: error: Invalid constant; const "a" must fold to a literal value; use a val for values computed at runtime`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			diags := Fold(ast)
+
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.Error())
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStringLiteral(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "abc", `a"b\c`, "a\nb\tc\r", "\x01", "${a} %{b} $ %"} {
+		got, diags := stringLiteral(Position{}, s).eval(&EvalContext{})
+		require.Empty(t, diags, diags.Error())
+
+		assert.Equal(t, value.String(s), got)
+	}
+}
@@ -0,0 +1,224 @@
+package etx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForClause is the `for k, v in collection :` clause of a for-expression.
+// The key is the index of the elements of a list or set, or the key of the
+// elements of a map.
+type ForClause struct {
+	ASTNode
+
+	Key        string      `parser:"'for' ( @Ident ',' )?" json:"key,omitempty"`
+	Value      string      `parser:"@Ident 'in'"           json:"value"`
+	Collection *ForOperand `parser:"@@ ':' LF*"            json:"collection"`
+}
+
+func (n *ForClause) Clone() *ForClause {
+	if n == nil {
+		return nil
+	}
+
+	return &ForClause{
+		ASTNode:    n.ASTNode.Clone(),
+		Key:        n.Key,
+		Value:      n.Value,
+		Collection: n.Collection.Clone(),
+	}
+}
+
+func (n *ForClause) Children() (children []Node) {
+	if n.Collection != nil {
+		children = append(children, n.Collection)
+	}
+
+	return
+}
+
+func (n ForClause) FormattedString() string {
+	if n.Key != "" {
+		return fmt.Sprintf("for %s, %s in %s", n.Key, n.Value, n.Collection.FormattedString())
+	}
+
+	return fmt.Sprintf("for %s in %s", n.Value, n.Collection.FormattedString())
+}
+
+// /////////////////////////////////////
+
+// ForList is a list for-expression, `[for k, v in xs : value if cond]`. It
+// holds the value for each element of the collection, or only for the
+// elements satisfying the condition.
+type ForList struct {
+	ASTNode
+
+	Clause *ForClause     `parser:"'[' LF* @@"        json:"clause"`
+	Value  *Expr          `parser:"@@ LF*"            json:"value"`
+	Cond   *ExprLogicalOr `parser:"[ If @@ LF* ] ']'" json:"cond,omitempty"`
+}
+
+func (n *ForList) Clone() *ForList {
+	if n == nil {
+		return nil
+	}
+
+	return &ForList{
+		ASTNode: n.ASTNode.Clone(),
+		Clause:  n.Clause.Clone(),
+		Value:   n.Value.Clone(),
+		Cond:    n.Cond.Clone(),
+	}
+}
+
+func (n *ForList) Children() (children []Node) {
+	if n.Clause != nil {
+		children = append(children, n.Clause)
+	}
+
+	if n.Value != nil {
+		children = append(children, n.Value)
+	}
+
+	if n.Cond != nil {
+		children = append(children, n.Cond)
+	}
+
+	return
+}
+
+func (n ForList) FormattedString() string {
+	var sb strings.Builder
+
+	mustFprintf(&sb, "[%s : %s", n.Clause.FormattedString(), n.Value.FormattedString())
+
+	if n.Cond != nil {
+		mustFprintf(&sb, " if %s", n.Cond.FormattedString())
+	}
+
+	sb.WriteString("]")
+
+	return sb.String()
+}
+
+// /////////////////////////////////////
+
+// ForMap is a map for-expression, `{for k, v in xs : key => value if cond}`.
+// It holds an entry for each element of the collection, or only for the
+// elements satisfying the condition. With the `...` grouping marker after
+// the value, the values of each key are collected into a list, whereas a
+// duplicate key is an error otherwise.
+type ForMap struct {
+	ASTNode
+
+	Clause *ForClause     `parser:"'{' LF* @@"        json:"clause"`
+	Key    *ForOperand    `parser:"@@ OpLambda LF*"   json:"key"`
+	Value  *Expr          `parser:"@@"                json:"value"`
+	Group  bool           `parser:"@Ellipsis? LF*"    json:"group,omitempty"`
+	Cond   *ExprLogicalOr `parser:"[ If @@ LF* ] '}'" json:"cond,omitempty"`
+}
+
+func (n *ForMap) Clone() *ForMap {
+	if n == nil {
+		return nil
+	}
+
+	return &ForMap{
+		ASTNode: n.ASTNode.Clone(),
+		Clause:  n.Clause.Clone(),
+		Key:     n.Key.Clone(),
+		Value:   n.Value.Clone(),
+		Group:   n.Group,
+		Cond:    n.Cond.Clone(),
+	}
+}
+
+func (n *ForMap) Children() (children []Node) {
+	if n.Clause != nil {
+		children = append(children, n.Clause)
+	}
+
+	if n.Key != nil {
+		children = append(children, n.Key)
+	}
+
+	if n.Value != nil {
+		children = append(children, n.Value)
+	}
+
+	if n.Cond != nil {
+		children = append(children, n.Cond)
+	}
+
+	return
+}
+
+func (n ForMap) FormattedString() string {
+	var sb strings.Builder
+
+	mustFprintf(&sb, "{%s : %s => %s", n.Clause.FormattedString(), n.Key.FormattedString(), n.Value.FormattedString())
+
+	if n.Group {
+		sb.WriteString(OpEllipsis)
+	}
+
+	if n.Cond != nil {
+		mustFprintf(&sb, " if %s", n.Cond.FormattedString())
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// /////////////////////////////////////
+
+// ForOperand is the collection or the key of a for-expression. A bare
+// identifier or keyword literal is parsed on its own, as the `:` or `=>`
+// following it would otherwise make it the parameters of a lambda.
+type ForOperand struct {
+	ASTNode
+
+	Ident   *Ident `parser:"(   (?! 'null' | 'true' | 'false') @@ (?= ':' | OpLambda)   " json:"ident,omitempty"`
+	Literal *Value `parser:"  | (?= 'null' | 'true' | 'false') @@ (?= ':' | OpLambda)   " json:"literal,omitempty"`
+	Expr    *Expr  `parser:"  | @@                                                      )" json:"expr,omitempty"`
+}
+
+func (n *ForOperand) Clone() *ForOperand {
+	if n == nil {
+		return nil
+	}
+
+	return &ForOperand{
+		ASTNode: n.ASTNode.Clone(),
+		Ident:   n.Ident.Clone(),
+		Literal: n.Literal.Clone(),
+		Expr:    n.Expr.Clone(),
+	}
+}
+
+func (n *ForOperand) Children() (children []Node) {
+	switch {
+	case n.Ident != nil:
+		children = append(children, n.Ident)
+	case n.Literal != nil:
+		children = append(children, n.Literal)
+	case n.Expr != nil:
+		children = append(children, n.Expr)
+	}
+
+	return
+}
+
+func (n ForOperand) FormattedString() string {
+	switch {
+	case n.Ident != nil:
+		return n.Ident.FormattedString()
+	case n.Literal != nil:
+		return n.Literal.FormattedString()
+	case n.Expr != nil:
+		return n.Expr.FormattedString()
+	default:
+		panic("operand is not set")
+	}
+}
@@ -0,0 +1,334 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFor_Parsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		want    *Value
+	}{
+		{
+			name:  "List",
+			input: `[for v in xs : v]`,
+			want: &Value{
+				ForList: &ForList{
+					Clause: &ForClause{
+						Value:      "v",
+						Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}},
+					},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				},
+			},
+		},
+		{
+			name:  "List with key and condition",
+			input: "[\n\tfor i, v in obj.items :\n\tv\n\tif i\n]",
+			want: &Value{
+				ForList: &ForList{
+					Clause: &ForClause{
+						Key:        "i",
+						Value:      "v",
+						Collection: &ForOperand{Ident: &Ident{Parts: []string{"obj", "items"}}},
+					},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+					Cond:  BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"i"}}),
+				},
+			},
+		},
+		{
+			name:  "List over an expression",
+			input: `[for v in [] : v]`,
+			want: &Value{
+				ForList: &ForList{
+					Clause: &ForClause{
+						Value:      "v",
+						Collection: &ForOperand{Expr: BuildTestExprTree[*Expr](t, &Value{List: &ValueList{}})},
+					},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				},
+			},
+		},
+		{
+			name:  "Map",
+			input: `{for k, v in m : k => v}`,
+			want: &Value{
+				ForMap: &ForMap{
+					Clause: &ForClause{
+						Key:        "k",
+						Value:      "v",
+						Collection: &ForOperand{Ident: &Ident{Parts: []string{"m"}}},
+					},
+					Key:   &ForOperand{Ident: &Ident{Parts: []string{"k"}}},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				},
+			},
+		},
+		{
+			name:  "Map with grouping and condition",
+			input: `{for v in m : v => v... if v}`,
+			want: &Value{
+				ForMap: &ForMap{
+					Clause: &ForClause{
+						Value:      "v",
+						Collection: &ForOperand{Ident: &Ident{Parts: []string{"m"}}},
+					},
+					Key:   &ForOperand{Ident: &Ident{Parts: []string{"v"}}},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+					Group: true,
+					Cond:  BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"v"}}),
+				},
+			},
+		},
+		{
+			name:  "Keyword collection",
+			input: `[for x in null : x]`,
+			want: &Value{
+				ForList: &ForList{
+					Clause: &ForClause{
+						Value:      "x",
+						Collection: &ForOperand{Literal: &Value{Null: true}},
+					},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}}),
+				},
+			},
+		},
+		{
+			name:  "Keyword key",
+			input: `{for x in xs : true => x}`,
+			want: &Value{
+				ForMap: &ForMap{
+					Clause: &ForClause{
+						Value:      "x",
+						Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}},
+					},
+					Key:   &ForOperand{Literal: &Value{Bool: &ValueBool{Value: true}}},
+					Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}}),
+				},
+			},
+		},
+		{
+			name:  "List of a for identifier",
+			input: `[for]`,
+			want: &Value{
+				List: &ValueList{
+					Items: []*ListItem{
+						{Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"for"}})},
+					},
+				},
+			},
+		},
+		{
+			name:    "Missing value",
+			input:   `[for v in xs : ]`,
+			wantErr: true,
+		},
+		{
+			name:    "Missing key",
+			input:   `{for v in xs : v}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testParser(t, tt.input, tt.want, tt.wantErr, false)
+		})
+	}
+}
+
+func TestForList_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *ForList
+		want  *ForList
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Empty",
+			input: &ForList{},
+			want:  &ForList{},
+		},
+		{
+			name: "Full",
+			input: &ForList{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+				Clause: &ForClause{
+					Key:        "i",
+					Value:      "v",
+					Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}},
+				},
+				Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				Cond:  BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"i"}}),
+			},
+			want: &ForList{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+				Clause: &ForClause{
+					Key:        "i",
+					Value:      "v",
+					Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}},
+				},
+				Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				Cond:  BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"i"}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCloner[*ForList](t, tt.want, tt.input.Clone())
+		})
+	}
+}
+
+func TestForMap_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *ForMap
+		want  *ForMap
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Empty",
+			input: &ForMap{},
+			want:  &ForMap{},
+		},
+		{
+			name: "Full",
+			input: &ForMap{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+				Clause: &ForClause{
+					Value:      "v",
+					Collection: &ForOperand{Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"xs"}})},
+				},
+				Key:   &ForOperand{Ident: &Ident{Parts: []string{"v"}}},
+				Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				Group: true,
+				Cond:  BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"v"}}),
+			},
+			want: &ForMap{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+				Clause: &ForClause{
+					Value:      "v",
+					Collection: &ForOperand{Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"xs"}})},
+				},
+				Key:   &ForOperand{Ident: &Ident{Parts: []string{"v"}}},
+				Value: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				Group: true,
+				Cond:  BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"v"}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCloner[*ForMap](t, tt.want, tt.input.Clone())
+		})
+	}
+}
+
+func TestForMap_Children(t *testing.T) {
+	t.Parallel()
+
+	clause := &ForClause{Value: "v", Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}}}
+	key := &ForOperand{Ident: &Ident{Parts: []string{"v"}}}
+	val := BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}})
+	cond := BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"v"}})
+
+	tests := []struct {
+		name  string
+		input *ForMap
+		want  []Node
+	}{
+		{
+			name:  "Empty",
+			input: &ForMap{},
+			want:  nil,
+		},
+		{
+			name:  "Full",
+			input: &ForMap{Clause: clause, Key: key, Value: val, Group: true, Cond: cond},
+			want:  []Node{clause, key, val, cond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.Children())
+		})
+	}
+}
+
+func TestFor_FormattedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     FormattedStringer
+		wantPanic bool
+		want      string
+	}{
+		{
+			name: "List",
+			input: &ForList{
+				Clause: &ForClause{Value: "v", Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}}},
+				Value:  BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+			},
+			want: "[for v in xs : v]",
+		},
+		{
+			name: "List with key and condition",
+			input: &ForList{
+				Clause: &ForClause{Key: "i", Value: "v", Collection: &ForOperand{Ident: &Ident{Parts: []string{"xs"}}}},
+				Value:  BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"v"}}),
+				Cond:   BuildTestExprTree[*ExprLogicalOr](t, &Ident{Parts: []string{"i"}}),
+			},
+			want: "[for i, v in xs : v if i]",
+		},
+		{
+			name: "Map with grouping",
+			input: &ForMap{
+				Clause: &ForClause{Key: "k", Value: "v", Collection: &ForOperand{Ident: &Ident{Parts: []string{"m"}}}},
+				Key:    &ForOperand{Ident: &Ident{Parts: []string{"v"}}},
+				Value:  BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"k"}}),
+				Group:  true,
+			},
+			want: "{for k, v in m : v => k...}",
+		},
+		{
+			name:      "Empty operand",
+			input:     &ForOperand{},
+			wantPanic: true,
+			want:      "operand is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testStringer(t, tt.wantPanic, tt.want, tt.input)
+		})
+	}
+}
@@ -10,7 +10,7 @@ type Func struct {
 
 	Label      string           `parser:"'def' @Ident "                     json:"label"`
 	Parameters []*FuncParameter `parser:"'(' [ @@ (',' @@)* ] ')'"         json:"parameters,omitempty"`
-	Return     []*ParameterType `parser:"('(' [ @@ (',' @@)* ] ')' | @@)?" json:"return,omitempty"`
+	Return     []*ParameterType `parser:"(@@ | '(' [ @@ (',' @@)* ] ')')?" json:"return,omitempty"`
 	Body       []*FuncStatement `parser:"[ LF+ ] '{' [ LF+ ] @@ * '}' "    json:"body,omitempty"`
 }
 
@@ -124,10 +124,11 @@ func (n FuncParameter) FormattedString() string {
 type FuncStatement struct {
 	ASTNode
 
-	EmptyLine string    `parser:"(   @LF+    " json:"empty_line,omitempty"`
-	Comment   *Comment  `parser:"  | @@      " json:"comment,omitempty"`
-	Decl      *FuncDecl `parser:"  | @@ LF?  " json:"decl,omitempty"`
-	Expr      *Expr     `parser:"  | @@ LF? )" json:"expr,omitempty"`
+	EmptyLine string      `parser:"(   @LF+    " json:"empty_line,omitempty"`
+	Comment   *Comment    `parser:"  | @@      " json:"comment,omitempty"`
+	Return    *FuncReturn `parser:"  | @@ LF?  " json:"return,omitempty"`
+	Decl      *FuncDecl   `parser:"  | @@ LF?  " json:"decl,omitempty"`
+	Expr      *Expr       `parser:"  | @@ LF? )" json:"expr,omitempty"`
 }
 
 func (n *FuncStatement) Clone() *FuncStatement {
@@ -138,6 +139,7 @@ func (n *FuncStatement) Clone() *FuncStatement {
 	return &FuncStatement{
 		ASTNode:   n.ASTNode.Clone(),
 		Comment:   n.Comment.Clone(),
+		Return:    n.Return.Clone(),
 		Decl:      n.Decl.Clone(),
 		Expr:      n.Expr.Clone(),
 		EmptyLine: n.EmptyLine,
@@ -149,6 +151,10 @@ func (n *FuncStatement) Children() (children []Node) {
 		children = append(children, n.Comment)
 	}
 
+	if n.Return != nil {
+		children = append(children, n.Return)
+	}
+
 	if n.Decl != nil {
 		children = append(children, n.Decl)
 	}
@@ -164,6 +170,8 @@ func (n FuncStatement) FormattedString() string {
 	switch {
 	case n.Comment != nil:
 		return n.Comment.FormattedString()
+	case n.Return != nil:
+		return n.Return.FormattedString()
 	case n.Decl != nil:
 		return n.Decl.FormattedString()
 	case n.Expr != nil:
@@ -177,13 +185,57 @@ func (n FuncStatement) FormattedString() string {
 
 // /////////////////////////////////////
 
+// FuncReturn ends the evaluation of a function body with its values. Several
+// values are returned together as a list.
+type FuncReturn struct {
+	ASTNode
+
+	Values []*Expr `parser:"'return' [ @@ (',' @@)* ]" json:"values,omitempty"`
+}
+
+func (n *FuncReturn) Clone() *FuncReturn {
+	if n == nil {
+		return nil
+	}
+
+	return &FuncReturn{
+		ASTNode: n.ASTNode.Clone(),
+		Values:  cloneCollection(n.Values),
+	}
+}
+
+func (n *FuncReturn) Children() (children []Node) {
+	for _, item := range n.Values {
+		children = append(children, item)
+	}
+
+	return
+}
+
+func (n FuncReturn) FormattedString() string {
+	if len(n.Values) == 0 {
+		return "return"
+	}
+
+	values := make([]string, 0, len(n.Values))
+	for _, item := range n.Values {
+		values = append(values, item.FormattedString())
+	}
+
+	return "return " + strings.Join(values, ", ")
+}
+
+// /////////////////////////////////////
+
 type FuncDecl struct {
 	ASTNode
 
-	DeclType string         `parser:"@('const' | 'val')" json:"decl_type"`
-	Label    string         `parser:"@Ident"         json:"label"`
-	Type     *ParameterType `parser:"[ ':' @@ ]"     json:"type,omitempty"`
-	Value    *Expr          `parser:"[ '=' @@     ]" json:"value,omitempty"`
+	DeclType string           `parser:"@('const' | 'val')"             json:"decl_type"`
+	Label    string           `parser:"(   (   @Ident"                 json:"label"`
+	Type     *ParameterType   `parser:"        [ ':' @@ ]"             json:"type,omitempty"`
+	Targets  []*FuncParameter `parser:"      | '(' @@ (',' @@)* ')' )" json:"targets,omitempty"`
+	Value    *Expr            `parser:"    [ '=' @@ ]"                 json:"value,omitempty"`
+	Group    []*DeclGroupItem `parser:"  | '(' [ LF+ ] @@+ ')' )"      json:"group,omitempty"`
 }
 
 func (n *FuncDecl) Clone() *FuncDecl {
@@ -195,8 +247,10 @@ func (n *FuncDecl) Clone() *FuncDecl {
 		ASTNode:  n.ASTNode.Clone(),
 		DeclType: n.DeclType,
 		Label:    n.Label,
-		Type:     n.Type,
+		Type:     n.Type.Clone(),
+		Targets:  cloneCollection(n.Targets),
 		Value:    n.Value.Clone(),
+		Group:    cloneCollection(n.Group),
 	}
 }
 
@@ -205,22 +259,40 @@ func (n *FuncDecl) Children() (children []Node) {
 		children = append(children, n.Type)
 	}
 
+	for _, item := range n.Targets {
+		children = append(children, item)
+	}
+
 	if n.Value != nil {
 		children = append(children, n.Value)
 	}
 
+	for _, item := range n.Group {
+		children = append(children, item)
+	}
+
 	return
 }
 
 func (n FuncDecl) FormattedString() string {
 	var sb strings.Builder
 
-	if n.Label == "" {
+	switch {
+	case len(n.Group) != 0:
+		return formatDeclGroup(n.DeclType, n.Group)
+	case n.Label != "":
+		mustFprintf(&sb, "%s %s", n.DeclType, n.Label)
+	case len(n.Targets) != 0:
+		targets := make([]string, 0, len(n.Targets))
+		for _, item := range n.Targets {
+			targets = append(targets, item.FormattedString())
+		}
+
+		mustFprintf(&sb, "%s (%s)", n.DeclType, strings.Join(targets, ", "))
+	default:
 		return ""
 	}
 
-	mustFprintf(&sb, "%s %s", n.DeclType, n.Label)
-
 	if n.Type != nil {
 		mustFprintf(&sb, ": %s", n.Type.FormattedString())
 	}
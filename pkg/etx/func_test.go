@@ -258,6 +258,39 @@ func TestFunc_Parsing(t *testing.T) {
 			},
 		},
 
+		{
+			name:    "Empty body, no params, unparenthesized func return",
+			input:   `def foo() (int) -> bool {}`,
+			wantErr: false,
+			want: &Func{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Label:   "foo",
+				Return: []*ParameterType{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}},
+						Func: &FuncSignature{
+							ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}},
+							Parameters: []*ParameterType{
+								{
+									ASTNode: ASTNode{Pos: Position{Offset: 11, Line: 1, Column: 12}},
+									Ident: &Ident{
+										ASTNode: ASTNode{Pos: Position{Offset: 11, Line: 1, Column: 12}},
+										Parts:   []string{"int"},
+									},
+								},
+							},
+							Return: ParameterType{
+								ASTNode: ASTNode{Pos: Position{Offset: 19, Line: 1, Column: 20}},
+								Ident: &Ident{
+									ASTNode: ASTNode{Pos: Position{Offset: 19, Line: 1, Column: 20}},
+									Parts:   []string{"bool"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "One Expr statement, no params, no return",
 			input: `
@@ -820,6 +853,27 @@ func TestFuncStatement_Parsing(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "Return",
+			input:   "return 1",
+			wantErr: false,
+			want: &FuncStatement{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Return: &FuncReturn{
+					ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+					Values: []*Expr{
+						BuildTestExprTree[*Expr](t, &Value{
+							ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+							Number: &ValueNumber{
+								ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+								Value:   big.NewFloat(1),
+								Source:  "1",
+							},
+						}),
+					},
+				},
+			},
+		},
 		{
 			name:    "Expr",
 			input:   "1",
@@ -1043,6 +1097,13 @@ func TestFuncStatement_FormattedString(t *testing.T) {
 			},
 			want: "val foo = 1",
 		},
+		{
+			name: "Return",
+			input: &FuncStatement{
+				Return: &FuncReturn{Values: []*Expr{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}})}},
+			},
+			want: "return 1",
+		},
 		{
 			name: "Expr",
 			input: &FuncStatement{
@@ -1163,6 +1224,76 @@ func TestFuncDecl_Parsing(t *testing.T) {
 				}),
 			},
 		},
+		{
+			name:    "destructuring - value",
+			input:   "val (foo, bar: number) = 1",
+			wantErr: false,
+			want: &FuncDecl{
+				ASTNode:  ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				DeclType: "val",
+				Targets: []*FuncParameter{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+						Label:   "foo",
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 1, Column: 11}},
+						Label:   "bar",
+						Type: &ParameterType{
+							ASTNode: ASTNode{Pos: Position{Offset: 15, Line: 1, Column: 16}},
+							Ident: &Ident{
+								ASTNode: ASTNode{Pos: Position{Offset: 15, Line: 1, Column: 16}},
+								Parts:   []string{"number"},
+							},
+						},
+					},
+				},
+				Value: BuildTestExprTree[*Expr](t, &Value{
+					ASTNode: ASTNode{Pos: Position{Offset: 25, Line: 1, Column: 26}},
+					Number: &ValueNumber{
+						ASTNode: ASTNode{Pos: Position{Offset: 25, Line: 1, Column: 26}},
+						Value:   big.NewFloat(1),
+						Source:  "1",
+					},
+				}),
+			},
+		},
+		{
+			name:    "group",
+			input:   "val (\n\tfoo = bar\n\tbar = 1\n)",
+			wantErr: false,
+			want: &FuncDecl{
+				ASTNode:  ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				DeclType: "val",
+				Group: []*DeclGroupItem{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 2, Column: 2}},
+						Label:   "foo",
+						Value: BuildTestExprTree[*Expr](t, &Ident{
+							ASTNode: ASTNode{Pos: Position{Offset: 13, Line: 2, Column: 8}},
+							Parts:   []string{"bar"},
+						}),
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 18, Line: 3, Column: 2}},
+						Label:   "bar",
+						Value: BuildTestExprTree[*Expr](t, &Value{
+							ASTNode: ASTNode{Pos: Position{Offset: 24, Line: 3, Column: 8}},
+							Number: &ValueNumber{
+								ASTNode: ASTNode{Pos: Position{Offset: 24, Line: 3, Column: 8}},
+								Value:   big.NewFloat(1),
+								Source:  "1",
+							},
+						}),
+					},
+				},
+			},
+		},
+		{
+			name:    "destructuring - empty",
+			input:   "val () = 1",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1226,6 +1357,24 @@ func TestFuncDecl_Clone(t *testing.T) {
 				Type: &ParameterType{Ident: &Ident{Parts: []string{"number"}}},
 			},
 		},
+		{
+			name: "Targets",
+			input: &FuncDecl{
+				Targets: []*FuncParameter{{Label: "foo"}, {Label: "bar"}},
+			},
+			want: &FuncDecl{
+				Targets: []*FuncParameter{{Label: "foo"}, {Label: "bar"}},
+			},
+		},
+		{
+			name: "Group",
+			input: &FuncDecl{
+				Group: []*DeclGroupItem{{Label: "foo"}, {Label: "bar"}},
+			},
+			want: &FuncDecl{
+				Group: []*DeclGroupItem{{Label: "foo"}, {Label: "bar"}},
+			},
+		},
 		{
 			name: "Value",
 			input: &FuncDecl{
@@ -1280,6 +1429,16 @@ func TestFuncDecl_Children(t *testing.T) {
 				&ParameterType{Ident: &Ident{Parts: []string{"number"}}},
 			},
 		},
+		{
+			name: "Targets",
+			input: &FuncDecl{
+				Targets: []*FuncParameter{{Label: "foo"}, {Label: "bar"}},
+			},
+			want: []Node{
+				&FuncParameter{Label: "foo"},
+				&FuncParameter{Label: "bar"},
+			},
+		},
 		{
 			name: "Value",
 			input: &FuncDecl{
@@ -1353,6 +1512,208 @@ func TestFuncDecl_FormattedString(t *testing.T) {
 			},
 			want: "val foo: number = 1",
 		},
+		{
+			name: "Targets and value",
+			input: &FuncDecl{
+				DeclType: "val",
+				Targets: []*FuncParameter{
+					{Label: "foo"},
+					{Label: "bar", Type: &ParameterType{Ident: &Ident{Parts: []string{"number"}}}},
+				},
+				Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+			},
+			want: "val (foo, bar: number) = 1",
+		},
+		{
+			name: "Group",
+			input: &FuncDecl{
+				DeclType: "val",
+				Group: []*DeclGroupItem{
+					{Comment: &Comment{SingleLine: []string{"// foo"}}},
+					{Label: "foo", Value: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}})},
+				},
+			},
+			want: "val (\n\t// foo\n\tfoo = 1\n)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testStringer(t, tt.wantPanic, tt.want, tt.input)
+		})
+	}
+}
+
+func TestFuncReturn_Parsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		want    *FuncReturn
+	}{
+		{
+			name:    "no value",
+			input:   "return",
+			wantErr: false,
+			want: &FuncReturn{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+			},
+		},
+		{
+			name:    "one value",
+			input:   "return foo",
+			wantErr: false,
+			want: &FuncReturn{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Values: []*Expr{
+					BuildTestExprTree[*Expr](t, &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+						Parts:   []string{"foo"},
+					}),
+				},
+			},
+		},
+		{
+			name:    "two values",
+			input:   "return foo, bar",
+			wantErr: false,
+			want: &FuncReturn{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Values: []*Expr{
+					BuildTestExprTree[*Expr](t, &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+						Parts:   []string{"foo"},
+					}),
+					BuildTestExprTree[*Expr](t, &Ident{
+						ASTNode: ASTNode{Pos: Position{Offset: 12, Line: 1, Column: 13}},
+						Parts:   []string{"bar"},
+					}),
+				},
+			},
+		},
+		{
+			name:    "trailing comma",
+			input:   "return foo,",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParser(t, tt.input, tt.want, tt.wantErr, true)
+		})
+	}
+}
+
+func TestFuncReturn_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *FuncReturn
+		want  *FuncReturn
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Empty",
+			input: &FuncReturn{},
+			want:  &FuncReturn{},
+		},
+		{
+			name: "ASTNode",
+			input: &FuncReturn{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+			},
+			want: &FuncReturn{
+				ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 2, Column: 3}},
+			},
+		},
+		{
+			name: "Values",
+			input: &FuncReturn{
+				Values: []*Expr{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}), BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(2), Source: "2"}})},
+			},
+			want: &FuncReturn{
+				Values: []*Expr{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}), BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(2), Source: "2"}})},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCloner[*FuncReturn](t, tt.want, tt.input.Clone())
+		})
+	}
+}
+
+func TestFuncReturn_Children(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *FuncReturn
+		want  []Node
+	}{
+		{
+			name:  "Empty",
+			input: &FuncReturn{},
+			want:  nil,
+		},
+		{
+			name: "Values",
+			input: &FuncReturn{
+				Values: []*Expr{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}), BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(2), Source: "2"}})},
+			},
+			want: []Node{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}), BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(2), Source: "2"}})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.input.Children())
+		})
+	}
+}
+
+func TestFuncReturn_FormattedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     *FuncReturn
+		wantPanic bool
+		want      string
+	}{
+		{
+			name:      "Nil",
+			input:     nil,
+			wantPanic: true,
+		},
+		{
+			name:  "Empty",
+			input: &FuncReturn{},
+			want:  "return",
+		},
+		{
+			name: "One value",
+			input: &FuncReturn{
+				Values: []*Expr{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}})},
+			},
+			want: "return 1",
+		},
+		{
+			name: "Two values",
+			input: &FuncReturn{
+				Values: []*Expr{BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}), BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(2), Source: "2"}})},
+			},
+			want: "return 1, 2",
+		},
 	}
 
 	for _, tt := range tests {
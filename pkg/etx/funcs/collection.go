@@ -0,0 +1,847 @@
+package funcs
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// maxRangeLength is the maximum number of elements generated by range.
+const maxRangeLength = 1024
+
+//nolint:gochecknoglobals // immutable function table
+var collectionFunctions = map[string]*value.Function{
+	"alltrue": {
+		Name:   "alltrue",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return allOrAny(args[0], true)
+		},
+	},
+	"anytrue": {
+		Name:   "anytrue",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return allOrAny(args[0], false)
+		},
+	},
+	"chunklist": {
+		Name: "chunklist",
+		Params: []value.Param{
+			{Name: "list"},
+			{Name: "size", Type: value.TypeNumber},
+		},
+		Impl: chunkListImpl,
+	},
+	"coalesce": {
+		Name:     "coalesce",
+		VarParam: &value.Param{Name: "vals", AllowNull: true},
+		Impl: func(args []value.Value) (value.Value, error) {
+			for _, arg := range args {
+				if arg.IsNull() || (arg.Kind() == value.KindString && arg.AsString() == "") {
+					continue
+				}
+
+				return arg, nil
+			}
+
+			return value.Null, argErrorf(0, "no non-null, non-empty-string arguments")
+		},
+	},
+	"coalescelist": {
+		Name:     "coalescelist",
+		VarParam: &value.Param{Name: "vals", AllowNull: true},
+		Impl: func(args []value.Value) (value.Value, error) {
+			for i, arg := range args {
+				if arg.IsNull() {
+					continue
+				}
+
+				if _, err := listArg(args, i, "vals"); err != nil {
+					return value.Null, err
+				}
+
+				if arg.Len() > 0 {
+					return arg, nil
+				}
+			}
+
+			return value.Null, argErrorf(0, "no non-null arguments")
+		},
+	},
+	"compact": {
+		Name:   "compact",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := stringListArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			var out []value.Value
+
+			for _, item := range items {
+				if !item.IsNull() && item.AsString() != "" {
+					out = append(out, item)
+				}
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"concat": {
+		Name:     "concat",
+		VarParam: &value.Param{Name: "seqs"},
+		Impl: func(args []value.Value) (value.Value, error) {
+			var out []value.Value
+
+			for i := range args {
+				items, err := listArg(args, i, "seqs")
+				if err != nil {
+					return value.Null, err
+				}
+
+				out = append(out, items...)
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"contains": {
+		Name: "contains",
+		Params: []value.Param{
+			{Name: "list"},
+			{Name: "value", AllowNull: true},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := listArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			return value.Bool(indexOf(items, args[1]) >= 0), nil
+		},
+	},
+	"distinct": {
+		Name:   "distinct",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := listArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			var out []value.Value
+
+			for _, item := range items {
+				if indexOf(out, item) < 0 {
+					out = append(out, item)
+				}
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"element": {
+		Name: "element",
+		Params: []value.Param{
+			{Name: "list"},
+			{Name: "index", Type: value.TypeNumber},
+		},
+		Impl: elementImpl,
+	},
+	"flatten": {
+		Name:   "flatten",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := listArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			return value.List(flatten(items)...), nil
+		},
+	},
+	"index": {
+		Name: "index",
+		Params: []value.Param{
+			{Name: "list"},
+			{Name: "value", AllowNull: true},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := listArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			i := indexOf(items, args[1])
+			if i < 0 {
+				return value.Null, argErrorf(1, "item not found")
+			}
+
+			return value.Int(int64(i)), nil
+		},
+	},
+	"keys": {
+		Name:   "keys",
+		Params: []value.Param{{Name: "inputMap"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].Kind() != value.KindMap {
+				return value.Null, argErrorf(0, "inputMap must be a map, got %s", args[0].Kind())
+			}
+
+			keys := args[0].Keys()
+
+			out := make([]value.Value, 0, len(keys))
+			for _, key := range keys {
+				out = append(out, value.String(key))
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"length": {
+		Name:   "length",
+		Params: []value.Param{{Name: "value", AllowUnknown: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			switch arg := args[0]; {
+			case !arg.IsKnown() && arg.Refinement().Length >= 0:
+				return value.Int(int64(arg.Refinement().Length)), nil
+			case !arg.IsKnown():
+				return value.Unknown(value.TypeNumber).RefineNotNull(), nil
+			case arg.Kind() == value.KindString:
+				return value.Int(int64(len([]rune(arg.AsString())))), nil
+			case arg.IsCollection():
+				return value.Int(int64(arg.Len())), nil
+			default:
+				return value.Null, argErrorf(0, "argument must be a string or a collection, got %s", arg.Kind())
+			}
+		},
+	},
+	"list": {
+		Name:     "list",
+		VarParam: &value.Param{Name: "vals", AllowNull: true},
+		Impl: func([]value.Value) (value.Value, error) {
+			return value.Null, argErrorf(0, "the \"list\" function is no longer available; use List(...) or tolist(...) instead")
+		},
+	},
+	"lookup": {
+		Name: "lookup",
+		Params: []value.Param{
+			{Name: "inputMap"},
+			{Name: "key", Type: value.TypeString},
+		},
+		VarParam: &value.Param{Name: "default", AllowNull: true},
+		Impl:     lookupImpl,
+	},
+	"map": {
+		Name:     "map",
+		VarParam: &value.Param{Name: "vals", AllowNull: true},
+		Impl: func([]value.Value) (value.Value, error) {
+			return value.Null, argErrorf(0, "the \"map\" function is no longer available; use a map literal or tomap(...) instead")
+		},
+	},
+	"matchkeys": {
+		Name: "matchkeys",
+		Params: []value.Param{
+			{Name: "values"},
+			{Name: "keys"},
+			{Name: "searchset"},
+		},
+		Impl: matchKeysImpl,
+	},
+	"merge": {
+		Name:     "merge",
+		VarParam: &value.Param{Name: "maps", AllowNull: true},
+		Impl: func(args []value.Value) (value.Value, error) {
+			out := make(map[string]value.Value)
+
+			for i, arg := range args {
+				if arg.IsNull() {
+					continue
+				}
+
+				if arg.Kind() != value.KindMap {
+					return value.Null, argErrorf(i, "maps must be maps, got %s", arg.Kind())
+				}
+
+				for k, v := range arg.AsMap() {
+					out[k] = v
+				}
+			}
+
+			return value.Map(out), nil
+		},
+	},
+	"one": {
+		Name:   "one",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := listArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			switch len(items) {
+			case 0:
+				return value.Null, nil
+			case 1:
+				return items[0], nil
+			default:
+				return value.Null, argErrorf(0, "must be a list or set with either zero or one elements")
+			}
+		},
+	},
+	"range": {
+		Name:     "range",
+		VarParam: &value.Param{Name: "params", Type: value.TypeNumber},
+		Impl:     rangeImpl,
+	},
+	"reverse": {
+		Name:   "reverse",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := listArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			out := make([]value.Value, 0, len(items))
+			for i := len(items) - 1; i >= 0; i-- {
+				out = append(out, items[i])
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"setintersection": {
+		Name:     "setintersection",
+		Params:   []value.Param{{Name: "first_set"}},
+		VarParam: &value.Param{Name: "other_sets"},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return setOperation(args, func(acc []value.Value, items []value.Value) []value.Value {
+				var out []value.Value
+
+				for _, item := range acc {
+					if indexOf(items, item) >= 0 {
+						out = append(out, item)
+					}
+				}
+
+				return out
+			})
+		},
+	},
+	"setproduct": {
+		Name:     "setproduct",
+		VarParam: &value.Param{Name: "sets"},
+		Impl:     setProductImpl,
+	},
+	"setsubtract": {
+		Name: "setsubtract",
+		Params: []value.Param{
+			{Name: "a"},
+			{Name: "b"},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return setOperation(args, func(acc []value.Value, items []value.Value) []value.Value {
+				var out []value.Value
+
+				for _, item := range acc {
+					if indexOf(items, item) < 0 {
+						out = append(out, item)
+					}
+				}
+
+				return out
+			})
+		},
+	},
+	"setunion": {
+		Name:     "setunion",
+		Params:   []value.Param{{Name: "first_set"}},
+		VarParam: &value.Param{Name: "other_sets"},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return setOperation(args, func(acc []value.Value, items []value.Value) []value.Value {
+				return append(acc, items...)
+			})
+		},
+	},
+	"slice": {
+		Name: "slice",
+		Params: []value.Param{
+			{Name: "list"},
+			{Name: "start_index", Type: value.TypeNumber},
+			{Name: "end_index", Type: value.TypeNumber},
+		},
+		Impl: sliceImpl,
+	},
+	"sort": {
+		Name:   "sort",
+		Params: []value.Param{{Name: "list"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			items, err := stringListArg(args, 0, "list")
+			if err != nil {
+				return value.Null, err
+			}
+
+			strs := make([]string, 0, len(items))
+
+			for i, item := range items {
+				if item.IsNull() {
+					return value.Null, argErrorf(0, "element %d is null; cannot sort null values", i)
+				}
+
+				strs = append(strs, item.AsString())
+			}
+
+			sort.Strings(strs)
+
+			out := make([]value.Value, 0, len(strs))
+			for _, s := range strs {
+				out = append(out, value.String(s))
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"sum": {
+		Name:   "sum",
+		Params: []value.Param{{Name: "list"}},
+		Impl:   sumImpl,
+	},
+	"transpose": {
+		Name:   "transpose",
+		Params: []value.Param{{Name: "values", Type: value.MapOf(value.ListOf(value.TypeString))}},
+		Impl:   transposeImpl,
+	},
+	"values": {
+		Name:   "values",
+		Params: []value.Param{{Name: "mapping"}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].Kind() != value.KindMap {
+				return value.Null, argErrorf(0, "mapping must be a map, got %s", args[0].Kind())
+			}
+
+			keys := args[0].Keys()
+
+			out := make([]value.Value, 0, len(keys))
+			for _, key := range keys {
+				v, _ := args[0].Get(key)
+				out = append(out, v)
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"zipmap": {
+		Name: "zipmap",
+		Params: []value.Param{
+			{Name: "keys"},
+			{Name: "values"},
+		},
+		Impl: zipMapImpl,
+	},
+}
+
+// listArg returns the elements of the list or set argument at index i.
+func listArg(args []value.Value, i int, name string) ([]value.Value, error) {
+	if k := args[i].Kind(); k != value.KindList && k != value.KindSet {
+		return nil, argErrorf(i, "%s must be a list or set, got %s", name, k)
+	}
+
+	return args[i].AsList(), nil
+}
+
+// stringListArg returns the elements of the list or set argument at index i,
+// converted to strings. Null elements are kept.
+func stringListArg(args []value.Value, i int, name string) ([]value.Value, error) {
+	items, err := listArg(args, i, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, item := range items {
+		if item.IsNull() {
+			continue
+		}
+
+		s, ok := toString(item)
+		if !ok {
+			return nil, argErrorf(i, "element %d of %s must be a string, got %s", j, name, item.Kind())
+		}
+
+		items[j] = value.String(s)
+	}
+
+	return items, nil
+}
+
+func indexOf(items []value.Value, v value.Value) int {
+	for i, item := range items {
+		if item.Equals(v) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func flatten(items []value.Value) []value.Value {
+	var out []value.Value
+
+	for _, item := range items {
+		if k := item.Kind(); k == value.KindList || k == value.KindSet {
+			out = append(out, flatten(item.AsList())...)
+		} else {
+			out = append(out, item)
+		}
+	}
+
+	return out
+}
+
+func allOrAny(list value.Value, all bool) (value.Value, error) {
+	items, err := listArg([]value.Value{list}, 0, "list")
+	if err != nil {
+		return value.Null, err
+	}
+
+	for j, item := range items {
+		b := false
+
+		if !item.IsNull() {
+			if b, err = toBool(item); err != nil {
+				return value.Null, argErrorf(0, "element %d of list must be a bool, got %s", j, item.Kind())
+			}
+		}
+
+		if b != all {
+			return value.Bool(!all), nil
+		}
+	}
+
+	return value.Bool(all), nil
+}
+
+func chunkListImpl(args []value.Value) (value.Value, error) {
+	items, err := listArg(args, 0, "list")
+	if err != nil {
+		return value.Null, err
+	}
+
+	size, err := intArg(args, 1, "size")
+	if err != nil {
+		return value.Null, err
+	}
+
+	switch {
+	case size < 0:
+		return value.Null, argErrorf(1, "the size argument must be positive")
+	case size == 0 || size > len(items):
+		size = len(items)
+	}
+
+	var out []value.Value
+
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+
+		out = append(out, value.List(items[start:end]...))
+	}
+
+	return value.List(out...), nil
+}
+
+func elementImpl(args []value.Value) (value.Value, error) {
+	items, err := listArg(args, 0, "list")
+	if err != nil {
+		return value.Null, err
+	}
+
+	i, err := intArg(args, 1, "index")
+	if err != nil {
+		return value.Null, err
+	}
+
+	switch {
+	case len(items) == 0:
+		return value.Null, argErrorf(0, "cannot use element function with an empty list")
+	case i < 0:
+		return value.Null, argErrorf(1, "cannot use element function with a negative index")
+	default:
+		return items[i%len(items)], nil
+	}
+}
+
+func lookupImpl(args []value.Value) (value.Value, error) {
+	if len(args) > 3 { //nolint:gomnd // map, key and default
+		return value.Null, argErrorf(3, "lookup expects at most 3 arguments, got %d", len(args)) //nolint:gomnd // extra argument
+	}
+
+	if args[0].Kind() != value.KindMap {
+		return value.Null, argErrorf(0, "inputMap must be a map, got %s", args[0].Kind())
+	}
+
+	key := args[1].AsString()
+
+	if v, ok := args[0].Get(key); ok {
+		return v, nil
+	}
+
+	if len(args) == 3 { //nolint:gomnd // map, key and default
+		return args[2], nil
+	}
+
+	return value.Null, argErrorf(1, "the given key %q does not exist in the map", key)
+}
+
+func matchKeysImpl(args []value.Value) (value.Value, error) {
+	values, err := listArg(args, 0, "values")
+	if err != nil {
+		return value.Null, err
+	}
+
+	keys, err := listArg(args, 1, "keys")
+	if err != nil {
+		return value.Null, err
+	}
+
+	searchset, err := listArg(args, 2, "searchset") //nolint:gomnd // third argument
+	if err != nil {
+		return value.Null, err
+	}
+
+	if len(keys) != len(values) {
+		return value.Null, argErrorf(1, "length of keys and values should be equal")
+	}
+
+	var out []value.Value
+
+	for i, key := range keys {
+		if indexOf(searchset, key) >= 0 {
+			out = append(out, values[i])
+		}
+	}
+
+	return value.List(out...), nil
+}
+
+func rangeImpl(args []value.Value) (value.Value, error) {
+	start, step := newFloat(), newFloat().SetInt64(1)
+
+	var limit *big.Float
+
+	switch len(args) {
+	case 1:
+		limit = args[0].AsBigFloat()
+	case 2: //nolint:gomnd // start and limit
+		start, limit = args[0].AsBigFloat(), args[1].AsBigFloat()
+		if limit.Cmp(start) < 0 {
+			step.SetInt64(-1)
+		}
+	case 3: //nolint:gomnd // start, limit and step
+		start, limit, step = args[0].AsBigFloat(), args[1].AsBigFloat(), args[2].AsBigFloat()
+	default:
+		return value.Null, argErrorf(0, "range expects 1 to 3 arguments, got %d", len(args))
+	}
+
+	switch {
+	case step.Sign() == 0:
+		return value.Null, argErrorf(len(args)-1, "step must not be zero")
+	case step.Sign() < 0 && limit.Cmp(start) > 0:
+		return value.Null, argErrorf(len(args)-1, "step must be positive when the limit is greater than the start")
+	case step.Sign() > 0 && limit.Cmp(start) < 0:
+		return value.Null, argErrorf(len(args)-1, "step must be negative when the limit is less than the start")
+	}
+
+	var out []value.Value
+
+	for v := start; v.Cmp(limit)*step.Sign() < 0; v = newFloat().Add(v, step) {
+		if len(out) == maxRangeLength {
+			return value.Null, argErrorf(0, "more than %d values were generated; either decrease the difference between start and limit or use a larger step", maxRangeLength)
+		}
+
+		out = append(out, value.Number(v))
+	}
+
+	return value.List(out...), nil
+}
+
+// setOperation folds the elements of the list or set arguments into a set.
+func setOperation(args []value.Value, op func(acc []value.Value, items []value.Value) []value.Value) (value.Value, error) {
+	acc, err := listArg(args, 0, "sets")
+	if err != nil {
+		return value.Null, err
+	}
+
+	for i := 1; i < len(args); i++ {
+		items, err := listArg(args, i, "sets")
+		if err != nil {
+			return value.Null, err
+		}
+
+		acc = op(acc, items)
+	}
+
+	return value.Set(acc...), nil
+}
+
+func setProductImpl(args []value.Value) (value.Value, error) {
+	if len(args) < 2 { //nolint:gomnd // at least two sets
+		return value.Null, argErrorf(0, "at least two arguments are required")
+	}
+
+	products := [][]value.Value{{}}
+	sets := true
+
+	for i := range args {
+		items, err := listArg(args, i, "sets")
+		if err != nil {
+			return value.Null, err
+		}
+
+		sets = sets && args[i].Kind() == value.KindSet
+
+		next := make([][]value.Value, 0, len(products)*len(items))
+
+		for _, prefix := range products {
+			for _, item := range items {
+				product := make([]value.Value, len(prefix), len(prefix)+1)
+				copy(product, prefix)
+				next = append(next, append(product, item))
+			}
+		}
+
+		products = next
+	}
+
+	out := make([]value.Value, 0, len(products))
+	for _, product := range products {
+		out = append(out, value.List(product...))
+	}
+
+	if sets {
+		return value.Set(out...), nil
+	}
+
+	return value.List(out...), nil
+}
+
+func sliceImpl(args []value.Value) (value.Value, error) {
+	items, err := listArg(args, 0, "list")
+	if err != nil {
+		return value.Null, err
+	}
+
+	start, err := intArg(args, 1, "start_index")
+	if err != nil {
+		return value.Null, err
+	}
+
+	end, err := intArg(args, 2, "end_index") //nolint:gomnd // third argument
+	if err != nil {
+		return value.Null, err
+	}
+
+	switch {
+	case start < 0:
+		return value.Null, argErrorf(1, "start index must not be less than zero")
+	case end > len(items):
+		return value.Null, argErrorf(2, "end index must not be greater than the length of the list") //nolint:gomnd // third argument
+	case start > end:
+		return value.Null, argErrorf(1, "start index must not be greater than end index")
+	default:
+		return value.List(items[start:end]...), nil
+	}
+}
+
+func sumImpl(args []value.Value) (value.Value, error) {
+	items, err := listArg(args, 0, "list")
+	if err != nil {
+		return value.Null, err
+	}
+
+	if len(items) == 0 {
+		return value.Null, argErrorf(0, "cannot sum an empty list")
+	}
+
+	sum := newFloat()
+
+	for j, item := range items {
+		f, ok := toNumber(item)
+		if item.IsNull() || !ok {
+			return value.Null, argErrorf(0, "element %d of list must be a number, got %s", j, item.Kind())
+		}
+
+		sum.Add(sum, f)
+	}
+
+	return value.Number(sum), nil
+}
+
+func transposeImpl(args []value.Value) (value.Value, error) {
+	index := make(map[string][]string)
+
+	for _, key := range args[0].Keys() {
+		list, _ := args[0].Get(key)
+		if list.IsNull() {
+			return value.Null, argErrorf(0, "the value for key %q is null", key)
+		}
+
+		for j, item := range list.AsList() {
+			if item.IsNull() {
+				return value.Null, argErrorf(0, "element %d of the value for key %q is null", j, key)
+			}
+
+			index[item.AsString()] = append(index[item.AsString()], key)
+		}
+	}
+
+	out := make(map[string]value.Value, len(index))
+
+	for k, keys := range index {
+		sort.Strings(keys)
+
+		items := make([]value.Value, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, value.String(key))
+		}
+
+		out[k] = value.List(items...)
+	}
+
+	return value.Map(out), nil
+}
+
+func zipMapImpl(args []value.Value) (value.Value, error) {
+	keys, err := stringListArg(args, 0, "keys")
+	if err != nil {
+		return value.Null, err
+	}
+
+	values, err := listArg(args, 1, "values")
+	if err != nil {
+		return value.Null, err
+	}
+
+	if len(keys) != len(values) {
+		return value.Null, argErrorf(1, "number of keys (%d) does not match number of values (%d)", len(keys), len(values))
+	}
+
+	out := make(map[string]value.Value, len(keys))
+
+	for i, key := range keys {
+		if key.IsNull() {
+			return value.Null, argErrorf(0, "element %d of keys is null", i)
+		}
+
+		out[key.AsString()] = values[i]
+	}
+
+	return value.Map(out), nil
+}
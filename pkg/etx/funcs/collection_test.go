@@ -0,0 +1,131 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestCollectionFunctions(t *testing.T) {
+	t.Parallel()
+
+	ints := func(items ...int64) value.Value {
+		out := make([]value.Value, 0, len(items))
+		for _, i := range items {
+			out = append(out, value.Int(i))
+		}
+
+		return value.List(out...)
+	}
+
+	m := func(kv ...any) value.Value {
+		out := make(map[string]value.Value)
+		for i := 0; i < len(kv); i += 2 {
+			out[kv[i].(string)] = args(kv[i+1])[0]
+		}
+
+		return value.Map(out)
+	}
+
+	runFuncTests(t, []funcTest{
+		{name: "alltrue", fn: "alltrue", args: args(value.List(value.True, value.String("true"))), want: value.True},
+		{name: "alltrue - false", fn: "alltrue", args: args(value.List(value.True, value.False)), want: value.False},
+		{name: "alltrue - empty", fn: "alltrue", args: args(value.List()), want: value.True},
+		{name: "alltrue - null element", fn: "alltrue", args: args(value.List(value.Null)), want: value.False},
+		{name: "alltrue - not bools", fn: "alltrue", args: args(ints(1)), wantErr: "element 0 of list must be a bool, got number"},
+		{name: "anytrue", fn: "anytrue", args: args(value.List(value.False, value.True)), want: value.True},
+		{name: "anytrue - empty", fn: "anytrue", args: args(value.List()), want: value.False},
+		{name: "chunklist", fn: "chunklist", args: args(strs("a", "b", "c", "d", "e"), 2), want: value.List(strs("a", "b"), strs("c", "d"), strs("e"))},
+		{name: "chunklist - zero size", fn: "chunklist", args: args(strs("a", "b"), 0), want: value.List(strs("a", "b"))},
+		{name: "chunklist - negative size", fn: "chunklist", args: args(strs("a"), -1), wantErr: "the size argument must be positive"},
+		{name: "coalesce", fn: "coalesce", args: args(nil, "", "b", "c"), want: value.String("b")},
+		{name: "coalesce - number", fn: "coalesce", args: args(nil, 1, 2), want: value.Int(1)},
+		{name: "coalesce - none", fn: "coalesce", args: args(nil, ""), wantErr: "no non-null, non-empty-string arguments"},
+		{name: "coalescelist", fn: "coalescelist", args: args(strs(), strs("c", "d")), want: strs("c", "d")},
+		{name: "coalescelist - none", fn: "coalescelist", args: args(strs(), nil), wantErr: "no non-null arguments"},
+		{name: "coalescelist - not a list", fn: "coalescelist", args: args(strs(), "a"), wantErr: "vals must be a list or set, got string"},
+		{name: "compact", fn: "compact", args: args(value.List(value.String("a"), value.String(""), value.Null, value.String("b"))), want: strs("a", "b")},
+		{name: "concat", fn: "concat", args: args(strs("a", ""), strs("b", "c")), want: strs("a", "", "b", "c")},
+		{name: "contains", fn: "contains", args: args(strs("a", "b"), "a"), want: value.True},
+		{name: "contains - missing", fn: "contains", args: args(strs("a", "b"), "c"), want: value.False},
+		{name: "distinct", fn: "distinct", args: args(strs("a", "b", "a", "c", "d", "b")), want: strs("a", "b", "c", "d")},
+		{name: "element", fn: "element", args: args(strs("a", "b", "c"), 1), want: value.String("b")},
+		{name: "element - wraps around", fn: "element", args: args(strs("a", "b", "c"), 3), want: value.String("a")},
+		{name: "element - negative", fn: "element", args: args(strs("a"), -1), wantErr: "cannot use element function with a negative index"},
+		{name: "element - empty", fn: "element", args: args(strs(), 0), wantErr: "cannot use element function with an empty list"},
+		{name: "flatten", fn: "flatten", args: args(value.List(strs("a", "b"), strs(), value.List(strs("c")))), want: strs("a", "b", "c")},
+		{name: "index", fn: "index", args: args(strs("a", "b", "c"), "b"), want: value.Int(1)},
+		{name: "index - missing", fn: "index", args: args(strs("a"), "b"), wantErr: "item not found"},
+		{name: "keys", fn: "keys", args: args(m("c", 3, "a", 1, "b", 2)), want: strs("a", "b", "c")},
+		{name: "keys - not a map", fn: "keys", args: args(strs()), wantErr: "inputMap must be a map, got list"},
+		{name: "length - list", fn: "length", args: args(strs("a", "b")), want: value.Int(2)},
+		{name: "length - map", fn: "length", args: args(m("a", 1)), want: value.Int(1)},
+		{name: "length - string", fn: "length", args: args("💡🔥"), want: value.Int(2)},
+		{name: "length - number", fn: "length", args: args(1), wantErr: "argument must be a string or a collection, got number"},
+		{name: "list", fn: "list", args: args("a"), wantErr: `the "list" function is no longer available`},
+		{name: "lookup", fn: "lookup", args: args(m("a", "ay", "b", "bee"), "a", "what?"), want: value.String("ay")},
+		{name: "lookup - default", fn: "lookup", args: args(m("a", "ay"), "c", "what?"), want: value.String("what?")},
+		{name: "lookup - missing", fn: "lookup", args: args(m("a", "ay"), "c"), wantErr: `the given key "c" does not exist in the map`},
+		{name: "lookup - too many arguments", fn: "lookup", args: args(m(), "c", 1, 2), wantErr: "lookup expects at most 3 arguments, got 4"},
+		{name: "map", fn: "map", args: args("a", 1), wantErr: `the "map" function is no longer available`},
+		{
+			name: "matchkeys",
+			fn:   "matchkeys",
+			args: args(strs("i-123", "i-abc", "i-def"), strs("us-west", "us-east", "us-east"), strs("us-east")),
+			want: strs("i-abc", "i-def"),
+		},
+		{name: "matchkeys - lengths", fn: "matchkeys", args: args(strs("a"), strs(), strs()), wantErr: "length of keys and values should be equal"},
+		{name: "merge", fn: "merge", args: args(m("a", "b", "c", "d"), nil, m("e", "f", "c", "z")), want: m("a", "b", "c", "z", "e", "f")},
+		{name: "merge - not a map", fn: "merge", args: args(m(), strs()), wantErr: "maps must be maps, got list"},
+		{name: "one", fn: "one", args: args(strs("a")), want: value.String("a")},
+		{name: "one - empty", fn: "one", args: args(strs()), want: value.Null},
+		{name: "one - several", fn: "one", args: args(strs("a", "b")), wantErr: "must be a list or set with either zero or one elements"},
+		{name: "range", fn: "range", args: args(3), want: ints(0, 1, 2)},
+		{name: "range - start and limit", fn: "range", args: args(1, 4), want: ints(1, 2, 3)},
+		{name: "range - descending", fn: "range", args: args(4, 1), want: ints(4, 3, 2)},
+		{name: "range - step", fn: "range", args: args(1, 8, 2), want: ints(1, 3, 5, 7)},
+		{name: "range - fractional step", fn: "range", args: args(0, 1, 0.5), want: value.List(value.Int(0), value.Float(0.5))},
+		{name: "range - empty", fn: "range", args: args(0), want: ints()},
+		{name: "range - zero step", fn: "range", args: args(1, 2, 0), wantErr: "step must not be zero"},
+		{name: "range - wrong direction", fn: "range", args: args(1, 4, -1), wantErr: "step must be positive when the limit is greater than the start"},
+		{name: "range - too long", fn: "range", args: args(2000), wantErr: "more than 1024 values were generated"},
+		{name: "range - no argument", fn: "range", args: args(), wantErr: "range expects 1 to 3 arguments, got 0"},
+		{name: "reverse", fn: "reverse", args: args(ints(1, 2, 3)), want: ints(3, 2, 1)},
+		{name: "setintersection", fn: "setintersection", args: args(strs("a", "b"), strs("b", "c"), strs("b", "d")), want: value.Set(value.String("b"))},
+		{
+			name: "setproduct",
+			fn:   "setproduct",
+			args: args(strs("development", "staging"), strs("app1", "app2")),
+			want: value.List(
+				strs("development", "app1"), strs("development", "app2"),
+				strs("staging", "app1"), strs("staging", "app2"),
+			),
+		},
+		{
+			name: "setproduct - sets",
+			fn:   "setproduct",
+			args: args(value.Set(value.Int(1)), value.Set(value.Int(2), value.Int(3))),
+			want: value.Set(ints(1, 2), ints(1, 3)),
+		},
+		{name: "setproduct - one argument", fn: "setproduct", args: args(strs("a")), wantErr: "at least two arguments are required"},
+		{name: "setsubtract", fn: "setsubtract", args: args(strs("a", "b", "c"), strs("a", "c")), want: value.Set(value.String("b"))},
+		{name: "setunion", fn: "setunion", args: args(strs("a", "b"), strs("b", "c"), strs("d")), want: value.Set(strs("a", "b", "c", "d").AsList()...)},
+		{name: "slice", fn: "slice", args: args(strs("a", "b", "c", "d"), 1, 3), want: strs("b", "c")},
+		{name: "slice - end out of range", fn: "slice", args: args(strs("a"), 0, 2), wantErr: "end index must not be greater than the length of the list"},
+		{name: "slice - start after end", fn: "slice", args: args(strs("a", "b"), 2, 1), wantErr: "start index must not be greater than end index"},
+		{name: "sort", fn: "sort", args: args(strs("e", "d", "a", "x")), want: strs("a", "d", "e", "x")},
+		{name: "sort - numbers as strings", fn: "sort", args: args(ints(10, 9)), want: strs("10", "9")},
+		{name: "sum", fn: "sum", args: args(value.List(value.Int(10), value.Int(13), value.Int(6), value.Float(0.5))), want: value.Float(29.5)},
+		{name: "sum - empty", fn: "sum", args: args(ints()), wantErr: "cannot sum an empty list"},
+		{name: "sum - not numbers", fn: "sum", args: args(strs("a")), wantErr: "element 0 of list must be a number, got string"},
+		{
+			name: "transpose",
+			fn:   "transpose",
+			args: args(value.Map(map[string]value.Value{"a": strs("1", "2"), "b": strs("2", "3"), "c": strs("2"), "d": strs()})),
+			want: value.Map(map[string]value.Value{"1": strs("a"), "2": strs("a", "b", "c"), "3": strs("b")}),
+		},
+		{name: "values", fn: "values", args: args(m("a", 3, "c", 2, "d", 1)), want: ints(3, 2, 1)},
+		{name: "zipmap", fn: "zipmap", args: args(strs("a", "b"), ints(1, 2)), want: m("a", 1, "b", 2)},
+		{name: "zipmap - lengths", fn: "zipmap", args: args(strs("a"), ints()), wantErr: "number of keys (1) does not match number of values (0)"},
+	})
+}
@@ -0,0 +1,269 @@
+package funcs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+//nolint:gochecknoglobals // immutable function table
+var conversionFunctions = map[string]*value.Function{
+	"can": {
+		Name:   "can",
+		Params: []value.Param{{Name: "expression", Lazy: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			_, err := args[0].AsFunction().Call(nil)
+
+			return value.Bool(err == nil), nil
+		},
+	},
+	"nonsensitive": {
+		Name:   "nonsensitive",
+		Params: []value.Param{{Name: "value", AllowNull: true, AllowUnknown: true, AllowSensitive: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			v, _ := args[0].Unmark()
+
+			return v, nil
+		},
+	},
+	"sensitive": {
+		Name:   "sensitive",
+		Params: []value.Param{{Name: "value", AllowNull: true, AllowUnknown: true, AllowSensitive: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return args[0].MarkSensitive(), nil
+		},
+	},
+	"tobool": {
+		Name:   "tobool",
+		Params: []value.Param{{Name: "v", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].IsNull() {
+				return value.Null, nil
+			}
+
+			b, err := toBool(args[0])
+			if err != nil {
+				return value.Null, &value.ArgError{Index: 0, Err: err}
+			}
+
+			return value.Bool(b), nil
+		},
+	},
+	"tolist": {
+		Name:   "tolist",
+		Params: []value.Param{{Name: "v", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].IsNull() {
+				return value.Null, nil
+			}
+
+			items, err := listArg(args, 0, "v")
+			if err != nil {
+				return value.Null, err
+			}
+
+			if items, err = unifyElements(items); err != nil {
+				return value.Null, &value.ArgError{Index: 0, Err: err}
+			}
+
+			return value.List(items...), nil
+		},
+	},
+	"tomap": {
+		Name:   "tomap",
+		Params: []value.Param{{Name: "v", AllowNull: true}},
+		Impl:   toMapImpl,
+	},
+	"tonumber": {
+		Name:   "tonumber",
+		Params: []value.Param{{Name: "v", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].IsNull() {
+				return value.Null, nil
+			}
+
+			f, ok := toNumber(args[0])
+			if !ok {
+				return value.Null, argErrorf(0, "cannot convert %s to number", describe(args[0]))
+			}
+
+			return value.Number(f), nil
+		},
+	},
+	"toset": {
+		Name:   "toset",
+		Params: []value.Param{{Name: "v", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].IsNull() {
+				return value.Null, nil
+			}
+
+			items, err := listArg(args, 0, "v")
+			if err != nil {
+				return value.Null, err
+			}
+
+			if items, err = unifyElements(items); err != nil {
+				return value.Null, &value.ArgError{Index: 0, Err: err}
+			}
+
+			return value.Set(items...), nil
+		},
+	},
+	"tostring": {
+		Name:   "tostring",
+		Params: []value.Param{{Name: "v", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].IsNull() {
+				return value.Null, nil
+			}
+
+			s, ok := toString(args[0])
+			if !ok {
+				return value.Null, argErrorf(0, "cannot convert %s to string", describe(args[0]))
+			}
+
+			return value.String(s), nil
+		},
+	},
+	"try": {
+		Name:     "try",
+		VarParam: &value.Param{Name: "expressions", Lazy: true},
+		Impl:     tryImpl,
+	},
+	"type": {
+		Name:   "type",
+		Params: []value.Param{{Name: "value", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			if args[0].IsNull() {
+				return value.String("null"), nil
+			}
+
+			return value.String(args[0].Type().String()), nil
+		},
+	},
+}
+
+// tryImpl returns the result of the first expression evaluated without
+// errors.
+func tryImpl(args []value.Value) (value.Value, error) {
+	if len(args) == 0 {
+		return value.Null, argErrorf(0, "at least one argument is required")
+	}
+
+	failures := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		res, err := arg.AsFunction().Call(nil)
+		if err == nil {
+			return res, nil
+		}
+
+		failures = append(failures, "- "+strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+
+	return value.Null, fmt.Errorf("%w: no expression succeeded:\n%s", value.ErrArgument, strings.Join(failures, "\n"))
+}
+
+func toMapImpl(args []value.Value) (value.Value, error) {
+	if args[0].IsNull() {
+		return value.Null, nil
+	}
+
+	if args[0].Kind() != value.KindMap {
+		return value.Null, argErrorf(0, "cannot convert %s to map", describe(args[0]))
+	}
+
+	keys := args[0].Keys()
+
+	items := make([]value.Value, 0, len(keys))
+	for _, key := range keys {
+		v, _ := args[0].Get(key)
+		items = append(items, v)
+	}
+
+	items, err := unifyElements(items)
+	if err != nil {
+		return value.Null, &value.ArgError{Index: 0, Err: err}
+	}
+
+	out := make(map[string]value.Value, len(keys))
+	for i, key := range keys {
+		out[key] = items[i]
+	}
+
+	return value.Map(out), nil
+}
+
+// toBool converts a bool or the "true" and "false" strings to a bool.
+func toBool(v value.Value) (bool, error) {
+	switch {
+	case v.Kind() == value.KindBool:
+		return v.AsBool(), nil
+	case v.Kind() == value.KindString && v.AsString() == "true":
+		return true, nil
+	case v.Kind() == value.KindString && v.AsString() == "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: cannot convert %s to bool", value.ErrArgument, describe(v))
+	}
+}
+
+// unifyElements converts the elements of a collection to a single type.
+// Mixed primitive elements are all converted to strings, other mixes of
+// types are an error.
+func unifyElements(items []value.Value) ([]value.Value, error) {
+	var kind value.Kind
+
+	mixed, hasString := false, false
+
+	for _, item := range items {
+		hasString = hasString || item.Kind() == value.KindString
+
+		switch {
+		case item.IsNull():
+		case kind == value.KindNull:
+			kind = item.Kind()
+		case kind != item.Kind():
+			mixed = true
+		}
+	}
+
+	if !mixed {
+		return items, nil
+	}
+
+	out := make([]value.Value, 0, len(items))
+
+	for _, item := range items {
+		if item.IsNull() {
+			out = append(out, item)
+
+			continue
+		}
+
+		s, ok := toString(item)
+		if !ok || !hasString {
+			return nil, fmt.Errorf("%w: all elements must have the same type", value.ErrArgument)
+		}
+
+		out = append(out, value.String(s))
+	}
+
+	return out, nil
+}
+
+// describe returns a short description of a value for error messages.
+func describe(v value.Value) string {
+	switch v.Kind() {
+	case value.KindString:
+		return fmt.Sprintf("%q", v.AsString())
+	case value.KindNumber, value.KindBool:
+		s, _ := toString(v)
+
+		return s
+	default:
+		return "a " + v.Kind().String()
+	}
+}
@@ -0,0 +1,85 @@
+package funcs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+var errThunk = errors.New("thunk failed")
+
+func thunk(v value.Value, err error) value.Value {
+	return value.Func(&value.Function{
+		Name: "thunk",
+		Impl: func([]value.Value) (value.Value, error) {
+			return v, err
+		},
+	})
+}
+
+func TestConversionFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "can", fn: "can", args: args(thunk(value.Int(1), nil)), want: value.True},
+		{name: "can - error", fn: "can", args: args(thunk(value.Null, errThunk)), want: value.False},
+		{name: "try", fn: "try", args: args(thunk(value.Null, errThunk), thunk(value.Int(2), nil), thunk(value.Int(3), nil)), want: value.Int(2)},
+		{name: "try - null result", fn: "try", args: args(thunk(value.Null, nil), thunk(value.Int(2), nil)), want: value.Null},
+		{name: "try - no success", fn: "try", args: args(thunk(value.Null, errThunk)), wantErr: "no expression succeeded:\n- thunk failed"},
+		{name: "try - no argument", fn: "try", args: args(), wantErr: "at least one argument is required"},
+		{name: "sensitive", fn: "sensitive", args: args("a"), want: value.String("a")},
+		{name: "nonsensitive", fn: "nonsensitive", args: args("a"), want: value.String("a")},
+		{name: "tobool", fn: "tobool", args: args(true), want: value.True},
+		{name: "tobool - string", fn: "tobool", args: args("false"), want: value.False},
+		{name: "tobool - null", fn: "tobool", args: args(nil), want: value.Null},
+		{name: "tobool - invalid", fn: "tobool", args: args("no"), wantErr: `cannot convert "no" to bool`},
+		{name: "tonumber", fn: "tonumber", args: args(1), want: value.Int(1)},
+		{name: "tonumber - string", fn: "tonumber", args: args("1.5"), want: value.Float(1.5)},
+		{name: "tonumber - null", fn: "tonumber", args: args(nil), want: value.Null},
+		{name: "tonumber - invalid", fn: "tonumber", args: args("no"), wantErr: `cannot convert "no" to number`},
+		{name: "tostring", fn: "tostring", args: args("hello"), want: value.String("hello")},
+		{name: "tostring - number", fn: "tostring", args: args(1.5), want: value.String("1.5")},
+		{name: "tostring - bool", fn: "tostring", args: args(true), want: value.String("true")},
+		{name: "tostring - null", fn: "tostring", args: args(nil), want: value.Null},
+		{name: "tostring - list", fn: "tostring", args: args(strs()), wantErr: "cannot convert a list to string"},
+		{name: "tolist", fn: "tolist", args: args(strs("a", "b")), want: strs("a", "b")},
+		{name: "tolist - set", fn: "tolist", args: args(value.Set(value.String("b"), value.String("a"))), want: strs("a", "b")},
+		{name: "tolist - mixed", fn: "tolist", args: args(value.List(value.String("a"), value.Int(1), value.True)), want: strs("a", "1", "true")},
+		{name: "tolist - incompatible", fn: "tolist", args: args(value.List(value.Int(1), value.True)), wantErr: "all elements must have the same type"},
+		{name: "tolist - map", fn: "tolist", args: args(value.Map(nil)), wantErr: "v must be a list or set, got map"},
+		{name: "toset", fn: "toset", args: args(strs("c", "b", "b")), want: value.Set(value.String("b"), value.String("c"))},
+		{name: "toset - mixed", fn: "toset", args: args(value.List(value.String("a"), value.Int(1))), want: value.Set(value.String("1"), value.String("a"))},
+		{
+			name: "tomap",
+			fn:   "tomap",
+			args: args(value.Map(map[string]value.Value{"a": value.Int(1), "b": value.String("x")})),
+			want: value.Map(map[string]value.Value{"a": value.String("1"), "b": value.String("x")}),
+		},
+		{name: "tomap - list", fn: "tomap", args: args(strs()), wantErr: "cannot convert a list to map"},
+		{name: "type - string", fn: "type", args: args("a"), want: value.String("string")},
+		{name: "type - list", fn: "type", args: args(strs("a")), want: value.String("list(string)")},
+		{name: "type - null", fn: "type", args: args(nil), want: value.String("null")},
+	})
+}
+
+func TestTry_Lazy(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	counted := value.Func(&value.Function{
+		Name: "thunk",
+		Impl: func([]value.Value) (value.Value, error) {
+			calls++
+
+			return value.Int(1), nil
+		},
+	})
+
+	res, err := Functions()["try"].Call(args(thunk(value.Int(0), nil), counted))
+	assert.NoError(t, err)
+	assert.True(t, res.Equals(value.Int(0)))
+	assert.Equal(t, 0, calls)
+}
@@ -0,0 +1,198 @@
+package funcs
+
+import (
+	"crypto/md5" //nolint:gosec // md5 is required for compatibility
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // sha1 is required for compatibility
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const uuidLength = 16
+
+//nolint:gochecknoglobals // immutable function table
+var cryptoFunctions = map[string]*value.Function{
+	"base64sha256": hashFunction("base64sha256", sha256.New, base64.StdEncoding.EncodeToString),
+	"base64sha512": hashFunction("base64sha512", sha512.New, base64.StdEncoding.EncodeToString),
+	"bcrypt": {
+		Name:     "bcrypt",
+		Params:   []value.Param{{Name: "str", Type: value.TypeString}},
+		VarParam: &value.Param{Name: "cost", Type: value.TypeNumber},
+		Impl:     bcryptImpl,
+	},
+	"md5":  hashFunction("md5", md5.New, hex.EncodeToString),
+	"sha1": hashFunction("sha1", sha1.New, hex.EncodeToString),
+	"rsadecrypt": {
+		Name: "rsadecrypt",
+		Params: []value.Param{
+			{Name: "ciphertext", Type: value.TypeString},
+			{Name: "privatekey", Type: value.TypeString},
+		},
+		Impl: rsaDecryptImpl,
+	},
+	"sha256": hashFunction("sha256", sha256.New, hex.EncodeToString),
+	"sha512": hashFunction("sha512", sha512.New, hex.EncodeToString),
+	"uuid": {
+		Name: "uuid",
+		Impl: func([]value.Value) (value.Value, error) {
+			var b [uuidLength]byte
+			if _, err := rand.Read(b[:]); err != nil {
+				return value.Null, fmt.Errorf("failed to generate a UUID: %w", err)
+			}
+
+			return value.String(formatUUID(b[:], 4)), nil //nolint:gomnd // random UUID version
+		},
+	},
+	"uuidv5": {
+		Name: "uuidv5",
+		Params: []value.Param{
+			{Name: "namespace", Type: value.TypeString},
+			{Name: "name", Type: value.TypeString},
+		},
+		Impl: uuidV5Impl,
+	},
+}
+
+// hashFunction returns a function hashing a string and encoding the digest.
+func hashFunction(name string, newHash func() hash.Hash, encode func([]byte) string) *value.Function {
+	return &value.Function{
+		Name:   name,
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			h := newHash()
+			h.Write([]byte(args[0].AsString()))
+
+			return value.String(encode(h.Sum(nil))), nil
+		},
+	}
+}
+
+func bcryptImpl(args []value.Value) (value.Value, error) {
+	cost := bcrypt.DefaultCost
+
+	switch len(args) {
+	case 1:
+	case 2: //nolint:gomnd // string and cost
+		n, err := intArg(args, 1, "cost")
+		if err != nil {
+			return value.Null, err
+		}
+
+		cost = n
+	default:
+		return value.Null, argErrorf(2, "bcrypt expects at most 2 arguments, got %d", len(args)) //nolint:gomnd // extra argument
+	}
+
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return value.Null, argErrorf(1, "cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	res, err := bcrypt.GenerateFromPassword([]byte(args[0].AsString()), cost)
+	if err != nil {
+		return value.Null, argErrorf(0, "error occurred generating password %s", err)
+	}
+
+	return value.String(string(res)), nil
+}
+
+func rsaDecryptImpl(args []value.Value) (value.Value, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(args[0].AsString())
+	if err != nil {
+		return value.Null, argErrorf(0, "failed to decode input %q: cipher text must be base64-encoded", args[0].AsString())
+	}
+
+	block, _ := pem.Decode([]byte(args[1].AsString()))
+	if block == nil {
+		return value.Null, argErrorf(1, "failed to parse key: no key found")
+	}
+
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return value.Null, argErrorf(1, "failed to parse key: %s", err)
+	}
+
+	out, err := rsa.DecryptPKCS1v15(nil, key, ciphertext)
+	if err != nil {
+		return value.Null, argErrorf(0, "failed to decrypt: %s", err)
+	}
+
+	return value.String(string(out)), nil
+}
+
+// parsePrivateKey parses an RSA private key in the PKCS #1 or PKCS #8 form.
+func parsePrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", value.ErrArgument, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA private key", value.ErrArgument)
+	}
+
+	return rsaKey, nil
+}
+
+// uuidNamespaces are the well-known namespaces accepted by uuidv5.
+//
+//nolint:gochecknoglobals // immutable namespace table
+var uuidNamespaces = map[string]string{
+	"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+	"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+}
+
+func uuidV5Impl(args []value.Value) (value.Value, error) {
+	namespace := args[0].AsString()
+	if known, ok := uuidNamespaces[namespace]; ok {
+		namespace = known
+	}
+
+	ns, err := parseUUID(namespace)
+	if err != nil {
+		return value.Null, argErrorf(0, "uuidv5() doesn't support namespace %s (%s)", args[0].AsString(), err)
+	}
+
+	h := sha1.New() //nolint:gosec // required by the UUID version 5 specification
+	h.Write(ns)
+	h.Write([]byte(args[1].AsString()))
+
+	return value.String(formatUUID(h.Sum(nil)[:uuidLength], 5)), nil //nolint:gomnd // name-based SHA-1 UUID version
+}
+
+func parseUUID(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != uuidLength || len(s) != 2*uuidLength+4 {
+		return nil, fmt.Errorf("%w: invalid UUID %q", value.ErrArgument, s)
+	}
+
+	return b, nil
+}
+
+// formatUUID sets the version and variant bits of a UUID and formats it.
+func formatUUID(b []byte, version byte) string {
+	b[6] = b[6]&0x0f | version<<4 //nolint:gomnd // version bits
+	b[8] = b[8]&0x3f | 0x80       //nolint:gomnd // RFC 4122 variant bits
+
+	s := hex.EncodeToString(b)
+
+	return strings.Join([]string{s[0:8], s[8:12], s[12:16], s[16:20], s[20:32]}, "-")
+}
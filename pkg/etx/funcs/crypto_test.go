@@ -0,0 +1,130 @@
+package funcs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestCryptoFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "md5", fn: "md5", args: args("hello world"), want: value.String("5eb63bbbe01eeed093cb22bb8f5acdc3")},
+		{name: "sha1", fn: "sha1", args: args("hello world"), want: value.String("2aae6c35c94fcfb415dbe95f408b9ce91ee846ed")},
+		{
+			name: "sha256",
+			fn:   "sha256",
+			args: args("hello world"),
+			want: value.String("b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"),
+		},
+		{
+			name: "sha512",
+			fn:   "sha512",
+			args: args("hello world"),
+			want: value.String("309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f"),
+		},
+		{name: "base64sha256", fn: "base64sha256", args: args("hello world"), want: value.String("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=")},
+		{
+			name: "base64sha512",
+			fn:   "base64sha512",
+			args: args("hello world"),
+			want: value.String("MJ7MSJwS1utMxA9QyQLytNDtd+5RGnx6m808qG1M2G+YndNbxf9JlnDaNCVbRbDP2DDoH2Bdz33FVC6TrpzXbw=="),
+		},
+		{name: "uuidv5 - dns", fn: "uuidv5", args: args("dns", "www.terraform.io"), want: value.String("a5008fae-b28c-5ba5-96cd-82b4c53552d6")},
+		{name: "uuidv5 - url", fn: "uuidv5", args: args("url", "https://www.terraform.io/"), want: value.String("9db6f67c-dd95-5ea0-aa5b-e70e5c5f7cf5")},
+		{
+			name: "uuidv5 - custom namespace",
+			fn:   "uuidv5",
+			args: args("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "www.terraform.io"),
+			want: value.String("a5008fae-b28c-5ba5-96cd-82b4c53552d6"),
+		},
+		{name: "uuidv5 - invalid namespace", fn: "uuidv5", args: args("tld", "a"), wantErr: "uuidv5() doesn't support namespace tld"},
+		{name: "bcrypt - low cost", fn: "bcrypt", args: args("a", 3), wantErr: "cost must be between 4 and 31"},
+		{name: "bcrypt - too many arguments", fn: "bcrypt", args: args("a", 4, 5), wantErr: "bcrypt expects at most 2 arguments, got 3"},
+		{name: "rsadecrypt - invalid cipher text", fn: "rsadecrypt", args: args("a", "b"), wantErr: "cipher text must be base64-encoded"},
+		{name: "rsadecrypt - invalid key", fn: "rsadecrypt", args: args("YQ==", "b"), wantErr: "failed to parse key: no key found"},
+	})
+}
+
+func TestBcrypt(t *testing.T) {
+	t.Parallel()
+
+	fn := Functions()["bcrypt"]
+
+	res, err := fn.Call([]value.Value{value.String("hello world")})
+	require.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(res.AsString()), []byte("hello world")))
+
+	cost, err := bcrypt.Cost([]byte(res.AsString()))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+
+	res, err = fn.Call([]value.Value{value.String("hello world"), value.Int(5)})
+	require.NoError(t, err)
+
+	cost, err = bcrypt.Cost([]byte(res.AsString()))
+	require.NoError(t, err)
+	assert.Equal(t, 5, cost)
+}
+
+func TestUUID(t *testing.T) {
+	t.Parallel()
+
+	fn := Functions()["uuid"]
+	format := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := fn.Call(nil)
+	require.NoError(t, err)
+	assert.Regexp(t, format, first.AsString())
+
+	second, err := fn.Call(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.AsString(), second.AsString())
+}
+
+func TestRSADecrypt(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte("message"))
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  *pem.Block
+	}{
+		{name: "PKCS1", key: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}},
+		{name: "PKCS8", key: &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Functions()["rsadecrypt"].Call([]value.Value{
+				value.String(base64.StdEncoding.EncodeToString(ciphertext)),
+				value.String(string(pem.EncodeToMemory(tt.key))),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "message", got.AsString())
+		})
+	}
+}
@@ -0,0 +1,459 @@
+package funcs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const yamlIndent = 2
+
+//nolint:gochecknoglobals // immutable function table
+var encodingFunctions = map[string]*value.Function{
+	"base64decode": {
+		Name:   "base64decode",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			data, err := base64.StdEncoding.DecodeString(args[0].AsString())
+			if err != nil {
+				return value.Null, argErrorf(0, "failed to decode base64 data %q", args[0].AsString())
+			}
+
+			if !utf8.Valid(data) {
+				return value.Null, argErrorf(0, "the result of decoding the provided string is not valid UTF-8")
+			}
+
+			return value.String(string(data)), nil
+		},
+	},
+	"base64encode": {
+		Name:   "base64encode",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+		},
+	},
+	"base64gzip": {
+		Name:   "base64gzip",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			var buf bytes.Buffer
+
+			w := gzip.NewWriter(&buf)
+			if _, err := w.Write([]byte(args[0].AsString())); err != nil {
+				return value.Null, fmt.Errorf("failed to write gzip raw data: %w", err)
+			}
+
+			if err := w.Close(); err != nil {
+				return value.Null, fmt.Errorf("failed to close gzip writer: %w", err)
+			}
+
+			return value.String(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+		},
+	},
+	"csvdecode": {
+		Name:   "csvdecode",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl:   csvDecodeImpl,
+	},
+	"jsondecode": {
+		Name:   "jsondecode",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			res, err := value.FromJSON([]byte(args[0].AsString()))
+			if err != nil {
+				return value.Null, argErrorf(0, "%s", err)
+			}
+
+			return res, nil
+		},
+	},
+	"jsonencode": {
+		Name:   "jsonencode",
+		Params: []value.Param{{Name: "val", AllowNull: true}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			data, err := args[0].MarshalJSON()
+			if err != nil {
+				return value.Null, argErrorf(0, "%s", err)
+			}
+
+			return value.String(string(data)), nil
+		},
+	},
+	"textdecodebase64": {
+		Name: "textdecodebase64",
+		Params: []value.Param{
+			{Name: "source", Type: value.TypeString},
+			{Name: "encoding", Type: value.TypeString},
+		},
+		Impl: textDecodeBase64Impl,
+	},
+	"textencodebase64": {
+		Name: "textencodebase64",
+		Params: []value.Param{
+			{Name: "string", Type: value.TypeString},
+			{Name: "encoding", Type: value.TypeString},
+		},
+		Impl: textEncodeBase64Impl,
+	},
+	"urlencode": {
+		Name:   "urlencode",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(url.QueryEscape(args[0].AsString())), nil
+		},
+	},
+	"yamldecode": {
+		Name:   "yamldecode",
+		Params: []value.Param{{Name: "src", Type: value.TypeString}},
+		Impl:   yamlDecodeImpl,
+	},
+	"yamlencode": {
+		Name:   "yamlencode",
+		Params: []value.Param{{Name: "value", AllowNull: true}},
+		Impl:   yamlEncodeImpl,
+	},
+}
+
+func csvDecodeImpl(args []value.Value) (value.Value, error) {
+	r := csv.NewReader(strings.NewReader(args[0].AsString()))
+	r.FieldsPerRecord = 0 // the header sets the number of fields
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return value.Null, argErrorf(0, "missing header line")
+	}
+
+	if err != nil {
+		return value.Null, argErrorf(0, "%s", err)
+	}
+
+	var out []value.Value
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return value.Null, argErrorf(0, "%s", err)
+		}
+
+		row := make(map[string]value.Value, len(header))
+		for i, name := range header {
+			row[name] = value.String(record[i])
+		}
+
+		out = append(out, value.Map(row))
+	}
+
+	return value.List(out...), nil
+}
+
+// /////////////////////////////////////
+
+// textEncodings are the character encodings supported by textencodebase64
+// and textdecodebase64, indexed by lowercase name.
+//
+//nolint:gochecknoglobals // immutable encoding table
+var textEncodings = map[string]struct {
+	encode func(s string) ([]byte, bool)
+	decode func(data []byte) (string, bool)
+}{
+	"utf-8": {
+		encode: func(s string) ([]byte, bool) { return []byte(s), true },
+		decode: func(data []byte) (string, bool) { return string(data), utf8.Valid(data) },
+	},
+	"utf-16le": {
+		encode: func(s string) ([]byte, bool) { return encodeUTF16(s, false), true },
+		decode: func(data []byte) (string, bool) { return decodeUTF16(data, false) },
+	},
+	"utf-16be": {
+		encode: func(s string) ([]byte, bool) { return encodeUTF16(s, true), true },
+		decode: func(data []byte) (string, bool) { return decodeUTF16(data, true) },
+	},
+	"iso-8859-1": {
+		encode: encodeLatin1,
+		decode: func(data []byte) (string, bool) {
+			runes := make([]rune, 0, len(data))
+			for _, b := range data {
+				runes = append(runes, rune(b))
+			}
+
+			return string(runes), true
+		},
+	},
+}
+
+func textEncodeBase64Impl(args []value.Value) (value.Value, error) {
+	enc, ok := textEncodings[strings.ToLower(args[1].AsString())]
+	if !ok {
+		return value.Null, argErrorf(1, "%q is not a supported encoding name", args[1].AsString())
+	}
+
+	data, ok := enc.encode(args[0].AsString())
+	if !ok {
+		return value.Null, argErrorf(0, "the given string contains characters that cannot be represented in %s", args[1].AsString())
+	}
+
+	return value.String(base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func textDecodeBase64Impl(args []value.Value) (value.Value, error) {
+	enc, ok := textEncodings[strings.ToLower(args[1].AsString())]
+	if !ok {
+		return value.Null, argErrorf(1, "%q is not a supported encoding name", args[1].AsString())
+	}
+
+	data, err := base64.StdEncoding.DecodeString(args[0].AsString())
+	if err != nil {
+		return value.Null, argErrorf(0, "the given value has an invalid base64 symbol")
+	}
+
+	s, ok := enc.decode(data)
+	if !ok {
+		return value.Null, argErrorf(0, "the given value is not valid %s", args[1].AsString())
+	}
+
+	return value.String(s), nil
+}
+
+func encodeUTF16(s string, bigEndian bool) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units)) //nolint:gomnd // two bytes per unit
+
+	for _, u := range units {
+		if bigEndian {
+			out = append(out, byte(u>>8), byte(u)) //nolint:gomnd // high byte
+		} else {
+			out = append(out, byte(u), byte(u>>8)) //nolint:gomnd // high byte
+		}
+	}
+
+	return out
+}
+
+func decodeUTF16(data []byte, bigEndian bool) (string, bool) {
+	if len(data)%2 != 0 {
+		return "", false
+	}
+
+	units := make([]uint16, 0, len(data)/2) //nolint:gomnd // two bytes per unit
+
+	for i := 0; i < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1])) //nolint:gomnd // high byte
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i])) //nolint:gomnd // high byte
+		}
+	}
+
+	return string(utf16.Decode(units)), true
+}
+
+func encodeLatin1(s string) ([]byte, bool) {
+	out := make([]byte, 0, len(s))
+
+	for _, r := range s {
+		if r > 0xff { //nolint:gomnd // last Latin-1 code point
+			return nil, false
+		}
+
+		out = append(out, byte(r))
+	}
+
+	return out, true
+}
+
+// /////////////////////////////////////
+
+func yamlEncodeImpl(args []value.Value) (value.Value, error) {
+	node, err := yamlNode(args[0])
+	if err != nil {
+		return value.Null, &value.ArgError{Index: 0, Err: err}
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+
+	if err := enc.Encode(node); err != nil {
+		return value.Null, argErrorf(0, "%s", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return value.Null, argErrorf(0, "%s", err)
+	}
+
+	return value.String(buf.String()), nil
+}
+
+// yamlNode converts a value to a YAML node. Strings are always quoted, so
+// that they are decoded back as strings.
+func yamlNode(v value.Value) (*yaml.Node, error) {
+	switch v.Kind() {
+	case value.KindNull:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case value.KindBool:
+		s, _ := toString(v)
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: s}, nil
+	case value.KindNumber:
+		f := v.AsBigFloat()
+		if f.IsInt() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value.FormatNumber(f)}, nil
+		}
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: f.Text('g', -1)}, nil
+	case value.KindString:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.AsString(), Style: yaml.DoubleQuotedStyle}, nil
+	case value.KindList, value.KindSet:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+
+		for _, item := range v.AsList() {
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content, child)
+		}
+
+		return node, nil
+	case value.KindMap:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+		for _, key := range v.Keys() {
+			item, _ := v.Get(key)
+
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, Style: yaml.DoubleQuotedStyle},
+				child)
+		}
+
+		return node, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot encode a %s as YAML", value.ErrArgument, v.Kind())
+	}
+}
+
+func yamlDecodeImpl(args []value.Value) (value.Value, error) {
+	dec := yaml.NewDecoder(strings.NewReader(args[0].AsString()))
+
+	var doc yaml.Node
+
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return value.Null, nil
+		}
+
+		return value.Null, argErrorf(0, "%s", err)
+	}
+
+	var extra yaml.Node
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		return value.Null, argErrorf(0, "unsupported YAML stream with multiple documents")
+	}
+
+	res, err := yamlValue(&doc)
+	if err != nil {
+		return value.Null, &value.ArgError{Index: 0, Err: err}
+	}
+
+	return res, nil
+}
+
+// yamlValue converts a decoded YAML node to a value.
+func yamlValue(node *yaml.Node) (value.Value, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.SequenceNode:
+		items := make([]value.Value, 0, len(node.Content))
+
+		for _, child := range node.Content {
+			item, err := yamlValue(child)
+			if err != nil {
+				return value.Null, err
+			}
+
+			items = append(items, item)
+		}
+
+		return value.List(items...), nil
+	case yaml.MappingNode:
+		items := make(map[string]value.Value, len(node.Content)/2) //nolint:gomnd // key and value nodes
+
+		for i := 0; i < len(node.Content); i += 2 {
+			key, err := yamlValue(node.Content[i])
+			if err != nil {
+				return value.Null, err
+			}
+
+			s, ok := toString(key)
+			if !ok {
+				return value.Null, fmt.Errorf("%w: on line %d, mapping keys must be scalars", value.ErrArgument, node.Content[i].Line)
+			}
+
+			if items[s], err = yamlValue(node.Content[i+1]); err != nil {
+				return value.Null, err
+			}
+		}
+
+		return value.Map(items), nil
+	default:
+		return yamlScalar(node)
+	}
+}
+
+func yamlScalar(node *yaml.Node) (value.Value, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return value.Null, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return value.Null, fmt.Errorf("%w: on line %d, cannot decode %q as a bool", value.ErrArgument, node.Line, node.Value)
+		}
+
+		return value.Bool(b), nil
+	case "!!int":
+		i, ok := new(big.Int).SetString(node.Value, 0)
+		if !ok {
+			return value.Null, fmt.Errorf("%w: on line %d, cannot decode %q as a number", value.ErrArgument, node.Line, node.Value)
+		}
+
+		return value.Number(newFloat().SetInt(i)), nil
+	case "!!float":
+		f, _, err := big.ParseFloat(strings.ReplaceAll(node.Value, "_", ""), decimalBase, value.NumberPrecision, big.ToNearestEven)
+		if err != nil {
+			return value.Null, fmt.Errorf("%w: on line %d, cannot decode %q as a number", value.ErrArgument, node.Line, node.Value)
+		}
+
+		return value.Number(f), nil
+	default:
+		return value.String(node.Value), nil
+	}
+}
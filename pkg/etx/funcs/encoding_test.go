@@ -0,0 +1,137 @@
+package funcs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestEncodingFunctions(t *testing.T) {
+	t.Parallel()
+
+	obj := value.Map(map[string]value.Value{
+		"name":  value.String("etx"),
+		"count": value.Int(2),
+		"ratio": value.Float(0.5),
+		"tags":  strs("a", "true"),
+		"none":  value.Null,
+	})
+
+	runFuncTests(t, []funcTest{
+		{name: "base64encode", fn: "base64encode", args: args("Hello World"), want: value.String("SGVsbG8gV29ybGQ=")},
+		{name: "base64decode", fn: "base64decode", args: args("SGVsbG8gV29ybGQ="), want: value.String("Hello World")},
+		{name: "base64decode - invalid", fn: "base64decode", args: args("SGVsbG8"), wantErr: `failed to decode base64 data "SGVsbG8"`},
+		{name: "base64decode - not UTF-8", fn: "base64decode", args: args("/w=="), wantErr: "not valid UTF-8"},
+		{name: "urlencode", fn: "urlencode", args: args("Hello World?"), want: value.String("Hello+World%3F")},
+		{name: "urlencode - path", fn: "urlencode", args: args("foo:bar@localhost/a"), want: value.String("foo%3Abar%40localhost%2Fa")},
+		{name: "textencodebase64", fn: "textencodebase64", args: args("Hello World", "UTF-16LE"), want: value.String("SABlAGwAbABvACAAVwBvAHIAbABkAA==")},
+		{name: "textencodebase64 - big endian", fn: "textencodebase64", args: args("é", "utf-16be"), want: value.String("AOk=")},
+		{name: "textencodebase64 - latin1", fn: "textencodebase64", args: args("é", "ISO-8859-1"), want: value.String("6Q==")},
+		{name: "textencodebase64 - not representable", fn: "textencodebase64", args: args("€", "ISO-8859-1"), wantErr: "cannot be represented in ISO-8859-1"},
+		{name: "textencodebase64 - unknown encoding", fn: "textencodebase64", args: args("a", "ebcdic"), wantErr: `"ebcdic" is not a supported encoding name`},
+		{name: "textdecodebase64", fn: "textdecodebase64", args: args("SABlAGwAbABvACAAVwBvAHIAbABkAA==", "UTF-16LE"), want: value.String("Hello World")},
+		{name: "textdecodebase64 - latin1", fn: "textdecodebase64", args: args("6Q==", "ISO-8859-1"), want: value.String("é")},
+		{name: "textdecodebase64 - odd length", fn: "textdecodebase64", args: args("AA==", "UTF-16BE"), wantErr: "the given value is not valid UTF-16BE"},
+		{name: "textdecodebase64 - invalid", fn: "textdecodebase64", args: args("A", "UTF-8"), wantErr: "invalid base64 symbol"},
+		{
+			name: "csvdecode",
+			fn:   "csvdecode",
+			args: args("a,b,c\n1,2,3\n4,5,6\n"),
+			want: value.List(
+				value.Map(map[string]value.Value{"a": value.String("1"), "b": value.String("2"), "c": value.String("3")}),
+				value.Map(map[string]value.Value{"a": value.String("4"), "b": value.String("5"), "c": value.String("6")}),
+			),
+		},
+		{name: "csvdecode - header only", fn: "csvdecode", args: args("a,b\n"), want: value.List()},
+		{name: "csvdecode - empty", fn: "csvdecode", args: args(""), wantErr: "missing header line"},
+		{name: "csvdecode - wrong field count", fn: "csvdecode", args: args("a,b\n1\n"), wantErr: "wrong number of fields"},
+		{name: "jsonencode", fn: "jsonencode", args: args(obj), want: value.String(`{"count":2,"name":"etx","none":null,"ratio":0.5,"tags":["a","true"]}`)},
+		{name: "jsonencode - null", fn: "jsonencode", args: args(nil), want: value.String("null")},
+		{name: "jsondecode", fn: "jsondecode", args: args(`{"count":2,"name":"etx","none":null,"ratio":0.5,"tags":["a","true"]}`), want: obj},
+		{name: "jsondecode - invalid", fn: "jsondecode", args: args(`{"a":`), wantErr: "invalid argument"},
+		{
+			name: "yamlencode",
+			fn:   "yamlencode",
+			args: args(obj),
+			want: value.String("\"count\": 2\n\"name\": \"etx\"\n\"none\": null\n\"ratio\": 0.5\n\"tags\":\n- \"a\"\n- \"true\"\n"),
+		},
+		{name: "yamlencode - scalar", fn: "yamlencode", args: args("a"), want: value.String("\"a\"\n")},
+		{name: "yamldecode", fn: "yamldecode", args: args("count: 2\nname: etx\nnone: ~\nratio: 0.5\ntags: [a, \"true\"]\n"), want: obj},
+		{name: "yamldecode - alias", fn: "yamldecode", args: args("a: &x 1\nb: *x\n"), want: value.Map(map[string]value.Value{"a": value.Int(1), "b": value.Int(1)})},
+		{name: "yamldecode - empty", fn: "yamldecode", args: args(""), want: value.Null},
+		{name: "yamldecode - multiple documents", fn: "yamldecode", args: args("a\n---\nb\n"), wantErr: "multiple documents"},
+		{name: "yamldecode - invalid", fn: "yamldecode", args: args("a: [b"), wantErr: "invalid argument"},
+	})
+}
+
+func TestEncodingFunctions_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	fns := Functions()
+
+	tests := []struct {
+		name   string
+		encode string
+		decode string
+	}{
+		{name: "json", encode: "jsonencode", decode: "jsondecode"},
+		{name: "yaml", encode: "yamlencode", decode: "yamldecode"},
+	}
+
+	values := []value.Value{
+		value.Null,
+		value.True,
+		value.Int(-42),
+		value.Float(1.25),
+		value.String("true"),
+		value.String("1e3"),
+		value.String("multi\nline"),
+		value.List(),
+		value.Map(map[string]value.Value{
+			"list": value.List(value.Int(1), value.String("2"), value.Null),
+			"map":  value.Map(map[string]value.Value{"nested": value.False}),
+		}),
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, v := range values {
+				encoded, err := fns[tt.encode].Call([]value.Value{v})
+				require.NoError(t, err)
+
+				decoded, err := fns[tt.decode].Call([]value.Value{encoded})
+				require.NoError(t, err)
+
+				assert.True(t, v.Equals(decoded), "want %s, got %s", v.GoString(), decoded.GoString())
+			}
+		})
+	}
+}
+
+func TestBase64Gzip(t *testing.T) {
+	t.Parallel()
+
+	res, err := Functions()["base64gzip"].Call([]value.Value{value.String("Hello World")})
+	require.NoError(t, err)
+
+	data, err := base64.StdEncoding.DecodeString(res.AsString())
+	require.NoError(t, err)
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World", string(got))
+}
@@ -0,0 +1,245 @@
+package funcs
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+//nolint:gochecknoglobals // immutable function table
+var pathFunctions = map[string]*value.Function{
+	"basename": {
+		Name:   "basename",
+		Params: []value.Param{{Name: "path", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(filepath.Base(args[0].AsString())), nil
+		},
+	},
+	"dirname": {
+		Name:   "dirname",
+		Params: []value.Param{{Name: "path", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(filepath.Dir(args[0].AsString())), nil
+		},
+	},
+	"pathexpand": {
+		Name:   "pathexpand",
+		Params: []value.Param{{Name: "path", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			p := args[0].AsString()
+			if p != "~" && !strings.HasPrefix(p, "~/") {
+				return value.String(p), nil
+			}
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return value.Null, argErrorf(0, "failed to expand %q: %s", p, err)
+			}
+
+			return value.String(filepath.Join(home, p[1:])), nil
+		},
+	},
+}
+
+func (s *Sandbox) readFile(args []value.Value, i int) ([]byte, error) {
+	p, err := s.resolve(args[i].AsString())
+	if err != nil {
+		return nil, &value.ArgError{Index: i, Err: err}
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, argErrorf(i, "failed to read file %q: %s", args[i].AsString(), unwrapPathError(err))
+	}
+
+	return data, nil
+}
+
+// unwrapPathError returns the cause of a path error, whose message would
+// leak the absolute path of the file.
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+
+	return err
+}
+
+// fileFunction returns a function reading a file and converting its content.
+func (s *Sandbox) fileFunction(name string, convert func([]byte) (value.Value, error)) *value.Function {
+	return &value.Function{
+		Name:   name,
+		Params: []value.Param{{Name: "path", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			data, err := s.readFile(args, 0)
+			if err != nil {
+				return value.Null, err
+			}
+
+			res, err := convert(data)
+			if err != nil {
+				return value.Null, &value.ArgError{Index: 0, Err: err}
+			}
+
+			return res, nil
+		},
+	}
+}
+
+// fileHashFunction returns a function hashing the content of a file and
+// encoding the digest.
+func (s *Sandbox) fileHashFunction(name string, newHash func() hash.Hash, encode func([]byte) string) *value.Function {
+	return s.fileFunction(name, func(data []byte) (value.Value, error) {
+		h := newHash()
+		h.Write(data)
+
+		return value.String(encode(h.Sum(nil))), nil
+	})
+}
+
+func (s *Sandbox) fileExistsImpl(args []value.Value) (value.Value, error) {
+	p, err := s.resolve(args[0].AsString())
+	if err != nil {
+		return value.Null, &value.ArgError{Index: 0, Err: err}
+	}
+
+	info, err := os.Stat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return value.False, nil
+	}
+
+	if err != nil {
+		return value.Null, argErrorf(0, "failed to stat %q: %s", args[0].AsString(), unwrapPathError(err))
+	}
+
+	if info.IsDir() {
+		return value.Null, argErrorf(0, "%q is a directory, not a regular file", args[0].AsString())
+	}
+
+	if !info.Mode().IsRegular() {
+		return value.Null, argErrorf(0, "%q is not a regular file", args[0].AsString())
+	}
+
+	return value.True, nil
+}
+
+// fileSetImpl returns the set of the regular files below a directory
+// matching a pattern, with paths relative to the directory.
+func (s *Sandbox) fileSetImpl(args []value.Value) (value.Value, error) {
+	root, err := s.resolve(args[0].AsString())
+	if err != nil {
+		return value.Null, &value.ArgError{Index: 0, Err: err}
+	}
+
+	pattern := path.Clean(filepath.ToSlash(args[1].AsString()))
+	if err := validateGlob(pattern); err != nil {
+		return value.Null, argErrorf(1, "failed to glob pattern %q: %s", args[1].AsString(), err)
+	}
+
+	var out []value.Value
+
+	err = filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		if rel = filepath.ToSlash(rel); matchGlob(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			out = append(out, value.String(rel))
+		}
+
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return value.Set(), nil
+	}
+
+	if err != nil {
+		return value.Null, argErrorf(0, "failed to list %q: %s", args[0].AsString(), unwrapPathError(err))
+	}
+
+	return value.Set(out...), nil
+}
+
+// validateGlob checks the syntax of each segment of a glob pattern.
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("%w: %s", value.ErrArgument, err)
+		}
+	}
+
+	return nil
+}
+
+// matchGlob reports whether the segments of a path match the segments of a
+// glob pattern, where a "**" segment matches any number of path segments.
+func matchGlob(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+
+	return matchGlob(pattern[1:], segments[1:])
+}
+
+func (s *Sandbox) templateFileImpl(args []value.Value) (value.Value, error) {
+	if args[1].Kind() != value.KindMap {
+		return value.Null, argErrorf(1, "vars must be a map, got %s", args[1].Kind())
+	}
+
+	if s.Template == nil {
+		return value.Null, argErrorf(0, "templates are not supported")
+	}
+
+	data, err := s.readFile(args, 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	if !utf8.Valid(data) {
+		return value.Null, argErrorf(0, "contents of %q are not valid UTF-8", args[0].AsString())
+	}
+
+	vars := make(map[string]value.Value)
+
+	for _, key := range args[1].Keys() {
+		vars[key], _ = args[1].Get(key)
+	}
+
+	return s.Template(args[0].AsString(), string(data), vars)
+}
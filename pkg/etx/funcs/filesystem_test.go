@@ -0,0 +1,149 @@
+package funcs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+}
+
+func TestPathFunctions(t *testing.T) {
+	t.Parallel()
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	runFuncTests(t, []funcTest{
+		{name: "basename", fn: "basename", args: args("foo/bar/baz.txt"), want: value.String("baz.txt")},
+		{name: "basename - directory", fn: "basename", args: args("foo/bar/"), want: value.String("bar")},
+		{name: "dirname", fn: "dirname", args: args("foo/bar/baz.txt"), want: value.String("foo/bar")},
+		{name: "dirname - file", fn: "dirname", args: args("baz.txt"), want: value.String(".")},
+		{name: "pathexpand", fn: "pathexpand", args: args("~/.ssh/id_rsa"), want: value.String(filepath.Join(home, ".ssh/id_rsa"))},
+		{name: "pathexpand - home", fn: "pathexpand", args: args("~"), want: value.String(home)},
+		{name: "pathexpand - unchanged", fn: "pathexpand", args: args("/etc/resolv.conf"), want: value.String("/etc/resolv.conf")},
+	})
+}
+
+func TestSandboxFunctions(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	writeFiles(t, root, map[string]string{
+		"hello.txt":            "Hello World",
+		"binary.dat":           "\xff\xfe",
+		"templates/greet.tmpl": "Hello ${name}!",
+		"dir/a.txt":            "a",
+		"dir/b.json":           "{}",
+		"dir/sub/c.txt":        "c",
+		"dir/sub/deep/d.txt":   "d",
+	})
+	writeFiles(t, outside, map[string]string{"secret.txt": "secret"})
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")))
+	require.NoError(t, os.Symlink("hello.txt", filepath.Join(root, "alias.txt")))
+
+	sandbox := &Sandbox{
+		BaseDir: root,
+		Template: func(path, src string, vars map[string]value.Value) (value.Value, error) {
+			return value.String(path + ": " + src + " " + vars["name"].AsString()), nil
+		},
+	}
+
+	runFuncTestsWith(t, sandbox.Functions(), []funcTest{
+		{name: "abspath", fn: "abspath", args: args("dir/../hello.txt"), want: value.String(filepath.ToSlash(filepath.Join(root, "hello.txt")))},
+		{name: "abspath - absolute", fn: "abspath", args: args("/etc"), want: value.String("/etc")},
+		{name: "file", fn: "file", args: args("hello.txt"), want: value.String("Hello World")},
+		{name: "file - absolute", fn: "file", args: args(filepath.Join(root, "hello.txt")), want: value.String("Hello World")},
+		{name: "file - symlink", fn: "file", args: args("alias.txt"), want: value.String("Hello World")},
+		{name: "file - not UTF-8", fn: "file", args: args("binary.dat"), wantErr: "contents are not valid UTF-8"},
+		{name: "file - missing", fn: "file", args: args("missing.txt"), wantErr: `failed to read file "missing.txt": no such file or directory`},
+		{name: "file - parent", fn: "file", args: args("../secret.txt"), wantErr: `path "../secret.txt" is outside of the base directory`},
+		{name: "file - outside", fn: "file", args: args(filepath.Join(outside, "secret.txt")), wantErr: "is outside of the base directory"},
+		{name: "file - escaping symlink", fn: "file", args: args("link.txt"), wantErr: `path "link.txt" is outside of the base directory`},
+		{name: "filebase64", fn: "filebase64", args: args("binary.dat"), want: value.String("//4=")},
+		{name: "filemd5", fn: "filemd5", args: args("hello.txt"), want: value.String("b10a8db164e0754105b7a99be72e3fe5")},
+		{name: "filesha1", fn: "filesha1", args: args("hello.txt"), want: value.String("0a4d55a8d778e5022fab701977c5d840bbc486d0")},
+		{name: "filesha256", fn: "filesha256", args: args("hello.txt"), want: value.String("a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e")},
+		{
+			name: "filesha512",
+			fn:   "filesha512",
+			args: args("hello.txt"),
+			want: value.String("2c74fd17edafd80e8447b0d46741ee243b7eb74dd2149a0ab1b9246fb30382f27e853d8585719e0e67cbda0daa8f51671064615d645ae27acb15bfb1447f459b"),
+		},
+		{name: "filebase64sha256", fn: "filebase64sha256", args: args("hello.txt"), want: value.String("pZGm1Av0IEBKARczz7exkNYsZb8LzaMrV7J32a2fFG4=")},
+		{
+			name: "filebase64sha512",
+			fn:   "filebase64sha512",
+			args: args("hello.txt"),
+			want: value.String("LHT9F+2v2A6ER7DUZ0HuJDt+t03SFJoKsbkkb7MDgvJ+hT2FhXGeDmfL2g2qj1FnEGRhXWRa4nrLFb+xRH9Fmw=="),
+		},
+		{name: "fileexists", fn: "fileexists", args: args("hello.txt"), want: value.True},
+		{name: "fileexists - missing", fn: "fileexists", args: args("missing.txt"), want: value.False},
+		{name: "fileexists - directory", fn: "fileexists", args: args("dir"), wantErr: `"dir" is a directory, not a regular file`},
+		{name: "fileexists - outside", fn: "fileexists", args: args("../hello.txt"), wantErr: "is outside of the base directory"},
+		{name: "fileset", fn: "fileset", args: args("dir", "*.txt"), want: value.Set(value.String("a.txt"))},
+		{
+			name: "fileset - recursive",
+			fn:   "fileset",
+			args: args("dir", "**/*.txt"),
+			want: value.Set(value.String("a.txt"), value.String("sub/c.txt"), value.String("sub/deep/d.txt")),
+		},
+		{name: "fileset - nested", fn: "fileset", args: args(".", "dir/sub/*"), want: value.Set(value.String("dir/sub/c.txt"))},
+		{name: "fileset - character class", fn: "fileset", args: args("dir", "?.[jt]*"), want: value.Set(value.String("a.txt"), value.String("b.json"))},
+		{name: "fileset - missing directory", fn: "fileset", args: args("missing", "*"), want: value.Set()},
+		{name: "fileset - bad pattern", fn: "fileset", args: args("dir", "[a"), wantErr: `failed to glob pattern "[a"`},
+		{name: "fileset - outside", fn: "fileset", args: args("..", "*"), wantErr: "is outside of the base directory"},
+		{
+			name: "templatefile",
+			fn:   "templatefile",
+			args: args("templates/greet.tmpl", value.Map(map[string]value.Value{"name": value.String("etx")})),
+			want: value.String("templates/greet.tmpl: Hello ${name}! etx"),
+		},
+		{name: "templatefile - vars", fn: "templatefile", args: args("templates/greet.tmpl", "etx"), wantErr: "vars must be a map, got string"},
+		{name: "templatefile - missing", fn: "templatefile", args: args("missing.tmpl", value.Map(nil)), wantErr: "failed to read file"},
+	})
+}
+
+func TestSandboxFunctions_NoBaseDir(t *testing.T) {
+	t.Parallel()
+
+	fns := (&Sandbox{}).Functions()
+
+	for _, name := range []string{"abspath", "file", "fileexists", "filemd5"} {
+		_, err := fns[name].Call([]value.Value{value.String("hello.txt")})
+		assert.ErrorIs(t, err, ErrNoBaseDir, name)
+	}
+}
+
+func TestSandbox_Function(t *testing.T) {
+	t.Parallel()
+
+	sandbox := &Sandbox{}
+
+	fn, ok := sandbox.Function("file")
+	require.True(t, ok)
+	assert.Equal(t, "file", fn.Name)
+
+	_, ok = sandbox.Function("upper")
+	assert.False(t, ok)
+
+	for name, fn := range sandbox.Functions() {
+		assert.Equal(t, name, fn.Name)
+		assert.NotContains(t, Functions(), name)
+	}
+}
@@ -0,0 +1,216 @@
+package funcs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// formatVerb is a parsed verb of a format string.
+type formatVerb struct {
+	raw    string
+	offset int
+	flags  string
+	width  string
+	prec   string
+	verb   rune
+}
+
+func (v formatVerb) spec(verb rune) string {
+	return "%" + v.flags + v.width + v.prec + string(verb)
+}
+
+// formatString produces a string from a format string and arguments, in the
+// same way as the Terraform format function.
+//
+// Errors are reported against the arguments of the format function, where
+// the format string is the first argument.
+func formatString(format string, args []value.Value) (string, error) {
+	var sb strings.Builder
+
+	next, used := 0, 0
+
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			i++
+
+			continue
+		}
+
+		if strings.HasPrefix(format[i:], "%%") {
+			sb.WriteByte('%')
+			i += 2
+
+			continue
+		}
+
+		verb, index, end, err := parseFormatVerb(format, i)
+		if err != nil {
+			return "", err
+		}
+
+		if index > 0 {
+			next = index - 1
+		}
+
+		if next >= len(args) {
+			return "", argErrorf(0, "not enough arguments for %q at %d: need index %d but have %d total",
+				format[i:end], i, next+1, len(args))
+		}
+
+		// The format string is the first argument of the format function.
+		s, err := verb.format(next+1, args[next])
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(s)
+
+		if next++; next > used {
+			used = next
+		}
+
+		i = end
+	}
+
+	switch {
+	case used < len(args) && used == 0:
+		return "", argErrorf(1, "too many arguments; no verbs in format string")
+	case used < len(args):
+		return "", argErrorf(used+1, "too many arguments; only %d used by format string", used)
+	default:
+		return sb.String(), nil
+	}
+}
+
+// parseFormatVerb parses the verb starting at offset i of the format string,
+// of the form %[index]flags width.precision verb.
+func parseFormatVerb(format string, i int) (verb formatVerb, index int, end int, err error) {
+	verb.offset = i
+	pos := i + 1
+
+	if pos < len(format) && format[pos] == '[' {
+		closing := strings.IndexByte(format[pos:], ']')
+		if closing < 0 {
+			return verb, 0, 0, argErrorf(0, "unterminated argument index at %d", i)
+		}
+
+		index, err = strconv.Atoi(format[pos+1 : pos+closing])
+		if err != nil || index < 1 {
+			return verb, 0, 0, argErrorf(0, "invalid argument index %q at %d", format[pos:pos+closing+1], i)
+		}
+
+		pos += closing + 1
+	}
+
+	start := pos
+	for pos < len(format) && strings.IndexByte("+-# 0", format[pos]) >= 0 {
+		pos++
+	}
+
+	verb.flags = format[start:pos]
+
+	start = pos
+	for pos < len(format) && isDigit(format[pos]) {
+		pos++
+	}
+
+	verb.width = format[start:pos]
+
+	if pos < len(format) && format[pos] == '.' {
+		start = pos
+		pos++
+
+		for pos < len(format) && isDigit(format[pos]) {
+			pos++
+		}
+
+		verb.prec = format[start:pos]
+	}
+
+	if pos >= len(format) {
+		return verb, 0, 0, argErrorf(0, "unterminated format verb at %d", i)
+	}
+
+	r, size := utf8.DecodeRuneInString(format[pos:])
+	verb.verb = r
+	verb.raw = format[i : pos+size]
+
+	return verb, index, pos + size, nil
+}
+
+// format formats the argument at index i of the format function.
+func (v formatVerb) format(i int, arg value.Value) (string, error) {
+	if arg.IsNull() && v.verb != 'v' {
+		return "", v.unsupported(i, "null value cannot be formatted")
+	}
+
+	switch v.verb {
+	case 'v':
+		return v.formatDefault(i, arg)
+	case 't':
+		if arg.Kind() != value.KindBool {
+			return "", v.unsupported(i, "bool required")
+		}
+
+		return fmt.Sprintf(v.spec('t'), arg.AsBool()), nil
+	case 'b', 'd', 'o', 'x', 'X':
+		f, ok := toNumber(arg)
+		if !ok || !f.IsInt() {
+			return "", v.unsupported(i, "integer required")
+		}
+
+		n, _ := f.Int(nil)
+
+		return fmt.Sprintf(v.spec(v.verb), n), nil
+	case 'e', 'E', 'f', 'g', 'G':
+		f, ok := toNumber(arg)
+		if !ok {
+			return "", v.unsupported(i, "number required")
+		}
+
+		return fmt.Sprintf(v.spec(v.verb), f), nil
+	case 's', 'q':
+		s, ok := toString(arg)
+		if !ok {
+			return "", v.unsupported(i, "string required")
+		}
+
+		return fmt.Sprintf(v.spec(v.verb), s), nil
+	default:
+		return "", argErrorf(0, "unrecognized format character %q at %d", v.verb, v.offset)
+	}
+}
+
+// formatDefault formats primitive values as %s and other values as JSON.
+// The "#" flag formats every value as JSON.
+func (v formatVerb) formatDefault(i int, arg value.Value) (string, error) {
+	spec := formatVerb{flags: strings.ReplaceAll(v.flags, "#", ""), width: v.width, prec: v.prec}.spec('s')
+
+	if s, ok := toString(arg); ok && !strings.Contains(v.flags, "#") {
+		return fmt.Sprintf(spec, s), nil
+	}
+
+	if arg.Kind() == value.KindFunction {
+		return "", v.unsupported(i, "function value cannot be formatted")
+	}
+
+	data, err := arg.MarshalJSON()
+	if err != nil {
+		return "", v.unsupported(i, err.Error())
+	}
+
+	return fmt.Sprintf(spec, string(data)), nil
+}
+
+func (v formatVerb) unsupported(i int, reason string) error {
+	return argErrorf(i, "unsupported value for %q at %d: %s", v.raw, v.offset, reason)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,92 @@
+// Package funcs implements the standard library of functions available to
+// etx expressions, compatible with the Terraform functions of the same name.
+package funcs
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const decimalBase = 10
+
+// Functions returns the functions of the library indexed by name.
+//
+// The returned map is a copy and can be extended by the caller.
+func Functions() map[string]*value.Function {
+	tables := []map[string]*value.Function{
+		numberFunctions,
+		stringFunctions,
+		collectionFunctions,
+		conversionFunctions,
+		encodingFunctions,
+		cryptoFunctions,
+		networkFunctions,
+		pathFunctions,
+		timeFunctions,
+	}
+
+	out := make(map[string]*value.Function)
+
+	for _, table := range tables {
+		for name, fn := range table {
+			out[name] = fn
+		}
+	}
+
+	return out
+}
+
+// /////////////////////////////////////
+
+// argErrorf returns an invalid argument error for the argument at index i.
+func argErrorf(i int, format string, a ...any) error {
+	return &value.ArgError{
+		Index: i,
+		Err:   fmt.Errorf("%w: %s", value.ErrArgument, fmt.Sprintf(format, a...)),
+	}
+}
+
+// intArg returns the argument at index i as an int.
+func intArg(args []value.Value, i int, name string) (int, error) {
+	n, err := args[i].AsInt()
+	if err != nil {
+		return 0, argErrorf(i, "%s must be a whole number, got %s", name, value.FormatNumber(args[i].AsBigFloat()))
+	}
+
+	return n, nil
+}
+
+// toString converts a primitive value to its string form.
+func toString(v value.Value) (string, bool) {
+	switch v.Kind() {
+	case value.KindString:
+		return v.AsString(), true
+	case value.KindNumber:
+		return value.FormatNumber(v.AsBigFloat()), true
+	case value.KindBool:
+		return strconv.FormatBool(v.AsBool()), true
+	default:
+		return "", false
+	}
+}
+
+// toNumber converts a number or a numeric string to a number.
+func toNumber(v value.Value) (*big.Float, bool) {
+	switch v.Kind() {
+	case value.KindNumber:
+		return v.AsBigFloat(), true
+	case value.KindString:
+		f, _, err := big.ParseFloat(v.AsString(), decimalBase, value.NumberPrecision, big.ToNearestEven)
+
+		return f, err == nil
+	default:
+		return nil, false
+	}
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(value.NumberPrecision)
+}
@@ -0,0 +1,96 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+type funcTest struct {
+	name    string
+	fn      string
+	args    []value.Value
+	want    value.Value
+	wantErr string
+}
+
+func runFuncTests(t *testing.T, tests []funcTest) {
+	t.Helper()
+
+	runFuncTestsWith(t, Functions(), tests)
+}
+
+func runFuncTestsWith(t *testing.T, fns map[string]*value.Function, tests []funcTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fn, ok := fns[tt.fn]
+			require.True(t, ok, "unknown function %q", tt.fn)
+
+			got, err := fn.Call(tt.args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equals(got), "want %s, got %s", tt.want.GoString(), got.GoString())
+		})
+	}
+}
+
+func strs(items ...string) value.Value {
+	out := make([]value.Value, 0, len(items))
+	for _, s := range items {
+		out = append(out, value.String(s))
+	}
+
+	return value.List(out...)
+}
+
+func args(items ...any) []value.Value {
+	out := make([]value.Value, 0, len(items))
+
+	for _, item := range items {
+		switch item := item.(type) {
+		case value.Value:
+			out = append(out, item)
+		case string:
+			out = append(out, value.String(item))
+		case int:
+			out = append(out, value.Int(int64(item)))
+		case float64:
+			out = append(out, value.Float(item))
+		case bool:
+			out = append(out, value.Bool(item))
+		case nil:
+			out = append(out, value.Null)
+		default:
+			panic("unsupported argument")
+		}
+	}
+
+	return out
+}
+
+func TestFunctions(t *testing.T) {
+	t.Parallel()
+
+	fns := Functions()
+	for name, fn := range fns {
+		assert.Equal(t, name, fn.Name)
+	}
+
+	delete(fns, "abs")
+	assert.Contains(t, Functions(), "abs")
+}
@@ -0,0 +1,229 @@
+package funcs
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// maxPrefixExtension is the maximum number of bits a prefix can be extended
+// by in a single call, for portability with the Terraform functions.
+const maxPrefixExtension = 32
+
+//nolint:gochecknoglobals // immutable function table
+var networkFunctions = map[string]*value.Function{
+	"cidrhost": {
+		Name: "cidrhost",
+		Params: []value.Param{
+			{Name: "prefix", Type: value.TypeString},
+			{Name: "hostnum", Type: value.TypeNumber},
+		},
+		Impl: cidrHostImpl,
+	},
+	"cidrnetmask": {
+		Name:   "cidrnetmask",
+		Params: []value.Param{{Name: "prefix", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			network, err := cidrArg(args, 0)
+			if err != nil {
+				return value.Null, err
+			}
+
+			if network.bits != 8*net.IPv4len {
+				return value.Null, argErrorf(0, "IPv6 addresses cannot have a netmask: %s", args[0].AsString())
+			}
+
+			return value.String(net.IP(net.CIDRMask(network.prefix, network.bits)).String()), nil
+		},
+	},
+	"cidrsubnet": {
+		Name: "cidrsubnet",
+		Params: []value.Param{
+			{Name: "prefix", Type: value.TypeString},
+			{Name: "newbits", Type: value.TypeNumber},
+			{Name: "netnum", Type: value.TypeNumber},
+		},
+		Impl: cidrSubnetImpl,
+	},
+	"cidrsubnets": {
+		Name:     "cidrsubnets",
+		Params:   []value.Param{{Name: "prefix", Type: value.TypeString}},
+		VarParam: &value.Param{Name: "newbits", Type: value.TypeNumber},
+		Impl:     cidrSubnetsImpl,
+	},
+}
+
+// ipNetwork is an IPv4 or IPv6 network, with its address as an integer.
+type ipNetwork struct {
+	start  *big.Int
+	prefix int
+	bits   int
+}
+
+func cidrArg(args []value.Value, i int) (ipNetwork, error) {
+	_, network, err := net.ParseCIDR(args[i].AsString())
+	if err != nil {
+		return ipNetwork{}, argErrorf(i, "invalid CIDR expression: %s", err)
+	}
+
+	prefix, bits := network.Mask.Size()
+
+	return ipNetwork{start: new(big.Int).SetBytes(network.IP), prefix: prefix, bits: bits}, nil
+}
+
+// size returns the number of addresses of a network with the given prefix
+// length.
+func (n ipNetwork) size(prefix int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n.bits-prefix))
+}
+
+func (n ipNetwork) ip(addr *big.Int) net.IP {
+	ip := make(net.IP, n.bits/8) //nolint:gomnd // bits per byte
+
+	return addr.FillBytes(ip)
+}
+
+func (n ipNetwork) subnet(start *big.Int, prefix int) string {
+	return fmt.Sprintf("%s/%d", n.ip(start), prefix)
+}
+
+func (n ipNetwork) protocol() string {
+	if n.bits == 8*net.IPv4len {
+		return "IPv4"
+	}
+
+	return "IPv6"
+}
+
+func cidrHostImpl(args []value.Value) (value.Value, error) {
+	network, err := cidrArg(args, 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	hostnum, err := args[1].AsBigInt()
+	if err != nil {
+		return value.Null, argErrorf(1, "hostnum must be a whole number")
+	}
+
+	size := network.size(network.prefix)
+
+	// Negative host numbers count back from the end of the range.
+	host := new(big.Int).Set(hostnum)
+	if host.Sign() < 0 {
+		host.Add(host, size)
+	}
+
+	if host.Sign() < 0 || host.Cmp(size) >= 0 {
+		return value.Null, argErrorf(1, "prefix of %d does not accommodate a host numbered %s", network.prefix, hostnum)
+	}
+
+	return value.String(network.ip(host.Add(host, network.start)).String()), nil
+}
+
+func cidrSubnetImpl(args []value.Value) (value.Value, error) {
+	network, err := cidrArg(args, 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	newbits, err := intArg(args, 1, "newbits")
+	if err != nil {
+		return value.Null, err
+	}
+
+	netnum, err := args[2].AsBigInt()
+	if err != nil {
+		return value.Null, argErrorf(2, "netnum must be a whole number") //nolint:gomnd // third argument
+	}
+
+	switch {
+	case newbits < 0:
+		return value.Null, argErrorf(1, "newbits must not be negative")
+	case newbits > maxPrefixExtension:
+		return value.Null, argErrorf(1, "may not extend prefix by more than %d bits", maxPrefixExtension)
+	case network.prefix+newbits > network.bits:
+		return value.Null, argErrorf(1, "insufficient address space to extend prefix of %d by %d", network.prefix, newbits)
+	}
+
+	prefix := network.prefix + newbits
+
+	if netnum.Sign() < 0 || netnum.Cmp(new(big.Int).Lsh(big.NewInt(1), uint(newbits))) >= 0 {
+		return value.Null, argErrorf(2, "prefix extension of %d does not accommodate a subnet numbered %s", newbits, netnum) //nolint:gomnd // third argument
+	}
+
+	start := new(big.Int).Mul(netnum, network.size(prefix))
+
+	return value.String(network.subnet(start.Add(start, network.start), prefix)), nil
+}
+
+// cidrSubnetsImpl allocates consecutive subnets of the given sizes, each one
+// aligned on its own size.
+func cidrSubnetsImpl(args []value.Value) (value.Value, error) {
+	network, err := cidrArg(args, 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	end := new(big.Int).Add(network.start, network.size(network.prefix))
+	out := make([]value.Value, 0, len(args)-1)
+
+	// The first subnet is reported as the one preceding the network.
+	cursor := new(big.Int).Set(network.start)
+	current := ""
+
+	for i := 1; i < len(args); i++ {
+		newbits, err := intArg(args, i, "newbits")
+		if err != nil {
+			return value.Null, err
+		}
+
+		switch {
+		case newbits < 1:
+			return value.Null, argErrorf(i, "must extend prefix by at least one bit")
+		case newbits > maxPrefixExtension:
+			return value.Null, argErrorf(i, "may not extend prefix by more than %d bits", maxPrefixExtension)
+		case network.prefix+newbits > network.bits:
+			return value.Null, argErrorf(i, "would extend prefix to %d bits, which is too long for an %s address",
+				network.prefix+newbits, network.protocol())
+		}
+
+		prefix := network.prefix + newbits
+		size := network.size(prefix)
+
+		if i == 1 {
+			current = network.subnet(previousSubnet(network, size), prefix)
+		}
+
+		// Align the cursor on the size of the subnet.
+		start := new(big.Int).Add(cursor, size)
+		start.Sub(start, big.NewInt(1))
+		start.Div(start, size)
+		start.Mul(start, size)
+
+		next := new(big.Int).Add(start, size)
+		if next.Cmp(end) > 0 {
+			return value.Null, argErrorf(i, "not enough remaining address space for a subnet with a prefix of %d bits after %s", prefix, current)
+		}
+
+		current = network.subnet(start, prefix)
+		cursor = next
+
+		out = append(out, value.String(current))
+	}
+
+	return value.List(out...), nil
+}
+
+// previousSubnet returns the start of the subnet of the given size just
+// before the network, wrapping around the address space.
+func previousSubnet(network ipNetwork, size *big.Int) *big.Int {
+	total := network.size(0)
+
+	prev := new(big.Int).Sub(network.start, big.NewInt(1))
+	prev.Mod(prev, total)
+
+	return prev.Sub(prev, new(big.Int).Mod(prev, size))
+}
@@ -0,0 +1,56 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestNetworkFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "cidrhost", fn: "cidrhost", args: args("10.12.112.0/20", 16), want: value.String("10.12.112.16")},
+		{name: "cidrhost - next block", fn: "cidrhost", args: args("10.12.112.0/20", 268), want: value.String("10.12.113.12")},
+		{name: "cidrhost - negative", fn: "cidrhost", args: args("10.12.112.0/20", -1), want: value.String("10.12.127.255")},
+		{name: "cidrhost - IPv6", fn: "cidrhost", args: args("fd00:fd12:3456:7890:00a2::/72", 34), want: value.String("fd00:fd12:3456:7890::22")},
+		{name: "cidrhost - too large", fn: "cidrhost", args: args("10.0.0.0/30", 4), wantErr: "prefix of 30 does not accommodate a host numbered 4"},
+		{name: "cidrhost - invalid prefix", fn: "cidrhost", args: args("10.0.0.0", 1), wantErr: "invalid CIDR expression"},
+		{name: "cidrhost - fraction", fn: "cidrhost", args: args("10.0.0.0/8", 1.5), wantErr: "hostnum must be a whole number"},
+		{name: "cidrnetmask", fn: "cidrnetmask", args: args("172.16.0.0/12"), want: value.String("255.240.0.0")},
+		{name: "cidrnetmask - IPv6", fn: "cidrnetmask", args: args("fd00::/8"), wantErr: "IPv6 addresses cannot have a netmask: fd00::/8"},
+		{name: "cidrsubnet", fn: "cidrsubnet", args: args("172.16.0.0/12", 4, 2), want: value.String("172.18.0.0/16")},
+		{name: "cidrsubnet - last", fn: "cidrsubnet", args: args("10.1.2.0/24", 4, 15), want: value.String("10.1.2.240/28")},
+		{name: "cidrsubnet - IPv6", fn: "cidrsubnet", args: args("fd00:fd12:3456:7890::/56", 16, 162), want: value.String("fd00:fd12:3456:7800:a200::/72")},
+		{name: "cidrsubnet - no extension", fn: "cidrsubnet", args: args("10.0.0.0/8", 0, 0), want: value.String("10.0.0.0/8")},
+		{name: "cidrsubnet - too long", fn: "cidrsubnet", args: args("10.0.0.0/30", 3, 0), wantErr: "insufficient address space to extend prefix of 30 by 3"},
+		{name: "cidrsubnet - too many bits", fn: "cidrsubnet", args: args("fd00::/8", 33, 0), wantErr: "may not extend prefix by more than 32 bits"},
+		{name: "cidrsubnet - netnum", fn: "cidrsubnet", args: args("10.1.2.0/24", 4, 16), wantErr: "prefix extension of 4 does not accommodate a subnet numbered 16"},
+		{
+			name: "cidrsubnets",
+			fn:   "cidrsubnets",
+			args: args("10.1.0.0/16", 4, 4, 8, 4),
+			want: strs("10.1.0.0/20", "10.1.16.0/20", "10.1.32.0/24", "10.1.48.0/20"),
+		},
+		{
+			name: "cidrsubnets - IPv6",
+			fn:   "cidrsubnets",
+			args: args("fd00:fd12:3456:7890::/56", 16, 16, 16, 32),
+			want: strs("fd00:fd12:3456:7800::/72", "fd00:fd12:3456:7800:100::/72", "fd00:fd12:3456:7800:200::/72", "fd00:fd12:3456:7800:300::/88"),
+		},
+		{name: "cidrsubnets - none", fn: "cidrsubnets", args: args("10.1.0.0/16"), want: strs()},
+		{name: "cidrsubnets - zero bits", fn: "cidrsubnets", args: args("10.1.0.0/16", 0), wantErr: "must extend prefix by at least one bit"},
+		{
+			name:    "cidrsubnets - too long",
+			fn:      "cidrsubnets",
+			args:    args("10.1.0.0/16", 17),
+			wantErr: "would extend prefix to 33 bits, which is too long for an IPv4 address",
+		},
+		{
+			name:    "cidrsubnets - exhausted",
+			fn:      "cidrsubnets",
+			args:    args("10.1.0.0/16", 1, 1, 1),
+			wantErr: "not enough remaining address space for a subnet with a prefix of 17 bits after 10.1.128.0/17",
+		},
+	})
+}
@@ -0,0 +1,219 @@
+package funcs
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+const (
+	minParseIntBase = 2
+	maxParseIntBase = 62
+)
+
+//nolint:gochecknoglobals // immutable function table
+var numberFunctions = map[string]*value.Function{
+	"abs": {
+		Name:   "abs",
+		Params: []value.Param{{Name: "num", Type: value.TypeNumber}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.Number(newFloat().Abs(args[0].AsBigFloat())), nil
+		},
+	},
+	"ceil": {
+		Name:   "ceil",
+		Params: []value.Param{{Name: "num", Type: value.TypeNumber}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.Number(roundNumber(args[0].AsBigFloat(), 1)), nil
+		},
+	},
+	"floor": {
+		Name:   "floor",
+		Params: []value.Param{{Name: "num", Type: value.TypeNumber}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.Number(roundNumber(args[0].AsBigFloat(), -1)), nil
+		},
+	},
+	"log": {
+		Name: "log",
+		Params: []value.Param{
+			{Name: "num", Type: value.TypeNumber},
+			{Name: "base", Type: value.TypeNumber},
+		},
+		Impl: logImpl,
+	},
+	"max": {
+		Name:     "max",
+		VarParam: &value.Param{Name: "numbers", Type: value.TypeNumber},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return extremum(args, 1)
+		},
+	},
+	"min": {
+		Name:     "min",
+		VarParam: &value.Param{Name: "numbers", Type: value.TypeNumber},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return extremum(args, -1)
+		},
+	},
+	"parseint": {
+		Name: "parseint",
+		Params: []value.Param{
+			{Name: "number", Type: value.TypeString},
+			{Name: "base", Type: value.TypeNumber},
+		},
+		Impl: parseIntImpl,
+	},
+	"pow": {
+		Name: "pow",
+		Params: []value.Param{
+			{Name: "num", Type: value.TypeNumber},
+			{Name: "power", Type: value.TypeNumber},
+		},
+		Impl: powImpl,
+	},
+	"signum": {
+		Name:   "signum",
+		Params: []value.Param{{Name: "num", Type: value.TypeNumber}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.Int(int64(args[0].AsBigFloat().Sign())), nil
+		},
+	},
+}
+
+// roundNumber rounds f to an integer, towards positive infinity when dir
+// is positive and towards negative infinity otherwise.
+func roundNumber(f *big.Float, dir int) *big.Float {
+	if f.IsInt() || f.IsInf() {
+		return f
+	}
+
+	i, _ := f.Int(nil)
+
+	switch {
+	case dir > 0 && f.Sign() > 0:
+		i.Add(i, big.NewInt(1))
+	case dir < 0 && f.Sign() < 0:
+		i.Sub(i, big.NewInt(1))
+	}
+
+	return newFloat().SetInt(i)
+}
+
+func extremum(args []value.Value, dir int) (value.Value, error) {
+	if len(args) == 0 {
+		return value.Null, argErrorf(0, "must pass at least one number")
+	}
+
+	res := args[0].AsBigFloat()
+
+	for _, arg := range args[1:] {
+		if f := arg.AsBigFloat(); f.Cmp(res)*dir > 0 {
+			res = f
+		}
+	}
+
+	return value.Number(res), nil
+}
+
+func logImpl(args []value.Value) (value.Value, error) {
+	num, base := args[0].AsBigFloat(), args[1].AsBigFloat()
+
+	if num.Sign() <= 0 {
+		return value.Null, argErrorf(0, "logarithm of %s is not a finite number", value.FormatNumber(num))
+	}
+
+	if base.Sign() <= 0 || base.Cmp(big.NewFloat(1)) == 0 {
+		return value.Null, argErrorf(1, "base %s is not a valid logarithm base", value.FormatNumber(base))
+	}
+
+	return value.Float(naturalLog(num) / naturalLog(base)), nil
+}
+
+// naturalLog returns the natural logarithm of a positive number, including
+// numbers out of the float64 range.
+func naturalLog(f *big.Float) float64 {
+	mant := new(big.Float)
+	exp := f.MantExp(mant)
+	m, _ := mant.Float64()
+
+	return math.Log(m) + float64(exp)*math.Ln2
+}
+
+func parseIntImpl(args []value.Value) (value.Value, error) {
+	base, err := intArg(args, 1, "base")
+	if err != nil {
+		return value.Null, err
+	}
+
+	if base < minParseIntBase || base > maxParseIntBase {
+		return value.Null, argErrorf(1, "base must be a whole number between %d and %d inclusive", minParseIntBase, maxParseIntBase)
+	}
+
+	// big.Int accepts a leading "+" sign that Terraform rejects.
+	s := args[0].AsString()
+	if strings.HasPrefix(s, "+") {
+		return value.Null, argErrorf(0, "cannot parse %q as a base %d integer", s, base)
+	}
+
+	i, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return value.Null, argErrorf(0, "cannot parse %q as a base %d integer", s, base)
+	}
+
+	return value.Number(newFloat().SetInt(i)), nil
+}
+
+func powImpl(args []value.Value) (value.Value, error) {
+	num, power := args[0].AsBigFloat(), args[1]
+
+	// Integer powers are computed exactly by repeated squaring.
+	if n, err := power.AsInt(); err == nil {
+		if n < 0 && num.Sign() == 0 {
+			return value.Null, argErrorf(0, "%s to the power of %d is not a finite number", value.FormatNumber(num), n)
+		}
+
+		res := integerPower(num, n)
+		if res.IsInf() {
+			return value.Null, argErrorf(1, "%s to the power of %d is out of range", value.FormatNumber(num), n)
+		}
+
+		return value.Number(res), nil
+	}
+
+	x, _ := num.Float64()
+	y, _ := power.AsBigFloat().Float64()
+
+	res := math.Pow(x, y)
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return value.Null, argErrorf(1, "%s to the power of %s is not a finite number", value.FormatNumber(num), value.FormatNumber(power.AsBigFloat()))
+	}
+
+	return value.Float(res), nil
+}
+
+func integerPower(x *big.Float, n int) *big.Float {
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+
+	res := newFloat().SetInt64(1)
+	sq := newFloat().Set(x)
+
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res.Mul(res, sq)
+		}
+
+		sq.Mul(sq, sq)
+	}
+
+	if negative {
+		res.Quo(newFloat().SetInt64(1), res)
+	}
+
+	return res
+}
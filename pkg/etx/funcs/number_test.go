@@ -0,0 +1,55 @@
+package funcs
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestNumberFunctions(t *testing.T) {
+	t.Parallel()
+
+	huge, _, _ := big.ParseFloat("1e100", 10, value.NumberPrecision, big.ToNearestEven)
+
+	runFuncTests(t, []funcTest{
+		{name: "abs", fn: "abs", args: args(-23), want: value.Int(23)},
+		{name: "abs - fraction", fn: "abs", args: args(-12.4), want: value.Float(12.4)},
+		{name: "abs - zero", fn: "abs", args: args(0), want: value.Int(0)},
+		{name: "abs - string", fn: "abs", args: args("1"), wantErr: `argument "num" must be a number`},
+		{name: "ceil", fn: "ceil", args: args(5.1), want: value.Int(6)},
+		{name: "ceil - integer", fn: "ceil", args: args(5), want: value.Int(5)},
+		{name: "ceil - negative", fn: "ceil", args: args(-5.1), want: value.Int(-5)},
+		{name: "floor", fn: "floor", args: args(4.9), want: value.Int(4)},
+		{name: "floor - negative", fn: "floor", args: args(-4.1), want: value.Int(-5)},
+		{name: "floor - huge", fn: "floor", args: args(value.Number(huge)), want: value.Number(huge)},
+		{name: "log", fn: "log", args: args(16, 2), want: value.Int(4)},
+		{name: "log - huge", fn: "log", args: args(value.Number(huge), 10), want: value.Int(100)},
+		{name: "log - zero", fn: "log", args: args(0, 10), wantErr: "logarithm of 0 is not a finite number"},
+		{name: "log - base one", fn: "log", args: args(10, 1), wantErr: "base 1 is not a valid logarithm base"},
+		{name: "max", fn: "max", args: args(12, 54, 3), want: value.Int(54)},
+		{name: "max - no argument", fn: "max", args: args(), wantErr: "must pass at least one number"},
+		{name: "min", fn: "min", args: args(12, 54, 3), want: value.Int(3)},
+		{name: "min - fraction", fn: "min", args: args(1, -0.5), want: value.Float(-0.5)},
+		{name: "parseint", fn: "parseint", args: args("100", 10), want: value.Int(100)},
+		{name: "parseint - hex", fn: "parseint", args: args("FF", 16), want: value.Int(255)},
+		{name: "parseint - negative hex", fn: "parseint", args: args("-12AF", 16), want: value.Int(-4783)},
+		{name: "parseint - binary", fn: "parseint", args: args("1011111011101111", 2), want: value.Int(48879)},
+		{name: "parseint - base 62", fn: "parseint", args: args("aA", 62), want: value.Int(10*62 + 36)},
+		{name: "parseint - invalid digit", fn: "parseint", args: args("12", 2), wantErr: `cannot parse "12" as a base 2 integer`},
+		{name: "parseint - plus sign", fn: "parseint", args: args("+1", 10), wantErr: `cannot parse "+1" as a base 10 integer`},
+		{name: "parseint - base too small", fn: "parseint", args: args("1", 1), wantErr: "base must be a whole number between 2 and 62 inclusive"},
+		{name: "parseint - base too large", fn: "parseint", args: args("1", 63), wantErr: "base must be a whole number between 2 and 62 inclusive"},
+		{name: "parseint - fractional base", fn: "parseint", args: args("1", 2.5), wantErr: "base must be a whole number, got 2.5"},
+		{name: "pow", fn: "pow", args: args(3, 2), want: value.Int(9)},
+		{name: "pow - zero", fn: "pow", args: args(4, 0), want: value.Int(1)},
+		{name: "pow - negative", fn: "pow", args: args(2, -2), want: value.Float(0.25)},
+		{name: "pow - exact", fn: "pow", args: args(10, 100), want: value.Number(huge)},
+		{name: "pow - fraction", fn: "pow", args: args(4, 0.5), want: value.Int(2)},
+		{name: "pow - zero to a negative power", fn: "pow", args: args(0, -1), wantErr: "0 to the power of -1 is not a finite number"},
+		{name: "pow - root of a negative number", fn: "pow", args: args(-4, 0.5), wantErr: "-4 to the power of 0.5 is not a finite number"},
+		{name: "signum", fn: "signum", args: args(-13), want: value.Int(-1)},
+		{name: "signum - zero", fn: "signum", args: args(0), want: value.Int(0)},
+		{name: "signum - positive", fn: "signum", args: args(0.5), want: value.Int(1)},
+	})
+}
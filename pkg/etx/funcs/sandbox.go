@@ -0,0 +1,206 @@
+package funcs
+
+import (
+	"crypto/md5"  //nolint:gosec // md5 is required for compatibility
+	"crypto/sha1" //nolint:gosec // sha1 is required for compatibility
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// ErrNoBaseDir is returned by the filesystem functions when the sandbox has
+// no base directory.
+var ErrNoBaseDir = errors.New("filesystem access is disabled")
+
+// Sandbox is the environment of the functions accessing the filesystem or
+// the clock.
+type Sandbox struct {
+	// BaseDir is the directory the filesystem functions are restricted to.
+	// Relative paths are resolved from it. Without a base directory, the
+	// filesystem functions fail.
+	BaseDir string
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	// Template renders the content of a template file with the given
+	// variables. Without it, templatefile fails.
+	Template func(path, src string, vars map[string]value.Value) (value.Value, error)
+}
+
+// sandboxFunctions are the constructors of the functions depending on the
+// sandbox, indexed by name.
+//
+//nolint:gochecknoglobals // immutable function table
+var sandboxFunctions = map[string]func(s *Sandbox) *value.Function{
+	"abspath": func(s *Sandbox) *value.Function {
+		return &value.Function{
+			Name:   "abspath",
+			Params: []value.Param{{Name: "path", Type: value.TypeString}},
+			Impl: func(args []value.Value) (value.Value, error) {
+				p, err := s.abs(args[0].AsString())
+				if err != nil {
+					return value.Null, &value.ArgError{Index: 0, Err: err}
+				}
+
+				return value.String(filepath.ToSlash(p)), nil
+			},
+		}
+	},
+	"file": func(s *Sandbox) *value.Function {
+		return s.fileFunction("file", func(data []byte) (value.Value, error) {
+			if !utf8.Valid(data) {
+				return value.Null, fmt.Errorf("%w: contents are not valid UTF-8; use the filebase64 function to obtain the Base64 encoded contents", value.ErrArgument)
+			}
+
+			return value.String(string(data)), nil
+		})
+	},
+	"filebase64": func(s *Sandbox) *value.Function {
+		return s.fileFunction("filebase64", func(data []byte) (value.Value, error) {
+			return value.String(base64.StdEncoding.EncodeToString(data)), nil
+		})
+	},
+	"filebase64sha256": func(s *Sandbox) *value.Function {
+		return s.fileHashFunction("filebase64sha256", sha256.New, base64.StdEncoding.EncodeToString)
+	},
+	"filebase64sha512": func(s *Sandbox) *value.Function {
+		return s.fileHashFunction("filebase64sha512", sha512.New, base64.StdEncoding.EncodeToString)
+	},
+	"fileexists": func(s *Sandbox) *value.Function {
+		return &value.Function{
+			Name:   "fileexists",
+			Params: []value.Param{{Name: "path", Type: value.TypeString}},
+			Impl:   s.fileExistsImpl,
+		}
+	},
+	"filemd5": func(s *Sandbox) *value.Function {
+		return s.fileHashFunction("filemd5", md5.New, hex.EncodeToString)
+	},
+	"fileset": func(s *Sandbox) *value.Function {
+		return &value.Function{
+			Name: "fileset",
+			Params: []value.Param{
+				{Name: "path", Type: value.TypeString},
+				{Name: "pattern", Type: value.TypeString},
+			},
+			Impl: s.fileSetImpl,
+		}
+	},
+	"filesha1": func(s *Sandbox) *value.Function {
+		return s.fileHashFunction("filesha1", sha1.New, hex.EncodeToString)
+	},
+	"filesha256": func(s *Sandbox) *value.Function {
+		return s.fileHashFunction("filesha256", sha256.New, hex.EncodeToString)
+	},
+	"filesha512": func(s *Sandbox) *value.Function {
+		return s.fileHashFunction("filesha512", sha512.New, hex.EncodeToString)
+	},
+	"templatefile": func(s *Sandbox) *value.Function {
+		return &value.Function{
+			Name: "templatefile",
+			Params: []value.Param{
+				{Name: "path", Type: value.TypeString},
+				{Name: "vars", Type: value.TypeAny},
+			},
+			Impl: s.templateFileImpl,
+		}
+	},
+	"timestamp": timestampFunction,
+}
+
+// Function returns the function with the given name depending on the
+// sandbox, if any.
+func (s *Sandbox) Function(name string) (*value.Function, bool) {
+	newFunction, ok := sandboxFunctions[name]
+	if !ok {
+		return nil, false
+	}
+
+	return newFunction(s), true
+}
+
+// Functions returns the functions depending on the sandbox indexed by name.
+func (s *Sandbox) Functions() map[string]*value.Function {
+	out := make(map[string]*value.Function, len(sandboxFunctions))
+
+	for name, newFunction := range sandboxFunctions {
+		out[name] = newFunction(s)
+	}
+
+	return out
+}
+
+// abs returns the absolute path of p, relative paths being resolved from the
+// base directory.
+func (s *Sandbox) abs(p string) (string, error) {
+	if s.BaseDir == "" {
+		return "", ErrNoBaseDir
+	}
+
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(s.BaseDir, p)
+	}
+
+	res, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", value.ErrArgument, err)
+	}
+
+	return res, nil
+}
+
+// resolve returns the absolute path of p, rejecting the paths outside of the
+// base directory, including through symbolic links.
+func (s *Sandbox) resolve(p string) (string, error) {
+	res, err := s.abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := filepath.Abs(s.BaseDir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", value.ErrArgument, err)
+	}
+
+	if !isWithin(root, res) {
+		return "", fmt.Errorf("%w: path %q is outside of the base directory", value.ErrArgument, p)
+	}
+
+	target, err := filepath.EvalSymlinks(res)
+	if errors.Is(err, fs.ErrNotExist) {
+		return res, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", value.ErrArgument, err)
+	}
+
+	if realRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = realRoot
+	}
+
+	if !isWithin(root, target) {
+		return "", fmt.Errorf("%w: path %q is outside of the base directory", value.ErrArgument, p)
+	}
+
+	return res, nil
+}
+
+// isWithin reports whether the absolute path p is root or one of its
+// descendants.
+func isWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,420 @@
+package funcs
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+//nolint:gochecknoglobals // immutable function table
+var stringFunctions = map[string]*value.Function{
+	"chomp": {
+		Name:   "chomp",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.TrimRight(args[0].AsString(), "\r\n")), nil
+		},
+	},
+	"format": {
+		Name:     "format",
+		Params:   []value.Param{{Name: "format", Type: value.TypeString}},
+		VarParam: &value.Param{Name: "args", AllowNull: true},
+		Impl: func(args []value.Value) (value.Value, error) {
+			s, err := formatString(args[0].AsString(), args[1:])
+			if err != nil {
+				return value.Null, err
+			}
+
+			return value.String(s), nil
+		},
+	},
+	"formatlist": {
+		Name:     "formatlist",
+		Params:   []value.Param{{Name: "format", Type: value.TypeString}},
+		VarParam: &value.Param{Name: "args", AllowNull: true},
+		Impl:     formatListImpl,
+	},
+	"indent": {
+		Name: "indent",
+		Params: []value.Param{
+			{Name: "spaces", Type: value.TypeNumber},
+			{Name: "str", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			n, err := intArg(args, 0, "spaces")
+			if err != nil {
+				return value.Null, err
+			}
+
+			if n < 0 {
+				return value.Null, argErrorf(0, "spaces must not be negative")
+			}
+
+			return value.String(strings.ReplaceAll(args[1].AsString(), "\n", "\n"+strings.Repeat(" ", n))), nil
+		},
+	},
+	"join": {
+		Name:     "join",
+		Params:   []value.Param{{Name: "separator", Type: value.TypeString}},
+		VarParam: &value.Param{Name: "lists", Type: value.ListOf(value.TypeString)},
+		Impl:     joinImpl,
+	},
+	"lower": {
+		Name:   "lower",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.ToLower(args[0].AsString())), nil
+		},
+	},
+	"regex": {
+		Name: "regex",
+		Params: []value.Param{
+			{Name: "pattern", Type: value.TypeString},
+			{Name: "string", Type: value.TypeString},
+		},
+		Impl: regexImpl,
+	},
+	"regexall": {
+		Name: "regexall",
+		Params: []value.Param{
+			{Name: "pattern", Type: value.TypeString},
+			{Name: "string", Type: value.TypeString},
+		},
+		Impl: regexAllImpl,
+	},
+	"replace": {
+		Name: "replace",
+		Params: []value.Param{
+			{Name: "str", Type: value.TypeString},
+			{Name: "substr", Type: value.TypeString},
+			{Name: "replace", Type: value.TypeString},
+		},
+		Impl: replaceImpl,
+	},
+	"split": {
+		Name: "split",
+		Params: []value.Param{
+			{Name: "separator", Type: value.TypeString},
+			{Name: "str", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			parts := strings.Split(args[1].AsString(), args[0].AsString())
+
+			out := make([]value.Value, 0, len(parts))
+			for _, part := range parts {
+				out = append(out, value.String(part))
+			}
+
+			return value.List(out...), nil
+		},
+	},
+	"strrev": {
+		Name:   "strrev",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			runes := []rune(args[0].AsString())
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+
+			return value.String(string(runes)), nil
+		},
+	},
+	"substr": {
+		Name: "substr",
+		Params: []value.Param{
+			{Name: "str", Type: value.TypeString},
+			{Name: "offset", Type: value.TypeNumber},
+			{Name: "length", Type: value.TypeNumber},
+		},
+		Impl: substrImpl,
+	},
+	"title": {
+		Name:   "title",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(title(args[0].AsString())), nil
+		},
+	},
+	"trim": {
+		Name: "trim",
+		Params: []value.Param{
+			{Name: "str", Type: value.TypeString},
+			{Name: "cutset", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.Trim(args[0].AsString(), args[1].AsString())), nil
+		},
+	},
+	"trimprefix": {
+		Name: "trimprefix",
+		Params: []value.Param{
+			{Name: "str", Type: value.TypeString},
+			{Name: "prefix", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.TrimPrefix(args[0].AsString(), args[1].AsString())), nil
+		},
+	},
+	"trimsuffix": {
+		Name: "trimsuffix",
+		Params: []value.Param{
+			{Name: "str", Type: value.TypeString},
+			{Name: "suffix", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.TrimSuffix(args[0].AsString(), args[1].AsString())), nil
+		},
+	},
+	"trimspace": {
+		Name:   "trimspace",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.TrimSpace(args[0].AsString())), nil
+		},
+	},
+	"upper": {
+		Name:   "upper",
+		Params: []value.Param{{Name: "str", Type: value.TypeString}},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return value.String(strings.ToUpper(args[0].AsString())), nil
+		},
+	},
+}
+
+func joinImpl(args []value.Value) (value.Value, error) {
+	if len(args) < 2 { //nolint:gomnd // separator and one list
+		return value.Null, argErrorf(0, "at least one list is required")
+	}
+
+	var items []string
+
+	for i, list := range args[1:] {
+		for j, item := range list.AsList() {
+			if item.IsNull() {
+				return value.Null, argErrorf(i+1, "element %d of list %d is null; cannot concatenate null values", j, i)
+			}
+
+			items = append(items, item.AsString())
+		}
+	}
+
+	return value.String(strings.Join(items, args[0].AsString())), nil
+}
+
+func formatListImpl(args []value.Value) (value.Value, error) {
+	length, lengthArg := -1, 0
+
+	for i, arg := range args[1:] {
+		if arg.Kind() != value.KindList {
+			continue
+		}
+
+		switch {
+		case length < 0:
+			length, lengthArg = arg.Len(), i
+		case arg.Len() != length:
+			return value.Null, argErrorf(i+1, "argument %d has length %d, which is inconsistent with argument %d of length %d",
+				i+1, arg.Len(), lengthArg+1, length)
+		}
+	}
+
+	// Without any list argument, the format string is applied once.
+	if length < 0 {
+		length = 1
+	}
+
+	out := make([]value.Value, 0, length)
+	row := make([]value.Value, len(args)-1)
+
+	for n := 0; n < length; n++ {
+		for i, arg := range args[1:] {
+			if arg.Kind() == value.KindList {
+				row[i] = arg.Index(n)
+			} else {
+				row[i] = arg
+			}
+		}
+
+		s, err := formatString(args[0].AsString(), row)
+		if err != nil {
+			return value.Null, err
+		}
+
+		out = append(out, value.String(s))
+	}
+
+	return value.List(out...), nil
+}
+
+// /////////////////////////////////////
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, argErrorf(0, "invalid regular expression pattern: %s", err)
+	}
+
+	named := 0
+
+	for _, name := range re.SubexpNames()[1:] {
+		if name != "" {
+			named++
+		}
+	}
+
+	if named != 0 && named != re.NumSubexp() {
+		return nil, argErrorf(0, "invalid regular expression pattern: named and unnamed capture groups cannot be mixed")
+	}
+
+	return re, nil
+}
+
+func regexImpl(args []value.Value) (value.Value, error) {
+	re, err := compileRegex(args[0].AsString())
+	if err != nil {
+		return value.Null, err
+	}
+
+	s := args[1].AsString()
+
+	match := re.FindStringSubmatchIndex(s)
+	if match == nil {
+		return value.Null, argErrorf(1, "pattern did not match any part of the given string")
+	}
+
+	return regexResult(re, s, match), nil
+}
+
+func regexAllImpl(args []value.Value) (value.Value, error) {
+	re, err := compileRegex(args[0].AsString())
+	if err != nil {
+		return value.Null, err
+	}
+
+	s := args[1].AsString()
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+
+	out := make([]value.Value, 0, len(matches))
+	for _, match := range matches {
+		out = append(out, regexResult(re, s, match))
+	}
+
+	return value.List(out...), nil
+}
+
+// regexResult returns the matched string when the pattern has no capture
+// group, a map of the named groups or a list of the unnamed groups.
+// Groups that did not participate in the match are null.
+func regexResult(re *regexp.Regexp, s string, match []int) value.Value {
+	if re.NumSubexp() == 0 {
+		return value.String(s[match[0]:match[1]])
+	}
+
+	groups := make([]value.Value, re.NumSubexp())
+
+	for i := range groups {
+		if start, end := match[2*i+2], match[2*i+3]; start >= 0 {
+			groups[i] = value.String(s[start:end])
+		}
+	}
+
+	names := re.SubexpNames()[1:]
+	if names[0] == "" {
+		return value.List(groups...)
+	}
+
+	out := make(map[string]value.Value, len(groups))
+	for i, name := range names {
+		out[name] = groups[i]
+	}
+
+	return value.Map(out)
+}
+
+func replaceImpl(args []value.Value) (value.Value, error) {
+	str, substr, replacement := args[0].AsString(), args[1].AsString(), args[2].AsString()
+
+	// A substring wrapped in forward slashes is a regular expression.
+	if len(substr) > 1 && strings.HasPrefix(substr, "/") && strings.HasSuffix(substr, "/") {
+		re, err := regexp.Compile(substr[1 : len(substr)-1])
+		if err != nil {
+			return value.Null, argErrorf(1, "invalid regular expression pattern: %s", err)
+		}
+
+		return value.String(re.ReplaceAllString(str, replacement)), nil
+	}
+
+	return value.String(strings.ReplaceAll(str, substr, replacement)), nil
+}
+
+func substrImpl(args []value.Value) (value.Value, error) {
+	offset, err := intArg(args, 1, "offset")
+	if err != nil {
+		return value.Null, err
+	}
+
+	length, err := intArg(args, 2, "length") //nolint:gomnd // third argument
+	if err != nil {
+		return value.Null, err
+	}
+
+	runes := []rune(args[0].AsString())
+
+	// A negative offset counts from the end of the string.
+	if offset < 0 {
+		offset += len(runes)
+	}
+
+	switch {
+	case offset < 0:
+		offset = 0
+	case offset > len(runes):
+		offset = len(runes)
+	}
+
+	runes = runes[offset:]
+
+	// A negative length extends to the end of the string.
+	if length >= 0 && length < len(runes) {
+		runes = runes[:length]
+	}
+
+	return value.String(string(runes)), nil
+}
+
+// title converts the first letter of each word to title case, where words
+// are delimited in the same way as the Terraform title function.
+func title(s string) string {
+	prev := ' '
+
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+
+		if isWordSeparator(prev) {
+			return unicode.ToTitle(r)
+		}
+
+		return r
+	}, s)
+}
+
+func isWordSeparator(r rune) bool {
+	if r < utf8.RuneSelf {
+		switch {
+		case '0' <= r && r <= '9', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '_':
+			return false
+		default:
+			return true
+		}
+	}
+
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return false
+	}
+
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,153 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestStringFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "chomp", fn: "chomp", args: args("hello\n"), want: value.String("hello")},
+		{name: "chomp - windows", fn: "chomp", args: args("hello\r\n"), want: value.String("hello")},
+		{name: "chomp - several", fn: "chomp", args: args("hello\n\n"), want: value.String("hello")},
+		{name: "chomp - inner newline", fn: "chomp", args: args("hello\nworld"), want: value.String("hello\nworld")},
+		{name: "indent", fn: "indent", args: args(2, "[\n  foo,\n  bar,\n]\n"), want: value.String("[\n    foo,\n    bar,\n  ]\n  ")},
+		{name: "indent - negative", fn: "indent", args: args(-1, "foo"), wantErr: "spaces must not be negative"},
+		{name: "join", fn: "join", args: args(", ", strs("foo", "bar", "baz")), want: value.String("foo, bar, baz")},
+		{name: "join - several lists", fn: "join", args: args("-", strs("a"), strs(), strs("b", "c")), want: value.String("a-b-c")},
+		{name: "join - no list", fn: "join", args: args(","), wantErr: "at least one list is required"},
+		{
+			name:    "join - null element",
+			fn:      "join",
+			args:    args(",", value.List(value.String("a"), value.Null)),
+			wantErr: "element 1 of list 0 is null; cannot concatenate null values",
+		},
+		{name: "join - not strings", fn: "join", args: args(",", value.List(value.Int(1))), wantErr: `argument "lists" must be a list(string)`},
+		{name: "lower", fn: "lower", args: args("HELLO ÀÉ"), want: value.String("hello àé")},
+		{name: "upper", fn: "upper", args: args("hello àé"), want: value.String("HELLO ÀÉ")},
+		{name: "title", fn: "title", args: args("hello world-foo_bar"), want: value.String("Hello World-Foo_bar")},
+		{name: "strrev", fn: "strrev", args: args("hello ☃"), want: value.String("☃ olleh")},
+		{name: "split", fn: "split", args: args(",", "foo,bar,baz"), want: strs("foo", "bar", "baz")},
+		{name: "split - no separator", fn: "split", args: args(",", "foo"), want: strs("foo")},
+		{name: "split - empty", fn: "split", args: args(",", ""), want: strs("")},
+		{name: "substr", fn: "substr", args: args("hello world", 1, 4), want: value.String("ello")},
+		{name: "substr - unicode", fn: "substr", args: args("🤔🤷", 0, 1), want: value.String("🤔")},
+		{name: "substr - to the end", fn: "substr", args: args("hello world", 6, -1), want: value.String("world")},
+		{name: "substr - negative offset", fn: "substr", args: args("hello world", -5, 3), want: value.String("wor")},
+		{name: "substr - past the end", fn: "substr", args: args("hello", 10, 2), want: value.String("")},
+		{name: "substr - long length", fn: "substr", args: args("hello", 3, 10), want: value.String("lo")},
+		{name: "substr - fractional offset", fn: "substr", args: args("hello", 1.5, 1), wantErr: "offset must be a whole number, got 1.5"},
+		{name: "trim", fn: "trim", args: args("?!hello?!", "!?"), want: value.String("hello")},
+		{name: "trimprefix", fn: "trimprefix", args: args("helloworld", "hello"), want: value.String("world")},
+		{name: "trimprefix - missing", fn: "trimprefix", args: args("helloworld", "cat"), want: value.String("helloworld")},
+		{name: "trimsuffix", fn: "trimsuffix", args: args("helloworld", "world"), want: value.String("hello")},
+		{name: "trimspace", fn: "trimspace", args: args("  hello\n\n"), want: value.String("hello")},
+		{name: "replace", fn: "replace", args: args("1 + 2 + 3", "+", "-"), want: value.String("1 - 2 - 3")},
+		{name: "replace - regex", fn: "replace", args: args("hello world", "/w.*d/", "everybody"), want: value.String("hello everybody")},
+		{name: "replace - regex groups", fn: "replace", args: args("hello world", "/(h)(e)/", "$2$1"), want: value.String("ehllo world")},
+		{name: "replace - single slash", fn: "replace", args: args("a/b", "/", "-"), want: value.String("a-b")},
+		{name: "replace - invalid regex", fn: "replace", args: args("a", "/(/", "-"), wantErr: "invalid regular expression pattern"},
+	})
+}
+
+func TestRegexFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "regex", fn: "regex", args: args("[a-z]+", "53453453.345345aaabbbccc23454"), want: value.String("aaabbbccc")},
+		{
+			name: "regex - unnamed groups",
+			fn:   "regex",
+			args: args(`(\d\d\d\d)-(\d\d)-(\d\d)`, "2019-02-01"),
+			want: strs("2019", "02", "01"),
+		},
+		{
+			name: "regex - named groups",
+			fn:   "regex",
+			args: args(`^(?:(?P<scheme>[^:/?#]+):)?(?://(?P<authority>[^/?#]*))?`, "https://terraform.io/docs/"),
+			want: value.Map(map[string]value.Value{"scheme": value.String("https"), "authority": value.String("terraform.io")}),
+		},
+		{
+			name: "regex - optional group",
+			fn:   "regex",
+			args: args(`(a)(b)?`, "a"),
+			want: value.List(value.String("a"), value.Null),
+		},
+		{name: "regex - no match", fn: "regex", args: args("[a-z]+", "123"), wantErr: "pattern did not match any part of the given string"},
+		{name: "regex - invalid", fn: "regex", args: args("[a-z", "123"), wantErr: "invalid regular expression pattern"},
+		{
+			name:    "regex - mixed groups",
+			fn:      "regex",
+			args:    args(`(?P<a>a)(b)`, "ab"),
+			wantErr: "named and unnamed capture groups cannot be mixed",
+		},
+		{name: "regexall", fn: "regexall", args: args("[a-z]+", "1234abcd5678efgh9"), want: strs("abcd", "efgh")},
+		{name: "regexall - no match", fn: "regexall", args: args("[a-z]+", "123"), want: strs()},
+		{
+			name: "regexall - groups",
+			fn:   "regexall",
+			args: args(`(\w)=(\d)`, "a=1 b=2"),
+			want: value.List(strs("a", "1"), strs("b", "2")),
+		},
+	})
+}
+
+func TestFormatFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "format", fn: "format", args: args("Hello, %s!", "Ander"), want: value.String("Hello, Ander!")},
+		{name: "format - number", fn: "format", args: args("There are %d lights", 4), want: value.String("There are 4 lights")},
+		{name: "format - no verb", fn: "format", args: args("100%%"), want: value.String("100%")},
+		{name: "format - default", fn: "format", args: args("%v %v %v", "a", 1.5, true), want: value.String("a 1.5 true")},
+		{name: "format - default null", fn: "format", args: args("%v", nil), want: value.String("null")},
+		{name: "format - json", fn: "format", args: args("%#v", "a"), want: value.String(`"a"`)},
+		{name: "format - list", fn: "format", args: args("%v", strs("a", "b")), want: value.String(`["a","b"]`)},
+		{name: "format - bool", fn: "format", args: args("%t", true), want: value.String("true")},
+		{name: "format - padding", fn: "format", args: args("[%5s][%-5s][%05d]", "a", "b", 42), want: value.String("[    a][b    ][00042]")},
+		{name: "format - precision", fn: "format", args: args("%.2f", 3.14159), want: value.String("3.14")},
+		{name: "format - exponent", fn: "format", args: args("%.3e", 1234.5), want: value.String("1.234e+03")},
+		{name: "format - hex", fn: "format", args: args("%x %X %#x", 255, 255, 255), want: value.String("ff FF 0xff")},
+		{name: "format - binary and octal", fn: "format", args: args("%b %o", 5, 8), want: value.String("101 10")},
+		{name: "format - numeric string", fn: "format", args: args("%d", "12"), want: value.String("12")},
+		{name: "format - number as string", fn: "format", args: args("%s", 12), want: value.String("12")},
+		{name: "format - quoted", fn: "format", args: args("%q", `a"b`), want: value.String(`"a\"b"`)},
+		{name: "format - argument index", fn: "format", args: args("%[2]s %[1]s %s", "a", "b"), want: value.String("b a b")},
+		{name: "format - unicode", fn: "format", args: args("☃ %s ☃", "x"), want: value.String("☃ x ☃")},
+		{
+			name:    "format - not enough arguments",
+			fn:      "format",
+			args:    args("%s %s", "a"),
+			wantErr: `not enough arguments for "%s" at 3: need index 2 but have 1 total`,
+		},
+		{name: "format - too many arguments", fn: "format", args: args("%s", "a", "b"), wantErr: "too many arguments; only 1 used by format string"},
+		{name: "format - no verbs", fn: "format", args: args("hello", "a"), wantErr: "too many arguments; no verbs in format string"},
+		{name: "format - not an integer", fn: "format", args: args("%d", 1.5), wantErr: `unsupported value for "%d" at 0: integer required`},
+		{name: "format - null", fn: "format", args: args("%s", nil), wantErr: `unsupported value for "%s" at 0: null value cannot be formatted`},
+		{name: "format - list as string", fn: "format", args: args("%s", strs("a")), wantErr: "string required"},
+		{name: "format - unknown verb", fn: "format", args: args("%z", "a"), wantErr: `unrecognized format character 'z' at 0`},
+		{name: "format - unterminated", fn: "format", args: args("%-5", "a"), wantErr: "unterminated format verb at 0"},
+		{
+			name: "formatlist",
+			fn:   "formatlist",
+			args: args("Hello, %s!", strs("Valentina", "Ander", "Olivia", "Sam")),
+			want: strs("Hello, Valentina!", "Hello, Ander!", "Hello, Olivia!", "Hello, Sam!"),
+		},
+		{
+			name: "formatlist - scalar arguments",
+			fn:   "formatlist",
+			args: args("%s, %s!", "Salutations", strs("Valentina", "Ander")),
+			want: strs("Salutations, Valentina!", "Salutations, Ander!"),
+		},
+		{name: "formatlist - no list", fn: "formatlist", args: args("%s", "a"), want: strs("a")},
+		{
+			name:    "formatlist - inconsistent lengths",
+			fn:      "formatlist",
+			args:    args("%s %s", strs("a", "b"), strs("c")),
+			wantErr: "argument 2 has length 1, which is inconsistent with argument 1 of length 2",
+		},
+	})
+}
@@ -0,0 +1,240 @@
+package funcs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+//nolint:gochecknoglobals // immutable function table
+var timeFunctions = map[string]*value.Function{
+	"formatdate": {
+		Name: "formatdate",
+		Params: []value.Param{
+			{Name: "format", Type: value.TypeString},
+			{Name: "time", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			t, err := timestampArg(args, 1)
+			if err != nil {
+				return value.Null, err
+			}
+
+			s, err := formatDate(args[0].AsString(), t)
+			if err != nil {
+				return value.Null, &value.ArgError{Index: 0, Err: err}
+			}
+
+			return value.String(s), nil
+		},
+	},
+	"timeadd": {
+		Name: "timeadd",
+		Params: []value.Param{
+			{Name: "timestamp", Type: value.TypeString},
+			{Name: "duration", Type: value.TypeString},
+		},
+		Impl: func(args []value.Value) (value.Value, error) {
+			t, err := timestampArg(args, 0)
+			if err != nil {
+				return value.Null, err
+			}
+
+			d, err := time.ParseDuration(args[1].AsString())
+			if err != nil {
+				return value.Null, argErrorf(1, "%s", err)
+			}
+
+			return value.String(t.Add(d).Format(time.RFC3339)), nil
+		},
+	},
+}
+
+// timestampFunction returns the timestamp function, reading the time from
+// the clock of the sandbox.
+func timestampFunction(s *Sandbox) *value.Function {
+	return &value.Function{
+		Name: "timestamp",
+		Impl: func([]value.Value) (value.Value, error) {
+			now := time.Now
+			if s.Now != nil {
+				now = s.Now
+			}
+
+			return value.String(now().UTC().Format(time.RFC3339)), nil
+		},
+	}
+}
+
+func timestampArg(args []value.Value, i int) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, args[i].AsString())
+	if err != nil {
+		return time.Time{}, argErrorf(i, "not a valid RFC3339 timestamp: %q", args[i].AsString())
+	}
+
+	return t, nil
+}
+
+// formatDate formats a time with the format specification of Terraform's
+// formatdate function, where letter sequences are verbs and literal letters
+// are quoted.
+func formatDate(format string, t time.Time) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); {
+		c := format[i]
+
+		switch {
+		case c == '\'':
+			literal, n, err := dateLiteral(format[i:])
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteString(literal)
+			i += n
+		case isLetter(c):
+			n := 1
+			for i+n < len(format) && format[i+n] == c {
+				n++
+			}
+
+			s, err := formatDateVerb(format[i:i+n], t)
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteString(s)
+			i += n
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// dateLiteral returns the text of the quoted literal at the start of s, and
+// the length of the literal in s. Two consecutive quotes are a literal quote.
+func dateLiteral(s string) (string, int, error) {
+	if strings.HasPrefix(s, "''") {
+		return "'", 2, nil //nolint:gomnd // two quotes
+	}
+
+	var sb strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			sb.WriteByte(s[i])
+
+			continue
+		}
+
+		if i+1 < len(s) && s[i+1] == '\'' {
+			sb.WriteByte('\'')
+			i++
+
+			continue
+		}
+
+		return sb.String(), i + 1, nil
+	}
+
+	return "", 0, fmt.Errorf("%w: unterminated literal '", value.ErrArgument)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+//nolint:cyclop,gomnd // one case per verb
+func formatDateVerb(verb string, t time.Time) (string, error) {
+	invalid := func(reason string) (string, error) {
+		return "", fmt.Errorf("%w: invalid date format verb %q: %s", value.ErrArgument, verb, reason)
+	}
+
+	switch verb {
+	case "YYYY":
+		return fmt.Sprintf("%04d", t.Year()), nil
+	case "YY":
+		return fmt.Sprintf("%02d", t.Year()%100), nil
+	case "MMMM":
+		return t.Month().String(), nil
+	case "MMM":
+		return t.Month().String()[:3], nil
+	case "MM":
+		return fmt.Sprintf("%02d", int(t.Month())), nil
+	case "M":
+		return fmt.Sprintf("%d", int(t.Month())), nil
+	case "DD":
+		return fmt.Sprintf("%02d", t.Day()), nil
+	case "D":
+		return fmt.Sprintf("%d", t.Day()), nil
+	case "EEEE":
+		return t.Weekday().String(), nil
+	case "EEE":
+		return t.Weekday().String()[:3], nil
+	case "hh":
+		return fmt.Sprintf("%02d", t.Hour()), nil
+	case "h":
+		return fmt.Sprintf("%d", t.Hour()), nil
+	case "HH":
+		return fmt.Sprintf("%02d", hour12(t)), nil
+	case "H":
+		return fmt.Sprintf("%d", hour12(t)), nil
+	case "AA":
+		return t.Format("PM"), nil
+	case "aa":
+		return t.Format("pm"), nil
+	case "mm":
+		return fmt.Sprintf("%02d", t.Minute()), nil
+	case "m":
+		return fmt.Sprintf("%d", t.Minute()), nil
+	case "ss":
+		return fmt.Sprintf("%02d", t.Second()), nil
+	case "s":
+		return fmt.Sprintf("%d", t.Second()), nil
+	case "ZZZZZ":
+		return t.Format("-07:00"), nil
+	case "ZZZZ":
+		return t.Format("-0700"), nil
+	case "ZZZ":
+		return t.Format("MST"), nil
+	case "Z":
+		return t.Format("Z07:00"), nil
+	}
+
+	switch verb[0] {
+	case 'Y':
+		return invalid(`year must either be "YY" or "YYYY"`)
+	case 'M':
+		return invalid(`month must be "M", "MM", "MMM", or "MMMM"`)
+	case 'D':
+		return invalid(`day of month must either be "D" or "DD"`)
+	case 'E':
+		return invalid(`day of week must either be "EEE" or "EEEE"`)
+	case 'h', 'H':
+		return invalid(`hour must either be "h"/"H" or "hh"/"HH"`)
+	case 'A', 'a':
+		return invalid(`AM/PM must be "AA" or "aa"`)
+	case 'm':
+		return invalid(`minute must either be "m" or "mm"`)
+	case 's':
+		return invalid(`second must either be "s" or "ss"`)
+	case 'Z':
+		return invalid(`timezone must be "Z", "ZZZ", "ZZZZ", or "ZZZZZ"`)
+	default:
+		return invalid("literal letters must be quoted, like 'text'")
+	}
+}
+
+func hour12(t time.Time) int {
+	if h := t.Hour() % 12; h != 0 { //nolint:gomnd // hours per half day
+		return h
+	}
+
+	return 12 //nolint:gomnd // noon and midnight
+}
@@ -0,0 +1,77 @@
+package funcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestTimeFunctions(t *testing.T) {
+	t.Parallel()
+
+	runFuncTests(t, []funcTest{
+		{name: "formatdate", fn: "formatdate", args: args("DD MMM YYYY hh:mm ZZZ", "2018-01-02T23:12:01Z"), want: value.String("02 Jan 2018 23:12 UTC")},
+		{
+			name: "formatdate - long names",
+			fn:   "formatdate",
+			args: args("EEEE, DD-MMMM-YY hh:mm:ss ZZZ", "2018-01-02T23:12:01Z"),
+			want: value.String("Tuesday, 02-January-18 23:12:01 UTC"),
+		},
+		{
+			name: "formatdate - offset",
+			fn:   "formatdate",
+			args: args("EEE, DD MMM YYYY hh:mm:ss ZZZ", "2018-01-02T23:12:01-08:00"),
+			want: value.String("Tue, 02 Jan 2018 23:12:01 -0800"),
+		},
+		{name: "formatdate - short", fn: "formatdate", args: args("MMM D, YYYY", "2018-01-02T23:12:01Z"), want: value.String("Jan 2, 2018")},
+		{name: "formatdate - 12 hours", fn: "formatdate", args: args("HH:mmaa", "2018-01-02T23:12:01Z"), want: value.String("11:12pm")},
+		{name: "formatdate - midnight", fn: "formatdate", args: args("H:mm AA", "2018-01-02T00:05:00Z"), want: value.String("12:05 AM")},
+		{name: "formatdate - unpadded", fn: "formatdate", args: args("M/D h:m:s", "2018-01-02T03:04:05Z"), want: value.String("1/2 3:4:5")},
+		{
+			name: "formatdate - time zones",
+			fn:   "formatdate",
+			args: args("Z ZZZZ ZZZZZ", "2018-01-02T03:04:05+05:30"),
+			want: value.String("+05:30 +0530 +05:30"),
+		},
+		{name: "formatdate - UTC zone", fn: "formatdate", args: args("Z", "2018-01-02T03:04:05Z"), want: value.String("Z")},
+		{name: "formatdate - literal", fn: "formatdate", args: args("'Year:' YYYY, 'o''clock' ''", "2018-01-02T03:04:05Z"), want: value.String("Year: 2018, o'clock '")},
+		{name: "formatdate - bad verb", fn: "formatdate", args: args("YYY", "2018-01-02T03:04:05Z"), wantErr: `invalid date format verb "YYY": year must either be "YY" or "YYYY"`},
+		{name: "formatdate - unquoted letters", fn: "formatdate", args: args("the hh", "2018-01-02T03:04:05Z"), wantErr: `invalid date format verb "t": literal letters must be quoted`},
+		{name: "formatdate - unterminated literal", fn: "formatdate", args: args("'at", "2018-01-02T03:04:05Z"), wantErr: "unterminated literal '"},
+		{name: "formatdate - invalid time", fn: "formatdate", args: args("YYYY", "2018-01-02"), wantErr: `not a valid RFC3339 timestamp: "2018-01-02"`},
+		{name: "timeadd", fn: "timeadd", args: args("2017-11-22T00:00:00Z", "10m"), want: value.String("2017-11-22T00:10:00Z")},
+		{name: "timeadd - negative", fn: "timeadd", args: args("2017-11-22T00:00:00Z", "-1h30m"), want: value.String("2017-11-21T22:30:00Z")},
+		{name: "timeadd - offset", fn: "timeadd", args: args("2017-11-22T00:00:00+01:00", "1s"), want: value.String("2017-11-22T00:00:01+01:00")},
+		{name: "timeadd - invalid duration", fn: "timeadd", args: args("2017-11-22T00:00:00Z", "1d"), wantErr: `unknown unit "d"`},
+	})
+}
+
+func TestTimestamp(t *testing.T) {
+	t.Parallel()
+
+	sandbox := &Sandbox{
+		Now: func() time.Time {
+			return time.Date(2022, 6, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+		},
+	}
+
+	fn, ok := sandbox.Function("timestamp")
+	require.True(t, ok)
+
+	got, err := fn.Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-06-01T12:30:00Z", got.AsString())
+
+	fn, _ = (&Sandbox{}).Function("timestamp")
+
+	got, err = fn.Call(nil)
+	require.NoError(t, err)
+
+	now, err := time.Parse(time.RFC3339, got.AsString())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), now, time.Minute)
+}
@@ -8,9 +8,9 @@ import (
 type Lambda struct {
 	ASTNode
 
-	Comment    *Comment           `parser:"[ @@ ]"                            json:"comment,omitempty"`
-	Parameters []*LambdaParameter `parser:"'(' [ @@ (',' @@)* ] ')' OpLambda" json:"parameters"`
-	Expr       Expr               `parser:"@@"                                json:"expr"`
+	Comment    *Comment           `parser:"[ @@ ]"                                    json:"comment,omitempty"`
+	Parameters []*LambdaParameter `parser:"( '(' [ @@ (',' @@)* ] ')' | @@ ) OpLambda" json:"parameters"`
+	Expr       Expr               `parser:"@@"                                        json:"expr"`
 }
 
 func (n *Lambda) Clone() *Lambda {
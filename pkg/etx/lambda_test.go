@@ -255,6 +255,34 @@ func TestLambda_Parsing(t *testing.T) {
 				),
 			},
 		},
+		{
+			name:    "Unparenthesised parameter",
+			input:   `v => v`,
+			wantErr: false,
+			want: &Lambda{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Parameters: []*LambdaParameter{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+						Label:   "v",
+					},
+				},
+				Expr: *BuildTestExprTree[*Expr](t, &Ident{
+					ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+					Parts:   []string{"v"},
+				}),
+			},
+		},
+		{
+			name:    "Unparenthesised parameter - missing arrow",
+			input:   `v`,
+			wantErr: true,
+		},
+		{
+			name:    "Unparenthesised parameters",
+			input:   `k, v => v`,
+			wantErr: true,
+		},
 		{
 			name: "Single-line comment",
 			input: `
@@ -40,6 +40,7 @@ const (
 	OpRBrace            = `}`
 	OpComma             = `,`
 	OpDot               = `.`
+	OpEllipsis          = `...`
 
 	TokenWhitespace = "whitespace"
 )
@@ -111,6 +112,7 @@ func lexRules() lexer.Rules {
 			{Name: `OpRBracket`, Pattern: regexp.QuoteMeta(OpRBracket)},
 
 			{Name: "String", Pattern: `(["'])`, Action: lexer.Push(lexerString)},
+			{Name: "Ellipsis", Pattern: regexp.QuoteMeta(OpEllipsis)},
 			{Name: "Dot", Pattern: regexp.QuoteMeta(OpDot)},
 			{Name: "LF", Pattern: `[\r\n]`},
 			{Name: TokenWhitespace, Pattern: `[\t ]+`},
@@ -122,26 +124,48 @@ func lexRules() lexer.Rules {
 			{Name: "StringEnd", Pattern: `\1`, Action: lexer.Pop()},
 			{Name: "Quote", Pattern: `["']`},
 			{Name: "NonExpr", Pattern: `(\$\${|%%{)`},
+			{Name: "ExprStrip", Pattern: `\${~`, Action: lexer.Push(lexerStringExpr)},
 			{Name: "Expr", Pattern: `\${`, Action: lexer.Push(lexerStringExpr)},
+			{Name: "DirectiveStrip", Pattern: `%{~`, Action: lexer.Push(lexerStringExpr)},
 			{Name: "Directive", Pattern: `%{`, Action: lexer.Push(lexerStringExpr)},
-			{Name: "Char", Pattern: `[^$%"'\\]+`},
+			{Name: "Char", Pattern: `[^$%"'\\]+|[$%]`},
 		},
 		lexerUnicode: {
 			{Name: "UnicodeLong", Pattern: `[0-9a-fA-F]{8}`, Action: lexer.Pop()},
 			{Name: "UnicodeShort", Pattern: `[0-9a-fA-F]{4}`, Action: lexer.Pop()},
 		},
 		lexerHeredoc: {
-			{Name: "HeredocEnd", Pattern: `^\1`, Action: lexer.Pop()},
+			{Name: "HeredocEnd", Pattern: `^[\t ]*\1`, Action: lexer.Pop()},
 			{Name: "EOL", Pattern: `\n`},
 			{Name: "NonExpr", Pattern: `(\$\${|%%{)`},
+			{Name: "ExprStrip", Pattern: `\${~`, Action: lexer.Push(lexerStringExpr)},
 			{Name: "Expr", Pattern: `\${`, Action: lexer.Push(lexerStringExpr)},
+			{Name: "DirectiveStrip", Pattern: `%{~`, Action: lexer.Push(lexerStringExpr)},
 			{Name: "Directive", Pattern: `%{`, Action: lexer.Push(lexerStringExpr)},
-			{Name: "Body", Pattern: `[^\n$%]+`},
+			{Name: "Body", Pattern: `[^\n$%]+|[$%]`},
 		},
 		lexerStringExpr: {
+			{Name: "ExprStripEnd", Pattern: `~}`, Action: lexer.Pop()},
 			{Name: "ExprEnd", Pattern: `}`, Action: lexer.Pop()},
 
 			lexer.Include(lexerCore),
 		},
 	}
 }
+
+// templateLexRules returns the rules of the lexer of template files, whose
+// content is lexed like the body of a heredoc.
+func templateLexRules() lexer.Rules {
+	rules := lexRules()
+	rules[lexerRoot] = []lexer.Rule{
+		{Name: "EOL", Pattern: `\n`},
+		{Name: "NonExpr", Pattern: `(\$\${|%%{)`},
+		{Name: "ExprStrip", Pattern: `\${~`, Action: lexer.Push(lexerStringExpr)},
+		{Name: "Expr", Pattern: `\${`, Action: lexer.Push(lexerStringExpr)},
+		{Name: "DirectiveStrip", Pattern: `%{~`, Action: lexer.Push(lexerStringExpr)},
+		{Name: "Directive", Pattern: `%{`, Action: lexer.Push(lexerStringExpr)},
+		{Name: "Body", Pattern: `[^\n$%]+|[$%]`},
+	}
+
+	return rules
+}
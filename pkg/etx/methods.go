@@ -0,0 +1,989 @@
+package etx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+// method is a built-in method of the sequence values (lists, sets and maps).
+type method struct {
+	params   []value.Param
+	varParam *value.Param
+	maps     bool // whether the method is available on maps
+	inspects bool // whether the method compares or unpacks the elements
+	impl     func(seq sequence, args []value.Value) (value.Value, error)
+}
+
+// errUnknown aborts a method when a callback returns an unknown value that
+// decides its result: the result of the method is unknown.
+var errUnknown = errors.New("unknown callback result")
+
+//nolint:gochecknoglobals // parameter descriptors
+var (
+	paramElem  = value.Param{Name: "elem", AllowNull: true, AllowSensitive: true}
+	paramSeq   = value.Param{Name: "other", AllowSensitive: true}
+	paramCount = value.Param{Name: "n", Type: value.TypeNumber}
+	paramFunc  = value.Param{Name: "f", Type: value.TypeFunction}
+	paramInit  = value.Param{Name: "init", AllowNull: true, AllowSensitive: true}
+)
+
+// sequenceMethods is the dispatch table of the Sequences API.
+//
+//nolint:gochecknoglobals // dispatch table
+var sequenceMethods = map[string]method{
+	"append":      {params: []value.Param{paramElem}, impl: seqAppend},
+	"appendAll":   {params: []value.Param{paramSeq}, impl: seqAppendAll},
+	"contains":    {params: []value.Param{paramElem}, maps: true, inspects: true, impl: seqContains},
+	"count":       {params: []value.Param{paramFunc}, maps: true, impl: seqCount},
+	"diff":        {params: []value.Param{paramSeq}, inspects: true, impl: seqDiff},
+	"distinct":    {inspects: true, impl: seqDistinct},
+	"dropLeft":    {params: []value.Param{paramCount}, maps: true, impl: seqDropLeft},
+	"dropRight":   {params: []value.Param{paramCount}, maps: true, impl: seqDropRight},
+	"dropWhile":   {params: []value.Param{paramFunc}, maps: true, impl: seqDropWhile},
+	"exists":      {params: []value.Param{paramFunc}, maps: true, impl: seqExists},
+	"filter":      {params: []value.Param{paramFunc}, maps: true, impl: seqFilter},
+	"find":        {params: []value.Param{paramFunc}, maps: true, impl: seqFind},
+	"findLast":    {params: []value.Param{paramFunc}, maps: true, impl: seqFindLast},
+	"flatMap":     {params: []value.Param{paramFunc}, maps: true, impl: seqFlatMap},
+	"flatten":     {inspects: true, impl: seqFlatten},
+	"foldLeft":    {params: []value.Param{paramInit}, maps: true, impl: seqFoldLeft},
+	"foldRight":   {params: []value.Param{paramInit}, maps: true, impl: seqFoldRight},
+	"group":       {params: []value.Param{paramCount}, impl: seqGroup},
+	"groupBy":     {params: []value.Param{paramFunc}, maps: true, impl: seqGroupBy},
+	"head":        {maps: true, impl: seqHead},
+	"indexOf":     {params: []value.Param{paramElem}, inspects: true, impl: seqIndexOf},
+	"indexWhere":  {params: []value.Param{paramFunc}, impl: seqIndexWhere},
+	"intersect":   {params: []value.Param{paramSeq}, inspects: true, impl: seqIntersect},
+	"isEmpty":     {maps: true, impl: seqIsEmpty},
+	"last":        {maps: true, impl: seqLast},
+	"length":      {maps: true, impl: seqLength},
+	"map":         {params: []value.Param{paramFunc}, maps: true, impl: seqMap},
+	"partition":   {params: []value.Param{paramFunc}, maps: true, impl: seqPartition},
+	"prepend":     {params: []value.Param{paramElem}, impl: seqPrepend},
+	"reduceLeft":  {params: []value.Param{paramFunc}, maps: true, impl: seqReduceLeft},
+	"reduceRight": {params: []value.Param{paramFunc}, maps: true, impl: seqReduceRight},
+	"reverse":     {impl: seqReverse},
+	"scanLeft":    {params: []value.Param{paramInit}, maps: true, impl: seqScanLeft},
+	"scanRight":   {params: []value.Param{paramInit}, maps: true, impl: seqScanRight},
+	"slice":       {params: []value.Param{{Name: "from", Type: value.TypeNumber}, {Name: "until", Type: value.TypeNumber}}, maps: true, impl: seqSlice},
+	"sort":        {params: []value.Param{paramFunc}, impl: seqSort},
+	"tail":        {maps: true, impl: seqTail},
+	"takeLeft":    {params: []value.Param{paramCount}, maps: true, impl: seqTakeLeft},
+	"takeRight":   {params: []value.Param{paramCount}, maps: true, impl: seqTakeRight},
+	"takeWhile":   {params: []value.Param{paramFunc}, maps: true, impl: seqTakeWhile},
+	"transpose":   {inspects: true, impl: seqTranspose},
+	"unzip":       {inspects: true, impl: seqUnzip},
+	"zip":         {varParam: &paramSeq, impl: seqZip},
+}
+
+// lookupMethod returns the method name of the sequence v, bound to v.
+func lookupMethod(v value.Value, name string) (value.Value, bool) {
+	m, ok := sequenceMethods[name]
+	if !ok {
+		return value.Null, false
+	}
+
+	switch v.Kind() {
+	case value.KindList, value.KindSet:
+	case value.KindMap:
+		if !m.maps {
+			return value.Null, false
+		}
+	default:
+		return value.Null, false
+	}
+
+	return value.Func(&value.Function{
+		Name:     name,
+		Params:   m.params,
+		VarParam: m.varParam,
+		Impl: func(args []value.Value) (value.Value, error) {
+			seq := newSequence(v)
+
+			res, err := m.call(v, seq, args)
+
+			// The elements and arguments of the sequence keep their own marks,
+			// but the result depends on all of them when they are compared or
+			// unpacked, or when it is decided by sensitive callback results.
+			if *seq.sensitive || (m.inspects && (v.ContainsSensitive() || containsSensitive(args))) {
+				return res.MarkSensitive(), value.RedactError(err)
+			}
+
+			return res, err
+		},
+	}), true
+}
+
+// call invokes the method on the sequence v. The result is unknown when the
+// method inspects elements that are not known yet, or when an unknown
+// callback result decides it.
+func (m method) call(v value.Value, seq sequence, args []value.Value) (value.Value, error) {
+	if m.inspects && !v.IsWhollyKnown() {
+		return value.Unknown(value.TypeAny), nil
+	}
+
+	res, err := m.impl(seq, args)
+	if errors.Is(err, errUnknown) {
+		return value.Unknown(value.TypeAny), nil
+	}
+
+	return res, err
+}
+
+func containsSensitive(values []value.Value) bool {
+	for _, v := range values {
+		if v.ContainsSensitive() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// /////////////////////////////////////
+
+// sequence is an ordered view of a list, set or map.
+//
+// The keys are the indexes of list and set elements, and the sorted keys of
+// map entries.
+type sequence struct {
+	kind  value.Kind
+	keys  []value.Value
+	items []value.Value
+
+	// sensitive is set once a sensitive callback result decides which
+	// elements make up the result.
+	sensitive *bool
+}
+
+func newSequence(v value.Value) sequence {
+	seq := sequence{kind: v.Kind(), sensitive: new(bool)}
+
+	if v.Kind() == value.KindMap {
+		for _, k := range v.Keys() {
+			item, _ := v.Get(k)
+
+			seq.keys = append(seq.keys, value.String(k))
+			seq.items = append(seq.items, item)
+		}
+
+		return seq
+	}
+
+	seq.items = v.AsList()
+	for i := range seq.items {
+		seq.keys = append(seq.keys, value.Int(int64(i)))
+	}
+
+	return seq
+}
+
+// pick returns a collection of the same kind as the sequence holding the
+// entries at the given indexes.
+func (s sequence) pick(indexes []int) value.Value {
+	if s.kind == value.KindMap {
+		out := make(map[string]value.Value, len(indexes))
+		for _, i := range indexes {
+			out[s.keys[i].AsString()] = s.items[i]
+		}
+
+		return value.Map(out)
+	}
+
+	items := make([]value.Value, 0, len(indexes))
+	for _, i := range indexes {
+		items = append(items, s.items[i])
+	}
+
+	return s.of(items)
+}
+
+// between returns the entries from index i up to index j (excluded).
+func (s sequence) between(i, j int) value.Value {
+	indexes := make([]int, 0, j-i)
+	for ; i < j; i++ {
+		indexes = append(indexes, i)
+	}
+
+	return s.pick(indexes)
+}
+
+// of returns a collection holding items, a set if the sequence is a set and
+// a list otherwise.
+func (s sequence) of(items []value.Value) value.Value {
+	if s.kind == value.KindSet {
+		return value.Set(items...)
+	}
+
+	return value.List(items...)
+}
+
+// call invokes the function f with the entry i, passing the arguments
+// pre, the key and the element. The key is left out when f takes one
+// parameter less.
+func (s sequence) call(f value.Value, i int, pre ...value.Value) (value.Value, error) {
+	fn := f.AsFunction()
+
+	args := append(append([]value.Value{}, pre...), s.keys[i], s.items[i])
+	if fn.VarParam == nil && len(fn.Params) == len(args)-1 {
+		args = append(args[:len(args)-2], s.items[i])
+	}
+
+	return fn.Call(args)
+}
+
+// test invokes the predicate f with the entry i.
+func (s sequence) test(f value.Value, i int) (bool, error) {
+	res, err := s.call(f, i)
+	if err != nil {
+		return false, err
+	}
+
+	if !res.IsKnown() && isBool(res) {
+		return false, errUnknown
+	}
+
+	if res.Kind() != value.KindBool {
+		return false, fmt.Errorf("%w: predicate must return a bool, got %s", value.ErrArgument, res.Kind())
+	}
+
+	if res.IsSensitive() {
+		*s.sensitive = true
+	}
+
+	return res.AsBool(), nil
+}
+
+// count returns the integer argument n, clamped to the sequence length.
+func (s sequence) count(n value.Value) (int, error) {
+	i, err := n.AsInt()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case i < 0:
+		return 0, fmt.Errorf("%w: count must not be negative, got %d", value.ErrArgument, i)
+	case i > len(s.items):
+		return len(s.items), nil
+	default:
+		return i, nil
+	}
+}
+
+func (s sequence) indexes(keep func(i int) (bool, error)) ([]int, error) {
+	var out []int
+
+	for i := range s.items {
+		ok, err := keep(i)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			out = append(out, i)
+		}
+	}
+
+	return out, nil
+}
+
+// elements returns the elements of a list or set argument.
+func elements(v value.Value) ([]value.Value, error) {
+	if v.Kind() != value.KindList && v.Kind() != value.KindSet {
+		return nil, fmt.Errorf("%w: expected a list or a set, got %s", value.ErrArgument, v.Kind())
+	}
+
+	return v.AsList(), nil
+}
+
+// curried returns a function taking the operator of a fold or a scan.
+func curried(name string, impl func(f value.Value) (value.Value, error)) value.Value {
+	return value.Func(&value.Function{
+		Name:   name,
+		Params: []value.Param{paramFunc},
+		Impl: func(args []value.Value) (value.Value, error) {
+			return impl(args[0])
+		},
+	})
+}
+
+func indexOf(items []value.Value, v value.Value) int {
+	for i, item := range items {
+		if item.Equals(v) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// /////////////////////////////////////
+
+func seqAppend(s sequence, args []value.Value) (value.Value, error) {
+	return s.of(append(append([]value.Value{}, s.items...), args[0])), nil
+}
+
+func seqAppendAll(s sequence, args []value.Value) (value.Value, error) {
+	other, err := elements(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.of(append(append([]value.Value{}, s.items...), other...)), nil
+}
+
+func seqContains(s sequence, args []value.Value) (value.Value, error) {
+	if s.kind == value.KindMap {
+		return value.Bool(indexOf(s.keys, args[0]) >= 0), nil
+	}
+
+	return value.Bool(indexOf(s.items, args[0]) >= 0), nil
+}
+
+func seqCount(s sequence, args []value.Value) (value.Value, error) {
+	indexes, err := s.indexes(func(i int) (bool, error) { return s.test(args[0], i) })
+	if err != nil {
+		return value.Null, err
+	}
+
+	return value.Int(int64(len(indexes))), nil
+}
+
+func seqDiff(s sequence, args []value.Value) (value.Value, error) {
+	other, err := elements(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	remaining := append([]value.Value{}, other...)
+
+	var out []value.Value
+
+	for _, item := range s.items {
+		if j := indexOf(remaining, item); j >= 0 {
+			remaining = append(remaining[:j], remaining[j+1:]...)
+
+			continue
+		}
+
+		out = append(out, item)
+	}
+
+	return s.of(out), nil
+}
+
+func seqDistinct(s sequence, _ []value.Value) (value.Value, error) {
+	var out []value.Value
+
+	for _, item := range s.items {
+		if indexOf(out, item) < 0 {
+			out = append(out, item)
+		}
+	}
+
+	return s.of(out), nil
+}
+
+func seqDropLeft(s sequence, args []value.Value) (value.Value, error) {
+	n, err := s.count(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.between(n, len(s.items)), nil
+}
+
+func seqDropRight(s sequence, args []value.Value) (value.Value, error) {
+	n, err := s.count(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.between(0, len(s.items)-n), nil
+}
+
+func seqDropWhile(s sequence, args []value.Value) (value.Value, error) {
+	n, err := s.prefix(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.between(n, len(s.items)), nil
+}
+
+// prefix returns the length of the longest prefix satisfying the predicate f.
+func (s sequence) prefix(f value.Value) (int, error) {
+	for i := range s.items {
+		ok, err := s.test(f, i)
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			return i, nil
+		}
+	}
+
+	return len(s.items), nil
+}
+
+func seqExists(s sequence, args []value.Value) (value.Value, error) {
+	for i := range s.items {
+		ok, err := s.test(args[0], i)
+		if err != nil || ok {
+			return value.Bool(ok), err
+		}
+	}
+
+	return value.False, nil
+}
+
+func seqFilter(s sequence, args []value.Value) (value.Value, error) {
+	indexes, err := s.indexes(func(i int) (bool, error) { return s.test(args[0], i) })
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.pick(indexes), nil
+}
+
+func seqFind(s sequence, args []value.Value) (value.Value, error) {
+	for i := range s.items {
+		ok, err := s.test(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if ok {
+			return s.items[i], nil
+		}
+	}
+
+	return value.Null, nil
+}
+
+func seqFindLast(s sequence, args []value.Value) (value.Value, error) {
+	for i := len(s.items) - 1; i >= 0; i-- {
+		ok, err := s.test(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if ok {
+			return s.items[i], nil
+		}
+	}
+
+	return value.Null, nil
+}
+
+func seqFlatMap(s sequence, args []value.Value) (value.Value, error) {
+	var out []value.Value
+
+	for i := range s.items {
+		res, err := s.call(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if !res.IsWhollyKnown() {
+			return value.Null, errUnknown
+		}
+
+		items, err := elements(res)
+		if err != nil {
+			return value.Null, err
+		}
+
+		out = append(out, items...)
+	}
+
+	return s.of(out), nil
+}
+
+func seqFlatten(s sequence, _ []value.Value) (value.Value, error) {
+	var out []value.Value
+
+	for _, item := range s.items {
+		items, err := elements(item)
+		if err != nil {
+			return value.Null, err
+		}
+
+		out = append(out, items...)
+	}
+
+	return s.of(out), nil
+}
+
+func seqFoldLeft(s sequence, args []value.Value) (value.Value, error) {
+	return curried("foldLeft", func(f value.Value) (value.Value, error) {
+		acc := args[0]
+
+		for i := range s.items {
+			res, err := s.call(f, i, acc)
+			if err != nil {
+				return value.Null, err
+			}
+
+			acc = res
+		}
+
+		return acc, nil
+	}), nil
+}
+
+func seqFoldRight(s sequence, args []value.Value) (value.Value, error) {
+	return curried("foldRight", func(f value.Value) (value.Value, error) {
+		acc := args[0]
+
+		for i := len(s.items) - 1; i >= 0; i-- {
+			res, err := s.call(f, i, acc)
+			if err != nil {
+				return value.Null, err
+			}
+
+			acc = res
+		}
+
+		return acc, nil
+	}), nil
+}
+
+func seqGroup(s sequence, args []value.Value) (value.Value, error) {
+	n, err := args[0].AsInt()
+	if err != nil {
+		return value.Null, err
+	}
+
+	if n <= 0 {
+		return value.Null, fmt.Errorf("%w: group size must be positive, got %d", value.ErrArgument, n)
+	}
+
+	var out []value.Value
+
+	for i := 0; i < len(s.items); i += n {
+		j := i + n
+		if j > len(s.items) {
+			j = len(s.items)
+		}
+
+		out = append(out, s.between(i, j))
+	}
+
+	return value.List(out...), nil
+}
+
+func seqGroupBy(s sequence, args []value.Value) (value.Value, error) {
+	groups := make(map[string][]int)
+
+	for i := range s.items {
+		res, err := s.call(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if !res.IsKnown() {
+			return value.Null, errUnknown
+		}
+
+		key, ok := templateString(res)
+		if !ok {
+			return value.Null, fmt.Errorf("%w: group key must be a string, a number or a bool, got %s", value.ErrArgument, res.Kind())
+		}
+
+		if res.IsSensitive() {
+			*s.sensitive = true
+		}
+
+		groups[key] = append(groups[key], i)
+	}
+
+	out := make(map[string]value.Value, len(groups))
+	for key, indexes := range groups {
+		out[key] = s.pick(indexes)
+	}
+
+	return value.Map(out), nil
+}
+
+func seqHead(s sequence, _ []value.Value) (value.Value, error) {
+	if len(s.items) == 0 {
+		return value.Null, fmt.Errorf("%w: head of an empty %s", value.ErrIndexOutOfRange, s.kind)
+	}
+
+	return s.items[0], nil
+}
+
+func seqIndexOf(s sequence, args []value.Value) (value.Value, error) {
+	return value.Int(int64(indexOf(s.items, args[0]))), nil
+}
+
+func seqIndexWhere(s sequence, args []value.Value) (value.Value, error) {
+	for i := range s.items {
+		ok, err := s.test(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if ok {
+			return value.Int(int64(i)), nil
+		}
+	}
+
+	return value.Int(-1), nil
+}
+
+func seqIntersect(s sequence, args []value.Value) (value.Value, error) {
+	other, err := elements(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	remaining := append([]value.Value{}, other...)
+
+	var out []value.Value
+
+	for _, item := range s.items {
+		if j := indexOf(remaining, item); j >= 0 {
+			remaining = append(remaining[:j], remaining[j+1:]...)
+			out = append(out, item)
+		}
+	}
+
+	return s.of(out), nil
+}
+
+func seqIsEmpty(s sequence, _ []value.Value) (value.Value, error) {
+	return value.Bool(len(s.items) == 0), nil
+}
+
+func seqLast(s sequence, _ []value.Value) (value.Value, error) {
+	if len(s.items) == 0 {
+		return value.Null, fmt.Errorf("%w: last of an empty %s", value.ErrIndexOutOfRange, s.kind)
+	}
+
+	return s.items[len(s.items)-1], nil
+}
+
+func seqLength(s sequence, _ []value.Value) (value.Value, error) {
+	return value.Int(int64(len(s.items))), nil
+}
+
+func seqMap(s sequence, args []value.Value) (value.Value, error) {
+	out := make([]value.Value, 0, len(s.items))
+
+	for i := range s.items {
+		res, err := s.call(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		out = append(out, res)
+	}
+
+	if s.kind == value.KindMap {
+		entries := make(map[string]value.Value, len(out))
+		for i, item := range out {
+			entries[s.keys[i].AsString()] = item
+		}
+
+		return value.Map(entries), nil
+	}
+
+	return s.of(out), nil
+}
+
+func seqPartition(s sequence, args []value.Value) (value.Value, error) {
+	var yes, no []int
+
+	for i := range s.items {
+		ok, err := s.test(args[0], i)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if ok {
+			yes = append(yes, i)
+		} else {
+			no = append(no, i)
+		}
+	}
+
+	return value.List(s.pick(yes), s.pick(no)), nil
+}
+
+func seqPrepend(s sequence, args []value.Value) (value.Value, error) {
+	return s.of(append([]value.Value{args[0]}, s.items...)), nil
+}
+
+func seqReduceLeft(s sequence, args []value.Value) (value.Value, error) {
+	if len(s.items) == 0 {
+		return value.Null, fmt.Errorf("%w: reduce of an empty %s", value.ErrIndexOutOfRange, s.kind)
+	}
+
+	acc := s.items[0]
+
+	for i := 1; i < len(s.items); i++ {
+		res, err := s.call(args[0], i, acc)
+		if err != nil {
+			return value.Null, err
+		}
+
+		acc = res
+	}
+
+	return acc, nil
+}
+
+func seqReduceRight(s sequence, args []value.Value) (value.Value, error) {
+	if len(s.items) == 0 {
+		return value.Null, fmt.Errorf("%w: reduce of an empty %s", value.ErrIndexOutOfRange, s.kind)
+	}
+
+	acc := s.items[len(s.items)-1]
+
+	for i := len(s.items) - 2; i >= 0; i-- {
+		res, err := s.call(args[0], i, acc)
+		if err != nil {
+			return value.Null, err
+		}
+
+		acc = res
+	}
+
+	return acc, nil
+}
+
+func seqReverse(s sequence, _ []value.Value) (value.Value, error) {
+	out := make([]value.Value, 0, len(s.items))
+	for i := len(s.items) - 1; i >= 0; i-- {
+		out = append(out, s.items[i])
+	}
+
+	return value.List(out...), nil
+}
+
+func seqScanLeft(s sequence, args []value.Value) (value.Value, error) {
+	return curried("scanLeft", func(f value.Value) (value.Value, error) {
+		out := []value.Value{args[0]}
+
+		for i := range s.items {
+			res, err := s.call(f, i, out[len(out)-1])
+			if err != nil {
+				return value.Null, err
+			}
+
+			out = append(out, res)
+		}
+
+		return value.List(out...), nil
+	}), nil
+}
+
+func seqScanRight(s sequence, args []value.Value) (value.Value, error) {
+	return curried("scanRight", func(f value.Value) (value.Value, error) {
+		out := make([]value.Value, len(s.items)+1)
+		out[len(s.items)] = args[0]
+
+		for i := len(s.items) - 1; i >= 0; i-- {
+			res, err := s.call(f, i, out[i+1])
+			if err != nil {
+				return value.Null, err
+			}
+
+			out[i] = res
+		}
+
+		return value.List(out...), nil
+	}), nil
+}
+
+func seqSlice(s sequence, args []value.Value) (value.Value, error) {
+	from, err := s.count(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	until, err := s.count(args[1])
+	if err != nil {
+		return value.Null, err
+	}
+
+	if until < from {
+		until = from
+	}
+
+	return s.between(from, until), nil
+}
+
+func seqSort(s sequence, args []value.Value) (value.Value, error) {
+	out := append([]value.Value{}, s.items...)
+	fn := args[0].AsFunction()
+
+	var sortErr error
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+
+		res, err := fn.Call([]value.Value{out[i], out[j]})
+		if err == nil && !res.IsKnown() && isBool(res) {
+			err = errUnknown
+		}
+
+		if err == nil && res.Kind() != value.KindBool {
+			err = fmt.Errorf("%w: comparison must return a bool, got %s", value.ErrArgument, res.Kind())
+		}
+
+		if err != nil {
+			sortErr = err
+
+			return false
+		}
+
+		if res.IsSensitive() {
+			*s.sensitive = true
+		}
+
+		return res.AsBool()
+	})
+
+	if sortErr != nil {
+		return value.Null, sortErr
+	}
+
+	return value.List(out...), nil
+}
+
+func seqTail(s sequence, _ []value.Value) (value.Value, error) {
+	if len(s.items) == 0 {
+		return value.Null, fmt.Errorf("%w: tail of an empty %s", value.ErrIndexOutOfRange, s.kind)
+	}
+
+	return s.between(1, len(s.items)), nil
+}
+
+func seqTakeLeft(s sequence, args []value.Value) (value.Value, error) {
+	n, err := s.count(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.between(0, n), nil
+}
+
+func seqTakeRight(s sequence, args []value.Value) (value.Value, error) {
+	n, err := s.count(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.between(len(s.items)-n, len(s.items)), nil
+}
+
+func seqTakeWhile(s sequence, args []value.Value) (value.Value, error) {
+	n, err := s.prefix(args[0])
+	if err != nil {
+		return value.Null, err
+	}
+
+	return s.between(0, n), nil
+}
+
+func seqTranspose(s sequence, _ []value.Value) (value.Value, error) {
+	rows := make([][]value.Value, 0, len(s.items))
+
+	for _, item := range s.items {
+		row, err := elements(item)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return value.Null, fmt.Errorf("%w: transpose requires collections of the same length", value.ErrArgument)
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return s.of(nil), nil
+	}
+
+	out := make([]value.Value, 0, len(rows[0]))
+
+	for j := range rows[0] {
+		column := make([]value.Value, 0, len(rows))
+		for _, row := range rows {
+			column = append(column, row[j])
+		}
+
+		out = append(out, s.of(column))
+	}
+
+	return s.of(out), nil
+}
+
+func seqUnzip(s sequence, _ []value.Value) (value.Value, error) {
+	var first, second []value.Value
+
+	for _, item := range s.items {
+		pair, err := elements(item)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if len(pair) != 2 { //nolint:gomnd // pairs
+			return value.Null, fmt.Errorf("%w: unzip requires pairs, got %d elements", value.ErrArgument, len(pair))
+		}
+
+		first = append(first, pair[0])
+		second = append(second, pair[1])
+	}
+
+	return value.List(s.of(first), s.of(second)), nil
+}
+
+// seqZip pairs the elements of the sequence with the ones of the other
+// collections, or, without arguments, the elements of its own collections.
+func seqZip(s sequence, args []value.Value) (value.Value, error) {
+	sources := s.items
+	if len(args) > 0 {
+		sources = append([]value.Value{value.List(s.items...)}, args...)
+	}
+
+	columns := make([][]value.Value, 0, len(sources))
+	length := -1
+
+	for _, src := range sources {
+		items, err := elements(src)
+		if err != nil {
+			return value.Null, err
+		}
+
+		if length < 0 || len(items) < length {
+			length = len(items)
+		}
+
+		columns = append(columns, items)
+	}
+
+	out := make([]value.Value, 0, length)
+
+	for i := 0; i < length; i++ {
+		tuple := make([]value.Value, 0, len(columns))
+		for _, column := range columns {
+			tuple = append(tuple, column[i])
+		}
+
+		out = append(out, value.List(tuple...))
+	}
+
+	return value.List(out...), nil
+}
@@ -0,0 +1,172 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexbee-net/etxe/pkg/value"
+)
+
+func TestSequenceMethods(t *testing.T) {
+	t.Parallel()
+
+	ints := func(items ...int64) value.Value {
+		out := make([]value.Value, 0, len(items))
+		for _, i := range items {
+			out = append(out, value.Int(i))
+		}
+
+		return value.List(out...)
+	}
+
+	intSet := func(items ...int64) value.Value {
+		return value.Set(ints(items...).AsList()...)
+	}
+
+	strs := func(items ...string) value.Value {
+		out := make([]value.Value, 0, len(items))
+		for _, s := range items {
+			out = append(out, value.String(s))
+		}
+
+		return value.List(out...)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  value.Value
+	}{
+		{name: "append", input: `List(1).append(2)`, want: ints(1, 2)},
+		{name: "append to set", input: `Set(2, 1).append(1)`, want: intSet(1, 2)},
+		{name: "appendAll", input: `List(1, 2).appendAll(List(3, 4))`, want: ints(1, 2, 3, 4)},
+		{name: "contains", input: `List(1, 2, 3).contains(2)`, want: value.True},
+		{name: "contains - missing", input: `List(1, 2, 3).contains(5)`, want: value.False},
+		{name: "contains - map key", input: `{a = 1}.contains("a")`, want: value.True},
+		{name: "count", input: `List(1, 2, 3).count((k, v) => v % 2 == 1)`, want: value.Int(2)},
+		{name: "diff", input: `List(1, 2, 3, 4, 4, 4).diff(List(2, 4, 4))`, want: ints(1, 3, 4)},
+		{name: "distinct", input: `List(1, 2, 3, 4, 4, 4).distinct()`, want: ints(1, 2, 3, 4)},
+		{name: "dropLeft", input: `List(1, 2, 3, 4).dropLeft(2)`, want: ints(3, 4)},
+		{name: "dropLeft - past the end", input: `List(1, 2).dropLeft(5)`, want: ints()},
+		{name: "dropRight", input: `List(1, 2, 3, 4).dropRight(2)`, want: ints(1, 2)},
+		{name: "dropWhile", input: `List(1, 2, 3, 4).dropWhile((k, v) => v < 3)`, want: ints(3, 4)},
+		{name: "exists", input: `List(1, 2, 3, 4).exists((k, v) => v < 3)`, want: value.True},
+		{name: "exists - none", input: `List(1, 2, 3, 4).exists((k, v) => v > 5)`, want: value.False},
+		{name: "filter", input: `List(1, 2, 3, 4).filter((k, v) => v % 2 == 1)`, want: ints(1, 3)},
+		{name: "filter - value only", input: `List(1, 2, 3, 4).filter(v => v > 2)`, want: ints(3, 4)},
+		{name: "filter - map", input: `{a = 1, b = 2}.filter((k, v) => k != "a")`, want: value.Map(map[string]value.Value{"b": value.Int(2)})},
+		{name: "find", input: `List(1, 2, 3, 4).find((k, v) => v % 2 == 0)`, want: value.Int(2)},
+		{name: "find - none", input: `List(1, 2, 3, 4).find((k, v) => v > 10)`, want: value.Null},
+		{name: "findLast", input: `List(1, 2, 3, 4).findLast((k, v) => v % 2 == 0)`, want: value.Int(4)},
+		{name: "findLast - none", input: `List(1, 2, 3, 4).findLast((k, v) => v > 10)`, want: value.Null},
+		{name: "flatMap", input: `List(1, 2, 3).flatMap((k, v) => List(v - 1, v, v + 1))`, want: ints(0, 1, 2, 1, 2, 3, 2, 3, 4)},
+		{name: "flatten", input: `List(Set(1, 2, 3), Set(1, 2, 3)).flatten()`, want: ints(1, 2, 3, 1, 2, 3)},
+		{name: "flatten - set", input: `Set(List(1, 2, 3), List(3, 2, 1)).flatten()`, want: intSet(1, 2, 3)},
+		{name: "foldLeft", input: `List("A", "B", "C").foldLeft("d")((acc, k, v) => acc + v)`, want: value.String("dABC")},
+		{name: "foldRight", input: `List("A", "B", "C").foldRight("d")((acc, k, v) => acc + v)`, want: value.String("dCBA")},
+		{name: "foldLeft - map", input: `{a = 1, b = 2}.foldLeft("")((acc, k, v) => acc + k)`, want: value.String("ab")},
+		{
+			name:  "groupBy",
+			input: `List(1, 2, 3, 4).groupBy((k, v) => v % 2)`,
+			want:  value.Map(map[string]value.Value{"0": ints(2, 4), "1": ints(1, 3)}),
+		},
+		{name: "group", input: `List(1, 2, 3, 4).group(2)`, want: value.List(ints(1, 2), ints(3, 4))},
+		{name: "group - remainder", input: `List(1, 2, 3).group(2)`, want: value.List(ints(1, 2), ints(3))},
+		{name: "head", input: `List(1, 2, 3, 4).head()`, want: value.Int(1)},
+		{name: "indexOf", input: `List(10, 20, 30, 40).indexOf(20)`, want: value.Int(1)},
+		{name: "indexOf - missing", input: `List(10, 20, 30, 40).indexOf(50)`, want: value.Int(-1)},
+		{name: "indexWhere", input: `List(10, 20, 30, 40).indexWhere((k, v) => v > 25)`, want: value.Int(2)},
+		{name: "intersect", input: `List(1, 2, 3, 4).intersect(List(2, 4, 5))`, want: ints(2, 4)},
+		{name: "isEmpty", input: `List().isEmpty()`, want: value.True},
+		{name: "isEmpty - not empty", input: `List(1, 2).isEmpty()`, want: value.False},
+		{name: "last", input: `List(1, 2, 3, 4).last()`, want: value.Int(4)},
+		{name: "length", input: `List("a", "b", "c", "d").length()`, want: value.Int(4)},
+		{name: "map", input: `List(1, 2, 3, 4).map((k, v) => v * 10)`, want: ints(10, 20, 30, 40)},
+		{name: "map - map", input: `{a = 1}.map((k, v) => k + "!")`, want: value.Map(map[string]value.Value{"a": value.String("a!")})},
+		{name: "partition", input: `List(1, 2, 3, 4).partition((k, v) => v % 2 == 0)`, want: value.List(ints(2, 4), ints(1, 3))},
+		{name: "prepend", input: `List(1, 2, 3, 4).prepend(5)`, want: ints(5, 1, 2, 3, 4)},
+		{name: "reduceLeft", input: `List("A", "B", "C").reduceLeft((acc, k, v) => acc + v)`, want: value.String("ABC")},
+		{name: "reduceRight", input: `List("A", "B", "C").reduceRight((acc, k, v) => acc + v)`, want: value.String("CBA")},
+		{name: "reverse", input: `List("A", "B", "C").reverse()`, want: strs("C", "B", "A")},
+		{name: "scanLeft", input: `List("A", "B", "C").scanLeft("d")((acc, k, v) => acc + v)`, want: strs("d", "dA", "dAB", "dABC")},
+		{name: "scanRight", input: `List("A", "B", "C").scanRight("d")((acc, k, v) => v + acc)`, want: strs("ABCd", "BCd", "Cd", "d")},
+		{name: "slice", input: `List("A", "B", "C", "D", "E").slice(1, 4)`, want: strs("B", "C", "D")},
+		{name: "sort", input: `List(3, 5, 1, 6, 2).sort((x, y) => x < y)`, want: ints(1, 2, 3, 5, 6)},
+		{name: "tail", input: `List(1, 2, 3, 4, 5, 6).tail()`, want: ints(2, 3, 4, 5, 6)},
+		{name: "takeLeft", input: `List(1, 2, 3, 4, 5, 6).takeLeft(3)`, want: ints(1, 2, 3)},
+		{name: "takeRight", input: `List(1, 2, 3, 4, 5, 6).takeRight(3)`, want: ints(4, 5, 6)},
+		{name: "takeWhile", input: `List(1, 3, 4, 2, 5, 6).takeWhile((k, v) => v < 4)`, want: ints(1, 3)},
+		{
+			name:  "transpose",
+			input: `List(Set(1, 2, 3), Set(4, 5, 6)).transpose()`,
+			want:  value.List(ints(1, 4), ints(2, 5), ints(3, 6)),
+		},
+		{
+			name:  "transpose - set",
+			input: `Set(List(1, 2, 3), List(4, 5, 6)).transpose()`,
+			want:  value.Set(intSet(1, 4), intSet(2, 5), intSet(3, 6)),
+		},
+		{
+			name:  "unzip",
+			input: `List(List(1, 10), List(2, 20), List(3, 30)).unzip()`,
+			want:  value.List(ints(1, 2, 3), ints(10, 20, 30)),
+		},
+		{
+			name:  "zip",
+			input: `List(List(1, 2, 3), List(10, 20, 30)).zip()`,
+			want:  value.List(ints(1, 10), ints(2, 20), ints(3, 30)),
+		},
+		{
+			name:  "zip - other collection",
+			input: `List(1, 2, 3).zip(List(10, 20))`,
+			want:  value.List(ints(1, 10), ints(2, 20)),
+		},
+		{name: "variable receiver", input: `list.filter((k, v) => v > foo).length()`, want: value.Int(0)},
+		{name: "map attribute first", input: `{map = 1}.map`, want: value.Int(1)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, diags := Eval(parseTestExpr(t, tt.input), testEvalContext())
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestSequenceMethods_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		summary string
+	}{
+		{name: "Unknown method", input: `List(1).frobnicate()`, summary: "Unsupported attribute"},
+		{name: "List method on a map", input: `{a = 1}.append(2)`, summary: "Unsupported attribute"},
+		{name: "Head of an empty list", input: `List().head()`, summary: `Error in function call "head"`},
+		{name: "Non-boolean predicate", input: `List(1).filter((k, v) => v)`, summary: `Error in function call "filter"`},
+		{name: "Not a function", input: `List(1).filter(1)`, summary: `Error in function call "filter"`},
+		{name: "Negative count", input: `List(1).takeLeft(-1)`, summary: `Error in function call "takeLeft"`},
+		{name: "Error in lambda", input: `List(1).map((k, v) => v + bar)`, summary: "Unknown variable"},
+		{name: "Ragged transpose", input: `List(List(1), List(1, 2)).transpose()`, summary: `Error in function call "transpose"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, diags := Eval(parseTestExpr(t, tt.input), testEvalContext())
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.summary, diags[0].Summary, diags.Error())
+		})
+	}
+}
@@ -18,6 +18,12 @@ func parser() *participle.Parser {
 		participle.UseLookahead(parserLookahead))
 }
 
+func templateParser() *participle.Parser {
+	return participle.MustBuild(&templateFile{},
+		participle.Lexer(lexer.MustStateful(templateLexRules())),
+		participle.UseLookahead(parserLookahead))
+}
+
 // Parse ETX from an io.Reader.
 func Parse(r io.Reader) (*AST, error) {
 	ast := &AST{}
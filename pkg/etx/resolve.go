@@ -0,0 +1,756 @@
+package etx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hexbee-net/etxe/pkg/etx/funcs"
+)
+
+// ScopeKind is the kind of construct a scope belongs to.
+type ScopeKind int
+
+const (
+	ScopeFile ScopeKind = iota
+	ScopeBlock
+	ScopeFunc
+	ScopeLambda
+	ScopeTemplate
+	ScopeFor
+)
+
+func (k ScopeKind) String() string {
+	switch k {
+	case ScopeFile:
+		return "file"
+	case ScopeBlock:
+		return "block"
+	case ScopeFunc:
+		return "function"
+	case ScopeLambda:
+		return "lambda"
+	case ScopeTemplate:
+		return "template"
+	case ScopeFor:
+		return "for"
+	default:
+		return fmt.Sprintf("scope(%d)", int(k))
+	}
+}
+
+// SymbolKind is the kind of declaration a symbol comes from.
+type SymbolKind int
+
+const (
+	// SymbolValue is an input, output, const or val declaration.
+	SymbolValue SymbolKind = iota
+	// SymbolParameter is a parameter of a function or a lambda, a
+	// variable of a template for directive or a for-expression, or the each
+	// or count variable of a repeated block.
+	SymbolParameter
+	SymbolFunction
+	SymbolType
+	SymbolBlock
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolValue:
+		return "value"
+	case SymbolParameter:
+		return "parameter"
+	case SymbolFunction:
+		return "function"
+	case SymbolType:
+		return "type"
+	case SymbolBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("symbol(%d)", int(k))
+	}
+}
+
+// Symbol is a name declared in a scope.
+//
+// Node is the declaring node: a *Decl, *DeclGroupItem or *FuncDecl for
+// values, a *FuncParameter for the parameters of functions and the names of
+// destructuring declarations, a *LambdaParameter, a *TemplateFor, a
+// *ForClause, a *Func, a *Type or a *Block, for blocks and the each and count
+// variables of repeated blocks.
+type Symbol struct {
+	Name string
+	Kind SymbolKind
+	Node Node
+	Pos  Position
+
+	// DeclType is the keyword declaring values: input, output, const or val.
+	DeclType string
+
+	// Scope is the scope the symbol is declared in, and Refs the
+	// identifiers bound to it.
+	Scope *Scope
+	Refs  []*Ident
+}
+
+// describe returns the kind and name of the symbol, as written in
+// diagnostics.
+func (s *Symbol) describe() string {
+	switch s.Kind {
+	case SymbolValue:
+		return fmt.Sprintf("%s %q", s.DeclType, s.Name)
+	default:
+		return fmt.Sprintf("%s %q", s.Kind, s.Name)
+	}
+}
+
+// Scope is a lexical scope: the names declared by a file, a block, a
+// function body, a lambda or a template for directive. Node is the node the
+// scope belongs to, and is nil for the file scope.
+type Scope struct {
+	Kind     ScopeKind
+	Node     Node
+	Parent   *Scope
+	Children []*Scope
+	Symbols  map[string]*Symbol
+}
+
+func newScope(kind ScopeKind, node Node, parent *Scope) *Scope {
+	s := &Scope{
+		Kind:    kind,
+		Node:    node,
+		Parent:  parent,
+		Symbols: make(map[string]*Symbol),
+	}
+
+	if parent != nil {
+		parent.Children = append(parent.Children, s)
+	}
+
+	return s
+}
+
+// Lookup returns the symbol named name in the scope or its ancestors.
+func (s *Scope) Lookup(name string) (*Symbol, bool) {
+	for scope := s; scope != nil; scope = scope.Parent {
+		if sym, ok := scope.Symbols[name]; ok {
+			return sym, true
+		}
+	}
+
+	return nil, false
+}
+
+// names returns the names visible from the scope.
+func (s *Scope) names() []string {
+	var names []string
+
+	for scope := s; scope != nil; scope = scope.Parent {
+		for name := range scope.Symbols {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolution binds the identifiers of an AST to the symbols they reference.
+type Resolution struct {
+	// Root is the file scope.
+	Root *Scope
+
+	// Types are the types of the file, referenced by their name in type
+	// annotations and through the enum namespace, and Blocks the root
+	// blocks, referenced by their name followed by their labels.
+	Types  map[string]*Symbol
+	Blocks []*Symbol
+
+	bindings map[*Ident]*Symbol
+}
+
+// Binding returns the symbol referenced by an identifier. The identifiers
+// referencing library functions and the undefined names have no binding.
+func (r *Resolution) Binding(i *Ident) (*Symbol, bool) {
+	sym, ok := r.bindings[i]
+
+	return sym, ok
+}
+
+// builtinTypes are the names of the types that are not defined by modules.
+//
+//nolint:gochecknoglobals // immutable name set
+var builtinTypes = map[string]bool{
+	"any":    true,
+	"bool":   true,
+	"number": true,
+	"string": true,
+	"list":   true,
+	"set":    true,
+	"map":    true,
+	"null":   true,
+}
+
+// Resolve builds the scopes of an AST and binds each identifier to the
+// declaration it references.
+//
+// The values, functions, types and blocks of the file are visible to the
+// whole file, whereas the declarations of a function body are only visible
+// to the statements after them, like at runtime. Undefined names are
+// reported as errors, and declarations shadowing a declaration of an
+// enclosing scope and vals that are never referenced as warnings.
+func Resolve(ast *AST) (*Resolution, Diagnostics) {
+	var diags Diagnostics
+
+	r := &resolver{
+		res: &Resolution{
+			Root:     newScope(ScopeFile, nil, nil),
+			Types:    make(map[string]*Symbol),
+			bindings: make(map[*Ident]*Symbol),
+		},
+		diags: &diags,
+	}
+
+	for _, item := range ast.Items {
+		switch {
+		case item.Decl != nil && len(item.Decl.Group) != 0:
+			r.declareGroup(r.res.Root, item.Decl.DeclType, item.Decl.Group)
+		case item.Decl != nil:
+			r.declare(r.res.Root, &Symbol{
+				Name: item.Decl.Label, Kind: SymbolValue, Node: item.Decl, Pos: item.Decl.Pos, DeclType: item.Decl.DeclType,
+			})
+		case item.Func != nil:
+			r.declare(r.res.Root, &Symbol{Name: item.Func.Label, Kind: SymbolFunction, Node: item.Func, Pos: item.Func.Pos})
+		case item.Type != nil:
+			r.declareType(item.Type)
+		case item.Block != nil:
+			r.declareBlock(item.Block)
+		case item.If != nil:
+			for _, body := range item.If.Body {
+				if body.Block != nil {
+					r.declareBlock(body.Block)
+				}
+			}
+		}
+	}
+
+	for _, item := range ast.Items {
+		switch {
+		case item.Decl != nil:
+			r.resolveDecl(r.res.Root, item.Decl)
+		case item.Func != nil:
+			r.resolveFunc(r.res.Root, item.Func)
+		case item.Type != nil:
+			r.resolve(r.res.Root, item.Type)
+		case item.Block != nil:
+			r.resolve(r.res.Root, item.Block)
+		case item.If != nil:
+			r.resolve(r.res.Root, item.If)
+		case item.Attribute != nil:
+			r.resolve(r.res.Root, item.Attribute)
+		}
+	}
+
+	r.reportUnused(r.res.Root)
+
+	diags.Sort()
+
+	return r.res, diags
+}
+
+// blockAddress returns the name of a block followed by its labels, as
+// referenced by identifiers.
+func blockAddress(b *Block) string {
+	return strings.Join(append([]string{b.Name}, b.Labels...), ".")
+}
+
+type resolver struct {
+	res   *Resolution
+	diags *Diagnostics
+}
+
+func (r *resolver) report(diags ...*Diagnostic) {
+	*r.diags = append(*r.diags, diags...)
+}
+
+// declare adds a symbol to a scope. Declaring a name twice in a scope is an
+// error, and hiding the declaration of an enclosing scope a warning.
+func (r *resolver) declare(scope *Scope, sym *Symbol) {
+	if sym.Name == "" {
+		return
+	}
+
+	if _, exists := scope.Symbols[sym.Name]; exists {
+		r.report(errorDiag(sym.Pos, "Duplicate declaration", fmt.Sprintf("%q is already declared in this scope", sym.Name)))
+
+		return
+	}
+
+	if scope.Parent != nil {
+		if outer, ok := scope.Parent.Lookup(sym.Name); ok {
+			r.report(warningDiag(sym.Pos, "Shadowed declaration",
+				fmt.Sprintf("%s shadows the %s declared at %s", sym.describe(), outer.describe(), outer.Pos)))
+		}
+	}
+
+	sym.Scope = scope
+	scope.Symbols[sym.Name] = sym
+}
+
+func (r *resolver) declareGroup(scope *Scope, declType string, group []*DeclGroupItem) {
+	for _, item := range group {
+		if item.Label == "" {
+			continue
+		}
+
+		r.declare(scope, &Symbol{Name: item.Label, Kind: SymbolValue, Node: item, Pos: item.Pos, DeclType: declType})
+	}
+}
+
+// declareBlock adds a root block, or a block of a top-level if block, to
+// the blocks referenced by their name followed by their labels.
+func (r *resolver) declareBlock(b *Block) {
+	r.res.Blocks = append(r.res.Blocks, &Symbol{
+		Name: blockAddress(b), Kind: SymbolBlock, Node: b, Pos: b.Pos, Scope: r.res.Root,
+	})
+}
+
+func (r *resolver) declareType(n *Type) {
+	if _, exists := r.res.Types[n.Label]; exists {
+		r.report(errorDiag(n.Pos, "Duplicate type", fmt.Sprintf("type %q is already defined", n.Label)))
+
+		return
+	}
+
+	r.res.Types[n.Label] = &Symbol{Name: n.Label, Kind: SymbolType, Node: n, Pos: n.Pos, Scope: r.res.Root}
+}
+
+// reportUnused reports the vals of a scope and its children that are never
+// referenced.
+func (r *resolver) reportUnused(scope *Scope) {
+	for _, sym := range scope.Symbols {
+		if sym.Kind == SymbolValue && sym.DeclType == "val" && len(sym.Refs) == 0 {
+			r.report(warningDiag(sym.Pos, "Unused value", fmt.Sprintf("val %q is never used", sym.Name)))
+		}
+	}
+
+	for _, child := range scope.Children {
+		r.reportUnused(child)
+	}
+}
+
+func (r *resolver) bind(i *Ident, sym *Symbol) {
+	sym.Refs = append(sym.Refs, i)
+	r.res.bindings[i] = sym
+}
+
+// /////////////////////////////////////
+
+// resolveDecl resolves the values of a root declaration. The names are
+// already declared.
+func (r *resolver) resolveDecl(scope *Scope, n *Decl) {
+	if len(n.Group) != 0 {
+		for _, item := range n.Group {
+			r.resolve(scope, item)
+		}
+
+		return
+	}
+
+	for _, child := range n.Children() {
+		r.resolve(scope, child)
+	}
+}
+
+// resolveFunc resolves the body of a function in a new scope holding its
+// parameters. The declarations of the body are visible to the statements
+// after them.
+func (r *resolver) resolveFunc(scope *Scope, n *Func) {
+	for _, item := range n.Return {
+		r.resolve(scope, item)
+	}
+
+	body := newScope(ScopeFunc, n, scope)
+
+	for _, p := range n.Parameters {
+		if p.Type != nil {
+			r.resolve(scope, p.Type)
+		}
+
+		r.declare(body, &Symbol{Name: p.Label, Kind: SymbolParameter, Node: p, Pos: p.Pos})
+	}
+
+	for _, stmt := range n.Body {
+		switch {
+		case stmt.Decl != nil:
+			r.resolveFuncDecl(body, stmt.Decl)
+		case stmt.Return != nil:
+			r.resolve(body, stmt.Return)
+		case stmt.Expr != nil:
+			r.resolve(body, stmt.Expr)
+		}
+	}
+}
+
+// resolveFuncDecl resolves the value of a declaration of a function body
+// before declaring its names, so the value cannot reference them. The
+// values of a grouped declaration block may reference each other.
+func (r *resolver) resolveFuncDecl(scope *Scope, n *FuncDecl) {
+	if len(n.Group) != 0 {
+		r.declareGroup(scope, n.DeclType, n.Group)
+
+		for _, item := range n.Group {
+			r.resolve(scope, item)
+		}
+
+		return
+	}
+
+	for _, child := range n.Children() {
+		if _, ok := child.(*FuncParameter); !ok {
+			r.resolve(scope, child)
+		}
+	}
+
+	if len(n.Targets) == 0 {
+		r.declare(scope, &Symbol{Name: n.Label, Kind: SymbolValue, Node: n, Pos: n.Pos, DeclType: n.DeclType})
+
+		return
+	}
+
+	for _, target := range n.Targets {
+		if target.Type != nil {
+			r.resolve(scope, target.Type)
+		}
+
+		r.declare(scope, &Symbol{Name: target.Label, Kind: SymbolValue, Node: target, Pos: target.Pos, DeclType: n.DeclType})
+	}
+}
+
+// resolve binds the identifiers of a node and its children in scope.
+func (r *resolver) resolve(scope *Scope, node Node) {
+	switch n := node.(type) {
+	case *Ident:
+		r.resolveIdent(scope, n)
+
+		return
+
+	case *ParameterType:
+		r.resolveParameterType(scope, n)
+
+		return
+
+	case *MapKey:
+		// Identifier keys are names, not references.
+		if n.Str != nil {
+			r.resolve(scope, n.Str)
+		}
+
+		return
+
+	case *Block:
+		r.resolveBlock(scope, n)
+
+		return
+
+	case *ForList:
+		r.resolveFor(scope, n, n.Clause)
+
+		return
+
+	case *ForMap:
+		r.resolveFor(scope, n, n.Clause)
+
+		return
+
+	case *Lambda:
+		inner := newScope(ScopeLambda, n, scope)
+
+		for _, p := range n.Parameters {
+			if p.Type != nil {
+				r.resolve(scope, p.Type)
+			}
+
+			r.declare(inner, &Symbol{Name: p.Label, Kind: SymbolParameter, Node: p, Pos: p.Pos})
+		}
+
+		r.resolve(inner, &n.Expr)
+
+		return
+
+	case *ValueString:
+		parts := make([]templatePart, 0, len(n.Fragment))
+		for _, f := range n.Fragment {
+			parts = append(parts, templatePart{expr: f.Expr, directive: f.Directive})
+		}
+
+		r.resolveTemplate(scope, parts)
+
+		return
+
+	case *Heredoc:
+		parts := make([]templatePart, 0, len(n.Fragments))
+		for _, f := range n.Fragments {
+			parts = append(parts, templatePart{expr: f.Expr, directive: f.Directive})
+		}
+
+		r.resolveTemplate(scope, parts)
+
+		return
+
+	case *ExprPostfix:
+		r.resolve(scope, &n.Value)
+
+		if n.Index != nil {
+			r.resolve(scope, n.Index)
+		}
+
+		r.resolveMember(scope, n.Post)
+
+		return
+
+	case *ExprPrimary:
+		if n.Ident != nil {
+			r.resolve(scope, n.Ident)
+
+			for _, item := range n.Monads {
+				r.resolve(scope, item)
+			}
+
+			r.resolveMember(scope, n.Post)
+
+			return
+		}
+	}
+
+	for _, child := range node.Children() {
+		r.resolve(scope, child)
+	}
+}
+
+// resolveBlock resolves the body of a block in a new scope. The for_each
+// and count meta-attributes are resolved in the enclosing scope, and declare
+// each and count in the scope of the block.
+func (r *resolver) resolveBlock(scope *Scope, n *Block) {
+	inner := newScope(ScopeBlock, n, scope)
+
+	for _, item := range n.Body {
+		switch {
+		case item.ForEach != nil:
+			r.resolve(scope, item.ForEach)
+			r.declare(inner, &Symbol{Name: eachVariable, Kind: SymbolParameter, Node: n, Pos: item.Pos})
+		case item.Count != nil:
+			r.resolve(scope, item.Count)
+			r.declare(inner, &Symbol{Name: countVariable, Kind: SymbolParameter, Node: n, Pos: item.Pos})
+		}
+	}
+
+	for _, item := range n.Body {
+		if item.ForEach == nil && item.Count == nil {
+			r.resolve(inner, item)
+		}
+	}
+}
+
+// resolveFor resolves the collection of a for-expression, and the rest of
+// the expression in a new scope holding its key and value variables.
+func (r *resolver) resolveFor(scope *Scope, n Node, clause *ForClause) {
+	r.resolve(scope, clause)
+
+	inner := newScope(ScopeFor, n, scope)
+	if clause.Key != "" {
+		r.declare(inner, &Symbol{Name: clause.Key, Kind: SymbolParameter, Node: clause, Pos: clause.Pos})
+	}
+
+	r.declare(inner, &Symbol{Name: clause.Value, Kind: SymbolParameter, Node: clause, Pos: clause.Pos})
+
+	for _, child := range n.Children() {
+		if child != Node(clause) {
+			r.resolve(inner, child)
+		}
+	}
+}
+
+// resolveMember resolves the arguments of an attribute or method access,
+// whose name is not a reference.
+func (r *resolver) resolveMember(scope *Scope, post *ExprPostfix) {
+	if post == nil {
+		return
+	}
+
+	if post.Value.Ident == nil {
+		r.resolve(scope, &post.Value)
+	} else {
+		for _, item := range post.Value.Monads {
+			r.resolve(scope, item)
+		}
+
+		r.resolveMember(scope, post.Value.Post)
+	}
+
+	if post.Index != nil {
+		r.resolve(scope, post.Index)
+	}
+
+	r.resolveMember(scope, post.Post)
+}
+
+// resolveTemplate resolves the interpolations and directives of a template.
+// The variables of a for directive are declared in a new scope, up to the
+// matching endfor.
+func (r *resolver) resolveTemplate(scope *Scope, parts []templatePart) {
+	stack := []*Scope{scope}
+
+	for _, part := range parts {
+		current := stack[len(stack)-1]
+
+		switch d := part.directive; {
+		case part.expr != nil:
+			r.resolve(current, part.expr)
+		case d == nil:
+		case d.If != nil:
+			r.resolve(current, d.If)
+		case d.For != nil:
+			r.resolve(current, d.For.Collection)
+
+			inner := newScope(ScopeTemplate, d.For, current)
+			if d.For.Key != "" {
+				r.declare(inner, &Symbol{Name: d.For.Key, Kind: SymbolParameter, Node: d.For, Pos: d.For.Pos})
+			}
+
+			r.declare(inner, &Symbol{Name: d.For.Value, Kind: SymbolParameter, Node: d.For, Pos: d.For.Pos})
+
+			stack = append(stack, inner)
+		case d.EndFor && len(stack) > 1:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// resolveParameterType binds the names of a type annotation to the types of
+// the file. The defaults of optional attributes are resolved as values.
+func (r *resolver) resolveParameterType(scope *Scope, n *ParameterType) {
+	if n.Ident != nil {
+		r.resolveTypeName(n.Ident)
+	}
+
+	for _, child := range n.Children() {
+		if child != n.Ident {
+			r.resolve(scope, child)
+		}
+	}
+}
+
+func (r *resolver) resolveTypeName(i *Ident) {
+	name := i.Parts[0]
+	if len(i.Parts) == 2 && name == enumNamespace { //nolint:gomnd // enum.name
+		name = i.Parts[1]
+	} else if len(i.Parts) != 1 || builtinTypes[name] {
+		return
+	}
+
+	if sym, ok := r.res.Types[name]; ok {
+		r.bind(i, sym)
+
+		return
+	}
+
+	r.report(errorDiag(i.Pos, "Undefined type", undefinedDetail("type", i.FormattedString(), name, r.typeNames())))
+}
+
+func (r *resolver) typeNames() []string {
+	names := make([]string, 0, len(r.res.Types))
+	for name := range r.res.Types {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// resolveIdent binds a dotted identifier, by its first part, to the symbol
+// of a scope. Otherwise, enum.name references a type of the file and the
+// name of a root block followed by its labels the block itself. The
+// identifiers of library functions are not bound.
+func (r *resolver) resolveIdent(scope *Scope, i *Ident) {
+	root := i.Parts[0]
+
+	if sym, ok := scope.Lookup(root); ok {
+		r.bind(i, sym)
+
+		return
+	}
+
+	if root == enumNamespace {
+		r.resolveEnum(i)
+
+		return
+	}
+
+	if sym, ok := r.lookupBlock(i.Parts); ok {
+		r.bind(i, sym)
+
+		return
+	}
+
+	if _, ok := (&funcs.Sandbox{}).Function(root); ok {
+		return
+	}
+
+	if _, ok := builtinFunctions[root]; ok {
+		return
+	}
+
+	for _, sym := range r.res.Blocks {
+		if sym.Node.(*Block).Name == root { //nolint:forcetypeassert // blocks are *Block
+			r.report(errorDiag(i.Pos, "Undefined reference",
+				fmt.Sprintf("there is no %s block matching %q", root, i.FormattedString())))
+
+			return
+		}
+	}
+
+	r.report(errorDiag(i.Pos, "Undefined name", undefinedDetail("declaration", root, root, scope.names())))
+}
+
+func (r *resolver) resolveEnum(i *Ident) {
+	if len(i.Parts) < 2 { //nolint:gomnd // enum.name
+		r.report(errorDiag(i.Pos, "Invalid enum reference", fmt.Sprintf("expected an enum name after %q", enumNamespace)))
+
+		return
+	}
+
+	sym, ok := r.res.Types[i.Parts[1]]
+	if !ok || sym.Node.(*Type).Enum == nil { //nolint:forcetypeassert // types are *Type
+		r.report(errorDiag(i.Pos, "Unknown enum", fmt.Sprintf("there is no enum named %q", i.Parts[1])))
+
+		return
+	}
+
+	r.bind(i, sym)
+}
+
+// lookupBlock returns the root block whose name and labels are a prefix of
+// the parts of an identifier.
+func (r *resolver) lookupBlock(parts []string) (*Symbol, bool) {
+	for _, sym := range r.res.Blocks {
+		if sym.Node.(*Block).matches(parts) { //nolint:forcetypeassert // blocks are *Block
+			return sym, true
+		}
+	}
+
+	return nil, false
+}
+
+// undefinedDetail describes an undefined name, with the closest candidate
+// when it is likely a misspelling.
+func undefinedDetail(kind, ref, name string, candidates []string) string {
+	detail := fmt.Sprintf("there is no %s named %q", kind, ref)
+	if suggestion, ok := suggest(name, candidates); ok {
+		detail += fmt.Sprintf("; did you mean %q?", suggestion)
+	}
+
+	return detail
+}
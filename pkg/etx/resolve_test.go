@@ -0,0 +1,214 @@
+package etx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	ast, err := ParseString(`
+input region: string
+val (
+	c = b * 2
+	b = a + 1
+)
+val a = 1
+val d = scale(c)("${region}-%{ for k, v in [d] }${k}${v}%{ endfor }")
+
+type color enum {
+	red: 1
+}
+
+resource "foo" {
+	id = upper(region)
+}
+
+def scale(v: number) (string) -> string {
+	val (x, y: number) = [v, enum.color.red]
+	(s) => s.map((w) => w * x * y).take(1)
+}
+
+def name(e: enum.color) color {
+	resource.foo.id
+}
+`)
+	require.NoError(t, err)
+
+	res, diags := Resolve(ast)
+	require.Empty(t, diags, diags.Error())
+
+	refs := make(map[string][]string)
+
+	var walk func(node Node)
+	walk = func(node Node) {
+		if i, ok := node.(*Ident); ok {
+			if sym, bound := res.Binding(i); bound {
+				refs[i.FormattedString()] = append(refs[i.FormattedString()], sym.Kind.String()+" "+sym.Name+" "+sym.Pos.String())
+			}
+		}
+
+		if post, ok := node.(*ExprPostfix); ok && post.Post != nil {
+			walk(post.Post)
+		}
+
+		for _, child := range node.Children() {
+			walk(child)
+		}
+	}
+
+	for _, item := range ast.Items {
+		walk(item)
+	}
+
+	assert.Equal(t, map[string][]string{
+		"a":               {"value a 7:1"},
+		"b":               {"value b 5:2"},
+		"c":               {"value c 4:2"},
+		"d":               {"value d 8:1"},
+		"k":               {"parameter k 8:32"},
+		"v":               {"parameter v 8:32", "parameter v 18:11"},
+		"w":               {"parameter w 20:16"},
+		"x":               {"value x 19:7"},
+		"y":               {"value y 19:10"},
+		"s.map":           {"parameter s 20:3"},
+		"region":          {"value region 2:1", "value region 2:1"},
+		"scale":           {"function scale 18:1"},
+		"color":           {"type color 10:1"},
+		"enum.color":      {"type color 10:1"},
+		"enum.color.red":  {"type color 10:1"},
+		"resource.foo.id": {"block resource.foo 14:1"},
+	}, refs)
+
+	sym, ok := res.Root.Lookup("scale")
+	require.True(t, ok)
+	assert.Equal(t, SymbolFunction, sym.Kind)
+
+	kinds := make([]string, 0, len(res.Root.Children))
+	for _, scope := range res.Root.Children {
+		kinds = append(kinds, scope.Kind.String())
+	}
+
+	assert.Equal(t, []string{"template", "block", "function", "function"}, kinds)
+}
+
+func TestResolve_Diagnostics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "Undefined name",
+			input: "const value = 1\nconst b = valeu + 1",
+			want:  []string{`2:11: error: Undefined name; there is no declaration named "valeu"; did you mean "value"?`},
+		},
+		{
+			name:  "Library function",
+			input: `val a = upper("a")`,
+			want:  []string{`1:1: warning: Unused value; val "a" is never used`},
+		},
+		{
+			name:  "Declared later in a function body",
+			input: "def f() {\n\tval a = b\n\tconst b = 1\n\ta\n}",
+			want:  []string{`2:10: error: Undefined name; there is no declaration named "b"`},
+		},
+		{
+			name:  "Redeclared in a function body",
+			input: "def f(a) {\n\tval a = 1\n}",
+			want: []string{
+				`2:2: error: Duplicate declaration; "a" is already declared in this scope`,
+			},
+		},
+		{
+			name:  "Shadowed parameter",
+			input: "const a = 1\ndef f(a) { a }",
+			want:  []string{`2:7: warning: Shadowed declaration; parameter "a" shadows the const "a" declared at 1:1`},
+		},
+		{
+			name:  "Shadowed in a lambda",
+			input: "const f = (x) => (x) => x",
+			want:  []string{`1:19: warning: Shadowed declaration; parameter "x" shadows the parameter "x" declared at 1:12`},
+		},
+		{
+			name:  "Unused value in a function body",
+			input: "def f() {\n\tval (a, b) = [1, 2]\n\ta\n}",
+			want:  []string{`2:10: warning: Unused value; val "b" is never used`},
+		},
+		{
+			name:  "Template variable out of its loop",
+			input: `const a = "%{ for v in [1] }${v}%{ endfor }${v}"`,
+			want:  []string{`1:46: error: Undefined name; there is no declaration named "v"`},
+		},
+		{
+			name:  "For variable out of its expression",
+			input: "const a = [for v in [1] : v]\nconst b = v",
+			want:  []string{`2:11: error: Undefined name; there is no declaration named "v"`},
+		},
+		{
+			name:  "Shadowed in a for-expression",
+			input: "const v = 1\nconst a = [for v in [v] : v]",
+			want:  []string{`2:12: warning: Shadowed declaration; parameter "v" shadows the const "v" declared at 1:1`},
+		},
+		{
+			name:  "Attribute and map key",
+			input: "const a = { key = 1 }.key",
+			want:  nil,
+		},
+		{
+			name:  "Undefined block",
+			input: "resource \"foo\" {}\nconst a = resource.bar.id",
+			want:  []string{`2:11: error: Undefined reference; there is no resource block matching "resource.bar.id"`},
+		},
+		{
+			name:  "Block in an if block",
+			input: "const on = true\nif on {\n\tresource \"foo\" {}\n}\nconst a = resource.foo.id\nconst b = resource.bar.id",
+			want:  []string{`6:11: error: Undefined reference; there is no resource block matching "resource.bar.id"`},
+		},
+		{
+			name:  "Repeated block",
+			input: "const names = [\"a\"]\nresource \"foo\" {\n\tfor_each: names\n\tid = each.key\n\tsub {\n\t\tcount: 1\n\t\tid = \"${each.value}-${count.index}\"\n\t}\n}\nconst a = each.key",
+			want:  []string{`10:11: error: Undefined name; there is no declaration named "each"`},
+		},
+		{
+			name:  "Unknown enum",
+			input: "type t object {}\nconst a = enum.t.a",
+			want:  []string{`2:11: error: Unknown enum; there is no enum named "t"`},
+		},
+		{
+			name:  "Undefined type",
+			input: "type server object {}\nconst a: servr = {}",
+			want:  []string{`2:10: error: Undefined type; there is no type named "servr"; did you mean "server"?`},
+		},
+		{
+			name:  "Duplicate type",
+			input: "type t object {}\ntype t object {}",
+			want:  []string{`2:1: error: Duplicate type; type "t" is already defined`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ast, err := ParseString(tt.input)
+			require.NoError(t, err)
+
+			_, diags := Resolve(ast)
+
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.Error())
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package etx
+
+import (
+	"fmt"
+	"strings"
+)
+
+type TemplateDirective struct {
+	ASTNode
+
+	StripLeft  bool         `parser:"( Directive | @DirectiveStrip )" json:"strip_left,omitempty"`
+	If         *Expr        `parser:"(   If @@                      " json:"if,omitempty"`
+	Else       bool         `parser:"  | @Else                      " json:"else,omitempty"`
+	EndIf      bool         `parser:"  | @'endif'                   " json:"end_if,omitempty"`
+	For        *TemplateFor `parser:"  | @@                         " json:"for,omitempty"`
+	EndFor     bool         `parser:"  | @'endfor' )                " json:"end_for,omitempty"`
+	StripRight bool         `parser:"( ExprEnd | @ExprStripEnd )    " json:"strip_right,omitempty"`
+}
+
+func (d *TemplateDirective) Clone() *TemplateDirective {
+	if d == nil {
+		return nil
+	}
+
+	return &TemplateDirective{
+		ASTNode:    d.ASTNode.Clone(),
+		StripLeft:  d.StripLeft,
+		If:         d.If.Clone(),
+		Else:       d.Else,
+		EndIf:      d.EndIf,
+		For:        d.For.Clone(),
+		EndFor:     d.EndFor,
+		StripRight: d.StripRight,
+	}
+}
+
+func (d *TemplateDirective) Children() (children []Node) {
+	if d.If != nil {
+		children = append(children, d.If)
+	}
+
+	if d.For != nil {
+		children = append(children, d.For)
+	}
+
+	return
+}
+
+func (d TemplateDirective) FormattedString() string {
+	var sb strings.Builder
+
+	sb.WriteString(templateOpen("%{", d.StripLeft))
+
+	switch {
+	case d.If != nil:
+		mustFprintf(&sb, "if %s", d.If.FormattedString())
+	case d.Else:
+		sb.WriteString("else")
+	case d.EndIf:
+		sb.WriteString("endif")
+	case d.For != nil:
+		sb.WriteString(d.For.FormattedString())
+	case d.EndFor:
+		sb.WriteString("endfor")
+	default:
+		panic("directive not set")
+	}
+
+	sb.WriteString(templateClose(d.StripRight))
+
+	return sb.String()
+}
+
+type TemplateFor struct {
+	ASTNode
+
+	Key        string `parser:"'for' ( @Ident ',' )?" json:"key,omitempty"`
+	Value      string `parser:"@Ident 'in'"           json:"value,omitempty"`
+	Collection *Expr  `parser:"@@"                    json:"collection,omitempty"`
+}
+
+func (f *TemplateFor) Clone() *TemplateFor {
+	if f == nil {
+		return nil
+	}
+
+	return &TemplateFor{
+		ASTNode:    f.ASTNode.Clone(),
+		Key:        f.Key,
+		Value:      f.Value,
+		Collection: f.Collection.Clone(),
+	}
+}
+
+func (f *TemplateFor) Children() (children []Node) {
+	if f.Collection != nil {
+		children = append(children, f.Collection)
+	}
+
+	return
+}
+
+func (f TemplateFor) FormattedString() string {
+	if f.Key != "" {
+		return fmt.Sprintf("for %s, %s in %s", f.Key, f.Value, f.Collection.FormattedString())
+	}
+
+	return fmt.Sprintf("for %s in %s", f.Value, f.Collection.FormattedString())
+}
+
+func templateOpen(marker string, strip bool) string {
+	if strip {
+		return marker + "~ "
+	}
+
+	return marker + " "
+}
+
+func templateClose(strip bool) string {
+	if strip {
+		return " ~}"
+	}
+
+	return " }"
+}
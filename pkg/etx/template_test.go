@@ -0,0 +1,195 @@
+package etx
+
+import (
+	"testing"
+)
+
+func TestTemplateDirective_Parsing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		want    *ValueString
+	}{
+		{
+			name:  "If",
+			input: `"%{ if foo }"`,
+			want: &ValueString{
+				Fragment: []*StringFragment{
+					{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
+				},
+			},
+		},
+		{
+			name:  "Else and endif",
+			input: `"%{else}%{endif}"`,
+			want: &ValueString{
+				Fragment: []*StringFragment{
+					{Directive: &TemplateDirective{Else: true}},
+					{Directive: &TemplateDirective{EndIf: true}},
+				},
+			},
+		},
+		{
+			name:  "For",
+			input: `"%{ for x in foo }%{ endfor }"`,
+			want: &ValueString{
+				Fragment: []*StringFragment{
+					{Directive: &TemplateDirective{For: &TemplateFor{
+						Value:      "x",
+						Collection: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+					}}},
+					{Directive: &TemplateDirective{EndFor: true}},
+				},
+			},
+		},
+		{
+			name:  "For with key",
+			input: `"%{ for k, v in foo }"`,
+			want: &ValueString{
+				Fragment: []*StringFragment{
+					{Directive: &TemplateDirective{For: &TemplateFor{
+						Key:        "k",
+						Value:      "v",
+						Collection: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+					}}},
+				},
+			},
+		},
+		{
+			name:  "Strip markers",
+			input: `"%{~ endif ~}${~ foo ~}${~foo}"`,
+			want: &ValueString{
+				Fragment: []*StringFragment{
+					{Directive: &TemplateDirective{StripLeft: true, EndIf: true, StripRight: true}},
+					{StripLeft: true, Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}), StripRight: true},
+					{StripLeft: true, Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+				},
+			},
+		},
+		{
+			name:    "Unknown directive",
+			input:   `"%{ foo }"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testParser(t, tt.input, tt.want, tt.wantErr, false)
+		})
+	}
+}
+
+func TestTemplateDirective_Clone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input *TemplateDirective
+		want  *TemplateDirective
+	}{
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "If",
+			input: &TemplateDirective{StripLeft: true, If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+			want:  &TemplateDirective{StripLeft: true, If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+		},
+		{
+			name: "For",
+			input: &TemplateDirective{For: &TemplateFor{
+				Key:        "k",
+				Value:      "v",
+				Collection: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+			}},
+			want: &TemplateDirective{For: &TemplateFor{
+				Key:        "k",
+				Value:      "v",
+				Collection: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+			}},
+		},
+		{
+			name:  "EndFor",
+			input: &TemplateDirective{EndFor: true, StripRight: true},
+			want:  &TemplateDirective{EndFor: true, StripRight: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCloner[*TemplateDirective](t, tt.want, tt.input)
+		})
+	}
+}
+
+func TestTemplateDirective_FormattedString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		input     *TemplateDirective
+		wantPanic bool
+		want      string
+	}{
+		{
+			name:      "Empty",
+			input:     &TemplateDirective{},
+			wantPanic: true,
+			want:      "directive not set",
+		},
+		{
+			name:  "If",
+			input: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+			want:  `%{ if foo }`,
+		},
+		{
+			name:  "Else",
+			input: &TemplateDirective{Else: true, StripLeft: true},
+			want:  `%{~ else }`,
+		},
+		{
+			name:  "EndIf",
+			input: &TemplateDirective{EndIf: true, StripRight: true},
+			want:  `%{ endif ~}`,
+		},
+		{
+			name:  "For",
+			input: &TemplateDirective{For: &TemplateFor{Value: "x", Collection: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
+			want:  `%{ for x in foo }`,
+		},
+		{
+			name:  "For with key",
+			input: &TemplateDirective{For: &TemplateFor{Key: "k", Value: "v", Collection: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
+			want:  `%{ for k, v in foo }`,
+		},
+		{
+			name:  "EndFor",
+			input: &TemplateDirective{EndFor: true},
+			want:  `%{ endfor }`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testStringer(t, tt.wantPanic, tt.want, tt.input)
+		})
+	}
+}
@@ -110,6 +110,8 @@ func BuildTestExprTree[E any](t *testing.T, value any) E {
 			return build(&ExprPrimary{ASTNode: v.ASTNode, Ident: v}, stop)
 		case *Value:
 			return build(&ExprPrimary{ASTNode: v.ASTNode, Value: v}, stop)
+		case *Lambda:
+			return build(&ExprPrimary{ASTNode: v.ASTNode, Lambda: v}, stop)
 		default:
 			panic("invalid type for expression tree")
 		}
@@ -119,3 +121,52 @@ func BuildTestExprTree[E any](t *testing.T, value any) E {
 
 	return build(value, reflect.TypeOf(stopVal)).(E) //nolint:forcetypeassert // only used in tests
 }
+
+// suggest returns the candidate closest to name, when it is close enough
+// for name to be a likely misspelling of it.
+func suggest(name string, candidates []string) (string, bool) {
+	best, bestDistance := "", len(name)/2+1 //nolint:gomnd // up to half of the name
+
+	for _, candidate := range candidates {
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best, best != ""
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
@@ -46,7 +46,9 @@ func testParser[T any](t *testing.T, input string, want *T, wantErr, compareNode
 	t.Helper()
 
 	var res T
-	parser := participle.MustBuild(&res, participle.Lexer(lexer.MustStateful(lexRules())))
+	parser := participle.MustBuild(&res,
+		participle.Lexer(lexer.MustStateful(lexRules())),
+		participle.UseLookahead(parserLookahead))
 
 	err := parser.ParseString("", input, &res)
 
@@ -25,6 +25,8 @@ type Value struct {
 	Number  *ValueNumber `parser:" | @Number"             json:"number,omitempty"`
 	Str     *ValueString `parser:" | @@"                  json:"str,omitempty"`
 	Heredoc *Heredoc     `parser:" | @@"                  json:"heredoc,omitempty"`
+	ForList *ForList     `parser:" | @@"                  json:"for_list,omitempty"`
+	ForMap  *ForMap      `parser:" | @@"                  json:"for_map,omitempty"`
 	List    *ValueList   `parser:" | @@"                  json:"list,omitempty"`
 	Map     *ValueMap    `parser:" | @@ )"                json:"map,omitempty"`
 }
@@ -41,6 +43,8 @@ func (v *Value) Clone() *Value {
 		Number:  v.Number.Clone(),
 		Str:     v.Str.Clone(),
 		Heredoc: v.Heredoc.Clone(),
+		ForList: v.ForList.Clone(),
+		ForMap:  v.ForMap.Clone(),
 		List:    v.List.Clone(),
 		Map:     v.Map.Clone(),
 	}
@@ -70,6 +74,14 @@ func (v *Value) Children() (children []Node) {
 		children = append(children, v.Heredoc)
 	}
 
+	if v.ForList != nil {
+		children = append(children, v.ForList)
+	}
+
+	if v.ForMap != nil {
+		children = append(children, v.ForMap)
+	}
+
 	if v.List != nil {
 		children = append(children, v.List)
 	}
@@ -100,6 +112,12 @@ func (v Value) FormattedString() string {
 	case v.Heredoc != nil:
 		sb.WriteString(v.Heredoc.FormattedString())
 
+	case v.ForList != nil:
+		sb.WriteString(v.ForList.FormattedString())
+
+	case v.ForMap != nil:
+		sb.WriteString(v.ForMap.FormattedString())
+
 	case v.List != nil:
 		sb.WriteString(v.List.FormattedString())
 
@@ -241,7 +259,7 @@ func (v Heredoc) FormattedString() string {
 
 	mustFprintf(&sb, "<<%s", v.Delimiter.FormattedString())
 
-	for _, fragment := range v.Fragments {
+	for _, fragment := range v.Body() {
 		sb.WriteString(fragment.FormattedString())
 	}
 
@@ -251,6 +269,98 @@ func (v Heredoc) FormattedString() string {
 	return sb.String()
 }
 
+// Body returns the fragments of the heredoc body.
+//
+// For indented heredocs (<<-), the indentation common to all the non-blank
+// lines is removed, so the body can be re-indented without changing its value.
+func (v *Heredoc) Body() []*HeredocFragment {
+	if !v.Delimiter.LeadingTabs {
+		return v.Fragments
+	}
+
+	n := heredocIndentation(v.Fragments)
+	if n == 0 {
+		return v.Fragments
+	}
+
+	out := cloneCollection(v.Fragments)
+	lineStart := true
+
+	for _, f := range out {
+		if f.Expr != nil || f.Directive != nil {
+			lineStart = false
+
+			continue
+		}
+
+		lines := strings.Split(f.Text, "\n")
+		for i := range lines {
+			if i > 0 || lineStart {
+				lines[i] = trimIndentation(lines[i], n)
+			}
+		}
+
+		f.Text = strings.Join(lines, "\n")
+		lineStart = strings.HasSuffix(f.Text, "\n")
+	}
+
+	return out
+}
+
+// heredocIndentation returns the smallest number of leading spaces and tabs
+// of the non-blank lines of a heredoc body.
+func heredocIndentation(fragments []*HeredocFragment) int {
+	res := -1
+	lineStart := true
+
+	for i, f := range fragments {
+		if f.Expr != nil || f.Directive != nil {
+			if lineStart {
+				return 0
+			}
+
+			continue
+		}
+
+		lines := strings.Split(f.Text, "\n")
+		for j, line := range lines {
+			if j == 0 && !lineStart {
+				continue
+			}
+
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+
+			// Whitespace-only lines are blank unless followed by an interpolation.
+			lastLine := j == len(lines)-1
+			if n == len(line) && (!lastLine || i == len(fragments)-1) {
+				continue
+			}
+
+			if res < 0 || n < res {
+				res = n
+			}
+		}
+
+		lineStart = strings.HasSuffix(f.Text, "\n")
+	}
+
+	if res < 0 {
+		return 0
+	}
+
+	return res
+}
+
+// trimIndentation removes up to n leading spaces and tabs from line.
+func trimIndentation(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+
+	return line[i:]
+}
+
 type HeredocDelimiter struct {
 	LeadingTabs bool   `json:"leading_tabs"`
 	Delimiter   string `json:"delimiter"`
@@ -293,9 +403,11 @@ func (v HeredocDelimiter) FormattedString() string {
 type HeredocFragment struct {
 	ASTNode
 
-	Expr      *Expr  `parser:"(  Expr @@ ExprEnd       " json:"expr,omitempty"`
-	Directive *Expr  `parser:" | Directive @@ ExprEnd  " json:"directive,omitempty"`
-	Text      string `parser:" | @(Body|EOL|NonExpr)+ )" json:"text,omitempty"`
+	StripLeft  bool               `parser:"(  ( ( Expr | @ExprStrip )"         json:"strip_left,omitempty"`
+	Expr       *Expr              `parser:"     @@"                             json:"expr,omitempty"`
+	StripRight bool               `parser:"     ( ExprEnd | @ExprStripEnd ) )" json:"strip_right,omitempty"`
+	Directive  *TemplateDirective `parser:" | @@"                               json:"directive,omitempty"`
+	Text       string             `parser:" | @(Body|EOL|NonExpr)+ )"          json:"text,omitempty"`
 }
 
 func (f *HeredocFragment) Clone() *HeredocFragment {
@@ -304,10 +416,12 @@ func (f *HeredocFragment) Clone() *HeredocFragment {
 	}
 
 	return &HeredocFragment{
-		ASTNode:   f.ASTNode.Clone(),
-		Expr:      f.Expr.Clone(),
-		Directive: f.Directive.Clone(),
-		Text:      f.Text,
+		ASTNode:    f.ASTNode.Clone(),
+		StripLeft:  f.StripLeft,
+		Expr:       f.Expr.Clone(),
+		StripRight: f.StripRight,
+		Directive:  f.Directive.Clone(),
+		Text:       f.Text,
 	}
 }
 
@@ -326,9 +440,9 @@ func (f *HeredocFragment) Children() (children []Node) {
 func (f HeredocFragment) FormattedString() string {
 	switch {
 	case f.Expr != nil:
-		return fmt.Sprintf("${ %s }", f.Expr.FormattedString())
+		return templateOpen("${", f.StripLeft) + f.Expr.FormattedString() + templateClose(f.StripRight)
 	case f.Directive != nil:
-		return fmt.Sprintf("%%{ %s }", f.Directive.FormattedString())
+		return f.Directive.FormattedString()
 	case f.Text != "":
 		return f.Text
 	default:
@@ -380,11 +494,13 @@ func (v ValueString) FormattedString() string {
 type StringFragment struct {
 	ASTNode
 
-	Escaped   string `parser:"(  @Escaped"                           json:"escaped,omitempty"`
-	Unicode   string `parser:" | Unicode@(UnicodeLong|UnicodeShort)" json:"unicode,omitempty"`
-	Expr      *Expr  `parser:" | Expr @@ ExprEnd"                    json:"expr,omitempty"`
-	Directive *Expr  `parser:" | Directive @@ ExprEnd"               json:"directive,omitempty"`
-	Text      string `parser:" | @(Char|Quote|NonExpr))"             json:"text,omitempty"`
+	Escaped    string             `parser:"(  @Escaped"                           json:"escaped,omitempty"`
+	Unicode    string             `parser:" | Unicode@(UnicodeLong|UnicodeShort)" json:"unicode,omitempty"`
+	StripLeft  bool               `parser:" | ( ( Expr | @ExprStrip )"            json:"strip_left,omitempty"`
+	Expr       *Expr              `parser:"     @@"                               json:"expr,omitempty"`
+	StripRight bool               `parser:"     ( ExprEnd | @ExprStripEnd ) )"   json:"strip_right,omitempty"`
+	Directive  *TemplateDirective `parser:" | @@"                                 json:"directive,omitempty"`
+	Text       string             `parser:" | @(Char|Quote|NonExpr))"             json:"text,omitempty"`
 }
 
 func (f *StringFragment) Clone() *StringFragment {
@@ -393,12 +509,14 @@ func (f *StringFragment) Clone() *StringFragment {
 	}
 
 	return &StringFragment{
-		ASTNode:   f.ASTNode.Clone(),
-		Escaped:   f.Escaped,
-		Unicode:   f.Unicode,
-		Expr:      f.Expr.Clone(),
-		Directive: f.Directive.Clone(),
-		Text:      f.Text,
+		ASTNode:    f.ASTNode.Clone(),
+		Escaped:    f.Escaped,
+		Unicode:    f.Unicode,
+		StripLeft:  f.StripLeft,
+		Expr:       f.Expr.Clone(),
+		StripRight: f.StripRight,
+		Directive:  f.Directive.Clone(),
+		Text:       f.Text,
 	}
 }
 
@@ -421,9 +539,18 @@ func (f StringFragment) FormattedString() string {
 	case f.Unicode != "":
 		return fmt.Sprintf("\\u%s", f.Unicode)
 	case f.Expr != nil:
-		return fmt.Sprintf("${%s}", f.Expr.FormattedString())
+		open, end := "${", "}"
+		if f.StripLeft {
+			open = "${~"
+		}
+
+		if f.StripRight {
+			end = "~}"
+		}
+
+		return open + f.Expr.FormattedString() + end
 	case f.Directive != nil:
-		return fmt.Sprintf("%%{%s}", f.Directive.FormattedString())
+		return f.Directive.FormattedString()
 	case f.Text != "":
 		return f.Text
 	default:
@@ -4,7 +4,12 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHeredoc_Parsing(t *testing.T) {
@@ -178,7 +183,7 @@ EOF`[1:],
 			name: "value - directive",
 			input: `
 <<EOF
-foo %{ 1 } bar
+foo %{ if x } bar
 EOF`[1:],
 			wantErr: false,
 			want: &Heredoc{
@@ -194,17 +199,16 @@ EOF`[1:],
 					},
 					{
 						ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 2, Column: 5}},
-						Directive: BuildTestExprTree[*Expr](t, &Value{
-							ASTNode: ASTNode{Pos: Position{Offset: 13, Line: 2, Column: 8}},
-							Number: &ValueNumber{
-								ASTNode: ASTNode{Pos: Position{Offset: 13, Line: 2, Column: 8}},
-								Value:   big.NewFloat(1),
-								Source:  "1",
-							},
-						}),
+						Directive: &TemplateDirective{
+							ASTNode: ASTNode{Pos: Position{Offset: 10, Line: 2, Column: 5}},
+							If: BuildTestExprTree[*Expr](t, &Ident{
+								ASTNode: ASTNode{Pos: Position{Offset: 16, Line: 2, Column: 11}},
+								Parts:   []string{"x"},
+							}),
+						},
 					},
 					{
-						ASTNode: ASTNode{Pos: Position{Offset: 16, Line: 2, Column: 11}},
+						ASTNode: ASTNode{Pos: Position{Offset: 19, Line: 2, Column: 14}},
 						Text:    " bar\n",
 					},
 				},
@@ -452,6 +456,27 @@ EOF`[1:],
 			want: `
 <<-EOF
 foo
+EOF`[1:],
+		},
+		{
+			name: "value - indented",
+			input: &Heredoc{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Delimiter: HeredocDelimiter{
+					LeadingTabs: true,
+					Delimiter:   "EOF",
+				},
+				Fragments: []*HeredocFragment{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+						Text:    "\n\t\tfoo\n\t\t\tbar",
+					},
+				},
+			},
+			want: `
+<<-EOF
+foo
+	bar
 EOF`[1:],
 		},
 	}
@@ -463,6 +488,70 @@ EOF`[1:],
 	}
 }
 
+func TestHeredoc_Body(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []*HeredocFragment
+	}{
+		{
+			name:  "Not indented",
+			input: "<<EOF\n  foo\n    bar\nEOF",
+			want:  []*HeredocFragment{{Text: "  foo\n    bar\n"}},
+		},
+		{
+			name:  "Indented",
+			input: "<<-EOF\n  foo\n    bar\n  EOF",
+			want:  []*HeredocFragment{{Text: "foo\n  bar\n"}},
+		},
+		{
+			name:  "Blank lines are ignored",
+			input: "<<-EOF\n    foo\n\n  \n    bar\nEOF",
+			want:  []*HeredocFragment{{Text: "foo\n\n\nbar\n"}},
+		},
+		{
+			name:  "Interpolation at line start",
+			input: "<<-EOF\n  foo\n${x}\nEOF",
+			want: []*HeredocFragment{
+				{Text: "  foo\n"},
+				{Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
+				{Text: "\n"},
+			},
+		},
+		{
+			name:  "Indented interpolation",
+			input: "<<-EOF\n    foo ${x}\n  ${x} bar\nEOF",
+			want: []*HeredocFragment{
+				{Text: "  foo "},
+				{Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
+				{Text: "\n"},
+				{Expr: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
+				{Text: " bar\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var res Heredoc
+
+			parser := participle.MustBuild(&res, participle.Lexer(lexer.MustStateful(lexRules())))
+			require.NoError(t, parser.ParseString("", tt.input, &res))
+
+			opts := cmpopts.IgnoreTypes(ASTNode{})
+			if got := res.Body(); !cmp.Equal(tt.want, got, opts) {
+				assert.Fail(t, "Not equal -want +res", cmp.Diff(tt.want, got, opts))
+			}
+		})
+	}
+}
+
 // /////////////////////////////////////
 
 func TestHeredocFragment_Clone(t *testing.T) {
@@ -502,10 +591,10 @@ func TestHeredocFragment_Clone(t *testing.T) {
 		{
 			name: "Directive",
 			input: &HeredocFragment{
-				Directive: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+				Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
 			},
 			want: &HeredocFragment{
-				Directive: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+				Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
 			},
 		},
 		{
@@ -552,10 +641,10 @@ func TestHeredocFragment_Children(t *testing.T) {
 		{
 			name: "Directive",
 			input: &HeredocFragment{
-				Directive: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+				Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
 			},
 			want: []Node{
-				BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+				&TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
 			},
 		},
 		{
@@ -605,9 +694,9 @@ func TestHeredocFragment_FormattedString(t *testing.T) {
 		{
 			name: "Directive",
 			input: &HeredocFragment{
-				Directive: BuildTestExprTree[*Expr](t, &Value{Number: &ValueNumber{Value: big.NewFloat(1), Source: "1"}}),
+				Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"x"}})},
 			},
-			want: "%{ 1 }",
+			want: "%{ if x }",
 		},
 		{
 			name: "Text",
@@ -51,6 +51,36 @@ func TestString_Parsing(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "Lone percent and dollar signs",
+			input:   `"100% $5"`,
+			wantErr: false,
+			want: &ValueString{
+				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
+				Fragment: []*StringFragment{
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 1, Line: 1, Column: 2}},
+						Text:    `100`,
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 4, Line: 1, Column: 5}},
+						Text:    `%`,
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 5, Line: 1, Column: 6}},
+						Text:    ` `,
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 6, Line: 1, Column: 7}},
+						Text:    `$`,
+					},
+					{
+						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+						Text:    `5`,
+					},
+				},
+			},
+		},
 
 		{
 			name:    "Single quoted",
@@ -252,7 +282,7 @@ func TestString_Parsing(t *testing.T) {
 
 		{
 			name:    "Directive",
-			input:   `"hello %{foo} world"`,
+			input:   `"hello %{if foo} world"`,
 			wantErr: false,
 			want: &ValueString{
 				ASTNode: ASTNode{Pos: Position{Offset: 0, Line: 1, Column: 1}},
@@ -263,13 +293,16 @@ func TestString_Parsing(t *testing.T) {
 					},
 					{
 						ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
-						Directive: BuildTestExprTree[*Expr](t, &Ident{
-							ASTNode: ASTNode{Pos: Position{Offset: 9, Line: 1, Column: 10}},
-							Parts:   []string{"foo"},
-						}),
+						Directive: &TemplateDirective{
+							ASTNode: ASTNode{Pos: Position{Offset: 7, Line: 1, Column: 8}},
+							If: BuildTestExprTree[*Expr](t, &Ident{
+								ASTNode: ASTNode{Pos: Position{Offset: 12, Line: 1, Column: 13}},
+								Parts:   []string{"foo"},
+							}),
+						},
 					},
 					{
-						ASTNode: ASTNode{Pos: Position{Offset: 13, Line: 1, Column: 14}},
+						ASTNode: ASTNode{Pos: Position{Offset: 16, Line: 1, Column: 17}},
 						Text:    ` world`,
 					},
 				},
@@ -408,14 +441,14 @@ func TestString_Clone(t *testing.T) {
 			input: &ValueString{
 				Fragment: []*StringFragment{
 					{Text: `hello `},
-					{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+					{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
 					{Text: ` world`},
 				},
 			},
 			want: &ValueString{
 				Fragment: []*StringFragment{
 					{Text: `hello `},
-					{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+					{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
 					{Text: ` world`},
 				},
 			},
@@ -549,11 +582,11 @@ func TestString_FormattedString(t *testing.T) {
 			input: &ValueString{
 				Fragment: []*StringFragment{
 					{Text: `hello `},
-					{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+					{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
 					{Text: ` world`},
 				},
 			},
-			want: `"hello %{foo} world"`,
+			want: `"hello %{ if foo } world"`,
 		},
 	}
 
@@ -606,8 +639,8 @@ func TestStringFragment_Clone(t *testing.T) {
 		},
 		{
 			name:  "Directive",
-			input: &StringFragment{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
-			want:  &StringFragment{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+			input: &StringFragment{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
+			want:  &StringFragment{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
 		},
 	}
 
@@ -655,9 +688,9 @@ func TestStringFragment_Children(t *testing.T) {
 		},
 		{
 			name:  "Directive",
-			input: &StringFragment{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
+			input: &StringFragment{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
 			want: []Node{
-				BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}}),
+				&TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
 			},
 		},
 	}
@@ -710,8 +743,8 @@ func TestStringFragment_FormattedString(t *testing.T) {
 		},
 		{
 			name:  "Directive",
-			input: &StringFragment{Directive: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})},
-			want:  `%{foo}`,
+			input: &StringFragment{Directive: &TemplateDirective{If: BuildTestExprTree[*Expr](t, &Ident{Parts: []string{"foo"}})}},
+			want:  `%{ if foo }`,
 		},
 	}
 
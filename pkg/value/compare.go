@@ -0,0 +1,83 @@
+package value
+
+import (
+	"strings"
+)
+
+// Compare defines a total ordering over values, returning -1, 0 or +1.
+//
+// Values of different kinds are ordered by kind. Within a kind, booleans
+// order false before true, numbers and strings use their natural ordering,
+// lists and sets compare element-wise, and maps compare their sorted keys
+// and then their values.
+// Functions are ordered by name.
+func Compare(a, b Value) int {
+	if a.kind != b.kind {
+		return compareInts(int(a.kind), int(b.kind))
+	}
+
+	switch a.kind {
+	case KindBool:
+		x, y := a.AsBool(), b.AsBool()
+
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	case KindNumber:
+		return a.number().Cmp(b.number())
+	case KindString:
+		return strings.Compare(a.AsString(), b.AsString())
+	case KindList, KindSet:
+		return compareLists(a.list(), b.list())
+	case KindMap:
+		ka, kb := a.Keys(), b.Keys()
+
+		for i := 0; i < len(ka) && i < len(kb); i++ {
+			if c := strings.Compare(ka[i], kb[i]); c != 0 {
+				return c
+			}
+		}
+
+		if c := compareInts(len(ka), len(kb)); c != 0 {
+			return c
+		}
+
+		for _, k := range ka {
+			if c := Compare(a.dict()[k], b.dict()[k]); c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	case KindFunction:
+		return strings.Compare(a.AsFunction().Name, b.AsFunction().Name)
+	default:
+		return 0
+	}
+}
+
+func compareLists(a, b []Value) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,52 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    Value
+		b    Value
+		want int
+	}{
+		{name: "Null", a: Null, b: Null, want: 0},
+		{name: "Kinds", a: Null, b: False, want: -1},
+		{name: "Bools", a: True, b: False, want: 1},
+		{name: "Numbers", a: Int(-1), b: Float(0.5), want: -1},
+		{name: "Equal numbers", a: Int(1), b: Float(1), want: 0},
+		{name: "Strings", a: String("b"), b: String("a"), want: 1},
+		{name: "Lists", a: List(Int(1), Int(2)), b: List(Int(1), Int(3)), want: -1},
+		{name: "List prefix", a: List(Int(1)), b: List(Int(1), Int(0)), want: -1},
+		{name: "Maps by key", a: Map(map[string]Value{"a": Int(9)}), b: Map(map[string]Value{"b": Int(0)}), want: -1},
+		{name: "Maps by value", a: Map(map[string]Value{"a": Int(2)}), b: Map(map[string]Value{"a": Int(1)}), want: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, Compare(tt.a, tt.b))
+			assert.Equal(t, -tt.want, Compare(tt.b, tt.a))
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	s := Set(Int(3), String("a"), Int(1), Int(3), Float(1))
+
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, KindSet, s.Kind())
+	assert.Equal(t, "Set(1, 3, \"a\")", s.GoString())
+	assert.True(t, s.Equals(Set(String("a"), Int(1), Int(3))))
+	assert.False(t, s.Equals(List(Int(1), Int(3), String("a"))))
+}
@@ -0,0 +1,408 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+const structTag = "etx"
+
+//nolint:gochecknoglobals // reflection types
+var (
+	valueType    = reflect.TypeOf(Value{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigIntType   = reflect.TypeOf(big.Int{})
+)
+
+// FromGo converts a Go value into a runtime value.
+//
+// Supported inputs are nil, booleans, strings, integers, floats, big.Int,
+// big.Float, slices, arrays, maps with string keys, structs, pointers to
+// any of them, Value and *Function. Numbers must be finite: NaN and
+// infinite floats are rejected.
+// Struct fields are mapped using their `etx` tag if present, their name
+// otherwise; fields tagged "-" are skipped.
+func FromGo(v any) (Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return Null, nil
+	case Value:
+		return x, nil
+	case *Function:
+		if x == nil {
+			return Null, nil
+		}
+
+		return Func(x), nil
+	}
+
+	return fromReflect(reflect.ValueOf(v))
+}
+
+//nolint:gocognit,exhaustive // reflection has many cases, the default handles the rest
+func fromReflect(rv reflect.Value) (Value, error) {
+	if rv.Type() == valueType {
+		return rv.Interface().(Value), nil //nolint:forcetypeassert // type checked above
+	}
+
+	switch rv.Type() {
+	case bigFloatType:
+		f := rv.Interface().(big.Float) //nolint:forcetypeassert // type checked above
+		if f.IsInf() {
+			return Null, fmt.Errorf("%w: %s is not a finite number", ErrArgument, &f)
+		}
+
+		return Number(&f), nil
+	case bigIntType:
+		i := rv.Interface().(big.Int) //nolint:forcetypeassert // type checked above
+
+		return Value{kind: KindNumber, v: newFloat().SetInt(&i)}, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return Null, nil
+		}
+
+		if fn, ok := rv.Interface().(*Function); ok {
+			return Func(fn), nil
+		}
+
+		return fromReflect(rv.Elem())
+	case reflect.Bool:
+		return Bool(rv.Bool()), nil
+	case reflect.String:
+		return String(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Value{kind: KindNumber, v: newFloat().SetUint64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return Null, fmt.Errorf("%w: %v is not a finite number", ErrArgument, f)
+		}
+
+		return Float(f), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return Null, nil
+		}
+
+		items := make([]Value, 0, rv.Len())
+
+		for i := 0; i < rv.Len(); i++ {
+			item, err := fromReflect(rv.Index(i))
+			if err != nil {
+				return Null, err
+			}
+
+			items = append(items, item)
+		}
+
+		return Value{kind: KindList, v: items}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return Null, fmt.Errorf("%w: map keys must be strings, got %s", ErrArgument, rv.Type().Key())
+		}
+
+		if rv.IsNil() {
+			return Null, nil
+		}
+
+		items := make(map[string]Value, rv.Len())
+
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := fromReflect(iter.Value())
+			if err != nil {
+				return Null, err
+			}
+
+			items[iter.Key().String()] = item
+		}
+
+		return Value{kind: KindMap, v: items}, nil
+	case reflect.Struct:
+		items := make(map[string]Value, rv.NumField())
+
+		for i := 0; i < rv.NumField(); i++ {
+			name, ok := fieldName(rv.Type().Field(i))
+			if !ok {
+				continue
+			}
+
+			item, err := fromReflect(rv.Field(i))
+			if err != nil {
+				return Null, err
+			}
+
+			items[name] = item
+		}
+
+		return Value{kind: KindMap, v: items}, nil
+	default:
+		return Null, fmt.Errorf("%w: cannot convert Go %s", ErrArgument, rv.Type())
+	}
+}
+
+// ToGo converts the value into plain Go types.
+//
+// Null becomes nil, numbers become *big.Float, lists and sets become []any,
+// maps become map[string]any and functions become *Function.
+func (v Value) ToGo() any {
+	switch v.kind {
+	case KindNull:
+		return nil
+	case KindBool:
+		return v.AsBool()
+	case KindNumber:
+		return v.AsBigFloat()
+	case KindString:
+		return v.AsString()
+	case KindList, KindSet:
+		out := make([]any, 0, len(v.list()))
+		for _, item := range v.list() {
+			out = append(out, item.ToGo())
+		}
+
+		return out
+	case KindMap:
+		out := make(map[string]any, len(v.dict()))
+		for k, item := range v.dict() {
+			out[k] = item.ToGo()
+		}
+
+		return out
+	case KindFunction:
+		return v.AsFunction()
+	default:
+		return nil
+	}
+}
+
+// Decode stores the value into the Go value pointed to by target,
+// following the same mapping rules as FromGo.
+func (v Value) Decode(target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: decode target must be a non-nil pointer", ErrArgument)
+	}
+
+	return v.decode(rv.Elem())
+}
+
+//nolint:gocognit,cyclop,exhaustive // reflection has many cases, the default handles the rest
+func (v Value) decode(rv reflect.Value) error {
+	switch rv.Type() {
+	case valueType:
+		rv.Set(reflect.ValueOf(v))
+
+		return nil
+	case bigFloatType:
+		if err := v.expect(KindNumber, rv); err != nil {
+			return err
+		}
+
+		rv.Set(reflect.ValueOf(*v.AsBigFloat()))
+
+		return nil
+	case bigIntType:
+		if err := v.expect(KindNumber, rv); err != nil {
+			return err
+		}
+
+		i, err := v.AsBigInt()
+		if err != nil {
+			return err
+		}
+
+		rv.Set(reflect.ValueOf(*i))
+
+		return nil
+	}
+
+	if v.IsNull() {
+		rv.Set(reflect.Zero(rv.Type()))
+
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		ptr := reflect.New(rv.Type().Elem())
+		if err := v.decode(ptr.Elem()); err != nil {
+			return err
+		}
+
+		rv.Set(ptr)
+
+		return nil
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fmt.Errorf("%w: cannot decode into %s", ErrArgument, rv.Type())
+		}
+
+		if res := v.ToGo(); res != nil {
+			rv.Set(reflect.ValueOf(res))
+		}
+
+		return nil
+	case reflect.Bool:
+		if err := v.expect(KindBool, rv); err != nil {
+			return err
+		}
+
+		rv.SetBool(v.AsBool())
+
+		return nil
+	case reflect.String:
+		if err := v.expect(KindString, rv); err != nil {
+			return err
+		}
+
+		rv.SetString(v.AsString())
+
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err := v.expect(KindNumber, rv); err != nil {
+			return err
+		}
+
+		i, err := v.AsBigInt()
+		if err != nil {
+			return err
+		}
+
+		if !i.IsInt64() || rv.OverflowInt(i.Int64()) {
+			return fmt.Errorf("%w: %s overflows %s", ErrArgument, i, rv.Type())
+		}
+
+		rv.SetInt(i.Int64())
+
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if err := v.expect(KindNumber, rv); err != nil {
+			return err
+		}
+
+		i, err := v.AsBigInt()
+		if err != nil {
+			return err
+		}
+
+		if !i.IsUint64() || rv.OverflowUint(i.Uint64()) {
+			return fmt.Errorf("%w: %s overflows %s", ErrArgument, i, rv.Type())
+		}
+
+		rv.SetUint(i.Uint64())
+
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if err := v.expect(KindNumber, rv); err != nil {
+			return err
+		}
+
+		f, _ := v.number().Float64()
+		rv.SetFloat(f)
+
+		return nil
+	case reflect.Slice:
+		if v.kind != KindList && v.kind != KindSet {
+			return v.mismatch(rv)
+		}
+
+		items := v.list()
+		out := reflect.MakeSlice(rv.Type(), len(items), len(items))
+
+		for i, item := range items {
+			if err := item.decode(out.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		rv.Set(out)
+
+		return nil
+	case reflect.Map:
+		if err := v.expect(KindMap, rv); err != nil {
+			return err
+		}
+
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: map keys must be strings, got %s", ErrArgument, rv.Type().Key())
+		}
+
+		out := reflect.MakeMapWithSize(rv.Type(), len(v.dict()))
+
+		for k, item := range v.dict() {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := item.decode(elem); err != nil {
+				return err
+			}
+
+			out.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+
+		rv.Set(out)
+
+		return nil
+	case reflect.Struct:
+		if err := v.expect(KindMap, rv); err != nil {
+			return err
+		}
+
+		for i := 0; i < rv.NumField(); i++ {
+			name, ok := fieldName(rv.Type().Field(i))
+			if !ok {
+				continue
+			}
+
+			item, exists := v.dict()[name]
+			if !exists {
+				continue
+			}
+
+			if err := item.decode(rv.Field(i)); err != nil {
+				return fmt.Errorf("field %q: %w", name, err)
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot decode into %s", ErrArgument, rv.Type())
+	}
+}
+
+func (v Value) expect(kind Kind, rv reflect.Value) error {
+	if v.kind != kind {
+		return v.mismatch(rv)
+	}
+
+	return nil
+}
+
+func (v Value) mismatch(rv reflect.Value) error {
+	return fmt.Errorf("%w: cannot decode a %s into %s", ErrArgument, v.kind, rv.Type())
+}
+
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+
+	tag := f.Tag.Get(structTag)
+	if tag == "-" {
+		return "", false
+	}
+
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+
+	return f.Name, true
+}
@@ -0,0 +1,154 @@
+package value
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStruct struct {
+	Name    string            `etx:"name"`
+	Count   int               `etx:"count"`
+	Tags    []string          `etx:"tags"`
+	Labels  map[string]string `etx:"labels"`
+	Ptr     *bool             `etx:"ptr"`
+	Ignored string            `etx:"-"`
+	Default float64
+	private string
+}
+
+func TestFromGo(t *testing.T) {
+	t.Parallel()
+
+	yes := true
+
+	tests := []struct {
+		name    string
+		input   any
+		want    Value
+		wantErr bool
+	}{
+		{name: "Nil", input: nil, want: Null},
+		{name: "Value", input: Int(1), want: Int(1)},
+		{name: "Bool", input: true, want: True},
+		{name: "String", input: "a", want: String("a")},
+		{name: "Int", input: int8(-3), want: Int(-3)},
+		{name: "Uint", input: uint64(1 << 63), want: Number(new(big.Float).SetUint64(1 << 63))},
+		{name: "Float", input: 0.5, want: Float(0.5)},
+		{name: "Big float", input: big.NewFloat(2), want: Int(2)},
+		{name: "Big int", input: big.NewInt(7), want: Int(7)},
+		{name: "Nil pointer", input: (*int)(nil), want: Null},
+		{name: "Slice", input: []any{1, "a", nil}, want: List(Int(1), String("a"), Null)},
+		{name: "Array", input: [2]int{1, 2}, want: List(Int(1), Int(2))},
+		{name: "Map", input: map[string]int{"a": 1}, want: Map(map[string]Value{"a": Int(1)})},
+		{
+			name: "Struct",
+			input: testStruct{
+				Name:    "n",
+				Count:   2,
+				Tags:    []string{"t"},
+				Ptr:     &yes,
+				Ignored: "i",
+				Default: 1.5,
+				private: "p",
+			},
+			want: Map(map[string]Value{
+				"name":    String("n"),
+				"count":   Int(2),
+				"tags":    List(String("t")),
+				"labels":  Null,
+				"ptr":     True,
+				"Default": Float(1.5),
+			}),
+		},
+		{name: "NaN", input: math.NaN(), wantErr: true},
+		{name: "Infinity", input: math.Inf(1), wantErr: true},
+		{name: "Negative infinity", input: []float32{float32(math.Inf(-1))}, wantErr: true},
+		{name: "Big infinity", input: new(big.Float).SetInf(false), wantErr: true},
+		{name: "Invalid map key", input: map[int]int{1: 1}, wantErr: true},
+		{name: "Unsupported type", input: make(chan int), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := FromGo(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrArgument)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestValue_ToGo(t *testing.T) {
+	t.Parallel()
+
+	res := Map(map[string]Value{
+		"a": List(Int(1), True, Null),
+		"b": Set(String("x")),
+	}).ToGo()
+
+	m, ok := res.(map[string]any)
+	require.True(t, ok)
+
+	a, ok := m["a"].([]any)
+	require.True(t, ok)
+	require.Len(t, a, 3)
+	assert.Equal(t, 0, big.NewFloat(1).Cmp(a[0].(*big.Float)))
+	assert.Equal(t, true, a[1])
+	assert.Nil(t, a[2])
+	assert.Equal(t, []any{"x"}, m["b"])
+}
+
+func TestValue_Decode(t *testing.T) {
+	t.Parallel()
+
+	input := Map(map[string]Value{
+		"name":    String("n"),
+		"count":   Int(2),
+		"tags":    Set(String("a"), String("b")),
+		"labels":  Map(map[string]Value{"k": String("v")}),
+		"ptr":     False,
+		"Default": Float(0.25),
+		"extra":   Int(1),
+	})
+
+	var res testStruct
+
+	require.NoError(t, input.Decode(&res))
+
+	no := false
+	assert.Equal(t, testStruct{
+		Name:    "n",
+		Count:   2,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"k": "v"},
+		Ptr:     &no,
+		Default: 0.25,
+	}, res)
+
+	var i8 int8
+	assert.ErrorIs(t, Int(1000).Decode(&i8), ErrArgument)
+	assert.ErrorIs(t, Float(1.5).Decode(&i8), ErrNotInteger)
+	assert.ErrorIs(t, String("a").Decode(&i8), ErrArgument)
+	assert.ErrorIs(t, Int(1).Decode(i8), ErrArgument)
+
+	var v Value
+	require.NoError(t, input.Decode(&v))
+	assert.True(t, input.Equals(v))
+
+	var anything any
+	require.NoError(t, String("a").Decode(&anything))
+	assert.Equal(t, "a", anything)
+}
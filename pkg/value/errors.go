@@ -0,0 +1,16 @@
+package value
+
+import (
+	"errors"
+)
+
+var (
+	ErrArity           = errors.New("wrong number of arguments")
+	ErrArgument        = errors.New("invalid argument")
+	ErrUnsupportedOp   = errors.New("unsupported operation")
+	ErrDivisionByZero  = errors.New("division by zero")
+	ErrNotInteger      = errors.New("number is not an integer")
+	ErrShiftOutOfRange = errors.New("shift count out of range")
+	ErrIndexOutOfRange = errors.New("index out of range")
+	ErrKeyNotFound     = errors.New("key not found")
+)
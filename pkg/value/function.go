@@ -0,0 +1,139 @@
+package value
+
+import (
+	"fmt"
+)
+
+// Param describes a function parameter.
+type Param struct {
+	Name string
+
+	// Type is the type arguments must conform to. The zero value is TypeAny.
+	Type Type
+
+	// AllowNull lets null values through to the implementation instead of
+	// reporting an error.
+	AllowNull bool
+
+	// AllowUnknown lets values that are not wholly known through to the
+	// implementation. Otherwise, the result of the call is unknown.
+	AllowUnknown bool
+
+	// AllowSensitive lets sensitive values through to the implementation
+	// with their marks. Otherwise, the marks are removed from the argument
+	// and the result of the call is sensitive.
+	AllowSensitive bool
+
+	// Lazy defers the evaluation of the argument to the implementation. The
+	// argument is passed as a function without parameters that evaluates it
+	// on each call, and reports the evaluation errors.
+	Lazy bool
+}
+
+// Function is a callable runtime value.
+type Function struct {
+	Name     string
+	Params   []Param
+	VarParam *Param
+
+	Impl func(args []Value) (Value, error)
+}
+
+// Call checks the arguments against the function parameters and invokes
+// the implementation.
+//
+// When an argument is not wholly known and its parameter does not allow
+// unknown values, the implementation is not invoked and the result is
+// unknown. When an argument contains sensitive values and its parameter does
+// not allow them, the result is sensitive and the message of the error, if
+// any, is redacted.
+func (f *Function) Call(args []Value) (Value, error) {
+	if err := f.CheckArity(len(args)); err != nil {
+		return Null, err
+	}
+
+	known, sensitive := true, false
+	unmarked := make([]Value, 0, len(args))
+
+	for i, arg := range args {
+		param := f.param(i)
+		if arg.IsNull() && !param.AllowNull {
+			return Null, &ArgError{Index: i, Err: fmt.Errorf("%w: argument %q must not be null", ErrArgument, param.Name)}
+		}
+
+		if !param.Type.Conforms(arg) {
+			return Null, &ArgError{Index: i, Err: fmt.Errorf("%w: argument %q must be a %s, got %s", ErrArgument, param.Name, param.Type, arg.Type())}
+		}
+
+		if !param.AllowUnknown && !arg.IsWhollyKnown() {
+			known = false
+		}
+
+		if !param.AllowSensitive {
+			var marked bool
+
+			arg, marked = arg.Unmark()
+			sensitive = sensitive || marked
+		}
+
+		unmarked = append(unmarked, arg)
+	}
+
+	if !known {
+		return markSensitiveIf(Unknown(TypeAny), sensitive), nil
+	}
+
+	res, err := f.Impl(unmarked)
+	if err != nil {
+		if sensitive {
+			err = RedactError(err)
+		}
+
+		return Null, err
+	}
+
+	return markSensitiveIf(res, sensitive), nil
+}
+
+// IsLazy reports whether the argument at index i is evaluated lazily.
+func (f *Function) IsLazy(i int) bool {
+	if i >= len(f.Params) && f.VarParam == nil {
+		return false
+	}
+
+	return f.param(i).Lazy
+}
+
+// CheckArity checks that the function accepts n arguments.
+func (f *Function) CheckArity(n int) error {
+	switch {
+	case n < len(f.Params):
+		return fmt.Errorf("%w: %s expects at least %d, got %d", ErrArity, f.Name, len(f.Params), n)
+	case f.VarParam == nil && n > len(f.Params):
+		return fmt.Errorf("%w: %s expects %d, got %d", ErrArity, f.Name, len(f.Params), n)
+	default:
+		return nil
+	}
+}
+
+func (f *Function) param(i int) Param {
+	if i < len(f.Params) {
+		return f.Params[i]
+	}
+
+	return *f.VarParam
+}
+
+// ArgError is an error caused by a specific argument of a function call.
+type ArgError struct {
+	Index int
+	Err   error
+}
+
+func (e *ArgError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ArgError) Unwrap() error {
+	return e.Err
+}
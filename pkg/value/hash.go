@@ -0,0 +1,66 @@
+package value
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"strconv"
+)
+
+// Hash returns a hash of the value.
+//
+// Equal values have equal hashes, which makes Hash suitable to bucket
+// values in sets and to detect duplicates.
+func (v Value) Hash() uint64 {
+	h := fnv.New64a()
+	v.writeHash(h)
+
+	return h.Sum64()
+}
+
+func (v Value) writeHash(h hash.Hash64) {
+	mustWrite(h, "%d:", v.kind)
+
+	switch v.kind {
+	case KindNull:
+	case KindBool:
+		mustWrite(h, "%t", v.AsBool())
+	case KindNumber:
+		// The 'p' format is exact and independent of the precision.
+		if f := v.number(); f.Sign() == 0 {
+			mustWrite(h, "0")
+		} else {
+			mustWrite(h, "%s", f.Text('p', 0))
+		}
+	case KindString:
+		mustWrite(h, "%s", strconv.Quote(v.AsString()))
+	case KindList, KindSet:
+		mustWrite(h, "%d[", len(v.list()))
+
+		for _, item := range v.list() {
+			item.writeHash(h)
+			mustWrite(h, ",")
+		}
+
+		mustWrite(h, "]")
+	case KindMap:
+		mustWrite(h, "%d{", len(v.dict()))
+
+		for _, k := range v.Keys() {
+			mustWrite(h, "%s=", strconv.Quote(k))
+			v.dict()[k].writeHash(h)
+			mustWrite(h, ",")
+		}
+
+		mustWrite(h, "}")
+	case KindFunction:
+		mustWrite(h, "%p", v.AsFunction())
+	}
+}
+
+func mustWrite(w io.Writer, format string, a ...any) {
+	if _, err := fmt.Fprintf(w, format, a...); err != nil {
+		panic(err)
+	}
+}
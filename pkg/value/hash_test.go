@@ -0,0 +1,44 @@
+package value
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_Hash(t *testing.T) {
+	t.Parallel()
+
+	lowPrecision, _, _ := big.ParseFloat("0.5", 10, 24, big.ToNearestEven)
+
+	tests := []struct {
+		name  string
+		a     Value
+		b     Value
+		equal bool
+	}{
+		{name: "Same number", a: Int(1), b: Float(1), equal: true},
+		{name: "Precision independent", a: Number(lowPrecision), b: Float(0.5), equal: true},
+		{name: "Signed zero", a: Float(0), b: Number(big.NewFloat(0).Neg(big.NewFloat(0))), equal: true},
+		{name: "Different numbers", a: Int(1), b: Int(2), equal: false},
+		{name: "Number and string", a: Int(1), b: String("1"), equal: false},
+		{name: "Lists", a: List(Int(1), String("a")), b: List(Int(1), String("a")), equal: true},
+		{name: "List order", a: List(Int(1), Int(2)), b: List(Int(2), Int(1)), equal: false},
+		{name: "Set order", a: Set(Int(1), Int(2)), b: Set(Int(2), Int(1)), equal: true},
+		{name: "List and set", a: List(Int(1)), b: Set(Int(1)), equal: false},
+		{name: "Maps", a: Map(map[string]Value{"a": Int(1), "b": Null}), b: Map(map[string]Value{"b": Null, "a": Int(1)}), equal: true},
+		{name: "Nesting boundaries", a: List(String("a,"), String("b")), b: List(String("a"), String(",b")), equal: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.equal, tt.a.Hash() == tt.b.Hash())
+			assert.Equal(t, tt.equal, tt.a.Equals(tt.b))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package value
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MarshalJSON encodes the value as JSON.
+//
+// Numbers are encoded with their full precision, sets are encoded as arrays
+// and map keys are sorted. Functions cannot be encoded.
+func (v Value) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := v.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (v Value) writeJSON(buf *bytes.Buffer) error {
+	switch v.kind {
+	case KindNull:
+		buf.WriteString("null")
+	case KindBool, KindString:
+		data, err := json.Marshal(v.ToGo())
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", v.kind, err)
+		}
+
+		buf.Write(data)
+	case KindNumber:
+		f := v.number()
+		if f.IsInf() {
+			return fmt.Errorf("%w: cannot encode infinity", ErrUnsupportedOp)
+		}
+
+		if f.IsInt() {
+			i, _ := f.Int(nil)
+			buf.WriteString(i.String())
+		} else {
+			buf.WriteString(f.Text('g', -1))
+		}
+	case KindList, KindSet:
+		buf.WriteByte('[')
+
+		for i, item := range v.list() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := item.writeJSON(buf); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+	case KindMap:
+		buf.WriteByte('{')
+
+		for i, k := range v.Keys() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, err := json.Marshal(k)
+			if err != nil {
+				return fmt.Errorf("failed to encode key: %w", err)
+			}
+
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			if err := v.dict()[k].writeJSON(buf); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("%w: cannot encode a %s as JSON", ErrUnsupportedOp, v.kind)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes JSON into the value, replacing it.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	res, err := FromJSON(data)
+	if err != nil {
+		return err
+	}
+
+	*v = res
+
+	return nil
+}
+
+// FromJSON decodes a JSON document into a runtime value.
+//
+// Numbers are decoded with the full runtime precision, arrays become lists
+// and objects become maps.
+func FromJSON(data []byte) (Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	res, err := readJSON(dec)
+	if err != nil {
+		return Null, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return Null, fmt.Errorf("%w: extra data after JSON value", ErrArgument)
+	}
+
+	return res, nil
+}
+
+func readJSON(dec *json.Decoder) (Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return Null, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	switch t := tok.(type) {
+	case nil:
+		return Null, nil
+	case bool:
+		return Bool(t), nil
+	case string:
+		return String(t), nil
+	case json.Number:
+		f, _, err := newFloat().Parse(string(t), 10)
+		if err != nil {
+			return Null, fmt.Errorf("invalid JSON number %q: %w", t, err)
+		}
+
+		return Value{kind: KindNumber, v: f}, nil
+	case json.Delim:
+		switch t {
+		case '[':
+			var items []Value
+
+			for dec.More() {
+				item, err := readJSON(dec)
+				if err != nil {
+					return Null, err
+				}
+
+				items = append(items, item)
+			}
+
+			if _, err := dec.Token(); err != nil {
+				return Null, fmt.Errorf("invalid JSON: %w", err)
+			}
+
+			return List(items...), nil
+		case '{':
+			items := make(map[string]Value)
+
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return Null, fmt.Errorf("invalid JSON: %w", err)
+				}
+
+				key, _ := keyTok.(string)
+
+				item, err := readJSON(dec)
+				if err != nil {
+					return Null, err
+				}
+
+				items[key] = item
+			}
+
+			if _, err := dec.Token(); err != nil {
+				return Null, fmt.Errorf("invalid JSON: %w", err)
+			}
+
+			return Value{kind: KindMap, v: items}, nil
+		}
+	}
+
+	return Null, fmt.Errorf("%w: unexpected JSON token %v", ErrArgument, tok)
+}
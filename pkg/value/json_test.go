@@ -0,0 +1,107 @@
+package value
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	huge, _ := new(big.Float).SetPrec(NumberPrecision).SetString("123456789012345678901234567890")
+
+	tests := []struct {
+		name    string
+		input   Value
+		want    string
+		wantErr bool
+	}{
+		{name: "Null", input: Null, want: `null`},
+		{name: "Bool", input: True, want: `true`},
+		{name: "Integer", input: Int(-3), want: `-3`},
+		{name: "Fraction", input: Float(0.5), want: `0.5`},
+		{name: "Huge integer", input: Number(huge), want: `123456789012345678901234567890`},
+		{name: "String", input: String("a\"<"), want: `"a\"\u003c"`},
+		{name: "List", input: List(Int(1), Null), want: `[1,null]`},
+		{name: "Set", input: Set(Int(2), Int(1)), want: `[1,2]`},
+		{name: "Map", input: Map(map[string]Value{"b": Int(2), "a": List()}), want: `{"a":[],"b":2}`},
+		{name: "Function", input: Func(&Function{}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := json.Marshal(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(res))
+		})
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Value
+		wantErr bool
+	}{
+		{name: "Null", input: `null`, want: Null},
+		{name: "Number", input: `1.5e2`, want: Int(150)},
+		{name: "Nested", input: `{"a": [1, "b", true, {}]}`, want: Map(map[string]Value{
+			"a": List(Int(1), String("b"), True, Map(nil)),
+		})},
+		{name: "Invalid", input: `{"a": }`, wantErr: true},
+		{name: "Trailing data", input: `1 2`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := FromJSON([]byte(tt.input))
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestJSON_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	precise, _ := new(big.Float).SetPrec(NumberPrecision).SetString("0.1000000000000000000000000001")
+
+	input := Map(map[string]Value{
+		"n": Number(precise),
+		"l": List(String("x"), Null, False),
+	})
+
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+
+	var res Value
+	require.NoError(t, json.Unmarshal(data, &res))
+	assert.True(t, input.Equals(res), "want %s, got %s", input.GoString(), res.GoString())
+}
@@ -0,0 +1,412 @@
+package value
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Add returns a + b for numbers, or the concatenation of a and b for strings.
+func Add(a, b Value) (Value, error) {
+	return binary(add, a, b)
+}
+
+// Subtract returns a - b.
+func Subtract(a, b Value) (Value, error) {
+	return binary(subtract, a, b)
+}
+
+// Multiply returns a * b.
+func Multiply(a, b Value) (Value, error) {
+	return binary(multiply, a, b)
+}
+
+// Divide returns a / b.
+func Divide(a, b Value) (Value, error) {
+	return binary(divide, a, b)
+}
+
+// Modulo returns the remainder of a / b, truncated towards zero.
+// The result has the sign of a.
+func Modulo(a, b Value) (Value, error) {
+	return binary(modulo, a, b)
+}
+
+// Negate returns -a.
+func Negate(a Value) (Value, error) {
+	return unary(negate, a)
+}
+
+// Plus returns +a, which is a itself for a number.
+func Plus(a Value) (Value, error) {
+	return unary(plus, a)
+}
+
+// Not returns the logical negation of a.
+func Not(a Value) (Value, error) {
+	return unary(not, a)
+}
+
+// BitwiseNot returns the bitwise complement of the integer a.
+func BitwiseNot(a Value) (Value, error) {
+	return unary(bitwiseNot, a)
+}
+
+// BitwiseAnd returns a & b.
+func BitwiseAnd(a, b Value) (Value, error) {
+	return binary(bitwise("&", (*big.Int).And), a, b)
+}
+
+// BitwiseOr returns a | b.
+func BitwiseOr(a, b Value) (Value, error) {
+	return binary(bitwise("|", (*big.Int).Or), a, b)
+}
+
+// BitwiseXor returns a ^ b.
+func BitwiseXor(a, b Value) (Value, error) {
+	return binary(bitwise("^", (*big.Int).Xor), a, b)
+}
+
+// ShiftLeft returns a << b.
+func ShiftLeft(a, b Value) (Value, error) {
+	return binary(shift("<<", (*big.Int).Lsh), a, b)
+}
+
+// ShiftRight returns a >> b.
+func ShiftRight(a, b Value) (Value, error) {
+	return binary(shift(">>", (*big.Int).Rsh), a, b)
+}
+
+// LessThan returns a < b for numbers or strings.
+func LessThan(a, b Value) (Value, error) {
+	return binary(compareWith("<", func(c int) bool { return c < 0 }), a, b)
+}
+
+// LessThanOrEqual returns a <= b for numbers or strings.
+func LessThanOrEqual(a, b Value) (Value, error) {
+	return binary(compareWith("<=", func(c int) bool { return c <= 0 }), a, b)
+}
+
+// GreaterThan returns a > b for numbers or strings.
+func GreaterThan(a, b Value) (Value, error) {
+	return binary(compareWith(">", func(c int) bool { return c > 0 }), a, b)
+}
+
+// GreaterThanOrEqual returns a >= b for numbers or strings.
+func GreaterThanOrEqual(a, b Value) (Value, error) {
+	return binary(compareWith(">=", func(c int) bool { return c >= 0 }), a, b)
+}
+
+// Equal returns whether a and b are deeply equal. The result is unknown
+// when either is not wholly known, unless the other is null and it is
+// refined to never be null.
+func Equal(a, b Value) (Value, error) {
+	return binary(equal, a, b)
+}
+
+// NotEqual returns whether a and b are not deeply equal.
+func NotEqual(a, b Value) (Value, error) {
+	return binary(notEqual, a, b)
+}
+
+// AsBigInt returns the integer held by a number value.
+func (v Value) AsBigInt() (*big.Int, error) {
+	v.mustBe(KindNumber)
+
+	if !v.number().IsInt() {
+		return nil, fmt.Errorf("%w: %s", ErrNotInteger, v.GoString())
+	}
+
+	i, _ := v.number().Int(nil)
+
+	return i, nil
+}
+
+// AsInt returns the integer held by a number value if it fits in an int.
+func (v Value) AsInt() (int, error) {
+	i, err := v.AsBigInt()
+	if err != nil {
+		return 0, err
+	}
+
+	if !i.IsInt64() || int64(int(i.Int64())) != i.Int64() {
+		return 0, fmt.Errorf("%w: %s is out of range", ErrArgument, i)
+	}
+
+	return int(i.Int64()), nil
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(NumberPrecision)
+}
+
+// binary applies the operator op to a and b. The result is sensitive when
+// either operand contains a sensitive value.
+func binary(op func(a, b Value) (Value, error), a, b Value) (Value, error) {
+	res, err := op(a, b)
+	if err != nil {
+		return Null, err
+	}
+
+	return markSensitiveIf(res, a.ContainsSensitive() || b.ContainsSensitive()), nil
+}
+
+// unary applies the operator op to a. The result is sensitive when a is.
+func unary(op func(a Value) (Value, error), a Value) (Value, error) {
+	res, err := op(a)
+	if err != nil {
+		return Null, err
+	}
+
+	return res.InheritSensitive(a), nil
+}
+
+func add(a, b Value) (Value, error) {
+	if res, ok, err := unknownBinary("+", a, b, KindUnknown, KindNumber, KindString); ok {
+		return res, err
+	}
+
+	switch {
+	case a.kind == KindNumber && b.kind == KindNumber:
+		return Value{kind: KindNumber, v: newFloat().Add(a.number(), b.number())}, nil
+	case a.kind == KindString && b.kind == KindString:
+		return String(a.AsString() + b.AsString()), nil
+	default:
+		return Null, unsupportedBinary("+", a, b)
+	}
+}
+
+func subtract(a, b Value) (Value, error) {
+	if res, ok, err := unknownBinary("-", a, b, KindNumber, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber || b.kind != KindNumber {
+		return Null, unsupportedBinary("-", a, b)
+	}
+
+	return Value{kind: KindNumber, v: newFloat().Sub(a.number(), b.number())}, nil
+}
+
+func multiply(a, b Value) (Value, error) {
+	if res, ok, err := unknownBinary("*", a, b, KindNumber, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber || b.kind != KindNumber {
+		return Null, unsupportedBinary("*", a, b)
+	}
+
+	return Value{kind: KindNumber, v: newFloat().Mul(a.number(), b.number())}, nil
+}
+
+func divide(a, b Value) (Value, error) {
+	if res, ok, err := unknownBinary("/", a, b, KindNumber, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber || b.kind != KindNumber {
+		return Null, unsupportedBinary("/", a, b)
+	}
+
+	if b.number().Sign() == 0 {
+		return Null, ErrDivisionByZero
+	}
+
+	return Value{kind: KindNumber, v: newFloat().Quo(a.number(), b.number())}, nil
+}
+
+func modulo(a, b Value) (Value, error) {
+	if res, ok, err := unknownBinary("%", a, b, KindNumber, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber || b.kind != KindNumber {
+		return Null, unsupportedBinary("%", a, b)
+	}
+
+	x, y := a.number(), b.number()
+	if y.Sign() == 0 {
+		return Null, ErrDivisionByZero
+	}
+
+	if x.IsInt() && y.IsInt() {
+		xi, _ := x.Int(nil)
+		yi, _ := y.Int(nil)
+
+		return Value{kind: KindNumber, v: newFloat().SetInt(new(big.Int).Rem(xi, yi))}, nil
+	}
+
+	q := newFloat().Quo(x, y)
+	qi, _ := q.Int(nil)
+
+	return Value{kind: KindNumber, v: newFloat().Sub(x, newFloat().Mul(y, newFloat().SetInt(qi)))}, nil
+}
+
+func negate(a Value) (Value, error) {
+	if res, ok, err := unknownUnary("-", a, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber {
+		return Null, unsupportedUnary("-", a)
+	}
+
+	return Value{kind: KindNumber, v: newFloat().Neg(a.number())}, nil
+}
+
+func plus(a Value) (Value, error) {
+	if res, ok, err := unknownUnary("+", a, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber {
+		return Null, unsupportedUnary("+", a)
+	}
+
+	return a, nil
+}
+
+func not(a Value) (Value, error) {
+	if res, ok, err := unknownUnary("!", a, KindBool); ok {
+		return res, err
+	}
+
+	if a.kind != KindBool {
+		return Null, unsupportedUnary("!", a)
+	}
+
+	return Bool(!a.AsBool()), nil
+}
+
+func bitwiseNot(a Value) (Value, error) {
+	if res, ok, err := unknownUnary("~", a, KindNumber); ok {
+		return res, err
+	}
+
+	if a.kind != KindNumber {
+		return Null, unsupportedUnary("~", a)
+	}
+
+	x, err := a.AsBigInt()
+	if err != nil {
+		return Null, err
+	}
+
+	return Value{kind: KindNumber, v: newFloat().SetInt(new(big.Int).Not(x))}, nil
+}
+
+func equal(a, b Value) (Value, error) {
+	if a.IsWhollyKnown() && b.IsWhollyKnown() {
+		return Bool(a.Equals(b)), nil
+	}
+
+	if (a.IsNull() && b.refinement().NotNull) || (b.IsNull() && a.refinement().NotNull) {
+		return False, nil
+	}
+
+	return Unknown(TypeBool).RefineNotNull(), nil
+}
+
+func notEqual(a, b Value) (Value, error) {
+	res, err := equal(a, b)
+	if err != nil || !res.IsKnown() {
+		return res, err
+	}
+
+	return Bool(!res.AsBool()), nil
+}
+
+func bitwise(op string, fn func(z, x, y *big.Int) *big.Int) func(a, b Value) (Value, error) {
+	return func(a, b Value) (Value, error) {
+		if res, ok, err := unknownBinary(op, a, b, KindNumber, KindNumber); ok {
+			return res, err
+		}
+
+		if a.kind != KindNumber || b.kind != KindNumber {
+			return Null, unsupportedBinary(op, a, b)
+		}
+
+		x, err := a.AsBigInt()
+		if err != nil {
+			return Null, err
+		}
+
+		y, err := b.AsBigInt()
+		if err != nil {
+			return Null, err
+		}
+
+		return Value{kind: KindNumber, v: newFloat().SetInt(fn(new(big.Int), x, y))}, nil
+	}
+}
+
+func shift(op string, fn func(z, x *big.Int, n uint) *big.Int) func(a, b Value) (Value, error) {
+	return func(a, b Value) (Value, error) {
+		if res, ok, err := unknownBinary(op, a, b, KindNumber, KindNumber); ok {
+			return res, err
+		}
+
+		if a.kind != KindNumber || b.kind != KindNumber {
+			return Null, unsupportedBinary(op, a, b)
+		}
+
+		x, err := a.AsBigInt()
+		if err != nil {
+			return Null, err
+		}
+
+		n, err := b.AsBigInt()
+		if err != nil {
+			return Null, err
+		}
+
+		if n.Sign() < 0 || n.Cmp(big.NewInt(NumberPrecision)) > 0 {
+			return Null, fmt.Errorf("%w: %s", ErrShiftOutOfRange, b.GoString())
+		}
+
+		return Value{kind: KindNumber, v: newFloat().SetInt(fn(new(big.Int), x, uint(n.Uint64())))}, nil
+	}
+}
+
+// compareWith returns the operator comparing a and b and returning whether
+// the result of the comparison matches.
+func compareWith(op string, match func(c int) bool) func(a, b Value) (Value, error) {
+	return func(a, b Value) (Value, error) {
+		if res, ok, err := unknownBinary(op, a, b, KindBool, KindNumber, KindString); ok {
+			return res, err
+		}
+
+		c, err := compareOperands(op, a, b)
+		if err != nil {
+			return Null, err
+		}
+
+		return Bool(match(c)), nil
+	}
+}
+
+func compareOperands(op string, a, b Value) (int, error) {
+	switch {
+	case a.kind == KindNumber && b.kind == KindNumber:
+		return a.number().Cmp(b.number()), nil
+	case a.kind == KindString && b.kind == KindString:
+		switch x, y := a.AsString(), b.AsString(); {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	default:
+		return 0, unsupportedBinary(op, a, b)
+	}
+}
+
+func unsupportedBinary(op string, a, b Value) error {
+	return fmt.Errorf("%w: %s %s %s", ErrUnsupportedOp, a.kind, op, b.kind)
+}
+
+func unsupportedUnary(op string, a Value) error {
+	return fmt.Errorf("%w: %s%s", ErrUnsupportedOp, op, a.kind)
+}
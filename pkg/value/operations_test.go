@@ -0,0 +1,103 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		op      func(a, b Value) (Value, error)
+		a       Value
+		b       Value
+		want    Value
+		wantErr error
+	}{
+		{name: "Add numbers", op: Add, a: Int(1), b: Int(2), want: Int(3)},
+		{name: "Add strings", op: Add, a: String("a"), b: String("b"), want: String("ab")},
+		{name: "Add mismatch", op: Add, a: String("a"), b: Int(1), wantErr: ErrUnsupportedOp},
+		{name: "Subtract", op: Subtract, a: Int(1), b: Int(3), want: Int(-2)},
+		{name: "Multiply", op: Multiply, a: Float(1.5), b: Int(2), want: Int(3)},
+		{name: "Divide", op: Divide, a: Int(1), b: Int(8), want: Float(0.125)},
+		{name: "Divide by zero", op: Divide, a: Int(1), b: Int(0), wantErr: ErrDivisionByZero},
+		{name: "Modulo", op: Modulo, a: Int(7), b: Int(-3), want: Int(1)},
+		{name: "Negative modulo", op: Modulo, a: Int(-7), b: Int(3), want: Int(-1)},
+		{name: "Fractional modulo", op: Modulo, a: Float(-5.5), b: Int(2), want: Float(-1.5)},
+		{name: "Modulo by zero", op: Modulo, a: Int(1), b: Int(0), wantErr: ErrDivisionByZero},
+		{name: "Bitwise and", op: BitwiseAnd, a: Int(12), b: Int(10), want: Int(8)},
+		{name: "Bitwise or", op: BitwiseOr, a: Int(12), b: Int(10), want: Int(14)},
+		{name: "Bitwise xor", op: BitwiseXor, a: Int(12), b: Int(10), want: Int(6)},
+		{name: "Bitwise on fraction", op: BitwiseOr, a: Float(1.5), b: Int(1), wantErr: ErrNotInteger},
+		{name: "Shift left", op: ShiftLeft, a: Int(3), b: Int(2), want: Int(12)},
+		{name: "Shift right", op: ShiftRight, a: Int(-8), b: Int(1), want: Int(-4)},
+		{name: "Negative shift", op: ShiftLeft, a: Int(1), b: Int(-1), wantErr: ErrShiftOutOfRange},
+		{name: "Shift string", op: ShiftLeft, a: String("a"), b: Int(2), wantErr: ErrUnsupportedOp},
+		{name: "Less than", op: LessThan, a: Int(1), b: Int(2), want: True},
+		{name: "Less than or equal", op: LessThanOrEqual, a: String("b"), b: String("a"), want: False},
+		{name: "Greater than", op: GreaterThan, a: Int(2), b: Int(1), want: True},
+		{name: "Greater than or equal", op: GreaterThanOrEqual, a: Int(2), b: Int(2), want: True},
+		{name: "Compare mismatch", op: LessThan, a: Int(2), b: String("a"), wantErr: ErrUnsupportedOp},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := tt.op(tt.a, tt.b)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
+
+func TestUnaryOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		op      func(a Value) (Value, error)
+		a       Value
+		want    Value
+		wantErr error
+	}{
+		{name: "Negate", op: Negate, a: Int(1), want: Int(-1)},
+		{name: "Negate string", op: Negate, a: String("a"), wantErr: ErrUnsupportedOp},
+		{name: "Plus", op: Plus, a: Int(1), want: Int(1)},
+		{name: "Plus string", op: Plus, a: String("a"), wantErr: ErrUnsupportedOp},
+		{name: "Not", op: Not, a: True, want: False},
+		{name: "Not number", op: Not, a: Int(1), wantErr: ErrUnsupportedOp},
+		{name: "Bitwise not", op: BitwiseNot, a: Int(0), want: Int(-1)},
+		{name: "Bitwise not fraction", op: BitwiseNot, a: Float(0.5), wantErr: ErrNotInteger},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := tt.op(tt.a)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equals(res), "want %s, got %s", tt.want.GoString(), res.GoString())
+		})
+	}
+}
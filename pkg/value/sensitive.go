@@ -0,0 +1,137 @@
+package value
+
+import (
+	"errors"
+)
+
+// redacted replaces sensitive values wherever they are displayed.
+const redacted = "(sensitive value)"
+
+// ErrSensitive wraps the errors whose messages are hidden because they may
+// reveal a sensitive value.
+var ErrSensitive = errors.New("the details are hidden because they involve a sensitive value")
+
+// MarkSensitive returns the value marked as sensitive, such as a password or
+// a private key.
+//
+// Sensitive values are redacted when displayed, and the operations and
+// function calls they take part in return sensitive results.
+func (v Value) MarkSensitive() Value {
+	v.sensitive = true
+
+	return v
+}
+
+// IsSensitive reports whether the value is marked as sensitive. The
+// elements of a collection may be sensitive on their own.
+func (v Value) IsSensitive() bool {
+	return v.sensitive
+}
+
+// ContainsSensitive reports whether the value or any of its elements is
+// marked as sensitive.
+func (v Value) ContainsSensitive() bool {
+	if v.sensitive {
+		return true
+	}
+
+	switch v.kind {
+	case KindList, KindSet:
+		for _, item := range v.list() {
+			if item.ContainsSensitive() {
+				return true
+			}
+		}
+	case KindMap:
+		for _, item := range v.dict() {
+			if item.ContainsSensitive() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// InheritSensitive returns the value marked as sensitive when any of the
+// values it is derived from is.
+func (v Value) InheritSensitive(from ...Value) Value {
+	for _, item := range from {
+		if item.sensitive {
+			return v.MarkSensitive()
+		}
+	}
+
+	return v
+}
+
+func markSensitiveIf(v Value, marked bool) Value {
+	if marked {
+		return v.MarkSensitive()
+	}
+
+	return v
+}
+
+// Unmark returns the value and all its elements without their sensitive
+// marks, and whether any of them was marked.
+func (v Value) Unmark() (Value, bool) {
+	marked := v.sensitive
+	v.sensitive = false
+
+	switch v.kind {
+	case KindList, KindSet:
+		items := make([]Value, 0, len(v.list()))
+
+		for _, item := range v.list() {
+			item, itemMarked := item.Unmark()
+			marked = marked || itemMarked
+			items = append(items, item)
+		}
+
+		if marked {
+			v.v = items
+		}
+	case KindMap:
+		items := make(map[string]Value, len(v.dict()))
+
+		for k, item := range v.dict() {
+			item, itemMarked := item.Unmark()
+			marked = marked || itemMarked
+			items[k] = item
+		}
+
+		if marked {
+			v.v = items
+		}
+	}
+
+	return v, marked
+}
+
+// RedactError returns err with its message hidden, for the errors caused by
+// sensitive values. The original error is still available to errors.Is and
+// errors.As.
+func RedactError(err error) error {
+	if err == nil || errors.Is(err, ErrSensitive) {
+		return err
+	}
+
+	return &redactedError{err: err}
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return ErrSensitive.Error()
+}
+
+func (e *redactedError) Is(target error) bool {
+	return target == ErrSensitive //nolint:errorlint // sentinel comparison
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
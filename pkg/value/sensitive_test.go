@@ -0,0 +1,125 @@
+package value
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue_Sensitive(t *testing.T) {
+	t.Parallel()
+
+	secret := String("hunter2").MarkSensitive()
+	assert.True(t, secret.IsSensitive())
+	assert.True(t, secret.Equals(String("hunter2")), "marks are not compared")
+	assert.Equal(t, String("hunter2").Hash(), secret.Hash())
+	assert.Equal(t, "(sensitive value)", secret.GoString())
+
+	list := List(String("a"), secret)
+	assert.False(t, list.IsSensitive())
+	assert.True(t, list.ContainsSensitive())
+	assert.Equal(t, `["a", (sensitive value)]`, list.GoString())
+
+	nested := Map(map[string]Value{"a": list})
+	assert.True(t, nested.ContainsSensitive())
+	assert.False(t, Map(map[string]Value{"a": String("b")}).ContainsSensitive())
+
+	unmarked, marked := nested.Unmark()
+	assert.True(t, marked)
+	assert.False(t, unmarked.ContainsSensitive())
+	assert.True(t, nested.ContainsSensitive(), "values are immutable")
+	assert.Equal(t, `{"a" = ["a", "hunter2"]}`, unmarked.GoString())
+
+	_, marked = List(String("a")).Unmark()
+	assert.False(t, marked)
+
+	assert.True(t, Int(1).InheritSensitive(Int(2), secret).IsSensitive())
+	assert.False(t, Int(1).InheritSensitive(list).IsSensitive())
+	assert.True(t, Unknown(TypeString).MarkSensitive().RefineNotNull().IsSensitive())
+	assert.True(t, Unify(secret, String("b")).IsSensitive())
+}
+
+func TestSensitive_Operations(t *testing.T) {
+	t.Parallel()
+
+	secret := Int(2).MarkSensitive()
+
+	tests := []struct {
+		name      string
+		op        func() (Value, error)
+		sensitive bool
+	}{
+		{name: "Add", op: func() (Value, error) { return Add(Int(1), secret) }, sensitive: true},
+		{name: "Add plain", op: func() (Value, error) { return Add(Int(1), Int(2)) }},
+		{name: "Shift", op: func() (Value, error) { return ShiftLeft(secret, Int(1)) }, sensitive: true},
+		{name: "Compare", op: func() (Value, error) { return LessThan(secret, Int(3)) }, sensitive: true},
+		{name: "Equal elements", op: func() (Value, error) { return Equal(List(secret), List(Int(2))) }, sensitive: true},
+		{name: "Not equal", op: func() (Value, error) { return NotEqual(Null, secret) }, sensitive: true},
+		{name: "Negate", op: func() (Value, error) { return Negate(secret) }, sensitive: true},
+		{name: "Unknown", op: func() (Value, error) { return Multiply(Unknown(TypeNumber), secret) }, sensitive: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := tt.op()
+			require.NoError(t, err)
+			assert.Equal(t, tt.sensitive, res.IsSensitive())
+		})
+	}
+
+	_, err := ShiftLeft(Int(1), Int(1000).MarkSensitive())
+	require.ErrorIs(t, err, ErrShiftOutOfRange)
+	assert.NotContains(t, err.Error(), "1000")
+}
+
+func TestFunction_CallSensitive(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed on hunter2")
+
+	var got []Value
+
+	fn := &Function{
+		Name:   "f",
+		Params: []Param{{Name: "a"}, {Name: "b", AllowSensitive: true}},
+		Impl: func(args []Value) (Value, error) {
+			got = args
+
+			if args[0].Equals(String("fail")) {
+				return Null, &ArgError{Index: 0, Err: errFailed}
+			}
+
+			return Int(1), nil
+		},
+	}
+
+	secret := String("hunter2").MarkSensitive()
+
+	res, err := fn.Call([]Value{List(secret), secret})
+	require.NoError(t, err)
+	assert.True(t, res.IsSensitive())
+	assert.False(t, got[0].ContainsSensitive(), "marks are removed from the arguments")
+	assert.True(t, got[1].IsSensitive(), "marks are kept when allowed")
+
+	res, err = fn.Call([]Value{String("a"), secret})
+	require.NoError(t, err)
+	assert.False(t, res.IsSensitive())
+
+	_, err = fn.Call([]Value{String("fail").MarkSensitive(), String("b")})
+	require.ErrorIs(t, err, errFailed)
+	require.ErrorIs(t, err, ErrSensitive)
+	assert.NotContains(t, err.Error(), "hunter2")
+
+	var argErr *ArgError
+	require.ErrorAs(t, err, &argErr)
+	assert.Equal(t, 0, argErr.Index)
+
+	_, err = fn.Call([]Value{String("fail"), secret})
+	assert.EqualError(t, err, "failed on hunter2")
+}
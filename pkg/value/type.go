@@ -0,0 +1,485 @@
+package value
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TypeKind is the kind of type descriptor.
+type TypeKind int
+
+const (
+	TypeKindAny TypeKind = iota
+	TypeKindBool
+	TypeKindNumber
+	TypeKindString
+	TypeKindList
+	TypeKindSet
+	TypeKindMap
+	TypeKindTuple
+	TypeKindObject
+	TypeKindFunction
+	TypeKindUnion
+)
+
+// Type describes the shape of runtime values.
+//
+// Types are immutable and compared with Equals.
+type Type struct {
+	kind  TypeKind
+	elem  *Type
+	elems []Type
+	attrs map[string]Type
+}
+
+//nolint:gochecknoglobals // immutable types
+var (
+	// TypeAny is the dynamic type, conformed to by every value.
+	TypeAny = Type{kind: TypeKindAny}
+
+	// TypeBool is the type of boolean values.
+	TypeBool = Type{kind: TypeKindBool}
+
+	// TypeNumber is the type of number values.
+	TypeNumber = Type{kind: TypeKindNumber}
+
+	// TypeString is the type of string values.
+	TypeString = Type{kind: TypeKindString}
+
+	// TypeFunction is the type of function values.
+	TypeFunction = Type{kind: TypeKindFunction}
+)
+
+// ListOf returns the type of lists of elem.
+func ListOf(elem Type) Type {
+	return Type{kind: TypeKindList, elem: &elem}
+}
+
+// SetOf returns the type of sets of elem.
+func SetOf(elem Type) Type {
+	return Type{kind: TypeKindSet, elem: &elem}
+}
+
+// MapOf returns the type of maps of elem.
+func MapOf(elem Type) Type {
+	return Type{kind: TypeKindMap, elem: &elem}
+}
+
+// TupleOf returns the type of lists with a fixed number of elements
+// of the given types.
+func TupleOf(elems ...Type) Type {
+	out := make([]Type, len(elems))
+	copy(out, elems)
+
+	return Type{kind: TypeKindTuple, elems: out}
+}
+
+// ObjectOf returns the type of maps with a fixed set of attributes
+// of the given types.
+func ObjectOf(attrs map[string]Type) Type {
+	out := make(map[string]Type, len(attrs))
+	for k, t := range attrs {
+		out[k] = t
+	}
+
+	return Type{kind: TypeKindObject, attrs: out}
+}
+
+// UnionOf returns the type of the values conforming to at least one of the
+// members. Nested unions are flattened, and a union with a single member or
+// with TypeAny as a member is that member.
+func UnionOf(members ...Type) Type {
+	out := make([]Type, 0, len(members))
+
+	for _, m := range members {
+		if m.kind == TypeKindAny {
+			return TypeAny
+		}
+
+		for _, item := range m.Members() {
+			if !containsType(out, item) {
+				out = append(out, item)
+			}
+		}
+	}
+
+	if len(out) == 1 {
+		return out[0]
+	}
+
+	return Type{kind: TypeKindUnion, elems: out}
+}
+
+func containsType(types []Type, t Type) bool {
+	for _, item := range types {
+		if item.Equals(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Members returns the member types of a union type, or the type itself for
+// the other kinds.
+func (t Type) Members() []Type {
+	if t.kind != TypeKindUnion {
+		return []Type{t}
+	}
+
+	return t.Elems()
+}
+
+// Kind returns the kind of the type.
+func (t Type) Kind() TypeKind {
+	return t.kind
+}
+
+// Elem returns the element type of a list, set or map type.
+func (t Type) Elem() Type {
+	if t.elem == nil {
+		panic(fmt.Sprintf("type %s has no element type", t))
+	}
+
+	return *t.elem
+}
+
+// Elems returns the element types of a tuple type, or the members of a
+// union type.
+func (t Type) Elems() []Type {
+	out := make([]Type, len(t.elems))
+	copy(out, t.elems)
+
+	return out
+}
+
+// Attrs returns the attribute types of an object type.
+func (t Type) Attrs() map[string]Type {
+	out := make(map[string]Type, len(t.attrs))
+	for k, a := range t.attrs {
+		out[k] = a
+	}
+
+	return out
+}
+
+// IsCollection reports whether the type is a list, set or map type.
+func (t Type) IsCollection() bool {
+	return t.kind == TypeKindList || t.kind == TypeKindSet || t.kind == TypeKindMap
+}
+
+// Equals reports whether two types are identical.
+func (t Type) Equals(other Type) bool {
+	if t.kind != other.kind {
+		return false
+	}
+
+	switch t.kind {
+	case TypeKindList, TypeKindSet, TypeKindMap:
+		return t.elem.Equals(*other.elem)
+	case TypeKindTuple, TypeKindUnion:
+		if len(t.elems) != len(other.elems) {
+			return false
+		}
+
+		for i := range t.elems {
+			if !t.elems[i].Equals(other.elems[i]) {
+				return false
+			}
+		}
+
+		return true
+	case TypeKindObject:
+		if len(t.attrs) != len(other.attrs) {
+			return false
+		}
+
+		for k, a := range t.attrs {
+			o, ok := other.attrs[k]
+			if !ok || !a.Equals(o) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return true
+	}
+}
+
+// String returns the type in etx syntax.
+func (t Type) String() string {
+	switch t.kind {
+	case TypeKindAny:
+		return "any"
+	case TypeKindBool:
+		return "bool"
+	case TypeKindNumber:
+		return "number"
+	case TypeKindString:
+		return "string"
+	case TypeKindList:
+		return fmt.Sprintf("list(%s)", t.elem)
+	case TypeKindSet:
+		return fmt.Sprintf("set(%s)", t.elem)
+	case TypeKindMap:
+		return fmt.Sprintf("map(%s)", t.elem)
+	case TypeKindTuple:
+		items := make([]string, 0, len(t.elems))
+		for _, e := range t.elems {
+			items = append(items, e.String())
+		}
+
+		return fmt.Sprintf("tuple([%s])", strings.Join(items, ", "))
+	case TypeKindObject:
+		keys := make([]string, 0, len(t.attrs))
+		for k := range t.attrs {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		items := make([]string, 0, len(keys))
+		for _, k := range keys {
+			items = append(items, fmt.Sprintf("%s = %s", k, t.attrs[k]))
+		}
+
+		return fmt.Sprintf("object({%s})", strings.Join(items, ", "))
+	case TypeKindFunction:
+		return "function"
+	case TypeKindUnion:
+		items := make([]string, 0, len(t.elems))
+		for _, e := range t.elems {
+			items = append(items, e.String())
+		}
+
+		return strings.Join(items, " | ")
+	default:
+		return fmt.Sprintf("type(%d)", int(t.kind))
+	}
+}
+
+// Conforms reports whether v can be used where a value of type t is expected.
+//
+// Null conforms to every type, and unknown values conform to the types
+// accepting their refined type. A value conforms to a union type when it
+// conforms to one of its members.
+func (t Type) Conforms(v Value) bool {
+	if v.IsNull() || t.kind == TypeKindAny {
+		return true
+	}
+
+	if !v.IsKnown() {
+		return t.Accepts(v.Refinement().Type)
+	}
+
+	switch t.kind {
+	case TypeKindUnion:
+		for _, m := range t.elems {
+			if m.Conforms(v) {
+				return true
+			}
+		}
+
+		return false
+	case TypeKindBool:
+		return v.kind == KindBool
+	case TypeKindNumber:
+		return v.kind == KindNumber
+	case TypeKindString:
+		return v.kind == KindString
+	case TypeKindFunction:
+		return v.kind == KindFunction
+	case TypeKindList, TypeKindSet:
+		if (t.kind == TypeKindList && v.kind != KindList) || (t.kind == TypeKindSet && v.kind != KindSet) {
+			return false
+		}
+
+		for _, item := range v.list() {
+			if !t.elem.Conforms(item) {
+				return false
+			}
+		}
+
+		return true
+	case TypeKindMap:
+		if v.kind != KindMap {
+			return false
+		}
+
+		for _, item := range v.dict() {
+			if !t.elem.Conforms(item) {
+				return false
+			}
+		}
+
+		return true
+	case TypeKindTuple:
+		if v.kind != KindList || len(v.list()) != len(t.elems) {
+			return false
+		}
+
+		for i, item := range v.list() {
+			if !t.elems[i].Conforms(item) {
+				return false
+			}
+		}
+
+		return true
+	case TypeKindObject:
+		if v.kind != KindMap {
+			return false
+		}
+
+		items := v.dict()
+		for k, a := range t.attrs {
+			if !a.Conforms(items[k]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// Accepts reports whether values of type u may be used where a value of
+// type t is expected. TypeAny stands for values whose type is not known
+// statically: it accepts and is accepted by every type. Likewise, a union
+// type accepts the types accepted by one of its members, and is accepted by
+// the types accepting one of its members.
+func (t Type) Accepts(u Type) bool {
+	if t.kind == TypeKindAny || u.kind == TypeKindAny {
+		return true
+	}
+
+	if t.kind == TypeKindUnion || u.kind == TypeKindUnion {
+		for _, tm := range t.Members() {
+			for _, um := range u.Members() {
+				if tm.Accepts(um) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	switch {
+	case t.kind == TypeKindTuple && u.kind == TypeKindTuple:
+		if len(t.elems) != len(u.elems) {
+			return false
+		}
+
+		for i := range t.elems {
+			if !t.elems[i].Accepts(u.elems[i]) {
+				return false
+			}
+		}
+
+		return true
+	case t.kind == TypeKindTuple && u.kind == TypeKindList:
+		for _, elem := range t.elems {
+			if !elem.Accepts(*u.elem) {
+				return false
+			}
+		}
+
+		return true
+	case t.kind == TypeKindList && u.kind == TypeKindTuple:
+		for _, elem := range u.elems {
+			if !t.elem.Accepts(elem) {
+				return false
+			}
+		}
+
+		return true
+	case t.kind == TypeKindObject && (u.kind == TypeKindObject || u.kind == TypeKindMap):
+		for k, a := range t.attrs {
+			if ua, ok := u.attrs[k]; ok && !a.Accepts(ua) {
+				return false
+			}
+
+			if u.kind == TypeKindMap && !a.Accepts(*u.elem) {
+				return false
+			}
+		}
+
+		return true
+	case t.kind == TypeKindMap && u.kind == TypeKindObject:
+		for _, a := range u.attrs {
+			if !t.elem.Accepts(a) {
+				return false
+			}
+		}
+
+		return true
+	case t.kind != u.kind:
+		return false
+	case t.elem != nil:
+		return t.elem.Accepts(*u.elem)
+	default:
+		return true
+	}
+}
+
+// Type returns the most specific type describing the value.
+//
+// The element type of heterogeneous collections is TypeAny.
+func (v Value) Type() Type {
+	switch v.kind {
+	case KindNull:
+		return TypeAny
+	case KindBool:
+		return TypeBool
+	case KindNumber:
+		return TypeNumber
+	case KindString:
+		return TypeString
+	case KindFunction:
+		return TypeFunction
+	case KindUnknown:
+		return v.Refinement().Type
+	case KindList:
+		return ListOf(unifyTypes(v.list()))
+	case KindSet:
+		return SetOf(unifyTypes(v.list()))
+	case KindMap:
+		items := make([]Value, 0, len(v.dict()))
+		for _, item := range v.dict() {
+			items = append(items, item)
+		}
+
+		return MapOf(unifyTypes(items))
+	default:
+		return TypeAny
+	}
+}
+
+// unifyTypes returns the common type of the non-null items, or TypeAny.
+func unifyTypes(items []Value) Type {
+	var res *Type
+
+	for _, item := range items {
+		if item.IsNull() {
+			continue
+		}
+
+		t := item.Type()
+
+		switch {
+		case res == nil:
+			res = &t
+		case !res.Equals(t):
+			return TypeAny
+		}
+	}
+
+	if res == nil {
+		return TypeAny
+	}
+
+	return *res
+}
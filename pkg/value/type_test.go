@@ -0,0 +1,164 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestType_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input Type
+		want  string
+	}{
+		{name: "Any", input: TypeAny, want: "any"},
+		{name: "Primitive", input: TypeNumber, want: "number"},
+		{name: "List", input: ListOf(TypeString), want: "list(string)"},
+		{name: "Nested", input: MapOf(SetOf(TypeBool)), want: "map(set(bool))"},
+		{name: "Tuple", input: TupleOf(TypeString, TypeNumber), want: "tuple([string, number])"},
+		{name: "Object", input: ObjectOf(map[string]Type{"b": TypeBool, "a": TypeString}), want: "object({a = string, b = bool})"},
+		{name: "Union", input: ListOf(UnionOf(TypeNumber, UnionOf(TypeString, TypeNumber))), want: "list(number | string)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.input.String())
+		})
+	}
+}
+
+func TestType_Equals(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, ListOf(TypeString).Equals(ListOf(TypeString)))
+	assert.False(t, ListOf(TypeString).Equals(SetOf(TypeString)))
+	assert.False(t, ListOf(TypeString).Equals(ListOf(TypeNumber)))
+	assert.True(t, TupleOf(TypeString).Equals(TupleOf(TypeString)))
+	assert.False(t, TupleOf(TypeString).Equals(TupleOf(TypeString, TypeString)))
+	assert.True(t, ObjectOf(map[string]Type{"a": TypeAny}).Equals(ObjectOf(map[string]Type{"a": TypeAny})))
+	assert.False(t, ObjectOf(map[string]Type{"a": TypeAny}).Equals(ObjectOf(map[string]Type{"b": TypeAny})))
+	assert.True(t, UnionOf(TypeString, TypeNumber).Equals(UnionOf(TypeString, TypeNumber)))
+	assert.True(t, UnionOf(TypeString).Equals(TypeString))
+	assert.True(t, UnionOf(TypeString, TypeAny).Equals(TypeAny))
+}
+
+func TestType_Conforms(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		typ   Type
+		input Value
+		want  bool
+	}{
+		{name: "Null conforms to anything", typ: TypeNumber, input: Null, want: true},
+		{name: "Any", typ: TypeAny, input: String("a"), want: true},
+		{name: "Primitive", typ: TypeString, input: String("a"), want: true},
+		{name: "Primitive mismatch", typ: TypeString, input: Int(1), want: false},
+		{name: "List", typ: ListOf(TypeNumber), input: List(Int(1), Int(2)), want: true},
+		{name: "List element mismatch", typ: ListOf(TypeNumber), input: List(Int(1), String("a")), want: false},
+		{name: "Set is not a list", typ: ListOf(TypeNumber), input: Set(Int(1)), want: false},
+		{name: "Set", typ: SetOf(TypeNumber), input: Set(Int(1)), want: true},
+		{name: "Map", typ: MapOf(TypeBool), input: Map(map[string]Value{"a": True}), want: true},
+		{name: "Tuple", typ: TupleOf(TypeNumber, TypeString), input: List(Int(1), String("a")), want: true},
+		{name: "Tuple length mismatch", typ: TupleOf(TypeNumber), input: List(Int(1), Int(2)), want: false},
+		{name: "Object", typ: ObjectOf(map[string]Type{"a": TypeNumber}), input: Map(map[string]Value{"a": Int(1), "b": True}), want: true},
+		{name: "Object attribute mismatch", typ: ObjectOf(map[string]Type{"a": TypeNumber}), input: Map(map[string]Value{"a": True}), want: false},
+		{name: "Union", typ: UnionOf(TypeNumber, TypeString), input: String("a"), want: true},
+		{name: "Union mismatch", typ: UnionOf(TypeNumber, TypeString), input: True, want: false},
+		{name: "List of union", typ: ListOf(UnionOf(TypeNumber, TypeString)), input: List(Int(1), True), want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.typ.Conforms(tt.input))
+		})
+	}
+}
+
+func TestType_Accepts(t *testing.T) {
+	t.Parallel()
+
+	object := ObjectOf(map[string]Type{"a": TypeNumber})
+
+	tests := []struct {
+		name  string
+		typ   Type
+		input Type
+		want  bool
+	}{
+		{name: "Any accepts anything", typ: TypeAny, input: ListOf(TypeString), want: true},
+		{name: "Any is accepted", typ: TypeNumber, input: TypeAny, want: true},
+		{name: "Primitive", typ: TypeString, input: TypeString, want: true},
+		{name: "Primitive mismatch", typ: TypeString, input: TypeNumber, want: false},
+		{name: "List", typ: ListOf(TypeNumber), input: ListOf(TypeNumber), want: true},
+		{name: "List of any", typ: ListOf(TypeNumber), input: ListOf(TypeAny), want: true},
+		{name: "List element mismatch", typ: ListOf(TypeNumber), input: ListOf(TypeString), want: false},
+		{name: "Set is not a list", typ: ListOf(TypeNumber), input: SetOf(TypeNumber), want: false},
+		{name: "Tuple", typ: TupleOf(TypeNumber, TypeString), input: TupleOf(TypeNumber, TypeString), want: true},
+		{name: "Tuple length mismatch", typ: TupleOf(TypeNumber), input: TupleOf(TypeNumber, TypeNumber), want: false},
+		{name: "Tuple from list", typ: TupleOf(TypeNumber, TypeNumber), input: ListOf(TypeNumber), want: true},
+		{name: "Tuple from list mismatch", typ: TupleOf(TypeNumber, TypeString), input: ListOf(TypeNumber), want: false},
+		{name: "List from tuple", typ: ListOf(TypeNumber), input: TupleOf(TypeNumber, TypeNumber), want: true},
+		{name: "Object", typ: object, input: ObjectOf(map[string]Type{"a": TypeNumber, "b": TypeBool}), want: true},
+		{name: "Object attribute mismatch", typ: object, input: ObjectOf(map[string]Type{"a": TypeBool}), want: false},
+		{name: "Object from map", typ: object, input: MapOf(TypeNumber), want: true},
+		{name: "Object from map mismatch", typ: object, input: MapOf(TypeString), want: false},
+		{name: "Map from object", typ: MapOf(TypeNumber), input: object, want: true},
+		{name: "Function", typ: TypeFunction, input: TypeFunction, want: true},
+		{name: "Union", typ: UnionOf(TypeNumber, TypeString), input: TypeString, want: true},
+		{name: "Union mismatch", typ: UnionOf(TypeNumber, TypeString), input: TypeBool, want: false},
+		{name: "Union is accepted", typ: TypeString, input: UnionOf(TypeNumber, TypeString), want: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.typ.Accepts(tt.input))
+		})
+	}
+}
+
+func TestValue_Type(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input Value
+		want  Type
+	}{
+		{name: "Null", input: Null, want: TypeAny},
+		{name: "Number", input: Int(1), want: TypeNumber},
+		{name: "Function", input: Func(&Function{}), want: TypeFunction},
+		{name: "Empty list", input: List(), want: ListOf(TypeAny)},
+		{name: "Homogeneous list", input: List(Int(1), Null, Int(2)), want: ListOf(TypeNumber)},
+		{name: "Heterogeneous list", input: List(Int(1), String("a")), want: ListOf(TypeAny)},
+		{name: "Set", input: Set(String("a")), want: SetOf(TypeString)},
+		{name: "Nested map", input: Map(map[string]Value{"a": List(True)}), want: MapOf(ListOf(TypeBool))},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want.String(), tt.input.Type().String())
+			assert.True(t, tt.want.Equals(tt.input.Type()))
+		})
+	}
+}
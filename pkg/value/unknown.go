@@ -0,0 +1,258 @@
+package value
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Refinement is what is known of an unknown value before it is known, such
+// as the attributes of a resource that only exist after it is created.
+type Refinement struct {
+	// Type is the type the value will conform to. It is TypeAny when
+	// nothing is known of the type.
+	Type Type
+
+	// NotNull reports that the value will not be null.
+	NotNull bool
+
+	// Length is the number of elements of a collection, or of characters
+	// of a string, or -1 when it is not known.
+	Length int
+}
+
+// Unknown returns a value that is not known yet and will conform to t.
+//
+// Operations on unknown values yield unknown results instead of failing,
+// so that expressions can be evaluated before every value is known.
+func Unknown(t Type) Value {
+	return Value{kind: KindUnknown, v: Refinement{Type: t, Length: -1}}
+}
+
+// IsKnown reports whether the value is known. The elements of a known
+// collection may still be unknown.
+func (v Value) IsKnown() bool {
+	return v.kind != KindUnknown
+}
+
+// IsWhollyKnown reports whether the value and all its elements are known.
+func (v Value) IsWhollyKnown() bool {
+	switch v.kind {
+	case KindUnknown:
+		return false
+	case KindList, KindSet:
+		for _, item := range v.list() {
+			if !item.IsWhollyKnown() {
+				return false
+			}
+		}
+	case KindMap:
+		for _, item := range v.dict() {
+			if !item.IsWhollyKnown() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Refinement returns what is known of an unknown value.
+func (v Value) Refinement() Refinement {
+	v.mustBe(KindUnknown)
+
+	return v.v.(Refinement) //nolint:forcetypeassert // kind checked above
+}
+
+// RefineNotNull returns the unknown value refined to never be null.
+func (v Value) RefineNotNull() Value {
+	r := v.Refinement()
+	r.NotNull = true
+
+	return v.refine(r)
+}
+
+// RefineType returns the unknown value refined to conform to t.
+func (v Value) RefineType(t Type) Value {
+	r := v.Refinement()
+	r.Type = t
+
+	return v.refine(r)
+}
+
+// RefineLength returns the unknown value refined to have n elements or
+// characters. Only collections and strings have a length, so the value is
+// also refined to never be null.
+func (v Value) RefineLength(n int) Value {
+	r := v.Refinement()
+	r.NotNull, r.Length = true, n
+
+	return v.refine(r)
+}
+
+// refine returns the unknown value with the refinement r, keeping its
+// sensitive mark.
+func (v Value) refine(r Refinement) Value {
+	v.v = r
+
+	return v
+}
+
+// Unify returns a when a and b are equal known values, or else an unknown
+// value refined with what a and b have in common. It is the value of a
+// conditional whose condition is unknown, and is sensitive when either a or
+// b is.
+func Unify(a, b Value) Value {
+	if a.IsWhollyKnown() && b.IsWhollyKnown() && a.Equals(b) {
+		return a.InheritSensitive(b)
+	}
+
+	ra, rb := a.refinement(), b.refinement()
+
+	res := Refinement{Type: TypeAny, NotNull: ra.NotNull && rb.NotNull, Length: -1}
+
+	switch {
+	case ra.Type.Equals(rb.Type):
+		res.Type = ra.Type
+	case a.IsNull():
+		res.Type = rb.Type
+	case b.IsNull():
+		res.Type = ra.Type
+	}
+
+	if ra.Length == rb.Length {
+		res.Length = ra.Length
+	}
+
+	return Value{kind: KindUnknown, v: res}.InheritSensitive(a, b)
+}
+
+// refinement returns the refinement of an unknown value, or the refinement
+// describing a known value.
+func (v Value) refinement() Refinement {
+	switch v.kind {
+	case KindUnknown:
+		return v.Refinement()
+	case KindNull:
+		return Refinement{Type: TypeAny, Length: -1}
+	case KindString, KindList, KindSet, KindMap:
+		return Refinement{Type: v.Type(), NotNull: true, Length: v.Len()}
+	default:
+		return Refinement{Type: v.Type(), NotNull: true, Length: -1}
+	}
+}
+
+// operandKind returns the kind of a known value, or the kind an unknown
+// value will have when its type is refined, or else KindUnknown.
+func (v Value) operandKind() (Kind, bool) {
+	if v.kind != KindUnknown {
+		return v.kind, true
+	}
+
+	switch v.Refinement().Type.kind {
+	case TypeKindBool:
+		return KindBool, true
+	case TypeKindNumber:
+		return KindNumber, true
+	case TypeKindString:
+		return KindString, true
+	case TypeKindList, TypeKindTuple:
+		return KindList, true
+	case TypeKindSet:
+		return KindSet, true
+	case TypeKindMap, TypeKindObject:
+		return KindMap, true
+	case TypeKindFunction:
+		return KindFunction, true
+	default:
+		return KindUnknown, false
+	}
+}
+
+// unknownBinary returns the unknown result of the binary operator op when
+// a or b is unknown. The operands must both be of one of the kinds, and the
+// result is of the kind of the operands, or of kind result when it is set.
+func unknownBinary(op string, a, b Value, result Kind, kinds ...Kind) (Value, bool, error) {
+	if a.IsKnown() && b.IsKnown() {
+		return Null, false, nil
+	}
+
+	ka, okA := a.operandKind()
+	kb, okB := b.operandKind()
+
+	if (okA && !containsKind(kinds, ka)) || (okB && !containsKind(kinds, kb)) || (okA && okB && ka != kb) {
+		return Null, true, fmt.Errorf("%w: %s %s %s", ErrUnsupportedOp, ka, op, kb)
+	}
+
+	switch {
+	case result != KindUnknown:
+	case okA:
+		result = ka
+	case okB:
+		result = kb
+	}
+
+	return Unknown(kindType(result)).RefineNotNull(), true, nil
+}
+
+// unknownUnary returns the unknown result of the unary operator op when a
+// is unknown.
+func unknownUnary(op string, a Value, kind Kind) (Value, bool, error) {
+	if a.IsKnown() {
+		return Null, false, nil
+	}
+
+	if k, ok := a.operandKind(); ok && k != kind {
+		return Null, true, fmt.Errorf("%w: %s%s", ErrUnsupportedOp, op, k)
+	}
+
+	return Unknown(kindType(kind)).RefineNotNull(), true, nil
+}
+
+// kindType returns the type of the values of a scalar kind.
+func kindType(k Kind) Type {
+	switch k {
+	case KindBool:
+		return TypeBool
+	case KindNumber:
+		return TypeNumber
+	case KindString:
+		return TypeString
+	default:
+		return TypeAny
+	}
+}
+
+func containsKind(kinds []Kind, k Kind) bool {
+	for _, kind := range kinds {
+		if kind == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unknownString returns the unknown value in etx syntax.
+func (v Value) unknownString() string {
+	r := v.Refinement()
+
+	var details []string
+
+	if r.Type.kind != TypeKindAny {
+		details = append(details, r.Type.String())
+	}
+
+	if r.NotNull {
+		details = append(details, "not null")
+	}
+
+	if r.Length >= 0 {
+		details = append(details, fmt.Sprintf("length %d", r.Length))
+	}
+
+	if len(details) == 0 {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("unknown(%s)", strings.Join(details, ", "))
+}
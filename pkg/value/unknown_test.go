@@ -0,0 +1,175 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknown_Operations(t *testing.T) {
+	t.Parallel()
+
+	number := Unknown(TypeNumber)
+
+	tests := []struct {
+		name    string
+		op      func(a, b Value) (Value, error)
+		a       Value
+		b       Value
+		want    string
+		wantErr error
+	}{
+		{name: "Add numbers", op: Add, a: number, b: Int(1), want: "unknown(number, not null)"},
+		{name: "Add strings", op: Add, a: String("a"), b: Unknown(TypeString), want: "unknown(string, not null)"},
+		{name: "Add any", op: Add, a: Unknown(TypeAny), b: Unknown(TypeAny), want: "unknown(not null)"},
+		{name: "Add mismatch", op: Add, a: number, b: String("a"), wantErr: ErrUnsupportedOp},
+		{name: "Subtract any", op: Subtract, a: Int(1), b: Unknown(TypeAny), want: "unknown(number, not null)"},
+		{name: "Subtract string", op: Subtract, a: Unknown(TypeString), b: Int(1), wantErr: ErrUnsupportedOp},
+		{name: "Shift", op: ShiftLeft, a: number, b: Int(1), want: "unknown(number, not null)"},
+		{name: "Compare", op: LessThan, a: number, b: Int(1), want: "unknown(bool, not null)"},
+		{name: "Compare mismatch", op: LessThan, a: Unknown(TypeBool), b: Int(1), wantErr: ErrUnsupportedOp},
+		{name: "Equal", op: Equal, a: number, b: Int(1), want: "unknown(bool, not null)"},
+		{name: "Equal elements", op: Equal, a: List(number), b: List(Int(1)), want: "unknown(bool, not null)"},
+		{name: "Equal null", op: Equal, a: number, b: Null, want: "unknown(bool, not null)"},
+		{name: "Equal null refined", op: Equal, a: Null, b: number.RefineNotNull(), want: "false"},
+		{name: "Not equal null refined", op: NotEqual, a: number.RefineNotNull(), b: Null, want: "true"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := tt.op(tt.a, tt.b)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res.GoString())
+		})
+	}
+}
+
+func TestUnknown_UnaryOperations(t *testing.T) {
+	t.Parallel()
+
+	res, err := Negate(Unknown(TypeAny))
+	require.NoError(t, err)
+	assert.Equal(t, "unknown(number, not null)", res.GoString())
+
+	res, err = Plus(Unknown(TypeAny))
+	require.NoError(t, err)
+	assert.Equal(t, "unknown(number, not null)", res.GoString())
+
+	_, err = Plus(Unknown(TypeString))
+	assert.ErrorIs(t, err, ErrUnsupportedOp)
+
+	res, err = Not(Unknown(TypeBool))
+	require.NoError(t, err)
+	assert.Equal(t, "unknown(bool, not null)", res.GoString())
+
+	_, err = Not(Unknown(TypeString))
+	assert.ErrorIs(t, err, ErrUnsupportedOp)
+}
+
+func TestUnknown_Refinement(t *testing.T) {
+	t.Parallel()
+
+	v := Unknown(ListOf(TypeString))
+	assert.False(t, v.IsKnown())
+	assert.Equal(t, Refinement{Type: ListOf(TypeString), Length: -1}, v.Refinement())
+	assert.Equal(t, "unknown(list(string))", v.GoString())
+
+	v = v.RefineLength(2)
+	assert.Equal(t, Refinement{Type: ListOf(TypeString), NotNull: true, Length: 2}, v.Refinement())
+	assert.Equal(t, "unknown(list(string), not null, length 2)", v.GoString())
+
+	assert.Equal(t, "unknown", Unknown(TypeAny).GoString())
+	assert.True(t, Unknown(TypeAny).RefineType(TypeBool).Type().Equals(TypeBool))
+	assert.False(t, v.Equals(v), "unknown values are never equal")
+}
+
+func TestUnknown_IsWhollyKnown(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, List(Int(1)).IsWhollyKnown())
+	assert.False(t, Unknown(TypeAny).IsWhollyKnown())
+
+	nested := Map(map[string]Value{"a": List(Int(1), Unknown(TypeNumber))})
+	assert.True(t, nested.IsKnown())
+	assert.False(t, nested.IsWhollyKnown())
+}
+
+func TestUnknown_Conforms(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, TypeNumber.Conforms(Unknown(TypeNumber)))
+	assert.True(t, TypeNumber.Conforms(Unknown(TypeAny)))
+	assert.True(t, TypeAny.Conforms(Unknown(TypeString)))
+	assert.False(t, TypeNumber.Conforms(Unknown(TypeString)))
+	assert.True(t, ListOf(TypeNumber).Conforms(List(Int(1), Unknown(TypeNumber))))
+	assert.False(t, ListOf(TypeNumber).Conforms(List(Unknown(TypeBool))))
+}
+
+func TestUnify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    Value
+		b    Value
+		want string
+	}{
+		{name: "Equal", a: Int(1), b: Int(1), want: "1"},
+		{name: "Same type", a: Int(1), b: Int(2), want: "unknown(number, not null)"},
+		{name: "Same length", a: String("a"), b: String("b"), want: "unknown(string, not null, length 1)"},
+		{name: "Different types", a: Int(1), b: String("a"), want: "unknown(not null)"},
+		{name: "Null", a: Null, b: String("a"), want: "unknown(string)"},
+		{name: "Unknown", a: Unknown(TypeNumber).RefineNotNull(), b: Int(1), want: "unknown(number, not null)"},
+		{name: "Equal unknowns", a: Unknown(TypeNumber), b: Unknown(TypeNumber), want: "unknown(number)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, Unify(tt.a, tt.b).GoString())
+		})
+	}
+}
+
+func TestFunction_CallUnknown(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+
+	fn := &Function{
+		Name:   "f",
+		Params: []Param{{Name: "a", Type: TypeNumber}, {Name: "b", AllowUnknown: true}},
+		Impl: func(args []Value) (Value, error) {
+			calls++
+
+			return Int(1), nil
+		},
+	}
+
+	res, err := fn.Call([]Value{Unknown(TypeNumber), Int(1)})
+	require.NoError(t, err)
+	assert.False(t, res.IsKnown())
+	assert.Equal(t, 0, calls)
+
+	res, err = fn.Call([]Value{Int(1), Unknown(TypeAny)})
+	require.NoError(t, err)
+	assert.True(t, res.Equals(Int(1)))
+	assert.Equal(t, 1, calls)
+
+	_, err = fn.Call([]Value{Unknown(TypeString), Int(1)})
+	assert.ErrorIs(t, err, ErrArgument)
+}
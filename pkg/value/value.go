@@ -0,0 +1,415 @@
+// Package value implements the immutable runtime values produced by the etx
+// evaluator and shared with the rest of etxe.
+package value
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NumberPrecision is the mantissa precision, in bits, used for all the
+// arithmetic performed on number values.
+const NumberPrecision = 512
+
+// Kind of runtime value.
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindList
+	KindSet
+	KindMap
+	KindFunction
+	KindUnknown
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindList:
+		return "list"
+	case KindSet:
+		return "set"
+	case KindMap:
+		return "map"
+	case KindFunction:
+		return "function"
+	case KindUnknown:
+		return "unknown"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Value is an immutable runtime value.
+//
+// The zero Value is null.
+type Value struct {
+	kind      Kind
+	v         any
+	sensitive bool
+}
+
+//nolint:gochecknoglobals // immutable values
+var (
+	// Null is the null value.
+	Null = Value{kind: KindNull}
+
+	// True is the boolean true value.
+	True = Value{kind: KindBool, v: true}
+
+	// False is the boolean false value.
+	False = Value{kind: KindBool, v: false}
+)
+
+// Bool returns a boolean value.
+func Bool(b bool) Value {
+	if b {
+		return True
+	}
+
+	return False
+}
+
+// Number returns a number value holding a copy of f.
+func Number(f *big.Float) Value {
+	out := new(big.Float).SetPrec(NumberPrecision)
+	out.Set(f)
+
+	return Value{kind: KindNumber, v: out}
+}
+
+// Int returns a number value holding i.
+func Int(i int64) Value {
+	return Value{kind: KindNumber, v: new(big.Float).SetPrec(NumberPrecision).SetInt64(i)}
+}
+
+// Float returns a number value holding f. Numbers are finite: it panics if
+// f is NaN or infinite.
+func Float(f float64) Value {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		panic(fmt.Sprintf("value: %v is not a finite number", f))
+	}
+
+	return Value{kind: KindNumber, v: new(big.Float).SetPrec(NumberPrecision).SetFloat64(f)}
+}
+
+// String returns a string value.
+func String(s string) Value {
+	return Value{kind: KindString, v: s}
+}
+
+// List returns a list value holding a copy of items.
+func List(items ...Value) Value {
+	out := make([]Value, len(items))
+	copy(out, items)
+
+	return Value{kind: KindList, v: out}
+}
+
+// Set returns a set value holding the distinct elements of items.
+//
+// The elements of a set are kept sorted by Compare.
+func Set(items ...Value) Value {
+	out := make([]Value, 0, len(items))
+
+	seen := make(map[uint64][]Value, len(items))
+
+	for _, item := range items {
+		h := item.Hash()
+		if containsValue(seen[h], item) {
+			continue
+		}
+
+		seen[h] = append(seen[h], item)
+		out = append(out, item)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return Compare(out[i], out[j]) < 0
+	})
+
+	return Value{kind: KindSet, v: out}
+}
+
+// Map returns a map value holding a copy of items.
+func Map(items map[string]Value) Value {
+	out := make(map[string]Value, len(items))
+	for k, v := range items {
+		out[k] = v
+	}
+
+	return Value{kind: KindMap, v: out}
+}
+
+// Func returns a function value.
+func Func(f *Function) Value {
+	return Value{kind: KindFunction, v: f}
+}
+
+// Kind returns the kind of the value.
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// IsNull reports whether the value is null.
+func (v Value) IsNull() bool {
+	return v.kind == KindNull
+}
+
+// AsBool returns the Go boolean held by a bool value.
+func (v Value) AsBool() bool {
+	v.mustBe(KindBool)
+
+	return v.v.(bool) //nolint:forcetypeassert // kind checked above
+}
+
+// AsBigFloat returns a copy of the number held by a number value.
+func (v Value) AsBigFloat() *big.Float {
+	v.mustBe(KindNumber)
+
+	return new(big.Float).Copy(v.v.(*big.Float)) //nolint:forcetypeassert // kind checked above
+}
+
+// AsString returns the Go string held by a string value.
+func (v Value) AsString() string {
+	v.mustBe(KindString)
+
+	return v.v.(string) //nolint:forcetypeassert // kind checked above
+}
+
+// IsCollection reports whether the value is a list, a set or a map.
+func (v Value) IsCollection() bool {
+	return v.kind == KindList || v.kind == KindSet || v.kind == KindMap
+}
+
+// AsList returns a copy of the elements of a list or set value.
+func (v Value) AsList() []Value {
+	items := v.list()
+	out := make([]Value, len(items))
+	copy(out, items)
+
+	return out
+}
+
+// AsMap returns a copy of the elements of a map value.
+func (v Value) AsMap() map[string]Value {
+	items := v.dict()
+	out := make(map[string]Value, len(items))
+
+	for k, item := range items {
+		out[k] = item
+	}
+
+	return out
+}
+
+// AsFunction returns the function held by a function value.
+func (v Value) AsFunction() *Function {
+	v.mustBe(KindFunction)
+
+	return v.v.(*Function) //nolint:forcetypeassert // kind checked above
+}
+
+// Len returns the number of elements of a list, set or map value,
+// or the number of characters of a string value.
+func (v Value) Len() int {
+	switch v.kind {
+	case KindList, KindSet:
+		return len(v.list())
+	case KindMap:
+		return len(v.dict())
+	case KindString:
+		return len([]rune(v.AsString()))
+	default:
+		panic(fmt.Sprintf("value of kind %s has no length", v.kind))
+	}
+}
+
+// Index returns the i-th element of a list or set value.
+func (v Value) Index(i int) Value {
+	return v.list()[i]
+}
+
+// Get returns the element at key in a map value.
+func (v Value) Get(key string) (Value, bool) {
+	item, ok := v.dict()[key]
+
+	return item, ok
+}
+
+// Keys returns the sorted keys of a map value.
+func (v Value) Keys() []string {
+	items := v.dict()
+
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Equals reports whether two values are deeply equal.
+//
+// Unknown values are not equal to any value, as they may end up different.
+// Sensitive marks are not compared.
+func (v Value) Equals(other Value) bool {
+	if v.kind != other.kind {
+		return false
+	}
+
+	switch v.kind {
+	case KindNull:
+		return true
+	case KindBool:
+		return v.AsBool() == other.AsBool()
+	case KindNumber:
+		return v.number().Cmp(other.number()) == 0
+	case KindString:
+		return v.AsString() == other.AsString()
+	case KindList, KindSet:
+		a, b := v.list(), other.list()
+		if len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !a[i].Equals(b[i]) {
+				return false
+			}
+		}
+
+		return true
+	case KindMap:
+		a, b := v.dict(), other.dict()
+		if len(a) != len(b) {
+			return false
+		}
+
+		for k, item := range a {
+			o, ok := b[k]
+			if !ok || !item.Equals(o) {
+				return false
+			}
+		}
+
+		return true
+	case KindFunction:
+		return v.v == other.v
+	default:
+		return false
+	}
+}
+
+// GoString returns the value in etx syntax. Sensitive values are redacted.
+func (v Value) GoString() string {
+	if v.sensitive {
+		return redacted
+	}
+
+	switch v.kind {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return strconv.FormatBool(v.AsBool())
+	case KindNumber:
+		return FormatNumber(v.number())
+	case KindString:
+		return strconv.Quote(v.AsString())
+	case KindList, KindSet:
+		items := make([]string, 0, len(v.list()))
+		for _, item := range v.list() {
+			items = append(items, item.GoString())
+		}
+
+		if v.kind == KindSet {
+			return fmt.Sprintf("Set(%s)", strings.Join(items, ", "))
+		}
+
+		return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+	case KindMap:
+		keys := v.Keys()
+
+		items := make([]string, 0, len(keys))
+		for _, k := range keys {
+			items = append(items, fmt.Sprintf("%s = %s", strconv.Quote(k), v.dict()[k].GoString()))
+		}
+
+		return fmt.Sprintf("{%s}", strings.Join(items, ", "))
+	case KindFunction:
+		return fmt.Sprintf("<function %s>", v.AsFunction().Name)
+	case KindUnknown:
+		return v.unknownString()
+	default:
+		return fmt.Sprintf("<%s>", v.kind)
+	}
+}
+
+// FormatNumber formats a number in decimal notation.
+//
+// Integers are formatted exactly, other numbers use the shortest
+// representation that round-trips through a float64.
+func FormatNumber(f *big.Float) string {
+	if f.IsInt() {
+		i, _ := f.Int(nil)
+
+		return i.String()
+	}
+
+	f64, _ := f.Float64()
+
+	return strconv.FormatFloat(f64, 'f', -1, 64)
+}
+
+func (v Value) mustBe(kind Kind) {
+	if v.kind != kind {
+		panic(fmt.Sprintf("value of kind %s is not a %s", v.kind, kind))
+	}
+}
+
+func (v Value) number() *big.Float {
+	v.mustBe(KindNumber)
+
+	return v.v.(*big.Float) //nolint:forcetypeassert // kind checked above
+}
+
+func (v Value) list() []Value {
+	if v.kind != KindSet {
+		v.mustBe(KindList)
+	}
+
+	return v.v.([]Value) //nolint:forcetypeassert // kind checked above
+}
+
+func (v Value) dict() map[string]Value {
+	v.mustBe(KindMap)
+
+	return v.v.(map[string]Value) //nolint:forcetypeassert // kind checked above
+}
+
+func containsValue(items []Value, v Value) bool {
+	for _, item := range items {
+		if item.Equals(v) {
+			return true
+		}
+	}
+
+	return false
+}
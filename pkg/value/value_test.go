@@ -0,0 +1,170 @@
+package value
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_Equals(t *testing.T) {
+	t.Parallel()
+
+	fn := &Function{Name: "f"}
+
+	tests := []struct {
+		name string
+		a    Value
+		b    Value
+		want bool
+	}{
+		{name: "Null", a: Null, b: Value{}, want: true},
+		{name: "Bool", a: True, b: Bool(true), want: true},
+		{name: "Different bools", a: True, b: False, want: false},
+		{name: "Number", a: Int(1), b: Float(1.0), want: true},
+		{name: "Different numbers", a: Int(1), b: Int(2), want: false},
+		{name: "String", a: String("a"), b: String("a"), want: true},
+		{name: "Different kinds", a: Int(1), b: String("1"), want: false},
+		{name: "List", a: List(Int(1), String("a")), b: List(Int(1), String("a")), want: true},
+		{name: "Different list lengths", a: List(Int(1)), b: List(Int(1), Int(2)), want: false},
+		{name: "Map", a: Map(map[string]Value{"a": Int(1)}), b: Map(map[string]Value{"a": Int(1)}), want: true},
+		{name: "Different map keys", a: Map(map[string]Value{"a": Int(1)}), b: Map(map[string]Value{"b": Int(1)}), want: false},
+		{name: "Same function", a: Func(fn), b: Func(fn), want: true},
+		{name: "Different functions", a: Func(fn), b: Func(&Function{Name: "f"}), want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.a.Equals(tt.b))
+			assert.Equal(t, tt.want, tt.b.Equals(tt.a))
+		})
+	}
+}
+
+func TestValue_Immutability(t *testing.T) {
+	t.Parallel()
+
+	f := big.NewFloat(1)
+	n := Number(f)
+	f.SetInt64(2)
+	assert.True(t, n.Equals(Int(1)))
+
+	n.AsBigFloat().SetInt64(3)
+	assert.True(t, n.Equals(Int(1)))
+
+	items := []Value{Int(1)}
+	l := List(items...)
+	items[0] = Int(2)
+	assert.True(t, l.Equals(List(Int(1))))
+
+	l.AsList()[0] = Int(3)
+	assert.True(t, l.Equals(List(Int(1))))
+
+	m := map[string]Value{"a": Int(1)}
+	mv := Map(m)
+	m["a"] = Int(2)
+	assert.True(t, mv.Equals(Map(map[string]Value{"a": Int(1)})))
+}
+
+func TestValue_GoString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input Value
+		want  string
+	}{
+		{name: "Null", input: Null, want: "null"},
+		{name: "Bool", input: True, want: "true"},
+		{name: "Integer", input: Int(-12), want: "-12"},
+		{name: "Fraction", input: Float(0.25), want: "0.25"},
+		{name: "String", input: String("a\"b"), want: `"a\"b"`},
+		{name: "List", input: List(Int(1), String("a")), want: `[1, "a"]`},
+		{name: "Map", input: Map(map[string]Value{"b": Int(2), "a": Int(1)}), want: `{"a" = 1, "b" = 2}`},
+		{name: "Function", input: Func(&Function{Name: "f"}), want: "<function f>"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.input.GoString())
+		})
+	}
+}
+
+func TestFloat(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Float(0.5).Equals(Number(big.NewFloat(0.5))))
+	assert.PanicsWithValue(t, "value: NaN is not a finite number", func() { Float(math.NaN()) })
+	assert.PanicsWithValue(t, "value: +Inf is not a finite number", func() { Float(math.Inf(1)) })
+}
+
+func TestFunction_Call(t *testing.T) {
+	t.Parallel()
+
+	fn := &Function{
+		Name:     "f",
+		Params:   []Param{{Name: "a"}, {Name: "b", AllowNull: true}},
+		VarParam: &Param{Name: "rest"},
+		Impl: func(args []Value) (Value, error) {
+			return Int(int64(len(args))), nil
+		},
+	}
+
+	res, err := fn.Call([]Value{Int(1), Null, Int(3), Int(4)})
+	assert.NoError(t, err)
+	assert.True(t, res.Equals(Int(4)))
+
+	_, err = fn.Call([]Value{Int(1)})
+	assert.ErrorIs(t, err, ErrArity)
+
+	_, err = fn.Call([]Value{Null, Int(2)})
+	assert.ErrorIs(t, err, ErrArgument)
+
+	_, err = fn.Call([]Value{Int(1), Int(2), Null})
+	assert.ErrorIs(t, err, ErrArgument)
+
+	typed := &Function{
+		Name:   "g",
+		Params: []Param{{Name: "a", Type: TypeNumber, AllowNull: true}},
+		Impl: func(args []Value) (Value, error) {
+			return args[0], nil
+		},
+	}
+
+	_, err = typed.Call([]Value{Int(1)})
+	assert.NoError(t, err)
+
+	_, err = typed.Call([]Value{Null})
+	assert.NoError(t, err)
+
+	_, err = typed.Call([]Value{String("1")})
+	assert.ErrorIs(t, err, ErrArgument)
+}
+
+func TestFunction_IsLazy(t *testing.T) {
+	t.Parallel()
+
+	fn := &Function{
+		Name:     "f",
+		Params:   []Param{{Name: "a"}, {Name: "b", Lazy: true}},
+		VarParam: &Param{Name: "rest", Lazy: true},
+	}
+
+	assert.False(t, fn.IsLazy(0))
+	assert.True(t, fn.IsLazy(1))
+	assert.True(t, fn.IsLazy(5))
+
+	fixed := &Function{Name: "g", Params: []Param{{Name: "a", Lazy: true}}}
+	assert.True(t, fixed.IsLazy(0))
+	assert.False(t, fixed.IsLazy(1))
+}